@@ -1,8 +1,11 @@
 package main
 
 import (
+	"context"
 	"log"
 	"os"
+	"os/signal"
+	"syscall"
 
 	"product-service/internal/httpserver"
 )
@@ -18,10 +21,13 @@ func main() {
 		port = "8080"
 	}
 
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
 	addr := ":" + port
 	log.Printf("Product service starting on port %s", port)
-	
-	if err := server.Run(addr); err != nil {
-		log.Fatalf("Server failed to start: %v", err)
+
+	if err := server.Run(ctx, addr); err != nil {
+		log.Fatalf("Server failed: %v", err)
 	}
-}
\ No newline at end of file
+}
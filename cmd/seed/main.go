@@ -0,0 +1,94 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+
+	"product-service/internal/auth"
+	"product-service/internal/database"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/service"
+)
+
+type summary struct {
+	Created int
+	Skipped int
+	Failed  int
+}
+
+func main() {
+	path := flag.String("file", "fixtures/products.json", "path to the JSON product fixture file")
+	flag.Parse()
+
+	f, err := os.Open(*path)
+	if err != nil {
+		log.Fatalf("Failed to open fixture file: %v", err)
+	}
+	defer f.Close()
+
+	fixtures, err := loadFixtures(f)
+	if err != nil {
+		log.Fatalf("Failed to parse fixture file: %v", err)
+	}
+
+	db, err := database.NewDynamoDBClient()
+	if err != nil {
+		log.Fatalf("Failed to create DynamoDB client: %v", err)
+	}
+
+	repo := repository.NewProductRepository(db)
+	svc := service.NewProductService(repo)
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: auth.System})
+	result := seed(ctx, svc, repo, fixtures)
+	log.Printf("Seeding finished: %d created, %d skipped, %d failed", result.Created, result.Skipped, result.Failed)
+
+	if result.Failed > 0 {
+		os.Exit(1)
+	}
+}
+
+func loadFixtures(r io.Reader) ([]models.CreateProductRequest, error) {
+	var fixtures []models.CreateProductRequest
+	if err := json.NewDecoder(r).Decode(&fixtures); err != nil {
+		return nil, fmt.Errorf("failed to decode fixtures: %w", err)
+	}
+	return fixtures, nil
+}
+
+// seed creates every fixture whose SKU is not already present, so running it
+// repeatedly against the same table is safe.
+func seed(ctx context.Context, svc service.ProductService, repo repository.ProductRepository, fixtures []models.CreateProductRequest) summary {
+	var result summary
+
+	for _, req := range fixtures {
+		existing, err := repo.GetBySKU(ctx, req.SKU)
+		if err != nil {
+			log.Printf("Failed to look up SKU %s: %v", req.SKU, err)
+			result.Failed++
+			continue
+		}
+		if existing != nil {
+			log.Printf("Skipping SKU %s: already exists as %s", req.SKU, existing.ID)
+			result.Skipped++
+			continue
+		}
+
+		product, err := svc.CreateProduct(ctx, req)
+		if err != nil {
+			log.Printf("Failed to create SKU %s: %v", req.SKU, err)
+			result.Failed++
+			continue
+		}
+		log.Printf("Created SKU %s as %s", req.SKU, product.ID)
+		result.Created++
+	}
+
+	return result
+}
@@ -0,0 +1,73 @@
+package main
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/service"
+)
+
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func TestLoadFixtures(t *testing.T) {
+	input := `[
+		{"name": "Mouse", "price": 24.99, "category": "electronics", "sku": "ELEC-0001", "stock": 5},
+		{"name": "Book", "description": "Paperback", "price": 9.5, "category": "books", "sku": "BOOK-0001", "stock": 2}
+	]`
+
+	fixtures, err := loadFixtures(strings.NewReader(input))
+
+	require.NoError(t, err)
+	assert.Len(t, fixtures, 2)
+	assert.Equal(t, "ELEC-0001", fixtures[0].SKU)
+	assert.Equal(t, "Paperback", fixtures[1].Description)
+	assert.Equal(t, 9.5, fixtures[1].Price)
+}
+
+func TestLoadFixtures_InvalidJSON(t *testing.T) {
+	_, err := loadFixtures(strings.NewReader("not json"))
+
+	assert.Error(t, err)
+}
+
+func TestSeed_SkipsExistingSKUs(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	svc := service.NewProductService(repo)
+
+	fixtures := []models.CreateProductRequest{
+		{Name: "Mouse", Price: 24.99, Category: "electronics", SKU: "ELEC-0001", Stock: floatPtr(5)},
+		{Name: "Book", Price: 9.5, Category: "books", SKU: "BOOK-0001", Stock: floatPtr(2)},
+	}
+
+	first := seed(context.Background(), svc, repo, fixtures)
+	assert.Equal(t, summary{Created: 2}, first)
+
+	second := seed(context.Background(), svc, repo, fixtures)
+	assert.Equal(t, summary{Skipped: 2}, second)
+
+	products, err := repo.GetAll(context.Background())
+	require.NoError(t, err)
+	assert.Len(t, products.Products, 2)
+}
+
+func TestSeed_CountsInvalidFixturesAsFailed(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	svc := service.NewProductService(repo)
+
+	fixtures := []models.CreateProductRequest{
+		{Name: "", Price: 24.99, Category: "electronics", SKU: "ELEC-0001", Stock: floatPtr(5)},
+	}
+
+	result := seed(context.Background(), svc, repo, fixtures)
+
+	assert.Equal(t, summary{Failed: 1}, result)
+}
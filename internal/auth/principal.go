@@ -0,0 +1,46 @@
+package auth
+
+import "context"
+
+// Actor IDs recorded when a change is not made on behalf of a known user.
+const (
+	Anonymous = "anonymous"
+	System    = "system"
+)
+
+// RoleAdmin is the role of a caller holding the admin token.
+const RoleAdmin = "admin"
+
+// Principal identifies the caller a request is made on behalf of.
+type Principal struct {
+	ID   string
+	Role string // e.g. RoleAdmin; empty for a caller without one
+}
+
+type principalKey struct{}
+
+func WithPrincipal(ctx context.Context, p Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, p)
+}
+
+func PrincipalFromContext(ctx context.Context) (Principal, bool) {
+	p, ok := ctx.Value(principalKey{}).(Principal)
+	return p, ok && p.ID != ""
+}
+
+// ActorID returns the ID of the principal in ctx, or Anonymous if there is
+// none.
+func ActorID(ctx context.Context) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.ID
+	}
+	return Anonymous
+}
+
+// Role returns the role of the principal in ctx, or "" if there is none.
+func Role(ctx context.Context) string {
+	if p, ok := PrincipalFromContext(ctx); ok {
+		return p.Role
+	}
+	return ""
+}
@@ -0,0 +1,566 @@
+package config
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"os"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config holds the service settings read from the environment. Zero values
+// preserve the behavior the service had before the setting existed.
+type Config struct {
+	MaxStock     float64 // 0 means unbounded
+	DefaultStock float64 // stock of products created without one
+
+	MinNameLen        int // shortest accepted product name, in characters
+	MaxDescriptionLen int // longest accepted description, in characters; 0 means unbounded
+	MaxTags           int // most tags a product may carry; 0 means unbounded
+	MaxTagLen         int // longest accepted tag, in characters; 0 means unbounded
+
+	CategoryMinPrice  map[string]float64 // price floors by category; nil means none
+	CategorySKUPrefix map[string]string  // required SKU prefixes by category; nil means none
+	CategoryParents   map[string]string  // each category's parent in the taxonomy; nil means flat, otherwise products must use a listed category
+
+	SKUSequenceTable string // DynamoDB table of SKU counters; empty disables generated SKUs
+
+	DefaultCurrency  string         // ISO 4217 code prices are held in; default "USD"
+	CurrencyDecimals map[string]int // minor-unit overrides by currency code; nil means ISO 4217
+
+	// RegionCurrencies lists the currencies each region may price in, e.g.
+	// {"eu":["EUR"]}. A write's region is taken from its X-Region header,
+	// falling back to DefaultRegion when the header is missing or names an
+	// unlisted region. nil leaves every region unrestricted.
+	RegionCurrencies map[string][]string
+	DefaultRegion    string
+
+	LowStockThreshold float64 // stock at or below which products report "low_stock"; 0 disables it
+
+	JSONFieldNaming string // "snake" (default) or "camel"
+	JSONOmitEmpty   bool
+	StrictJSON      bool // reject unknown request body fields
+
+	// FieldAccess maps product response fields to the caller roles allowed
+	// to see them, e.g. {"created_by":["admin"]}. Unlisted fields are
+	// public; nil makes every field public. The only role a caller can hold
+	// is "admin", granted by presenting ADMIN_TOKEN.
+	FieldAccess map[string][]string
+
+	// FeatureFlags lists the audiences each feature flag is on for:
+	// "*" for everyone, "role:<role>", "actor:<id>", or "opt-in" for
+	// requests naming the flag in X-Features, e.g.
+	// {"strict_json":["role:admin","opt-in"]}. nil turns every flag off.
+	FeatureFlags map[string][]string
+
+	ArchiveInactiveAfter time.Duration // 0 disables archival
+	ArchiveInterval      time.Duration
+
+	ReconcileInterval time.Duration // 0 disables the counter reconciliation sweep
+
+	ReservationSweepInterval time.Duration // 0 disables releasing expired stock reservations
+
+	CacheTTL time.Duration // 0 disables the product cache
+
+	// AdminToken guards the /admin endpoints. Empty leaves them unmounted.
+	AdminToken string
+
+	ScanMaxItems int // 0 means uncapped
+
+	// MaxBatchSize bounds how many items one batch request may carry;
+	// default 100. It is independent of how the items are chunked into
+	// DynamoDB calls.
+	MaxBatchSize int
+	ScanSegments int // parallel segments for full exports; 1 scans sequentially
+
+	// ScanCapacityBudget caps the read capacity units one listing scan may
+	// consume. 0 means unlimited.
+	ScanCapacityBudget float64
+
+	// DynamoMaxConcurrency caps the DynamoDB calls in flight at once; 0
+	// means unbounded. A call beyond the cap waits for a slot, or fails at
+	// once when DynamoFailFast is set.
+	DynamoMaxConcurrency int
+	DynamoFailFast       bool
+
+	SanitizeMode string // "strip" (default) or "reject"
+
+	DefaultSort string // e.g. "created_at:desc"; empty keeps scan order
+
+	IDScheme string // "uuid" (default) or "ulid"
+
+	RegenerateSlug bool // regenerate a product's slug when it is renamed
+
+	AutoDeactivateOOS bool // deactivate products updated to zero stock and reactivate them on restock
+
+	PublishEvents    bool          // log a product change event after every write
+	EventBatchWindow time.Duration // coalesce events over this window; 0 publishes each one
+
+	ImageBucket    string        // empty serves raw image keys instead of signed URLs
+	ImageURLExpiry time.Duration // lifetime of signed image URLs
+	MaxImageSize   int64         // largest accepted image upload, in bytes
+
+	CheckImageURLs bool // HEAD image URLs in the background and flag broken ones
+	ImageCheckRate int  // image URL checks per second
+
+	DisableHardDelete bool
+
+	PaginationHeaders    bool   // default true
+	PaginationTotalCount string // X-Total-Count strategy: "none" (default), "estimate" or "exact"
+
+	RequestTimeout time.Duration // 0 disables the per-request deadline
+
+	// Connection limits of the HTTP server. 0 disables the timeout; an
+	// unset IdleTimeout falls back to ReadTimeout, as in net/http.
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	KeepAlive         bool // default true; false closes each connection after one response
+
+	// Cache-Control max-age of public GET responses for product listings
+	// and for single products. 0 makes caches revalidate on every use.
+	ListingCacheMaxAge time.Duration // default 30s
+	ProductCacheMaxAge time.Duration // default 5m
+
+	PublicBaseURL string // e.g. "https://api.example.com"; empty makes Location headers relative
+
+	// Headers and query parameters whose values are replaced with *** in
+	// request logs, in addition to the server's defaults.
+	LogRedactHeaders     []string
+	LogRedactQueryParams []string
+
+	// LogSampleRate is the fraction of successful requests written to the
+	// request log, between 0 and 1. Failed requests are always logged.
+	// Default 1.
+	LogSampleRate float64
+}
+
+var currencyCode = regexp.MustCompile(`^[A-Z]{3}$`)
+
+// validAudience reports whether a names who a feature flag is on for.
+func validAudience(a string) bool {
+	if a == "*" || a == "opt-in" {
+		return true
+	}
+	for _, prefix := range []string{"role:", "actor:"} {
+		if name, ok := strings.CutPrefix(a, prefix); ok {
+			return name != ""
+		}
+	}
+	return false
+}
+
+func FromEnv() (Config, error) {
+	var cfg Config
+	var err error
+
+	if cfg.MaxStock, err = floatEnv("MAX_STOCK", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxStock < 0 {
+		return Config{}, fmt.Errorf("MAX_STOCK must not be negative")
+	}
+	if cfg.DefaultStock, err = floatEnv("DEFAULT_STOCK", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.DefaultStock < 0 {
+		return Config{}, fmt.Errorf("DEFAULT_STOCK must not be negative")
+	}
+	if cfg.MaxStock > 0 && cfg.DefaultStock > cfg.MaxStock {
+		return Config{}, fmt.Errorf("DEFAULT_STOCK must not exceed MAX_STOCK")
+	}
+
+	if cfg.MinNameLen, err = intEnv("MIN_NAME_LEN", 1); err != nil {
+		return Config{}, err
+	}
+	if cfg.MinNameLen < 1 {
+		return Config{}, fmt.Errorf("MIN_NAME_LEN must be at least 1")
+	}
+	if cfg.MaxDescriptionLen, err = intEnv("MAX_DESCRIPTION_LEN", 5000); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxDescriptionLen < 0 {
+		return Config{}, fmt.Errorf("MAX_DESCRIPTION_LEN must not be negative")
+	}
+	if cfg.MaxTags, err = intEnv("MAX_TAGS", 20); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxTags < 0 {
+		return Config{}, fmt.Errorf("MAX_TAGS must not be negative")
+	}
+	if cfg.MaxTagLen, err = intEnv("MAX_TAG_LEN", 50); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxTagLen < 0 {
+		return Config{}, fmt.Errorf("MAX_TAG_LEN must not be negative")
+	}
+
+	if raw := os.Getenv("CATEGORY_MIN_PRICE"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.CategoryMinPrice); err != nil {
+			return Config{}, fmt.Errorf("invalid CATEGORY_MIN_PRICE: %w", err)
+		}
+		for category, floor := range cfg.CategoryMinPrice {
+			if floor < 0 {
+				return Config{}, fmt.Errorf("CATEGORY_MIN_PRICE for %q must not be negative", category)
+			}
+		}
+	}
+
+	if raw := os.Getenv("CATEGORY_SKU_PREFIX"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.CategorySKUPrefix); err != nil {
+			return Config{}, fmt.Errorf("invalid CATEGORY_SKU_PREFIX: %w", err)
+		}
+		for category, prefix := range cfg.CategorySKUPrefix {
+			if prefix == "" {
+				return Config{}, fmt.Errorf("CATEGORY_SKU_PREFIX for %q must not be empty", category)
+			}
+		}
+	}
+
+	cfg.SKUSequenceTable = os.Getenv("SKU_SEQUENCE_TABLE")
+	if cfg.SKUSequenceTable != "" && len(cfg.CategorySKUPrefix) == 0 {
+		return Config{}, fmt.Errorf("SKU_SEQUENCE_TABLE requires CATEGORY_SKU_PREFIX")
+	}
+
+	if raw := os.Getenv("CATEGORY_PARENTS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.CategoryParents); err != nil {
+			return Config{}, fmt.Errorf("invalid CATEGORY_PARENTS: %w", err)
+		}
+	}
+
+	cfg.DefaultCurrency = strings.ToUpper(stringEnv("DEFAULT_CURRENCY", "USD"))
+	if !currencyCode.MatchString(cfg.DefaultCurrency) {
+		return Config{}, fmt.Errorf("invalid DEFAULT_CURRENCY %q: must be a three-letter code", cfg.DefaultCurrency)
+	}
+	if raw := os.Getenv("CURRENCY_DECIMALS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.CurrencyDecimals); err != nil {
+			return Config{}, fmt.Errorf("invalid CURRENCY_DECIMALS: %w", err)
+		}
+		for code, decimals := range cfg.CurrencyDecimals {
+			if decimals < 0 || decimals > 4 {
+				return Config{}, fmt.Errorf("CURRENCY_DECIMALS for %q must be between 0 and 4", code)
+			}
+		}
+	}
+	if raw := os.Getenv("REGION_CURRENCIES"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.RegionCurrencies); err != nil {
+			return Config{}, fmt.Errorf("invalid REGION_CURRENCIES: %w", err)
+		}
+		for region, codes := range cfg.RegionCurrencies {
+			for _, code := range codes {
+				if !currencyCode.MatchString(strings.ToUpper(code)) {
+					return Config{}, fmt.Errorf("invalid REGION_CURRENCIES currency %q for %q: must be a three-letter code", code, region)
+				}
+			}
+		}
+	}
+	cfg.DefaultRegion = os.Getenv("DEFAULT_REGION")
+
+	if cfg.LowStockThreshold, err = floatEnv("LOW_STOCK_THRESHOLD", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.LowStockThreshold < 0 {
+		return Config{}, fmt.Errorf("LOW_STOCK_THRESHOLD must not be negative")
+	}
+
+	cfg.JSONFieldNaming = stringEnv("JSON_FIELD_NAMING", "snake")
+	if cfg.JSONOmitEmpty, err = boolEnv("JSON_OMIT_EMPTY", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.StrictJSON, err = boolEnv("STRICT_JSON", false); err != nil {
+		return Config{}, err
+	}
+	if raw := os.Getenv("FIELD_ACCESS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.FieldAccess); err != nil {
+			return Config{}, fmt.Errorf("invalid FIELD_ACCESS: %w", err)
+		}
+		for field, roles := range cfg.FieldAccess {
+			if slices.Contains(roles, "") {
+				return Config{}, fmt.Errorf("FIELD_ACCESS roles for %q must not be empty", field)
+			}
+		}
+	}
+
+	if raw := os.Getenv("FEATURE_FLAGS"); raw != "" {
+		if err := json.Unmarshal([]byte(raw), &cfg.FeatureFlags); err != nil {
+			return Config{}, fmt.Errorf("invalid FEATURE_FLAGS: %w", err)
+		}
+		for flag, audiences := range cfg.FeatureFlags {
+			for _, audience := range audiences {
+				if !validAudience(audience) {
+					return Config{}, fmt.Errorf("invalid FEATURE_FLAGS audience %q for %q", audience, flag)
+				}
+			}
+		}
+	}
+
+	if cfg.ArchiveInactiveAfter, err = durationEnv("ARCHIVE_INACTIVE_AFTER", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.ArchiveInterval, err = durationEnv("ARCHIVE_INTERVAL", time.Hour); err != nil {
+		return Config{}, err
+	}
+	if cfg.ArchiveInterval <= 0 {
+		return Config{}, fmt.Errorf("ARCHIVE_INTERVAL must be positive")
+	}
+
+	if cfg.ReconcileInterval, err = durationEnv("RECONCILE_INTERVAL", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReconcileInterval < 0 {
+		return Config{}, fmt.Errorf("RECONCILE_INTERVAL must not be negative")
+	}
+
+	if cfg.ReservationSweepInterval, err = durationEnv("RESERVATION_SWEEP_INTERVAL", time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.ReservationSweepInterval < 0 {
+		return Config{}, fmt.Errorf("RESERVATION_SWEEP_INTERVAL must not be negative")
+	}
+
+	if cfg.CacheTTL, err = durationEnv("CACHE_TTL", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.CacheTTL < 0 {
+		return Config{}, fmt.Errorf("CACHE_TTL must not be negative")
+	}
+	cfg.AdminToken = os.Getenv("ADMIN_TOKEN")
+
+	if cfg.MaxBatchSize, err = intEnv("MAX_BATCH_SIZE", 100); err != nil {
+		return Config{}, err
+	}
+	if cfg.MaxBatchSize <= 0 {
+		return Config{}, fmt.Errorf("MAX_BATCH_SIZE must be positive")
+	}
+	if cfg.ScanMaxItems, err = intEnv("SCAN_MAX_ITEMS", 10000); err != nil {
+		return Config{}, err
+	}
+	if cfg.ScanMaxItems < 0 {
+		return Config{}, fmt.Errorf("SCAN_MAX_ITEMS must not be negative")
+	}
+	if cfg.ScanSegments, err = intEnv("SCAN_SEGMENTS", 1); err != nil {
+		return Config{}, err
+	}
+	// DynamoDB allows up to 1,000,000 segments, but each one here is a
+	// goroutine with its own page in flight.
+	if cfg.ScanSegments < 1 || cfg.ScanSegments > 64 {
+		return Config{}, fmt.Errorf("SCAN_SEGMENTS must be between 1 and 64")
+	}
+	if cfg.ScanCapacityBudget, err = floatEnv("SCAN_CAPACITY_BUDGET", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.ScanCapacityBudget < 0 {
+		return Config{}, fmt.Errorf("SCAN_CAPACITY_BUDGET must not be negative")
+	}
+	if cfg.DynamoMaxConcurrency, err = intEnv("DYNAMO_MAX_CONCURRENCY", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.DynamoMaxConcurrency < 0 {
+		return Config{}, fmt.Errorf("DYNAMO_MAX_CONCURRENCY must not be negative")
+	}
+	if cfg.DynamoFailFast, err = boolEnv("DYNAMO_FAIL_FAST", false); err != nil {
+		return Config{}, err
+	}
+
+	cfg.SanitizeMode = stringEnv("SANITIZE_MODE", "strip")
+	cfg.DefaultSort = stringEnv("DEFAULT_SORT", "")
+	cfg.IDScheme = stringEnv("ID_SCHEME", "uuid")
+	if cfg.RegenerateSlug, err = boolEnv("REGENERATE_SLUG", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.AutoDeactivateOOS, err = boolEnv("AUTO_DEACTIVATE_OOS", false); err != nil {
+		return Config{}, err
+	}
+
+	if cfg.PublishEvents, err = boolEnv("PUBLISH_EVENTS", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.EventBatchWindow, err = durationEnv("EVENT_BATCH_WINDOW", 0); err != nil {
+		return Config{}, err
+	}
+	if cfg.EventBatchWindow < 0 {
+		return Config{}, fmt.Errorf("EVENT_BATCH_WINDOW must not be negative")
+	}
+
+	cfg.ImageBucket = stringEnv("IMAGE_BUCKET", "")
+	if cfg.ImageURLExpiry, err = durationEnv("IMAGE_URL_EXPIRY", 15*time.Minute); err != nil {
+		return Config{}, err
+	}
+	// S3 rejects presigned URLs valid for longer than seven days.
+	if cfg.ImageURLExpiry <= 0 || cfg.ImageURLExpiry > 7*24*time.Hour {
+		return Config{}, fmt.Errorf("IMAGE_URL_EXPIRY must be positive and at most 168h")
+	}
+	maxImageSize, err := intEnv("MAX_IMAGE_SIZE", 5<<20)
+	if err != nil {
+		return Config{}, err
+	}
+	if maxImageSize <= 0 {
+		return Config{}, fmt.Errorf("MAX_IMAGE_SIZE must be positive")
+	}
+	cfg.MaxImageSize = int64(maxImageSize)
+	if cfg.CheckImageURLs, err = boolEnv("CHECK_IMAGE_URLS", false); err != nil {
+		return Config{}, err
+	}
+	if cfg.ImageCheckRate, err = intEnv("IMAGE_CHECK_RATE", 5); err != nil {
+		return Config{}, err
+	}
+	if cfg.ImageCheckRate < 1 {
+		return Config{}, fmt.Errorf("IMAGE_CHECK_RATE must be at least 1")
+	}
+
+	if cfg.DisableHardDelete, err = boolEnv("DISABLE_HARD_DELETE", false); err != nil {
+		return Config{}, err
+	}
+	// The archiver removes products outright, which the flag forbids.
+	if cfg.DisableHardDelete && cfg.ArchiveInactiveAfter > 0 {
+		return Config{}, fmt.Errorf("ARCHIVE_INACTIVE_AFTER cannot be set when DISABLE_HARD_DELETE is on")
+	}
+
+	if cfg.PaginationHeaders, err = boolEnv("PAGINATION_HEADERS", true); err != nil {
+		return Config{}, err
+	}
+	cfg.PaginationTotalCount = stringEnv("PAGINATION_TOTAL_COUNT", "none")
+	// Exact totals finish truncated listings with a COUNT scan of the rest
+	// of the table, which only the capacity budget bounds.
+	if strings.EqualFold(cfg.PaginationTotalCount, "exact") && cfg.ScanCapacityBudget == 0 {
+		return Config{}, fmt.Errorf("PAGINATION_TOTAL_COUNT=exact requires a SCAN_CAPACITY_BUDGET")
+	}
+
+	if cfg.RequestTimeout, err = durationEnv("REQUEST_TIMEOUT", 10*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.RequestTimeout < 0 {
+		return Config{}, fmt.Errorf("REQUEST_TIMEOUT must not be negative")
+	}
+
+	for _, timeout := range []struct {
+		key  string
+		def  time.Duration
+		dest *time.Duration
+	}{
+		{"HTTP_READ_HEADER_TIMEOUT", 5 * time.Second, &cfg.ReadHeaderTimeout},
+		{"HTTP_READ_TIMEOUT", 30 * time.Second, &cfg.ReadTimeout},
+		{"HTTP_WRITE_TIMEOUT", 30 * time.Second, &cfg.WriteTimeout},
+		{"HTTP_IDLE_TIMEOUT", 2 * time.Minute, &cfg.IdleTimeout},
+	} {
+		if *timeout.dest, err = durationEnv(timeout.key, timeout.def); err != nil {
+			return Config{}, err
+		}
+		if *timeout.dest < 0 {
+			return Config{}, fmt.Errorf("%s must not be negative", timeout.key)
+		}
+	}
+	if cfg.ListingCacheMaxAge, err = durationEnv("LISTING_CACHE_MAX_AGE", 30*time.Second); err != nil {
+		return Config{}, err
+	}
+	if cfg.ProductCacheMaxAge, err = durationEnv("PRODUCT_CACHE_MAX_AGE", 5*time.Minute); err != nil {
+		return Config{}, err
+	}
+	if cfg.ListingCacheMaxAge < 0 || cfg.ProductCacheMaxAge < 0 {
+		return Config{}, fmt.Errorf("LISTING_CACHE_MAX_AGE and PRODUCT_CACHE_MAX_AGE must not be negative")
+	}
+
+	// The write deadline starts when the request headers are read, so it
+	// must leave room for the handler to time out and write its 504.
+	if cfg.WriteTimeout > 0 && cfg.RequestTimeout > 0 && cfg.WriteTimeout <= cfg.RequestTimeout {
+		return Config{}, fmt.Errorf("HTTP_WRITE_TIMEOUT must be longer than REQUEST_TIMEOUT")
+	}
+	if cfg.KeepAlive, err = boolEnv("HTTP_KEEP_ALIVE", true); err != nil {
+		return Config{}, err
+	}
+
+	cfg.PublicBaseURL = os.Getenv("PUBLIC_BASE_URL")
+	if cfg.PublicBaseURL != "" {
+		u, err := url.Parse(cfg.PublicBaseURL)
+		if err != nil || u.Scheme == "" || u.Host == "" {
+			return Config{}, fmt.Errorf("invalid PUBLIC_BASE_URL %q: must be an absolute URL", cfg.PublicBaseURL)
+		}
+	}
+
+	cfg.LogRedactHeaders = listEnv("LOG_REDACT_HEADERS")
+	cfg.LogRedactQueryParams = listEnv("LOG_REDACT_QUERY_PARAMS")
+	if cfg.LogSampleRate, err = floatEnv("LOG_SAMPLE_RATE", 1); err != nil {
+		return Config{}, err
+	}
+	if cfg.LogSampleRate < 0 || cfg.LogSampleRate > 1 {
+		return Config{}, fmt.Errorf("LOG_SAMPLE_RATE must be between 0 and 1")
+	}
+
+	return cfg, nil
+}
+
+func stringEnv(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// listEnv splits a comma-separated variable, dropping empty entries. It
+// returns nil when the variable is unset.
+func listEnv(key string) []string {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return nil
+	}
+	var list []string
+	for _, item := range strings.Split(raw, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			list = append(list, item)
+		}
+	}
+	return list
+}
+
+func boolEnv(key string, def bool) (bool, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+	}
+	return v, nil
+}
+
+func intEnv(key string, def int) (int, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.Atoi(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+	}
+	return v, nil
+}
+
+func floatEnv(key string, def float64) (float64, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+	}
+	return v, nil
+}
+
+func durationEnv(key string, def time.Duration) (time.Duration, error) {
+	raw := os.Getenv(key)
+	if raw == "" {
+		return def, nil
+	}
+	v, err := time.ParseDuration(raw)
+	if err != nil {
+		return 0, fmt.Errorf("invalid %s %q: %w", key, raw, err)
+	}
+	return v, nil
+}
@@ -0,0 +1,571 @@
+package config
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFromEnv_Defaults(t *testing.T) {
+	t.Setenv("MAX_STOCK", "")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Zero(t, cfg.MaxStock)
+	assert.Zero(t, cfg.DefaultStock)
+	assert.Equal(t, 1, cfg.MinNameLen)
+	assert.Equal(t, 5000, cfg.MaxDescriptionLen)
+	assert.Equal(t, 20, cfg.MaxTags)
+	assert.Equal(t, 50, cfg.MaxTagLen)
+	assert.Nil(t, cfg.CategoryMinPrice)
+	assert.Nil(t, cfg.CategorySKUPrefix)
+	assert.Empty(t, cfg.SKUSequenceTable)
+	assert.Nil(t, cfg.CategoryParents)
+	assert.Equal(t, "USD", cfg.DefaultCurrency)
+	assert.Nil(t, cfg.CurrencyDecimals)
+	assert.Nil(t, cfg.RegionCurrencies)
+	assert.Empty(t, cfg.DefaultRegion)
+	assert.Zero(t, cfg.LowStockThreshold)
+	assert.Equal(t, "snake", cfg.JSONFieldNaming)
+	assert.False(t, cfg.JSONOmitEmpty)
+	assert.False(t, cfg.StrictJSON)
+	assert.Nil(t, cfg.FieldAccess)
+	assert.Nil(t, cfg.FeatureFlags)
+	assert.Zero(t, cfg.ArchiveInactiveAfter)
+	assert.Equal(t, time.Hour, cfg.ArchiveInterval)
+	assert.Zero(t, cfg.ReconcileInterval)
+	assert.Equal(t, time.Minute, cfg.ReservationSweepInterval)
+	assert.Zero(t, cfg.CacheTTL)
+	assert.Empty(t, cfg.AdminToken)
+	assert.Equal(t, 10000, cfg.ScanMaxItems)
+	assert.Equal(t, 100, cfg.MaxBatchSize)
+	assert.Equal(t, 1, cfg.ScanSegments)
+	assert.Zero(t, cfg.ScanCapacityBudget)
+	assert.Zero(t, cfg.DynamoMaxConcurrency)
+	assert.False(t, cfg.DynamoFailFast)
+	assert.Equal(t, "strip", cfg.SanitizeMode)
+	assert.Empty(t, cfg.DefaultSort)
+	assert.Equal(t, "uuid", cfg.IDScheme)
+	assert.False(t, cfg.RegenerateSlug)
+	assert.False(t, cfg.AutoDeactivateOOS)
+	assert.False(t, cfg.PublishEvents)
+	assert.Zero(t, cfg.EventBatchWindow)
+	assert.Empty(t, cfg.ImageBucket)
+	assert.Equal(t, 15*time.Minute, cfg.ImageURLExpiry)
+	assert.Equal(t, int64(5<<20), cfg.MaxImageSize)
+	assert.False(t, cfg.CheckImageURLs)
+	assert.Equal(t, 5, cfg.ImageCheckRate)
+	assert.False(t, cfg.DisableHardDelete)
+	assert.True(t, cfg.PaginationHeaders)
+	assert.Equal(t, "none", cfg.PaginationTotalCount)
+	assert.Equal(t, 10*time.Second, cfg.RequestTimeout)
+	assert.Equal(t, 5*time.Second, cfg.ReadHeaderTimeout)
+	assert.Equal(t, 30*time.Second, cfg.ReadTimeout)
+	assert.Equal(t, 30*time.Second, cfg.WriteTimeout)
+	assert.Equal(t, 2*time.Minute, cfg.IdleTimeout)
+	assert.True(t, cfg.KeepAlive)
+	assert.Equal(t, 30*time.Second, cfg.ListingCacheMaxAge)
+	assert.Equal(t, 5*time.Minute, cfg.ProductCacheMaxAge)
+	assert.Empty(t, cfg.PublicBaseURL)
+	assert.Nil(t, cfg.LogRedactHeaders)
+	assert.Nil(t, cfg.LogRedactQueryParams)
+	assert.Equal(t, 1.0, cfg.LogSampleRate)
+}
+
+func TestFromEnv_CacheMaxAge(t *testing.T) {
+	t.Setenv("LISTING_CACHE_MAX_AGE", "0s")
+	t.Setenv("PRODUCT_CACHE_MAX_AGE", "1h")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Zero(t, cfg.ListingCacheMaxAge)
+	assert.Equal(t, time.Hour, cfg.ProductCacheMaxAge)
+
+	t.Setenv("PRODUCT_CACHE_MAX_AGE", "-1s")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_PublicBaseURL(t *testing.T) {
+	t.Setenv("PUBLIC_BASE_URL", "https://api.example.com")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "https://api.example.com", cfg.PublicBaseURL)
+
+	t.Setenv("PUBLIC_BASE_URL", "api.example.com")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_LogRedaction(t *testing.T) {
+	t.Setenv("LOG_REDACT_HEADERS", "Authorization, X-Session ,")
+	t.Setenv("LOG_REDACT_QUERY_PARAMS", "signature")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Authorization", "X-Session"}, cfg.LogRedactHeaders)
+	assert.Equal(t, []string{"signature"}, cfg.LogRedactQueryParams)
+}
+
+func TestFromEnv_LogSampleRate(t *testing.T) {
+	t.Setenv("LOG_SAMPLE_RATE", "0.05")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 0.05, cfg.LogSampleRate)
+
+	for _, invalid := range []string{"-0.1", "1.5", "half"} {
+		t.Setenv("LOG_SAMPLE_RATE", invalid)
+		_, err = FromEnv()
+		assert.Error(t, err, invalid)
+	}
+}
+
+func TestFromEnv_AutoDeactivateOOS(t *testing.T) {
+	t.Setenv("AUTO_DEACTIVATE_OOS", "true")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.True(t, cfg.AutoDeactivateOOS)
+
+	t.Setenv("AUTO_DEACTIVATE_OOS", "sometimes")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_DynamoConcurrency(t *testing.T) {
+	t.Setenv("DYNAMO_MAX_CONCURRENCY", "64")
+	t.Setenv("DYNAMO_FAIL_FAST", "true")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 64, cfg.DynamoMaxConcurrency)
+	assert.True(t, cfg.DynamoFailFast)
+
+	t.Setenv("DYNAMO_MAX_CONCURRENCY", "-1")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_MaxStock(t *testing.T) {
+	t.Setenv("MAX_STOCK", "5000")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(5000), cfg.MaxStock)
+}
+
+func TestFromEnv_DefaultStock(t *testing.T) {
+	t.Setenv("DEFAULT_STOCK", "25")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(25), cfg.DefaultStock)
+
+	t.Setenv("MAX_STOCK", "10")
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("MAX_STOCK", "")
+	t.Setenv("DEFAULT_STOCK", "-1")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_TextLimits(t *testing.T) {
+	t.Setenv("MIN_NAME_LEN", "3")
+	t.Setenv("MAX_DESCRIPTION_LEN", "0")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, cfg.MinNameLen)
+	assert.Zero(t, cfg.MaxDescriptionLen)
+
+	for key, raw := range map[string]string{"MIN_NAME_LEN": "0", "MAX_DESCRIPTION_LEN": "-1"} {
+		t.Setenv(key, raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, key)
+		t.Setenv(key, "")
+	}
+}
+
+func TestFromEnv_TagLimits(t *testing.T) {
+	t.Setenv("MAX_TAGS", "5")
+	t.Setenv("MAX_TAG_LEN", "0")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 5, cfg.MaxTags)
+	assert.Zero(t, cfg.MaxTagLen)
+
+	for key, raw := range map[string]string{"MAX_TAGS": "-1", "MAX_TAG_LEN": "-1"} {
+		t.Setenv(key, raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, key)
+		t.Setenv(key, "")
+	}
+}
+
+func TestFromEnv_LowStockThreshold(t *testing.T) {
+	t.Setenv("LOW_STOCK_THRESHOLD", "5")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(5), cfg.LowStockThreshold)
+
+	t.Setenv("LOW_STOCK_THRESHOLD", "-1")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_InvalidMaxStock(t *testing.T) {
+	for _, raw := range []string{"lots", "-1"} {
+		t.Setenv("MAX_STOCK", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_CategoryMinPrice(t *testing.T) {
+	t.Setenv("CATEGORY_MIN_PRICE", `{"electronics":5,"books":0.5}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]float64{"electronics": 5, "books": 0.5}, cfg.CategoryMinPrice)
+
+	for _, raw := range []string{"electronics=5", `{"electronics":-1}`} {
+		t.Setenv("CATEGORY_MIN_PRICE", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_CategorySKUPrefix(t *testing.T) {
+	t.Setenv("CATEGORY_SKU_PREFIX", `{"electronics":"ELEC-"}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"electronics": "ELEC-"}, cfg.CategorySKUPrefix)
+
+	for _, raw := range []string{"electronics=ELEC-", `{"electronics":""}`} {
+		t.Setenv("CATEGORY_SKU_PREFIX", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_SKUSequenceTable(t *testing.T) {
+	t.Setenv("SKU_SEQUENCE_TABLE", "sku-sequences")
+
+	_, err := FromEnv()
+	assert.ErrorContains(t, err, "requires CATEGORY_SKU_PREFIX")
+
+	t.Setenv("CATEGORY_SKU_PREFIX", `{"electronics":"ELEC-"}`)
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "sku-sequences", cfg.SKUSequenceTable)
+}
+
+func TestFromEnv_CategoryParents(t *testing.T) {
+	t.Setenv("CATEGORY_PARENTS", `{"phones":"electronics","accessories":"phones"}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"phones": "electronics", "accessories": "phones"}, cfg.CategoryParents)
+
+	t.Setenv("CATEGORY_PARENTS", "phones>electronics")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_Currency(t *testing.T) {
+	t.Setenv("DEFAULT_CURRENCY", "jpy")
+	t.Setenv("CURRENCY_DECIMALS", `{"USD":3}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", cfg.DefaultCurrency)
+	assert.Equal(t, map[string]int{"USD": 3}, cfg.CurrencyDecimals)
+
+	t.Setenv("CURRENCY_DECIMALS", `{"USD":9}`)
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("CURRENCY_DECIMALS", "")
+	t.Setenv("DEFAULT_CURRENCY", "dollars")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_RegionCurrencies(t *testing.T) {
+	t.Setenv("REGION_CURRENCIES", `{"eu":["EUR"],"ch":["CHF","eur"]}`)
+	t.Setenv("DEFAULT_REGION", "eu")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"eu": {"EUR"}, "ch": {"CHF", "eur"}}, cfg.RegionCurrencies)
+	assert.Equal(t, "eu", cfg.DefaultRegion)
+
+	t.Setenv("REGION_CURRENCIES", `{"eu":["euros"]}`)
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("REGION_CURRENCIES", `{"eu":"EUR"}`)
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_FeatureFlags(t *testing.T) {
+	t.Setenv("FEATURE_FLAGS", `{"strict_json":["role:admin","actor:user-7","opt-in"],"v2":["*"]}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{
+		"strict_json": {"role:admin", "actor:user-7", "opt-in"},
+		"v2":          {"*"},
+	}, cfg.FeatureFlags)
+
+	for _, raw := range []string{`{"strict_json":["admins"]}`, `{"strict_json":["role:"]}`, `{"strict_json":true}`} {
+		t.Setenv("FEATURE_FLAGS", raw)
+		_, err = FromEnv()
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_MaxBatchSize(t *testing.T) {
+	t.Setenv("MAX_BATCH_SIZE", "250")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 250, cfg.MaxBatchSize)
+
+	t.Setenv("MAX_BATCH_SIZE", "0")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_PaginationTotalCount(t *testing.T) {
+	t.Setenv("PAGINATION_TOTAL_COUNT", "estimate")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "estimate", cfg.PaginationTotalCount)
+
+	// Exact counts scan the rest of the table, so they need a budget.
+	t.Setenv("PAGINATION_TOTAL_COUNT", "exact")
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("SCAN_CAPACITY_BUDGET", "500")
+	cfg, err = FromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, "exact", cfg.PaginationTotalCount)
+}
+
+func TestFromEnv_HTTPServerLimits(t *testing.T) {
+	t.Setenv("HTTP_READ_HEADER_TIMEOUT", "2s")
+	t.Setenv("HTTP_READ_TIMEOUT", "1m")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "0")
+	t.Setenv("HTTP_IDLE_TIMEOUT", "5m")
+	t.Setenv("HTTP_KEEP_ALIVE", "false")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 2*time.Second, cfg.ReadHeaderTimeout)
+	assert.Equal(t, time.Minute, cfg.ReadTimeout)
+	assert.Zero(t, cfg.WriteTimeout)
+	assert.Equal(t, 5*time.Minute, cfg.IdleTimeout)
+	assert.False(t, cfg.KeepAlive)
+
+	t.Setenv("HTTP_READ_TIMEOUT", "-1s")
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("HTTP_READ_TIMEOUT", "1m")
+	t.Setenv("HTTP_WRITE_TIMEOUT", "10s")
+	_, err = FromEnv()
+	assert.ErrorContains(t, err, "HTTP_WRITE_TIMEOUT must be longer than REQUEST_TIMEOUT")
+}
+
+func TestFromEnv_JSONSettings(t *testing.T) {
+	t.Setenv("JSON_FIELD_NAMING", "camel")
+	t.Setenv("JSON_OMIT_EMPTY", "true")
+	t.Setenv("STRICT_JSON", "true")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "camel", cfg.JSONFieldNaming)
+	assert.True(t, cfg.JSONOmitEmpty)
+	assert.True(t, cfg.StrictJSON)
+}
+
+func TestFromEnv_FieldAccess(t *testing.T) {
+	t.Setenv("FIELD_ACCESS", `{"created_by":["admin"],"updated_by":["admin","auditor"]}`)
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string][]string{"created_by": {"admin"}, "updated_by": {"admin", "auditor"}}, cfg.FieldAccess)
+
+	t.Setenv("FIELD_ACCESS", `{"created_by":[""]}`)
+	_, err = FromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("FIELD_ACCESS", "created_by=admin")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_ScanSegments(t *testing.T) {
+	t.Setenv("SCAN_SEGMENTS", "8")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 8, cfg.ScanSegments)
+
+	for _, raw := range []string{"many", "0", "65"} {
+		t.Setenv("SCAN_SEGMENTS", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_ScanCapacityBudget(t *testing.T) {
+	t.Setenv("SCAN_CAPACITY_BUDGET", "12.5")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 12.5, cfg.ScanCapacityBudget)
+
+	for _, raw := range []string{"lots", "-1"} {
+		t.Setenv("SCAN_CAPACITY_BUDGET", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_Archive(t *testing.T) {
+	t.Setenv("ARCHIVE_INACTIVE_AFTER", "720h")
+	t.Setenv("ARCHIVE_INTERVAL", "15m")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 720*time.Hour, cfg.ArchiveInactiveAfter)
+	assert.Equal(t, 15*time.Minute, cfg.ArchiveInterval)
+
+	t.Setenv("ARCHIVE_INTERVAL", "0s")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_ReservationSweepInterval(t *testing.T) {
+	t.Setenv("RESERVATION_SWEEP_INTERVAL", "30s")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, 30*time.Second, cfg.ReservationSweepInterval)
+
+	t.Setenv("RESERVATION_SWEEP_INTERVAL", "0s")
+	cfg, err = FromEnv()
+	require.NoError(t, err)
+	assert.Zero(t, cfg.ReservationSweepInterval)
+
+	t.Setenv("RESERVATION_SWEEP_INTERVAL", "-1m")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
+
+func TestFromEnv_ImageURLExpiry(t *testing.T) {
+	t.Setenv("IMAGE_BUCKET", "product-images")
+	t.Setenv("IMAGE_URL_EXPIRY", "1h")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, "product-images", cfg.ImageBucket)
+	assert.Equal(t, time.Hour, cfg.ImageURLExpiry)
+
+	for _, raw := range []string{"soon", "0s", "169h"} {
+		t.Setenv("IMAGE_URL_EXPIRY", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_ImageChecks(t *testing.T) {
+	t.Setenv("CHECK_IMAGE_URLS", "true")
+	t.Setenv("IMAGE_CHECK_RATE", "2")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.True(t, cfg.CheckImageURLs)
+	assert.Equal(t, 2, cfg.ImageCheckRate)
+
+	for _, raw := range []string{"fast", "0"} {
+		t.Setenv("IMAGE_CHECK_RATE", raw)
+
+		_, err := FromEnv()
+
+		assert.Error(t, err, raw)
+	}
+}
+
+func TestFromEnv_DisableHardDelete(t *testing.T) {
+	t.Setenv("DISABLE_HARD_DELETE", "true")
+
+	cfg, err := FromEnv()
+
+	require.NoError(t, err)
+	assert.True(t, cfg.DisableHardDelete)
+
+	t.Setenv("ARCHIVE_INACTIVE_AFTER", "720h")
+	_, err = FromEnv()
+	assert.Error(t, err)
+}
@@ -3,15 +3,33 @@ package database
 import (
 	"fmt"
 	"os"
+	"strconv"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/aws/session"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 )
 
+// DynamoDBAPI is the subset of the DynamoDB client used by the repositories.
+// It is satisfied by *dynamodb.DynamoDB and can be mocked in tests. Only the
+// context-aware calls are included so request deadlines reach DynamoDB.
+type DynamoDBAPI interface {
+	PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error)
+	GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error)
+	BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error)
+	UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error)
+	ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error)
+	QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error)
+	DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error)
+}
+
 type DynamoDBClient struct {
-	Client    *dynamodb.DynamoDB
+	Client    DynamoDBAPI
 	TableName string
+
+	// tables backs DescribeTable; it is nil for clients built by hand.
+	tables describeTableAPI
 }
 
 func NewDynamoDBClient() (*DynamoDBClient, error) {
@@ -25,17 +43,44 @@ func NewDynamoDBClient() (*DynamoDBClient, error) {
 		tableName = "products-db"
 	}
 
-	sess, err := session.NewSession(&aws.Config{
+	cfg := &aws.Config{
 		Region: aws.String(region),
-	})
+	}
+
+	// AWS_ENDPOINT_URL points the client at dynamodb-local during development.
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+	}
+
+	sess, err := session.NewSession(cfg)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create AWS session: %w", err)
 	}
 
 	client := dynamodb.New(sess)
 
+	// AUTO_CREATE_TABLE is meant for local and test environments and is off
+	// unless explicitly enabled, so production tables are never created
+	// implicitly.
+	if raw := os.Getenv("AUTO_CREATE_TABLE"); raw != "" {
+		autoCreate, err := strconv.ParseBool(raw)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AUTO_CREATE_TABLE %q: %w", raw, err)
+		}
+		if autoCreate {
+			capacity, err := tableCapacityFromEnv()
+			if err != nil {
+				return nil, err
+			}
+			if err := ensureTable(client, tableName, capacity); err != nil {
+				return nil, err
+			}
+		}
+	}
+
 	return &DynamoDBClient{
 		Client:    client,
 		TableName: tableName,
+		tables:    client,
 	}, nil
-}
\ No newline at end of file
+}
@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// TableStatusMissing is reported for a table that does not exist, alongside
+// the dynamodb.TableStatus* values DynamoDB itself reports.
+const TableStatusMissing = "MISSING"
+
+// TableStatus is the state of the products table as reported by
+// DescribeTable. ItemCount is DynamoDB's estimate, which it refreshes
+// roughly every six hours.
+type TableStatus struct {
+	Status    string `json:"status"`
+	ItemCount int64  `json:"item_count"`
+}
+
+// TableDescriber reports the state of the products table. It is implemented
+// by *DynamoDBClient and can be mocked in tests.
+type TableDescriber interface {
+	DescribeTable(ctx context.Context) (TableStatus, error)
+}
+
+// describeTableAPI is the DynamoDB call behind DescribeTable.
+type describeTableAPI interface {
+	DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error)
+}
+
+// DescribeTable returns the table's status and item count estimate. A table
+// that does not exist is reported as TableStatusMissing rather than as an
+// error, which is kept for failures to reach DynamoDB.
+func (c *DynamoDBClient) DescribeTable(ctx context.Context) (TableStatus, error) {
+	if c.tables == nil {
+		return TableStatus{}, fmt.Errorf("table status is not available for %s", c.TableName)
+	}
+
+	output, err := c.tables.DescribeTableWithContext(ctx, &dynamodb.DescribeTableInput{
+		TableName: aws.String(c.TableName),
+	})
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeResourceNotFoundException {
+			return TableStatus{Status: TableStatusMissing}, nil
+		}
+		return TableStatus{}, fmt.Errorf("failed to describe table %s: %w", c.TableName, err)
+	}
+
+	return TableStatus{
+		Status:    aws.StringValue(output.Table.TableStatus),
+		ItemCount: aws.Int64Value(output.Table.ItemCount),
+	}, nil
+}
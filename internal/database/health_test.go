@@ -0,0 +1,60 @@
+package database
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubDescribeTable struct {
+	output *dynamodb.DescribeTableOutput
+	err    error
+}
+
+func (s stubDescribeTable) DescribeTableWithContext(ctx aws.Context, input *dynamodb.DescribeTableInput, opts ...request.Option) (*dynamodb.DescribeTableOutput, error) {
+	return s.output, s.err
+}
+
+func TestDynamoDBClient_DescribeTable(t *testing.T) {
+	client := &DynamoDBClient{TableName: "products", tables: stubDescribeTable{
+		output: &dynamodb.DescribeTableOutput{Table: &dynamodb.TableDescription{
+			TableStatus: aws.String(dynamodb.TableStatusUpdating),
+			ItemCount:   aws.Int64(42),
+		}},
+	}}
+
+	status, err := client.DescribeTable(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, TableStatus{Status: dynamodb.TableStatusUpdating, ItemCount: 42}, status)
+}
+
+func TestDynamoDBClient_DescribeTable_Missing(t *testing.T) {
+	client := &DynamoDBClient{TableName: "products", tables: stubDescribeTable{
+		err: awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil),
+	}}
+
+	status, err := client.DescribeTable(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, TableStatusMissing, status.Status)
+}
+
+func TestDynamoDBClient_DescribeTable_Error(t *testing.T) {
+	client := &DynamoDBClient{TableName: "products", tables: stubDescribeTable{
+		err: errors.New("connection refused"),
+	}}
+
+	_, err := client.DescribeTable(context.Background())
+	assert.ErrorContains(t, err, "connection refused")
+
+	_, err = (&DynamoDBClient{TableName: "products"}).DescribeTable(context.Background())
+	assert.Error(t, err)
+}
@@ -0,0 +1,122 @@
+package database
+
+import (
+	"context"
+	"errors"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// ErrSaturated is returned instead of calling DynamoDB when every
+// concurrency slot is taken and the limiter fails fast.
+var ErrSaturated = errors.New("too many concurrent DynamoDB calls")
+
+type noWaitKey struct{}
+
+// WithoutWaiting marks ctx so that its calls fail with ErrSaturated rather
+// than wait for a free slot, for best-effort background work that must not
+// hold up requests when the limiter is saturated.
+func WithoutWaiting(ctx context.Context) context.Context {
+	return context.WithValue(ctx, noWaitKey{}, true)
+}
+
+// LimitConcurrency wraps api so that at most max calls are in flight at
+// once, smoothing traffic spikes that would otherwise be throttled. A call
+// beyond the limit waits for a slot or for its context to end; with
+// failFast it returns ErrSaturated at once instead. A max of zero or less
+// returns api unchanged.
+func LimitConcurrency(api DynamoDBAPI, max int, failFast bool) DynamoDBAPI {
+	if max <= 0 {
+		return api
+	}
+	return &concurrencyLimiter{
+		api:      api,
+		slots:    make(chan struct{}, max),
+		failFast: failFast,
+	}
+}
+
+type concurrencyLimiter struct {
+	api      DynamoDBAPI
+	slots    chan struct{}
+	failFast bool
+}
+
+func (l *concurrencyLimiter) acquire(ctx aws.Context) error {
+	if noWait, _ := ctx.Value(noWaitKey{}).(bool); l.failFast || noWait {
+		select {
+		case l.slots <- struct{}{}:
+			return nil
+		default:
+			return ErrSaturated
+		}
+	}
+	select {
+	case l.slots <- struct{}{}:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+func (l *concurrencyLimiter) release() {
+	<-l.slots
+}
+
+func (l *concurrencyLimiter) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.PutItemWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.GetItemWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.BatchGetItemWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.UpdateItemWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.ScanWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.QueryWithContext(ctx, input, opts...)
+}
+
+func (l *concurrencyLimiter) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
+	if err := l.acquire(ctx); err != nil {
+		return nil, err
+	}
+	defer l.release()
+	return l.api.DeleteItemWithContext(ctx, input, opts...)
+}
@@ -0,0 +1,113 @@
+package database
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/request"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// blockingAPI holds every GetItem call until it is released, reporting
+// each call as it starts.
+type blockingAPI struct {
+	DynamoDBAPI
+	started chan struct{}
+	release chan struct{}
+}
+
+func (b *blockingAPI) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
+	b.started <- struct{}{}
+	<-b.release
+	return &dynamodb.GetItemOutput{}, nil
+}
+
+func newBlockingAPI() *blockingAPI {
+	return &blockingAPI{started: make(chan struct{}, 10), release: make(chan struct{})}
+}
+
+// waitStarted reports whether a call started within a short wait.
+func (b *blockingAPI) waitStarted() bool {
+	select {
+	case <-b.started:
+		return true
+	case <-time.After(50 * time.Millisecond):
+		return false
+	}
+}
+
+func TestLimitConcurrency_BlocksBeyondLimit(t *testing.T) {
+	api := newBlockingAPI()
+	limited := LimitConcurrency(api, 2, false)
+
+	done := make(chan error, 3)
+	for i := 0; i < 3; i++ {
+		go func() {
+			_, err := limited.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+			done <- err
+		}()
+	}
+
+	require.True(t, api.waitStarted())
+	require.True(t, api.waitStarted())
+	assert.False(t, api.waitStarted(), "a third call must wait for a slot")
+
+	api.release <- struct{}{}
+	assert.True(t, api.waitStarted(), "the waiting call starts once a slot frees")
+
+	close(api.release)
+	for i := 0; i < 3; i++ {
+		assert.NoError(t, <-done)
+	}
+}
+
+func TestLimitConcurrency_WaitEndsWithContext(t *testing.T) {
+	api := newBlockingAPI()
+	limited := LimitConcurrency(api, 1, false)
+	defer close(api.release)
+
+	go limited.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+	require.True(t, api.waitStarted())
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	_, err := limited.GetItemWithContext(ctx, &dynamodb.GetItemInput{})
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestLimitConcurrency_FailFast(t *testing.T) {
+	api := newBlockingAPI()
+	limited := LimitConcurrency(api, 1, true)
+
+	go limited.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+	require.True(t, api.waitStarted())
+
+	_, err := limited.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+	assert.ErrorIs(t, err, ErrSaturated)
+
+	close(api.release)
+}
+
+func TestLimitConcurrency_WithoutWaiting(t *testing.T) {
+	api := newBlockingAPI()
+	limited := LimitConcurrency(api, 1, false)
+
+	go limited.GetItemWithContext(context.Background(), &dynamodb.GetItemInput{})
+	require.True(t, api.waitStarted())
+
+	_, err := limited.GetItemWithContext(WithoutWaiting(context.Background()), &dynamodb.GetItemInput{})
+	assert.ErrorIs(t, err, ErrSaturated)
+
+	close(api.release)
+}
+
+func TestLimitConcurrency_Disabled(t *testing.T) {
+	api := newBlockingAPI()
+
+	assert.Same(t, api, LimitConcurrency(api, 0, false))
+}
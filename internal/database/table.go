@@ -0,0 +1,144 @@
+package database
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// tableAPI is the part of the DynamoDB client used to manage the table
+// itself rather than its items.
+type tableAPI interface {
+	DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error)
+	CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error)
+	WaitUntilTableExists(input *dynamodb.DescribeTableInput) error
+}
+
+// tableCapacity is how an auto-created table is billed. On-demand suits
+// spiky development workloads; provisioned capacity is cheaper at a steady,
+// known load.
+type tableCapacity struct {
+	billingMode string
+	read        int64
+	write       int64
+}
+
+// tableCapacityFromEnv reads TABLE_BILLING_MODE (PAY_PER_REQUEST by default
+// or PROVISIONED) and, for provisioned tables, the TABLE_RCU and TABLE_WCU
+// capacities, which must both be positive.
+func tableCapacityFromEnv() (tableCapacity, error) {
+	capacity := tableCapacity{billingMode: dynamodb.BillingModePayPerRequest}
+	if mode := os.Getenv("TABLE_BILLING_MODE"); mode != "" {
+		capacity.billingMode = mode
+	}
+
+	switch capacity.billingMode {
+	case dynamodb.BillingModePayPerRequest:
+		return capacity, nil
+	case dynamodb.BillingModeProvisioned:
+	default:
+		return tableCapacity{}, fmt.Errorf("invalid TABLE_BILLING_MODE %q", capacity.billingMode)
+	}
+
+	var err error
+	if capacity.read, err = capacityEnv("TABLE_RCU"); err != nil {
+		return tableCapacity{}, err
+	}
+	if capacity.write, err = capacityEnv("TABLE_WCU"); err != nil {
+		return tableCapacity{}, err
+	}
+	return capacity, nil
+}
+
+func capacityEnv(key string) (int64, error) {
+	raw := os.Getenv(key)
+	v, err := strconv.ParseInt(raw, 10, 64)
+	if err != nil || v <= 0 {
+		return 0, fmt.Errorf("%s must be a positive integer for PROVISIONED billing, got %q", key, raw)
+	}
+	return v, nil
+}
+
+// SlugIndex is the global secondary index products are looked up by slug
+// through. It is sparse: products without a slug are not indexed.
+const SlugIndex = "slug-index"
+
+// tableDefinition describes the products table. Products are keyed by id;
+// slugs are served by SlugIndex, while category and SKU lookups scan.
+func tableDefinition(name string, capacity tableCapacity) *dynamodb.CreateTableInput {
+	slugIndex := &dynamodb.GlobalSecondaryIndex{
+		IndexName: aws.String(SlugIndex),
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("slug"),
+				KeyType:       aws.String(dynamodb.KeyTypeHash),
+			},
+		},
+		Projection: &dynamodb.Projection{
+			ProjectionType: aws.String(dynamodb.ProjectionTypeAll),
+		},
+	}
+	input := &dynamodb.CreateTableInput{
+		TableName: aws.String(name),
+		AttributeDefinitions: []*dynamodb.AttributeDefinition{
+			{
+				AttributeName: aws.String("id"),
+				AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+			},
+			{
+				AttributeName: aws.String("slug"),
+				AttributeType: aws.String(dynamodb.ScalarAttributeTypeS),
+			},
+		},
+		KeySchema: []*dynamodb.KeySchemaElement{
+			{
+				AttributeName: aws.String("id"),
+				KeyType:       aws.String(dynamodb.KeyTypeHash),
+			},
+		},
+		GlobalSecondaryIndexes: []*dynamodb.GlobalSecondaryIndex{slugIndex},
+		BillingMode:            aws.String(capacity.billingMode),
+	}
+	if capacity.billingMode == dynamodb.BillingModeProvisioned {
+		throughput := &dynamodb.ProvisionedThroughput{
+			ReadCapacityUnits:  aws.Int64(capacity.read),
+			WriteCapacityUnits: aws.Int64(capacity.write),
+		}
+		input.ProvisionedThroughput = throughput
+		slugIndex.ProvisionedThroughput = throughput
+	}
+	return input
+}
+
+// ensureTable creates the table when it does not exist and waits until it
+// is active. An existing table is left as is.
+func ensureTable(api tableAPI, name string, capacity tableCapacity) error {
+	describe := &dynamodb.DescribeTableInput{TableName: aws.String(name)}
+
+	_, err := api.DescribeTable(describe)
+	if err == nil {
+		return nil
+	}
+	var aerr awserr.Error
+	if !errors.As(err, &aerr) || aerr.Code() != dynamodb.ErrCodeResourceNotFoundException {
+		return fmt.Errorf("failed to describe table %s: %w", name, err)
+	}
+
+	_, err = api.CreateTable(tableDefinition(name, capacity))
+	if err != nil {
+		// Another instance may have created it between the two calls.
+		if !errors.As(err, &aerr) || aerr.Code() != dynamodb.ErrCodeResourceInUseException {
+			return fmt.Errorf("failed to create table %s: %w", name, err)
+		}
+	}
+
+	if err := api.WaitUntilTableExists(describe); err != nil {
+		return fmt.Errorf("failed waiting for table %s: %w", name, err)
+	}
+	return nil
+}
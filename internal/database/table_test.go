@@ -0,0 +1,153 @@
+package database
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type mockTableAPI struct {
+	mock.Mock
+}
+
+func (m *mockTableAPI) DescribeTable(input *dynamodb.DescribeTableInput) (*dynamodb.DescribeTableOutput, error) {
+	args := m.Called(*input.TableName)
+	return &dynamodb.DescribeTableOutput{}, args.Error(0)
+}
+
+func (m *mockTableAPI) CreateTable(input *dynamodb.CreateTableInput) (*dynamodb.CreateTableOutput, error) {
+	args := m.Called(input)
+	return &dynamodb.CreateTableOutput{}, args.Error(0)
+}
+
+func (m *mockTableAPI) WaitUntilTableExists(input *dynamodb.DescribeTableInput) error {
+	args := m.Called(*input.TableName)
+	return args.Error(0)
+}
+
+var onDemand = tableCapacity{billingMode: dynamodb.BillingModePayPerRequest}
+
+func TestEnsureTable_Exists(t *testing.T) {
+	api := new(mockTableAPI)
+	api.On("DescribeTable", "products").Return(nil)
+
+	err := ensureTable(api, "products", onDemand)
+
+	assert.NoError(t, err)
+	api.AssertNotCalled(t, "CreateTable", mock.Anything)
+	api.AssertNotCalled(t, "WaitUntilTableExists", mock.Anything)
+}
+
+func TestEnsureTable_CreatesMissingTable(t *testing.T) {
+	api := new(mockTableAPI)
+	api.On("DescribeTable", "products").
+		Return(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil))
+	api.On("CreateTable", mock.MatchedBy(func(input *dynamodb.CreateTableInput) bool {
+		return *input.TableName == "products" &&
+			*input.KeySchema[0].AttributeName == "id" &&
+			*input.KeySchema[0].KeyType == dynamodb.KeyTypeHash
+	})).Return(nil)
+	api.On("WaitUntilTableExists", "products").Return(nil)
+
+	err := ensureTable(api, "products", onDemand)
+
+	assert.NoError(t, err)
+	api.AssertExpectations(t)
+}
+
+func TestEnsureTable_ConcurrentCreate(t *testing.T) {
+	api := new(mockTableAPI)
+	api.On("DescribeTable", "products").
+		Return(awserr.New(dynamodb.ErrCodeResourceNotFoundException, "not found", nil))
+	api.On("CreateTable", mock.Anything).
+		Return(awserr.New(dynamodb.ErrCodeResourceInUseException, "in use", nil))
+	api.On("WaitUntilTableExists", "products").Return(nil)
+
+	assert.NoError(t, ensureTable(api, "products", onDemand))
+}
+
+func TestEnsureTable_DescribeFails(t *testing.T) {
+	api := new(mockTableAPI)
+	api.On("DescribeTable", "products").Return(errors.New("access denied"))
+
+	err := ensureTable(api, "products", onDemand)
+
+	assert.Error(t, err)
+	api.AssertNotCalled(t, "CreateTable", mock.Anything)
+}
+
+func TestTableDefinition_BillingMode(t *testing.T) {
+	input := tableDefinition("products", onDemand)
+
+	assert.Equal(t, dynamodb.BillingModePayPerRequest, *input.BillingMode)
+	assert.Nil(t, input.ProvisionedThroughput)
+
+	input = tableDefinition("products", tableCapacity{
+		billingMode: dynamodb.BillingModeProvisioned,
+		read:        5,
+		write:       2,
+	})
+
+	assert.Equal(t, dynamodb.BillingModeProvisioned, *input.BillingMode)
+	require.NotNil(t, input.ProvisionedThroughput)
+	assert.Equal(t, int64(5), *input.ProvisionedThroughput.ReadCapacityUnits)
+	assert.Equal(t, int64(2), *input.ProvisionedThroughput.WriteCapacityUnits)
+}
+
+func TestTableDefinition_SlugIndex(t *testing.T) {
+	input := tableDefinition("products", tableCapacity{
+		billingMode: dynamodb.BillingModeProvisioned,
+		read:        5,
+		write:       2,
+	})
+
+	require.Len(t, input.GlobalSecondaryIndexes, 1)
+	index := input.GlobalSecondaryIndexes[0]
+	assert.Equal(t, SlugIndex, *index.IndexName)
+	assert.Equal(t, "slug", *index.KeySchema[0].AttributeName)
+	require.NotNil(t, index.ProvisionedThroughput)
+	assert.Equal(t, int64(5), *index.ProvisionedThroughput.ReadCapacityUnits)
+
+	assert.Nil(t, tableDefinition("products", onDemand).GlobalSecondaryIndexes[0].ProvisionedThroughput)
+}
+
+func TestTableCapacityFromEnv(t *testing.T) {
+	t.Setenv("TABLE_BILLING_MODE", "")
+
+	capacity, err := tableCapacityFromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, onDemand, capacity)
+
+	t.Setenv("TABLE_BILLING_MODE", "PROVISIONED")
+	t.Setenv("TABLE_RCU", "10")
+	t.Setenv("TABLE_WCU", "4")
+
+	capacity, err = tableCapacityFromEnv()
+
+	require.NoError(t, err)
+	assert.Equal(t, tableCapacity{billingMode: dynamodb.BillingModeProvisioned, read: 10, write: 4}, capacity)
+}
+
+func TestTableCapacityFromEnv_Invalid(t *testing.T) {
+	for _, tc := range []struct{ mode, rcu, wcu string }{
+		{mode: "FREE"},
+		{mode: "PROVISIONED", wcu: "4"},
+		{mode: "PROVISIONED", rcu: "0", wcu: "4"},
+		{mode: "PROVISIONED", rcu: "10", wcu: "-1"},
+		{mode: "PROVISIONED", rcu: "ten", wcu: "4"},
+	} {
+		t.Setenv("TABLE_BILLING_MODE", tc.mode)
+		t.Setenv("TABLE_RCU", tc.rcu)
+		t.Setenv("TABLE_WCU", tc.wcu)
+
+		_, err := tableCapacityFromEnv()
+
+		assert.Error(t, err, tc)
+	}
+}
@@ -0,0 +1,68 @@
+// Package features decides, request by request, whether a behavior that is
+// still being rolled out is turned on.
+package features
+
+import (
+	"slices"
+	"strings"
+)
+
+// Flags consulted by the service.
+const (
+	// StrictJSON rejects request bodies with unknown fields, as the
+	// STRICT_JSON setting does for every request.
+	StrictJSON = "strict_json"
+)
+
+// Header lists, comma separated, the flags a request opts into. It only
+// turns on flags whose audience includes OptIn.
+const Header = "X-Features"
+
+// Audiences a flag can be turned on for. Roles and actors are named with a
+// prefix, e.g. "role:admin" or "actor:user-7".
+const (
+	Everyone = "*"
+	OptIn    = "opt-in"
+
+	RolePrefix  = "role:"
+	ActorPrefix = "actor:"
+)
+
+// Request is what a flag is evaluated against.
+type Request struct {
+	Role    string
+	ActorID string
+	OptIns  []string // flags named in the request's Header
+}
+
+// Flags reports whether a flag is on for a request. Unknown flags are off.
+type Flags interface {
+	Enabled(flag string, req Request) bool
+}
+
+// Static turns each flag on for a fixed list of audiences, e.g.
+// {"strict_json": ["role:admin", "opt-in"]}. A nil Static turns every flag
+// off.
+type Static map[string][]string
+
+func (s Static) Enabled(flag string, req Request) bool {
+	for _, audience := range s[flag] {
+		switch {
+		case audience == Everyone:
+			return true
+		case audience == OptIn:
+			if slices.Contains(req.OptIns, flag) {
+				return true
+			}
+		case strings.HasPrefix(audience, RolePrefix):
+			if req.Role != "" && req.Role == strings.TrimPrefix(audience, RolePrefix) {
+				return true
+			}
+		case strings.HasPrefix(audience, ActorPrefix):
+			if req.ActorID != "" && req.ActorID == strings.TrimPrefix(audience, ActorPrefix) {
+				return true
+			}
+		}
+	}
+	return false
+}
@@ -0,0 +1,26 @@
+package features
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStatic_Enabled(t *testing.T) {
+	flags := Static{
+		"everyone": {Everyone},
+		"targeted": {"role:admin", "actor:user-7", OptIn},
+	}
+
+	assert.True(t, flags.Enabled("everyone", Request{}))
+	assert.False(t, flags.Enabled("unknown", Request{Role: "admin"}))
+
+	assert.True(t, flags.Enabled("targeted", Request{Role: "admin"}))
+	assert.True(t, flags.Enabled("targeted", Request{ActorID: "user-7"}))
+	assert.True(t, flags.Enabled("targeted", Request{OptIns: []string{"other", "targeted"}}))
+	assert.False(t, flags.Enabled("targeted", Request{Role: "editor", ActorID: "user-8", OptIns: []string{"everyone"}}))
+
+	// Opting in only works for flags that allow it.
+	assert.False(t, Static{"targeted": {"role:admin"}}.Enabled("targeted", Request{OptIns: []string{"targeted"}}))
+	assert.False(t, Static(nil).Enabled("everyone", Request{}))
+}
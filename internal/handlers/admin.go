@@ -0,0 +1,104 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// Cache is the operator's view of the product cache.
+type Cache interface {
+	Flush() int
+	Evict(id string) bool
+}
+
+// Reindexer backfills products' derived fields one batch at a time.
+type Reindexer interface {
+	Reindex(ctx context.Context, cursor string, limit int) (*models.ReindexResult, error)
+}
+
+// AdminHandler serves operator endpoints. They are mounted behind the admin
+// token check and must not be exposed otherwise.
+type AdminHandler struct {
+	cache     Cache
+	reindexer Reindexer
+}
+
+// NewAdminHandler returns an admin handler. cache may be nil when the
+// product cache is disabled, in which case the cache routes are not mounted.
+func NewAdminHandler(cache Cache, reindexer Reindexer) *AdminHandler {
+	return &AdminHandler{cache: cache, reindexer: reindexer}
+}
+
+// HasCache reports whether the cache endpoints have a cache to manage.
+func (h *AdminHandler) HasCache() bool {
+	return h.cache != nil
+}
+
+// FlushCache empties the product cache, e.g. after an out-of-band change to
+// the table.
+func (h *AdminHandler) FlushCache(c *gin.Context) {
+	writeJSON(c, http.StatusOK, gin.H{
+		"cleared": h.cache.Flush(),
+	})
+}
+
+// EvictCache drops a single product from the cache. Evicting a product that
+// is not cached succeeds with nothing cleared.
+func (h *AdminHandler) EvictCache(c *gin.Context) {
+	cleared := 0
+	if h.cache.Evict(c.Param("id")) {
+		cleared = 1
+	}
+	writeJSON(c, http.StatusOK, gin.H{
+		"cleared": cleared,
+	})
+}
+
+// Reindex backfills derived fields for one batch of products. Callers repeat
+// it with the returned next_cursor until done is true.
+func (h *AdminHandler) Reindex(c *gin.Context) {
+	limit := 0
+	if raw := c.Query("limit"); raw != "" {
+		var err error
+		if limit, err = strconv.Atoi(raw); err != nil {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(service.ErrInvalidQuery),
+				"details": "limit: " + err.Error(),
+			})
+			return
+		}
+	}
+
+	dryRun := isDryRun(c)
+	result, err := h.reindexer.Reindex(mutationContext(c, dryRun), c.Query("cursor"), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to reindex products", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"result":  result,
+		})
+		return
+	}
+
+	writeJSON(c, http.StatusOK, result)
+}
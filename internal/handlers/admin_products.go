@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// newAdminProductDTO is the admin tool's listing row. It differs from the
+// public shape: it carries the audit and moderation fields the tool shows
+// and the values it would otherwise compute per row, and drops
+// translations and image keys.
+func newAdminProductDTO(p *models.Product, naming FieldNaming, lowStock float64, now time.Time) productDTO {
+	return newDTO([]productField{
+		{key: "id", value: p.ID},
+		{key: "name", value: p.Name},
+		{key: "sku", value: p.SKU},
+		{key: "category", value: p.Category},
+		{key: "price", value: p.Price},
+		{key: "effective_price", value: p.EffectivePrice()},
+		{key: "stock", value: p.Stock},
+		{key: "unit", value: p.Unit},
+		{key: "availability", value: p.Availability(lowStock, now)},
+		{key: "available_from", value: p.AvailableFrom},
+		{key: "is_active", value: p.IsActive},
+		{key: "status", value: p.CurrentStatus()},
+		{key: "deleted_at", value: p.DeletedAt},
+		{key: "image_count", value: len(p.Images)},
+		{key: "view_count", value: p.ViewCount},
+		{key: "average_rating", value: p.AverageRating()},
+		{key: "rating_count", value: p.RatingCount},
+		{key: "version", value: p.Version},
+		{key: "created_at", value: p.CreatedAt},
+		{key: "created_by", value: p.CreatedBy},
+		{key: "updated_at", value: p.UpdatedAt},
+		{key: "updated_by", value: p.UpdatedBy},
+	}, naming, false)
+}
+
+// AdminListProducts serves the admin tool's product listing. It takes the
+// same query parameters and pagination as GetAllProducts but renders each
+// product with newAdminProductDTO. It is mounted behind the admin token.
+func (h *ProductHandler) AdminListProducts(c *gin.Context) {
+	opts, err := listOptions(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	list, err := h.service.GetAllProducts(c.Request.Context(), opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get products", err)
+		return
+	}
+
+	naming := h.fieldNaming(c)
+	now := h.clock.Now()
+	views := make([]productDTO, 0, len(list.Products))
+	for _, p := range list.Products {
+		views = append(views, newAdminProductDTO(p, naming, h.lowStock, now))
+	}
+	writeJSON(c, listStatus(list), h.pagedResponse(c, list, views))
+}
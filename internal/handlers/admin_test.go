@@ -0,0 +1,161 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+type fakeCache map[string]bool
+
+func (f fakeCache) Flush() int {
+	n := len(f)
+	clear(f)
+	return n
+}
+
+func (f fakeCache) Evict(id string) bool {
+	ok := f[id]
+	delete(f, id)
+	return ok
+}
+
+func TestAdminHandler_Cache(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	cache := fakeCache{"a": true, "b": true, "c": true}
+	admin := NewAdminHandler(cache, nil)
+	router := gin.New()
+	router.POST("/admin/cache/flush", admin.FlushCache)
+	router.DELETE("/admin/cache/:id", admin.EvictCache)
+
+	cleared := func(method, path string) float64 {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(method, path, nil)
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response map[string]float64
+		json.Unmarshal(w.Body.Bytes(), &response)
+		return response["cleared"]
+	}
+
+	assert.Equal(t, float64(1), cleared("DELETE", "/admin/cache/a"))
+	assert.Equal(t, float64(0), cleared("DELETE", "/admin/cache/a"))
+	assert.Equal(t, float64(2), cleared("POST", "/admin/cache/flush"))
+	assert.Equal(t, float64(0), cleared("POST", "/admin/cache/flush"))
+}
+
+func TestAdminHandler_Reindex(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	admin := NewAdminHandler(nil, mockService)
+	router := gin.New()
+	router.POST("/admin/reindex", admin.Reindex)
+
+	mockService.On("Reindex", "", 0).Return(&models.ReindexResult{Processed: 100, Updated: 3, NextCursor: "next"}, nil)
+	mockService.On("Reindex", "next", 50).Return(&models.ReindexResult{Processed: 20, Done: true}, nil)
+	mockService.On("Reindex", "bad", 0).Return(nil, fmt.Errorf("%w: malformed cursor", service.ErrInvalidQuery))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/admin/reindex", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var result models.ReindexResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &result))
+	assert.Equal(t, models.ReindexResult{Processed: 100, Updated: 3, NextCursor: "next"}, result)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admin/reindex?cursor=next&limit=50", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"processed":20,"updated":0,"skipped":0,"done":true}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admin/reindex?cursor=bad", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_AdminListProducts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithLowStockThreshold(5))
+	router := gin.New()
+	router.GET("/admin/products", handler.AdminListProducts)
+
+	list := &models.ProductList{
+		Products: []*models.Product{{
+			ID: "1", Name: "Widget", Price: 12.5, Stock: 3, IsActive: true,
+			Images: []string{"a.png", "b.png"}, UpdatedBy: "user-7", Version: 4,
+			Translations: map[string]models.ProductTranslation{"fr": {Name: "Gadget"}},
+		}},
+		Limit:      1,
+		Total:      2,
+		NextCursor: "MQ",
+	}
+	mockService.On("GetAllProducts", models.ListOptions{Sort: "-price", Limit: 1}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/products?limit=1&sort=-price", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Total-Count"))
+
+	var response struct {
+		Products   []map[string]any `json:"products"`
+		Pagination models.PageMeta  `json:"pagination"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Products, 1)
+	product := response.Products[0]
+	assert.Equal(t, 12.5, product["effective_price"])
+	assert.Equal(t, models.LowStock, product["availability"])
+	assert.Equal(t, "user-7", product["updated_by"])
+	assert.Equal(t, float64(2), product["image_count"])
+	assert.Equal(t, float64(4), product["version"])
+	assert.NotContains(t, product, "images")
+	assert.NotContains(t, product, "translations")
+	assert.Equal(t, "MQ", response.Pagination.NextCursor)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_AdminListDrafts(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := gin.New()
+	router.GET("/admin/products/drafts", handler.AdminListDrafts)
+
+	mockService.On("GetDraftProducts").Return([]*models.Product{
+		{ID: "1", Name: "Lamp", Status: models.StatusDraft},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/admin/products/drafts", nil)
+	router.ServeHTTP(w, req)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Products []map[string]any `json:"products"`
+		Count    int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Products, 1)
+	assert.Equal(t, "1", response.Products[0]["id"])
+	assert.Equal(t, models.StatusDraft, response.Products[0]["status"])
+	assert.Equal(t, 1, response.Count)
+	mockService.AssertExpectations(t)
+}
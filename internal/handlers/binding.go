@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+
+	"product-service/internal/features"
+)
+
+// bindJSON decodes the request body into obj and validates it, answering
+// 400 and returning false when that fails. In strict mode, enabled by
+// configuration, per request with Prefer: strict, or by the strict_json
+// feature flag, fields the target does not define are rejected instead of
+// silently dropped.
+func (h *ProductHandler) bindJSON(c *gin.Context, obj any) bool {
+	if !h.strictBody(c) {
+		if err := c.ShouldBindJSON(obj); err != nil {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return false
+		}
+		return true
+	}
+	return h.decodeStrict(c, obj, binding.JSON.BindBody)
+}
+
+// bindJSONItems is bindJSON for batch bodies whose items the service checks
+// one by one. It rejects unknown fields in strict mode like bindJSON, but
+// leaves the binding rules to the per-item checks, so one malformed item
+// does not fail the whole batch.
+func (h *ProductHandler) bindJSONItems(c *gin.Context, obj any) bool {
+	if !h.strictBody(c) {
+		if err := json.NewDecoder(c.Request.Body).Decode(obj); err != nil {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid request body",
+				"details": err.Error(),
+			})
+			return false
+		}
+		return true
+	}
+	return h.decodeStrict(c, obj, json.Unmarshal)
+}
+
+// strictBody reports whether the request body is decoded in strict mode.
+func (h *ProductHandler) strictBody(c *gin.Context) bool {
+	return h.strictJSON || prefers(c, "strict") || h.featureEnabled(c, features.StrictJSON)
+}
+
+// decodeStrict rejects a body with fields obj does not define and decodes
+// the rest with decode.
+func (h *ProductHandler) decodeStrict(c *gin.Context, obj any, decode func([]byte, any) error) bool {
+	body, err := io.ReadAll(c.Request.Body)
+	if err == nil {
+		if unknown := unknownFields(body, reflect.TypeOf(obj)); len(unknown) > 0 {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":          "Invalid request body",
+				"details":        fmt.Sprintf("unknown fields: %s", strings.Join(unknown, ", ")),
+				"unknown_fields": unknown,
+			})
+			return false
+		}
+		err = decode(body, obj)
+	}
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid request body",
+			"details": err.Error(),
+		})
+		return false
+	}
+	return true
+}
+
+// unknownFields lists the keys of a JSON object body that no field of t
+// accepts. For arrays, each element is checked and keys are reported with
+// their index. Bodies that do not match t's shape are left to the decoder.
+func unknownFields(body []byte, t reflect.Type) []string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+
+	switch t.Kind() {
+	case reflect.Struct:
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(body, &object); err != nil {
+			return nil
+		}
+		known := jsonFieldNames(t)
+		var unknown []string
+		for key := range object {
+			if !known[strings.ToLower(key)] {
+				unknown = append(unknown, key)
+			}
+		}
+		sort.Strings(unknown)
+		return unknown
+	case reflect.Slice:
+		var elements []json.RawMessage
+		if err := json.Unmarshal(body, &elements); err != nil {
+			return nil
+		}
+		var unknown []string
+		for i, element := range elements {
+			for _, key := range unknownFields(element, t.Elem()) {
+				unknown = append(unknown, fmt.Sprintf("[%d].%s", i, key))
+			}
+		}
+		return unknown
+	}
+	return nil
+}
+
+// jsonFieldNames returns the lower-cased keys encoding/json would match to
+// t's fields; like encoding/json, matching is case-insensitive.
+func jsonFieldNames(t reflect.Type) map[string]bool {
+	names := make(map[string]bool, t.NumField())
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		names[strings.ToLower(name)] = true
+	}
+	return names
+}
+
+// prefers reports whether the Prefer request header carries the given
+// preference token.
+func prefers(c *gin.Context, preference string) bool {
+	for _, pref := range strings.Split(c.GetHeader("Prefer"), ",") {
+		if strings.EqualFold(strings.TrimSpace(pref), preference) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,71 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// GetProductChanges serves the change feed: products updated at or after
+// the since query parameter, an RFC 3339 timestamp, oldest first. Deleted
+// products are included with "deleted": true so consumers can drop them.
+func (h *ProductHandler) GetProductChanges(c *gin.Context) {
+	opts, err := listOptions(c)
+	var since time.Time
+	if err == nil {
+		since, err = changesSince(c)
+	}
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
+			"details": err.Error(),
+		})
+		return
+	}
+
+	list, err := h.service.GetProductChanges(c.Request.Context(), since, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get product changes", err)
+		return
+	}
+
+	writeJSON(c, listStatus(list), h.pagedResponse(c, list, h.changeViews(c, list.Products)))
+}
+
+// changesSince reads the required since parameter.
+func changesSince(c *gin.Context) (time.Time, error) {
+	raw := c.Query("since")
+	if raw == "" {
+		return time.Time{}, errors.New("since is required")
+	}
+	since, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("since must be an RFC 3339 timestamp: %w", err)
+	}
+	return since, nil
+}
+
+// changeViews renders the products of the change feed, each flagged with
+// whether it has been soft deleted.
+func (h *ProductHandler) changeViews(c *gin.Context, products []*models.Product) []productDTO {
+	views := h.productViews(c, products)
+	for i, p := range products {
+		views[i] = append(views[i], productField{key: "deleted", value: p.DeletedAt != nil})
+	}
+	return views
+}
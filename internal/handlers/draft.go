@@ -0,0 +1,78 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// CreateDraft stores a draft product. Only the name is required; the rest
+// is checked when the draft is published.
+func (h *ProductHandler) CreateDraft(c *gin.Context) {
+	var req models.CreateDraftRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	product, err := h.service.CreateDraft(mutationContext(c, dryRun), models.CreateProductRequest(req))
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateSKU) {
+			writeJSON(c, http.StatusConflict, h.duplicateSKUBody(err))
+			return
+		}
+		writeServerError(c, "Failed to create draft", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"product": h.productView(c, product),
+		})
+		return
+	}
+
+	c.Header("Location", h.productLocation(product.ID))
+	writeJSON(c, http.StatusCreated, h.productView(c, product))
+}
+
+// PublishProduct publishes a draft or archived product, answering 400 with
+// the first field that keeps it from being published.
+func (h *ProductHandler) PublishProduct(c *gin.Context) {
+	id := c.Param("id")
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.PublishProduct(ctx, id)
+	})
+}
+
+// AdminListDrafts lists the unpublished drafts for the admin tool, which
+// public listings leave out.
+func (h *ProductHandler) AdminListDrafts(c *gin.Context) {
+	drafts, err := h.service.GetDraftProducts(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to get draft products", err)
+		return
+	}
+
+	naming := h.fieldNaming(c)
+	now := h.clock.Now()
+	views := make([]productDTO, 0, len(drafts))
+	for _, p := range drafts {
+		views = append(views, newAdminProductDTO(p, naming, h.lowStock, now))
+	}
+	writeJSON(c, http.StatusOK, gin.H{
+		"products": views,
+		"count":    len(views),
+	})
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/database"
+	"product-service/internal/service"
+)
+
+// ErrorCode describes one machine-readable error code the API returns in
+// the "code" field of an error response.
+type ErrorCode struct {
+	Code        string `json:"code"`
+	HTTPStatus  int    `json:"http_status"`
+	Description string `json:"description"`
+}
+
+// errorCatalog is the single source of error codes: responses take their
+// code from it, and GET /errors lists it. Each entry is matched with
+// errors.Is against the service error it describes.
+var errorCatalog = []struct {
+	err error
+	ErrorCode
+}{
+	{service.ErrProductNotFound, ErrorCode{"PRODUCT_NOT_FOUND", http.StatusNotFound, "No product has the requested ID, slug or SKU."}},
+	{service.ErrInvalidProduct, ErrorCode{"INVALID_PRODUCT", http.StatusBadRequest, "The product data failed validation; field names the offending field when known."}},
+	{service.ErrInvalidQuery, ErrorCode{"INVALID_QUERY", http.StatusBadRequest, "A query parameter or batch request is malformed or out of range."}},
+	{service.ErrPreconditionFailed, ErrorCode{"PRECONDITION_FAILED", http.StatusPreconditionFailed, "The product changed since the version or time the request was conditional on."}},
+	{service.ErrConcurrentModification, ErrorCode{"CONCURRENT_MODIFICATION", http.StatusConflict, "Other writes to the product kept landing while the request was applied; retry it."}},
+	{service.ErrPatchTestFailed, ErrorCode{"PATCH_TEST_FAILED", http.StatusConflict, "A test operation in a JSON Patch did not match the product."}},
+	{service.ErrHardDeleteDisabled, ErrorCode{"HARD_DELETE_DISABLED", http.StatusForbidden, "Permanent deletes are disabled; delete with soft=true instead."}},
+	{service.ErrImageStoreDisabled, ErrorCode{"IMAGE_STORE_DISABLED", http.StatusNotImplemented, "Image uploads are not configured on this server."}},
+	{service.ErrNotBundle, ErrorCode{"NOT_A_BUNDLE", http.StatusNotFound, "The product exists but is not a bundle."}},
+	{service.ErrInsufficientStock, ErrorCode{"INSUFFICIENT_STOCK", http.StatusConflict, "The product does not have enough stock to reserve."}},
+	{service.ErrDuplicateSKU, ErrorCode{"DUPLICATE_SKU", http.StatusConflict, "Another product already has the SKU; existing_id names it."}},
+	{service.ErrReservationNotFound, ErrorCode{"RESERVATION_NOT_FOUND", http.StatusNotFound, "The product has no reservation with the requested ID."}},
+	{database.ErrSaturated, ErrorCode{"SERVICE_SATURATED", http.StatusServiceUnavailable, "Every database connection slot is busy; retry after the Retry-After delay."}},
+}
+
+// errorCode returns the catalog code for err, or "" if err matches none.
+func errorCode(err error) string {
+	for _, entry := range errorCatalog {
+		if errors.Is(err, entry.err) {
+			return entry.Code
+		}
+	}
+	return ""
+}
+
+// saturatedRetryAfter is the Retry-After, in seconds, sent when the
+// database limiter turns a request away. Slots free as fast as DynamoDB
+// answers, so a short wait is enough.
+const saturatedRetryAfter = "1"
+
+// writeServerError answers a request that failed for a reason other than
+// the client's input. A database saturated by concurrent calls is reported
+// as 503 with Retry-After, so clients back off instead of treating it as a
+// fault; anything else is a 500 with message.
+func writeServerError(c *gin.Context, message string, err error) {
+	if errors.Is(err, database.ErrSaturated) {
+		c.Header("Retry-After", saturatedRetryAfter)
+		writeJSON(c, http.StatusServiceUnavailable, gin.H{
+			"error":   "Service is busy",
+			"code":    errorCode(err),
+			"details": err.Error(),
+		})
+		return
+	}
+	writeJSON(c, http.StatusInternalServerError, gin.H{
+		"error":   message,
+		"details": err.Error(),
+	})
+}
+
+// ListErrorCodes lists every error code the API can return.
+func (h *ProductHandler) ListErrorCodes(c *gin.Context) {
+	codes := make([]ErrorCode, 0, len(errorCatalog))
+	for _, entry := range errorCatalog {
+		codes = append(codes, entry.ErrorCode)
+	}
+	writeJSON(c, http.StatusOK, gin.H{
+		"errors": codes,
+		"count":  len(codes),
+	})
+}
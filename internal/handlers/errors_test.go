@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"io/fs"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// serviceSentinels parses the service package and returns the message of
+// every exported Err variable created with errors.New, keyed by name.
+func serviceSentinels(t *testing.T) map[string]string {
+	t.Helper()
+	pkgs, err := parser.ParseDir(token.NewFileSet(), "../service", func(info fs.FileInfo) bool {
+		return !strings.HasSuffix(info.Name(), "_test.go")
+	}, 0)
+	require.NoError(t, err)
+
+	sentinels := make(map[string]string)
+	for _, pkg := range pkgs {
+		ast.Inspect(pkg, func(n ast.Node) bool {
+			spec, ok := n.(*ast.ValueSpec)
+			if !ok {
+				return true
+			}
+			for i, name := range spec.Names {
+				if !strings.HasPrefix(name.Name, "Err") || i >= len(spec.Values) {
+					continue
+				}
+				call, ok := spec.Values[i].(*ast.CallExpr)
+				if !ok || len(call.Args) != 1 {
+					continue
+				}
+				if lit, ok := call.Args[0].(*ast.BasicLit); ok && lit.Kind == token.STRING {
+					sentinels[name.Name], _ = strconv.Unquote(lit.Value)
+				}
+			}
+			return true
+		})
+	}
+	return sentinels
+}
+
+func TestErrorCatalog_CoversServiceSentinels(t *testing.T) {
+	sentinels := serviceSentinels(t)
+	require.NotEmpty(t, sentinels)
+
+	cataloged := make(map[string]bool, len(errorCatalog))
+	codes := make(map[string]bool, len(errorCatalog))
+	for _, entry := range errorCatalog {
+		cataloged[entry.err.Error()] = true
+		assert.False(t, codes[entry.Code], "duplicate code %s", entry.Code)
+		codes[entry.Code] = true
+	}
+	for name, message := range sentinels {
+		assert.True(t, cataloged[message], "service.%s is missing from the error catalog", name)
+	}
+}
+
+func TestProductHandler_ListErrorCodes(t *testing.T) {
+	router := setupRouter(NewProductHandler(new(MockProductService)))
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/errors", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Errors []ErrorCode `json:"errors"`
+		Count  int         `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, len(errorCatalog), response.Count)
+	assert.Contains(t, response.Errors, ErrorCode{
+		Code:        "PRODUCT_NOT_FOUND",
+		HTTPStatus:  http.StatusNotFound,
+		Description: "No product has the requested ID, slug or SKU.",
+	})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+)
+
+// listETag is a weak validator for a listing response. It covers the
+// request's query and representation settings and, for each listed
+// product, its ID, UpdatedAt and availability, so adding, removing or
+// updating a product changes it, and so does a pre-order going on sale,
+// which no write marks. View and rating counters do not move UpdatedAt and
+// are deliberately left out; otherwise every product view would defeat it.
+func (h *ProductHandler) listETag(c *gin.Context, list *models.ProductList) string {
+	now := h.clock.Now()
+	hash := sha256.New()
+	mediaType, _ := negotiateVersion(c.GetHeader("Accept"))
+	fmt.Fprintf(hash, "%s|%d|%t|%s|%t|%t|%d|%s\n",
+		c.Request.URL.RawQuery, h.fieldNaming(c), h.omitEmpty, mediaType,
+		list.Truncated, list.BudgetExceeded, list.Total, list.NextCursor)
+	for _, p := range list.Products {
+		fmt.Fprintf(hash, "%s|%d|%s\n", p.ID, p.UpdatedAt.UnixNano(), p.Availability(h.lowStock, now))
+	}
+	return fmt.Sprintf(`W/"%x"`, hash.Sum(nil)[:16])
+}
+
+// etagMatches reports whether an If-None-Match header matches etag, using
+// the weak comparison RFC 9110 requires for If-None-Match.
+func etagMatches(ifNoneMatch, etag string) bool {
+	if strings.TrimSpace(ifNoneMatch) == "*" {
+		return true
+	}
+	for _, candidate := range strings.Split(ifNoneMatch, ",") {
+		if strings.TrimPrefix(strings.TrimSpace(candidate), "W/") == strings.TrimPrefix(etag, "W/") {
+			return true
+		}
+	}
+	return false
+}
+
+// expireBy lowers the max-age the cache policy gave the response, served at
+// now, so that caches stop reusing it at t, when a field derived from the clock, such as
+// a pre-order's availability, changes without a write.
+func expireBy(c *gin.Context, now, t time.Time) {
+	header := c.Writer.Header()
+	value := header.Get("Cache-Control")
+	if value == "" {
+		return
+	}
+	left := max(int(t.Sub(now).Seconds()), 0)
+	directives := strings.Split(value, ",")
+	for i, directive := range directives {
+		directive = strings.TrimSpace(directive)
+		if age, ok := strings.CutPrefix(directive, "max-age="); ok {
+			if n, err := strconv.Atoi(age); err == nil && n > left {
+				directive = "max-age=" + strconv.Itoa(left)
+			}
+		}
+		directives[i] = directive
+	}
+	header.Set("Cache-Control", strings.Join(directives, ", "))
+}
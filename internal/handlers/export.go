@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+)
+
+const (
+	exportFormatNDJSON = "ndjson"
+	ndjsonContentType  = "application/x-ndjson"
+)
+
+// exportWriteTimeout is how long the export may take to write each page,
+// including the scan that produces it. The deadline is pushed out before
+// every page, so the export as a whole outlasts the server's WriteTimeout.
+const exportWriteTimeout = 30 * time.Second
+
+// ExportProducts streams every active product as newline-delimited JSON,
+// flushing after each scan page so memory use does not grow with the
+// catalog. A failure after the first line has been sent ends the stream
+// with an error record, {"error": ..., "details": ...}, in place of the
+// remaining products; a complete export never contains one.
+func (h *ProductHandler) ExportProducts(c *gin.Context) {
+	if format := c.DefaultQuery("format", exportFormatNDJSON); format != exportFormatNDJSON {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Unsupported export format",
+			"details": fmt.Sprintf("format %q is not supported; use %q", format, exportFormatNDJSON),
+		})
+		return
+	}
+
+	controller := http.NewResponseController(c.Writer)
+	extendDeadline := func() {
+		// Writers that cannot set deadlines, such as test recorders, have
+		// none to extend.
+		_ = controller.SetWriteDeadline(time.Now().Add(exportWriteTimeout))
+	}
+	extendDeadline()
+
+	started := false
+	start := func() {
+		if !started {
+			c.Header("Content-Type", ndjsonContentType)
+			c.Status(http.StatusOK)
+			started = true
+		}
+	}
+
+	encoder := json.NewEncoder(c.Writer)
+	err := h.service.ExportProducts(c.Request.Context(), func(page []*models.Product) error {
+		start()
+		for _, product := range page {
+			if err := encoder.Encode(h.productView(c, product)); err != nil {
+				return err
+			}
+		}
+		c.Writer.Flush()
+		extendDeadline()
+		return nil
+	})
+	if err != nil {
+		if started {
+			_ = c.Error(err)
+			_ = encoder.Encode(gin.H{
+				"error":   "Failed to export products",
+				"details": err.Error(),
+			})
+			c.Writer.Flush()
+			return
+		}
+		writeServerError(c, "Failed to export products", err)
+		return
+	}
+
+	start()
+	c.Writer.WriteHeaderNow()
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// GetFeaturedProducts lists the featured active products in rank order,
+// for the homepage.
+func (h *ProductHandler) GetFeaturedProducts(c *gin.Context) {
+	products, err := h.service.GetFeaturedProducts(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to get featured products", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, gin.H{
+		"products": h.productViews(c, products),
+		"count":    len(products),
+	})
+}
+
+// SetFeatured features a product at the rank in the body, or moves it
+// there if it is already featured.
+func (h *ProductHandler) SetFeatured(c *gin.Context) {
+	id := c.Param("id")
+
+	var req models.SetFeaturedRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.SetFeatured(ctx, id, req.Rank)
+	})
+}
+
+func (h *ProductHandler) UnsetFeatured(c *gin.Context) {
+	id := c.Param("id")
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.UnsetFeatured(ctx, id)
+	})
+}
+
+// ReorderFeatured ranks the featured products listed in the body in the
+// order given.
+func (h *ProductHandler) ReorderFeatured(c *gin.Context) {
+	var req models.ReorderFeaturedRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	products, err := h.service.ReorderFeatured(mutationContext(c, dryRun), req.IDs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid featured order",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to reorder featured products", err)
+		return
+	}
+
+	response := gin.H{
+		"products": h.productViews(c, products),
+		"count":    len(products),
+	}
+	if dryRun {
+		response["dry_run"] = true
+	}
+	writeJSON(c, http.StatusOK, response)
+}
@@ -0,0 +1,30 @@
+package handlers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/auth"
+	"product-service/internal/features"
+)
+
+// featureEnabled reports whether flag is on for the request: for its
+// caller, or because it opted in with the X-Features header.
+func (h *ProductHandler) featureEnabled(c *gin.Context, flag string) bool {
+	if h.flags == nil {
+		return false
+	}
+	req := features.Request{}
+	if principal, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+		req.Role, req.ActorID = principal.Role, principal.ID
+	}
+	for _, header := range c.Request.Header.Values(features.Header) {
+		for _, name := range strings.Split(header, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				req.OptIns = append(req.OptIns, name)
+			}
+		}
+	}
+	return h.flags.Enabled(flag, req)
+}
@@ -0,0 +1,57 @@
+package handlers
+
+import "slices"
+
+// FieldAccess restricts product response fields to roles. It maps a field's
+// snake_case key to the roles allowed to see it; fields it does not name are
+// public. A key is hidden wherever it appears, including inside the grouped
+// objects of a v2 response.
+type FieldAccess map[string][]string
+
+// WithFieldAccess hides restricted product fields from callers whose role
+// is not allowed to see them. Callers without a principal get only the
+// public fields.
+func WithFieldAccess(access FieldAccess) HandlerOption {
+	return func(h *ProductHandler) {
+		h.fieldAccess = access
+	}
+}
+
+// hidden returns the keys role may not see, in both naming styles.
+func (a FieldAccess) hidden(role string) map[string]bool {
+	var hidden map[string]bool
+	for key, roles := range a {
+		if role != "" && slices.Contains(roles, role) {
+			continue
+		}
+		if hidden == nil {
+			hidden = make(map[string]bool)
+		}
+		hidden[key] = true
+		hidden[snakeToCamel(key)] = true
+	}
+	return hidden
+}
+
+// without returns d minus the hidden fields, descending into nested
+// objects.
+func (d productDTO) without(hidden map[string]bool) productDTO {
+	kept := make(productDTO, 0, len(d))
+	for _, f := range d {
+		if hidden[f.key] {
+			continue
+		}
+		switch v := f.value.(type) {
+		case productDTO:
+			f.value = v.without(hidden)
+		case []productDTO:
+			nested := make([]productDTO, 0, len(v))
+			for _, item := range v {
+				nested = append(nested, item.without(hidden))
+			}
+			f.value = nested
+		}
+		kept = append(kept, f)
+	}
+	return kept
+}
@@ -1,197 +1,1655 @@
 package handlers
 
 import (
+	"context"
 	"errors"
+	"fmt"
+	"io"
+	"math"
 	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
 
+	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/gin-gonic/gin"
 
+	"product-service/internal/database"
+	"product-service/internal/features"
 	"product-service/internal/models"
 	"product-service/internal/service"
+	"product-service/internal/storage"
 )
 
+const defaultTrendingLimit = 10
+
+const defaultSuggestLimit = 10
+
+const jsonPatchContentType = "application/json-patch+json"
+
+// DefaultMaxImageSize bounds uploaded images when no limit is configured.
+const DefaultMaxImageSize = 5 << 20
+
+// multipartOverhead allows for the multipart framing around an uploaded
+// image when limiting the request body.
+const multipartOverhead = 64 << 10
+
 type ProductHandler struct {
-	service service.ProductService
+	service   service.ProductService
+	naming    FieldNaming
+	omitEmpty bool
+
+	paginationHeaders bool
+	totalCount        TotalCount
+	strictJSON        bool
+
+	lowStock float64
+
+	images       storage.ImageStore
+	maxImageSize int64
+
+	publicBaseURL string
+
+	fieldAccess FieldAccess
+
+	flags features.Flags
+
+	tables database.TableDescriber
+
+	clock models.Clock
+}
+
+// HandlerOption configures optional ProductHandler behavior.
+type HandlerOption func(*ProductHandler)
+
+// WithFieldNaming sets the default key style for product responses. Clients
+// can override it per request with the X-Field-Naming header.
+func WithFieldNaming(naming FieldNaming) HandlerOption {
+	return func(h *ProductHandler) {
+		h.naming = naming
+	}
+}
+
+// WithOmitEmpty drops optional product fields, such as an empty description,
+// from responses instead of serializing their zero value.
+func WithOmitEmpty(omit bool) HandlerOption {
+	return func(h *ProductHandler) {
+		h.omitEmpty = omit
+	}
+}
+
+// WithPaginationHeaders controls whether paginated listings advertise
+// X-Page-Limit, X-Total-Count and a rel="next" Link header. On by default.
+func WithPaginationHeaders(enabled bool) HandlerOption {
+	return func(h *ProductHandler) {
+		h.paginationHeaders = enabled
+	}
+}
+
+// WithStrictJSON rejects request bodies carrying fields the endpoint does
+// not know, rather than ignoring them. Clients can opt in per request with
+// a Prefer: strict header.
+func WithStrictJSON(strict bool) HandlerOption {
+	return func(h *ProductHandler) {
+		h.strictJSON = strict
+	}
+}
+
+// WithFeatureFlags sets the flags consulted for behaviors still being
+// rolled out. Without it every flag is off.
+func WithFeatureFlags(flags features.Flags) HandlerOption {
+	return func(h *ProductHandler) {
+		h.flags = flags
+	}
+}
+
+// WithLowStockThreshold sets the stock level at or below which products are
+// reported with availability "low_stock". Zero, the default, only
+// distinguishes in_stock from out_of_stock.
+func WithLowStockThreshold(threshold float64) HandlerOption {
+	return func(h *ProductHandler) {
+		h.lowStock = threshold
+	}
 }
 
-func NewProductHandler(service service.ProductService) *ProductHandler {
-	return &ProductHandler{
-		service: service,
+// WithImageStore makes product responses carry signed image URLs instead of
+// the stored image keys.
+func WithImageStore(store storage.ImageStore) HandlerOption {
+	return func(h *ProductHandler) {
+		h.images = store
 	}
 }
 
+// WithMaxImageSize sets the largest image, in bytes, accepted by
+// UploadImage.
+func WithMaxImageSize(size int64) HandlerOption {
+	return func(h *ProductHandler) {
+		h.maxImageSize = size
+	}
+}
+
+// WithPublicBaseURL sets the scheme and host, e.g. https://api.example.com,
+// prefixed to the Location header of created products. Without it the
+// header holds a path relative to the request's host.
+func WithPublicBaseURL(base string) HandlerOption {
+	return func(h *ProductHandler) {
+		h.publicBaseURL = strings.TrimSuffix(base, "/")
+	}
+}
+
+// WithTableStatus makes ReadinessCheck report the products table's status.
+// Without it the check only confirms the service is serving requests.
+func WithTableStatus(tables database.TableDescriber) HandlerOption {
+	return func(h *ProductHandler) {
+		h.tables = tables
+	}
+}
+
+// WithClock sets the clock pre-order availability is judged by. It should
+// be the service's clock. The system clock is the default.
+func WithClock(clock models.Clock) HandlerOption {
+	return func(h *ProductHandler) {
+		h.clock = clock
+	}
+}
+
+func NewProductHandler(service service.ProductService, opts ...HandlerOption) *ProductHandler {
+	h := &ProductHandler{
+		service:           service,
+		paginationHeaders: true,
+		maxImageSize:      DefaultMaxImageSize,
+		clock:             models.SystemClock,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
 func (h *ProductHandler) CreateProduct(c *gin.Context) {
 	var req models.CreateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
-			"details": err.Error(),
-		})
+	if !h.bindJSON(c, &req) {
 		return
 	}
 
-	product, err := h.service.CreateProduct(req)
+	dryRun := isDryRun(c)
+	product, err := h.service.CreateProduct(mutationContext(c, dryRun), req)
 	if err != nil {
 		if errors.Is(err, service.ErrInvalidProduct) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid product data",
-				"details": err.Error(),
-			})
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to create product",
-			"details": err.Error(),
+		if errors.Is(err, service.ErrDuplicateSKU) {
+			writeJSON(c, http.StatusConflict, h.duplicateSKUBody(err))
+			return
+		}
+		writeServerError(c, "Failed to create product", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"product": h.productView(c, product),
 		})
 		return
 	}
 
-	c.JSON(http.StatusCreated, product)
+	c.Header("Location", h.productLocation(product.ID))
+	writeJSON(c, http.StatusCreated, h.productView(c, product))
+}
+
+// productLocation is the URL of the product with the given ID.
+func (h *ProductHandler) productLocation(id string) string {
+	return h.publicBaseURL + "/api/v1/products/" + url.PathEscape(id)
 }
 
 func (h *ProductHandler) GetProduct(c *gin.Context) {
 	id := c.Param("id")
 	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	h.getProduct(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.GetProduct(ctx, id)
+	})
+}
+
+// GetShipping serves the product's weight, dimensions and derived
+// shipping weights for shipping estimates.
+func (h *ProductHandler) GetShipping(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	shipping, err := h.service.GetShipping(c.Request.Context(), id)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		writeServerError(c, "Failed to get product shipping", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, shipping)
+}
+
+// GetBundle serves a bundle expanded into its component products, with the
+// price and availability derived from them.
+func (h *ProductHandler) GetBundle(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
 			"error": "Product ID is required",
 		})
 		return
 	}
 
-	product, err := h.service.GetProduct(id)
+	bundle, err := h.service.GetBundle(c.Request.Context(), id)
 	if err != nil {
 		if errors.Is(err, service.ErrProductNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Product not found",
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrNotBundle) {
+			writeJSON(c, http.StatusNotFound, gin.H{
+				"error": "Product is not a bundle",
+				"code":  errorCode(err),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get product",
-			"details": err.Error(),
+		writeServerError(c, "Failed to get product bundle", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, h.bundleView(c, bundle))
+}
+
+// GetProductBySlug serves the storefront's slug URLs.
+func (h *ProductHandler) GetProductBySlug(c *gin.Context) {
+	slug := c.Param("slug")
+	if slug == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product slug is required",
+		})
+		return
+	}
+
+	h.getProduct(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.GetProductBySlug(ctx, slug)
+	})
+}
+
+// LookupProduct serves a product by whichever of the id and sku query
+// parameters the client has; exactly one must be given. A miss is reported
+// like GET /products/:id, naming the key that was looked up.
+func (h *ProductHandler) LookupProduct(c *gin.Context) {
+	id, sku := c.Query("id"), c.Query("sku")
+	if (id == "") == (sku == "") {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
+			"details": "exactly one of id or sku is required",
+		})
+		return
+	}
+
+	if id != "" {
+		c.AddParam("id", id)
+		h.getProduct(c, func(ctx context.Context) (*models.Product, error) {
+			return h.service.GetProduct(ctx, id)
 		})
 		return
 	}
+	c.AddParam("sku", sku)
+	h.getProduct(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.GetProductBySKU(ctx, sku)
+	})
+}
+
+// getProduct writes the product fetch returns, localized to the request's
+// Accept-Language when the product has translations.
+func (h *ProductHandler) getProduct(c *gin.Context, fetch func(ctx context.Context) (*models.Product, error)) {
+	product, err := fetch(c.Request.Context())
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		writeServerError(c, "Failed to get product", err)
+		return
+	}
+
+	if len(product.Translations) > 0 {
+		c.Writer.Header().Add("Vary", "Accept-Language")
+		if localized, locale := product.Localized(c.GetHeader("Accept-Language")); locale != "" {
+			c.Header("Content-Language", locale)
+			product = localized
+		}
+	}
+
+	product, err = h.signImages(c.Request.Context(), product)
+	if err != nil {
+		writeServerError(c, "Failed to sign image URLs", err)
+		return
+	}
+
+	if now := h.clock.Now(); product.IsPreorder(now) {
+		expireBy(c, now, *product.AvailableFrom)
+	}
+
+	writeJSON(c, http.StatusOK, h.productView(c, product))
+}
+
+// signImages returns a copy of product whose images are signed URLs. Only
+// keys under the product's own prefix are signed; URLs and any other
+// entries are returned as stored. The product is returned unchanged when no
+// image store is configured.
+func (h *ProductHandler) signImages(ctx context.Context, product *models.Product) (*models.Product, error) {
+	if h.images == nil || len(product.Images) == 0 {
+		return product, nil
+	}
+
+	prefix := storage.ProductImagePrefix(product.ID)
+	urls := make([]string, len(product.Images))
+	for i, key := range product.Images {
+		if !strings.HasPrefix(key, prefix) {
+			urls[i] = key
+			continue
+		}
+		url, err := h.images.SignedURL(ctx, key)
+		if err != nil {
+			return nil, err
+		}
+		urls[i] = url
+	}
+
+	signed := *product
+	signed.Images = urls
+	return &signed, nil
+}
+
+// ProductExistsBySKU answers HEAD requests with 200 or 404 and no body, so
+// clients can cheaply check a SKU before creating a product.
+func (h *ProductHandler) ProductExistsBySKU(c *gin.Context) {
+	sku := c.Param("sku")
+	if sku == "" {
+		c.Status(http.StatusBadRequest)
+		return
+	}
+
+	exists, err := h.service.ProductExistsBySKU(c.Request.Context(), sku)
+	if errors.Is(err, database.ErrSaturated) {
+		c.Header("Retry-After", saturatedRetryAfter)
+		c.Status(http.StatusServiceUnavailable)
+		return
+	}
+	if err != nil {
+		c.Status(http.StatusInternalServerError)
+		return
+	}
+
+	if !exists {
+		c.Status(http.StatusNotFound)
+		return
+	}
 
-	c.JSON(http.StatusOK, product)
+	c.Status(http.StatusOK)
 }
 
 func (h *ProductHandler) GetAllProducts(c *gin.Context) {
-	products, err := h.service.GetAllProducts()
+	opts, err := listOptions(c)
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get products",
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"products": products,
-		"count":    len(products),
-	})
+	list, err := h.service.GetAllProducts(c.Request.Context(), opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get products", err)
+		return
+	}
+
+	// Polling clients send back the ETag and get a bodiless 304 while the
+	// catalog is unchanged.
+	etag := h.listETag(c, list)
+	c.Header("ETag", etag)
+	c.Writer.Header().Add("Vary", fieldNamingHeader)
+	response := h.listResponse(c, list)
+	if !list.BudgetExceeded && etagMatches(c.GetHeader("If-None-Match"), etag) {
+		c.Status(http.StatusNotModified)
+		return
+	}
+
+	writeJSON(c, listStatus(list), response)
 }
 
 func (h *ProductHandler) GetProductsByCategory(c *gin.Context) {
 	category := c.Query("category")
 	if category == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
+		writeJSON(c, http.StatusBadRequest, gin.H{
 			"error": "Category query parameter is required",
 		})
 		return
 	}
 
-	products, err := h.service.GetProductsByCategory(category)
+	opts, err := listOptions(c)
+	if err == nil {
+		opts.Subcategories, err = includeSubcategories(c)
+	}
 	if err != nil {
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to get products by category",
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
 			"details": err.Error(),
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"products": products,
-		"category": category,
-		"count":    len(products),
-	})
+	list, err := h.service.GetProductsByCategory(c.Request.Context(), category, opts)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get products by category", err)
+		return
+	}
+
+	response := h.listResponse(c, list)
+	response["category"] = category
+	writeJSON(c, listStatus(list), response)
 }
 
-func (h *ProductHandler) UpdateProduct(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Product ID is required",
+func (h *ProductHandler) FilterProducts(c *gin.Context) {
+	filter, err := productFilter(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid filter query parameter",
+			"code":    errorCode(service.ErrInvalidQuery),
+			"details": err.Error(),
 		})
 		return
 	}
 
-	var req models.UpdateProductRequest
-	if err := c.ShouldBindJSON(&req); err != nil {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error":   "Invalid request body",
+	opts, err := listOptions(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
 			"details": err.Error(),
 		})
 		return
 	}
 
-	product, err := h.service.UpdateProduct(id, req)
+	list, err := h.service.FilterProducts(c.Request.Context(), filter, opts)
 	if err != nil {
-		if errors.Is(err, service.ErrProductNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Product not found",
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
 			})
 			return
 		}
-		if errors.Is(err, service.ErrInvalidProduct) {
-			c.JSON(http.StatusBadRequest, gin.H{
-				"error":   "Invalid product data",
+		writeServerError(c, "Failed to filter products", err)
+		return
+	}
+
+	writeJSON(c, listStatus(list), h.listResponse(c, list))
+}
+
+func (h *ProductHandler) GetTrendingProducts(c *gin.Context) {
+	limit := defaultTrendingLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit query parameter",
+				"code":    errorCode(service.ErrInvalidQuery),
 				"details": err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to update product",
-			"details": err.Error(),
-		})
+		limit = parsed
+	}
+
+	products, err := h.service.GetTrendingProducts(c.Request.Context(), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get trending products", err)
 		return
 	}
 
-	c.JSON(http.StatusOK, product)
+	writeJSON(c, http.StatusOK, gin.H{
+		"products": h.productViews(c, products),
+		"count":    len(products),
+	})
 }
 
-func (h *ProductHandler) DeleteProduct(c *gin.Context) {
-	id := c.Param("id")
-	if id == "" {
-		c.JSON(http.StatusBadRequest, gin.H{
-			"error": "Product ID is required",
+// SuggestProducts serves typeahead: the IDs and names of active products
+// whose name starts with the prefix query parameter.
+func (h *ProductHandler) SuggestProducts(c *gin.Context) {
+	limit := defaultSuggestLimit
+	if raw := c.Query("limit"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid limit query parameter",
+				"code":    errorCode(service.ErrInvalidQuery),
+				"details": err.Error(),
+			})
+			return
+		}
+		limit = parsed
+	}
+
+	suggestions, err := h.service.SuggestProducts(c.Request.Context(), strings.TrimSpace(c.Query("prefix")), limit)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to suggest products", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, gin.H{
+		"suggestions": suggestions,
+		"count":       len(suggestions),
+	})
+}
+
+// SearchProducts serves a page of products whose name contains the q
+// query parameter. It pages with limit and cursor like the listings.
+func (h *ProductHandler) SearchProducts(c *gin.Context) {
+	opts, err := listOptions(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid query",
+			"code":    errorCode(service.ErrInvalidQuery),
+			"details": err.Error(),
 		})
 		return
 	}
 
-	err := h.service.DeleteProduct(id)
+	list, err := h.service.SearchProducts(c.Request.Context(), c.Query("q"), opts)
 	if err != nil {
-		if errors.Is(err, service.ErrProductNotFound) {
-			c.JSON(http.StatusNotFound, gin.H{
-				"error": "Product not found",
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid query",
+				"code":    errorCode(err),
+				"details": err.Error(),
 			})
 			return
 		}
-		c.JSON(http.StatusInternalServerError, gin.H{
-			"error":   "Failed to delete product",
-			"details": err.Error(),
+		writeServerError(c, "Failed to search products", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, h.listResponse(c, list))
+}
+
+func (h *ProductHandler) GetInventoryValuation(c *gin.Context) {
+	valuation, err := h.service.GetInventoryValuation(c.Request.Context())
+	if err != nil {
+		writeServerError(c, "Failed to compute inventory valuation", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, valuation)
+}
+
+func (h *ProductHandler) UpdateProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
 		})
 		return
 	}
 
-	c.JSON(http.StatusOK, gin.H{
-		"message": "Product deleted successfully",
+	var req models.UpdateProductRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.UpdateProduct(ctx, id, req)
 	})
 }
 
-func (h *ProductHandler) HealthCheck(c *gin.Context) {
-	c.JSON(http.StatusOK, gin.H{
-		"status": "healthy",
-		"service": "product-service",
+// PatchProduct applies an RFC 6902 JSON Patch when the body is sent as
+// application/json-patch+json. Any other body is treated as a merge patch
+// and handled exactly like UpdateProduct.
+func (h *ProductHandler) PatchProduct(c *gin.Context) {
+	if c.ContentType() != jsonPatchContentType {
+		h.UpdateProduct(c)
+		return
+	}
+
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	var ops []models.PatchOperation
+	if !h.bindJSON(c, &ops) {
+		return
+	}
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.PatchProduct(ctx, id, ops)
 	})
-}
\ No newline at end of file
+}
+
+// update runs an update with the request's dry-run, return=old and
+// If-Unmodified-Since preferences applied and writes the response.
+func (h *ProductHandler) update(c *gin.Context, apply func(ctx context.Context) (*models.Product, error)) {
+	dryRun := isDryRun(c)
+	ctx := mutationContext(c, dryRun)
+	var previous *service.Previous
+	if returnOld(c) {
+		ctx, previous = service.WithPrevious(ctx)
+	}
+	// An unparseable If-Unmodified-Since is ignored, as RFC 9110 requires.
+	if since, err := http.ParseTime(c.GetHeader("If-Unmodified-Since")); err == nil {
+		ctx = service.WithUnmodifiedSince(ctx, since)
+	}
+	ctx, changes := service.WithChanges(ctx)
+
+	product, err := apply(ctx)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			writeJSON(c, http.StatusPreconditionFailed, gin.H{
+				"error": "Product was modified since the If-Unmodified-Since time",
+				"code":  errorCode(err),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrConcurrentModification) {
+			writeJSON(c, http.StatusConflict, concurrentModificationBody(err))
+			return
+		}
+		if errors.Is(err, service.ErrPatchTestFailed) {
+			writeJSON(c, http.StatusConflict, gin.H{
+				"error":   "Patch test failed",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		if errors.Is(err, service.ErrDuplicateSKU) {
+			writeJSON(c, http.StatusConflict, h.duplicateSKUBody(err))
+			return
+		}
+		writeServerError(c, "Failed to update product", err)
+		return
+	}
+
+	if previous != nil && previous.Product != nil {
+		response := gin.H{
+			"old":     h.productView(c, previous.Product),
+			"new":     h.productView(c, product),
+			"changes": h.changesView(c, changes.Fields),
+		}
+		if dryRun {
+			response["dry_run"] = true
+		}
+		writeJSON(c, http.StatusOK, response)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"product": h.productView(c, product),
+			"changes": h.changesView(c, changes.Fields),
+		})
+		return
+	}
+
+	view := append(h.productView(c, product), productField{key: "changes", value: h.changesView(c, changes.Fields)})
+	writeJSON(c, http.StatusOK, view)
+}
+
+func (h *ProductHandler) RateProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	var req models.RatingRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	product, err := h.service.RateProduct(c.Request.Context(), id, req.Rating)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		writeServerError(c, "Failed to rate product", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, h.productView(c, product))
+}
+
+func (h *ProductHandler) DeleteProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	dryRun := isDryRun(c)
+	ctx := mutationContext(c, dryRun)
+	var previous *service.Previous
+	if returnOld(c) {
+		ctx, previous = service.WithPrevious(ctx)
+	}
+	version, ok, err := expectedVersion(c)
+	if err != nil {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "Invalid expected version",
+			"details": err.Error(),
+		})
+		return
+	}
+	if ok {
+		ctx = service.WithExpectedVersion(ctx, version)
+	}
+
+	if soft, _ := strconv.ParseBool(c.Query("soft")); soft {
+		err = h.service.SoftDeleteProduct(ctx, id)
+	} else {
+		err = h.service.DeleteProduct(ctx, id)
+	}
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrHardDeleteDisabled) {
+			writeJSON(c, http.StatusForbidden, gin.H{
+				"error": "Hard delete is disabled; use soft=true",
+				"code":  errorCode(err),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrPreconditionFailed) {
+			writeJSON(c, http.StatusPreconditionFailed, gin.H{
+				"error":   "Product version does not match",
+				"code":    errorCode(err),
+				"id":      id,
+				"version": version,
+			})
+			return
+		}
+		if errors.Is(err, service.ErrConcurrentModification) {
+			writeJSON(c, http.StatusConflict, concurrentModificationBody(err))
+			return
+		}
+		writeServerError(c, "Failed to delete product", err)
+		return
+	}
+
+	var response gin.H
+	if dryRun {
+		response = gin.H{
+			"dry_run": true,
+			"message": "Product would be deleted",
+		}
+	} else {
+		response = gin.H{
+			"message": "Product deleted successfully",
+		}
+	}
+	if previous != nil && previous.Product != nil {
+		response["deleted"] = h.productView(c, previous.Product)
+	}
+	writeJSON(c, http.StatusOK, response)
+}
+
+func (h *ProductHandler) RestoreProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	dryRun := isDryRun(c)
+	product, err := h.service.RestoreProduct(mutationContext(c, dryRun), id)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrConcurrentModification) {
+			writeJSON(c, http.StatusConflict, concurrentModificationBody(err))
+			return
+		}
+		writeServerError(c, "Failed to restore product", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"product": h.productView(c, product),
+		})
+		return
+	}
+
+	writeJSON(c, http.StatusOK, h.productView(c, product))
+}
+
+// UploadImage stores the multipart "image" file and appends it to the
+// product's images.
+func (h *ProductHandler) UploadImage(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	if h.images == nil {
+		writeJSON(c, http.StatusNotImplemented, gin.H{
+			"error": "Image uploads are not configured",
+		})
+		return
+	}
+
+	c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, h.maxImageSize+multipartOverhead)
+	header, err := c.FormFile("image")
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			h.imageTooLarge(c)
+			return
+		}
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error":   "An image file is required in the image form field",
+			"details": err.Error(),
+		})
+		return
+	}
+	if header.Size > h.maxImageSize {
+		h.imageTooLarge(c)
+		return
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		writeServerError(c, "Failed to read image", err)
+		return
+	}
+	defer file.Close()
+
+	// The part's Content-Type header is whatever the client claims, so the
+	// type is sniffed from the content instead.
+	head := make([]byte, 512)
+	n, _ := io.ReadFull(file, head)
+	contentType := http.DetectContentType(head[:n])
+	if _, ok := storage.ImageExtension(contentType); !ok {
+		writeJSON(c, http.StatusUnsupportedMediaType, gin.H{
+			"error": fmt.Sprintf("Unsupported image type %q; use JPEG, PNG, GIF or WebP", contentType),
+		})
+		return
+	}
+	if _, err := file.Seek(0, io.SeekStart); err != nil {
+		writeServerError(c, "Failed to read image", err)
+		return
+	}
+
+	dryRun := isDryRun(c)
+	product, key, err := h.service.AddProductImage(mutationContext(c, dryRun), id, contentType, file)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrImageStoreDisabled) {
+			writeJSON(c, http.StatusNotImplemented, gin.H{
+				"error": "Image uploads are not configured",
+				"code":  errorCode(err),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		writeServerError(c, "Failed to upload image", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"key":     key,
+			"product": h.productView(c, product),
+		})
+		return
+	}
+
+	url, err := h.images.SignedURL(c.Request.Context(), key)
+	if err == nil {
+		product, err = h.signImages(c.Request.Context(), product)
+	}
+	if err != nil {
+		writeServerError(c, "Failed to sign image URLs", err)
+		return
+	}
+
+	writeJSON(c, http.StatusCreated, gin.H{
+		"key":     key,
+		"url":     url,
+		"product": h.productView(c, product),
+	})
+}
+
+func (h *ProductHandler) imageTooLarge(c *gin.Context) {
+	writeJSON(c, http.StatusRequestEntityTooLarge, gin.H{
+		"error": fmt.Sprintf("Image exceeds the maximum size of %d bytes", h.maxImageSize),
+	})
+}
+
+// ReconcileProduct recomputes the product's view and rating counters and
+// corrects any that have drifted.
+func (h *ProductHandler) ReconcileProduct(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	dryRun := isDryRun(c)
+	result, err := h.service.ReconcileProduct(mutationContext(c, dryRun), id)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		writeServerError(c, "Failed to reconcile product", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"result":  result,
+		})
+		return
+	}
+
+	writeJSON(c, http.StatusOK, result)
+}
+
+func (h *ProductHandler) ReserveStock(c *gin.Context) {
+	id := c.Param("id")
+	if id == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product ID is required",
+		})
+		return
+	}
+
+	var req models.ReserveStockRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	reservation, err := h.service.ReserveStock(mutationContext(c, dryRun), id, req)
+	if err != nil {
+		if errors.Is(err, service.ErrProductNotFound) {
+			writeJSON(c, http.StatusNotFound, productNotFoundBody(c))
+			return
+		}
+		if errors.Is(err, service.ErrInsufficientStock) {
+			writeJSON(c, http.StatusConflict, gin.H{
+				"error":   "Insufficient stock",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		writeServerError(c, "Failed to reserve stock", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"result":  reservation,
+		})
+		return
+	}
+
+	writeJSON(c, http.StatusCreated, reservation)
+}
+
+func (h *ProductHandler) ReleaseReservation(c *gin.Context) {
+	id, reservationID := c.Param("id"), c.Param("reservation_id")
+	if id == "" || reservationID == "" {
+		writeJSON(c, http.StatusBadRequest, gin.H{
+			"error": "Product and reservation IDs are required",
+		})
+		return
+	}
+
+	dryRun := isDryRun(c)
+	if err := h.service.ReleaseReservation(mutationContext(c, dryRun), id, reservationID); err != nil {
+		if errors.Is(err, service.ErrReservationNotFound) {
+			writeJSON(c, http.StatusNotFound, gin.H{
+				"error":      "Reservation not found",
+				"code":       errorCode(err),
+				"resource":   "reservation",
+				"id":         reservationID,
+				"product_id": id,
+			})
+			return
+		}
+		writeServerError(c, "Failed to release reservation", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"message": "Reservation would be released",
+		})
+		return
+	}
+
+	writeJSON(c, http.StatusOK, gin.H{
+		"message": "Reservation released successfully",
+	})
+}
+
+func (h *ProductHandler) RenameCategory(c *gin.Context) {
+	var req models.RenameCategoryRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	updated, err := h.service.RenameCategory(mutationContext(c, dryRun), req.From, req.To)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid category rename",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		if errors.Is(err, service.ErrInvalidProduct) {
+			writeJSON(c, http.StatusBadRequest, invalidProductBody(err))
+			return
+		}
+		writeJSON(c, http.StatusInternalServerError, gin.H{
+			"error":   "Failed to rename category",
+			"details": err.Error(),
+			"updated": updated,
+		})
+		return
+	}
+
+	response := gin.H{
+		"from":    req.From,
+		"to":      req.To,
+		"updated": updated,
+	}
+	if dryRun {
+		response["dry_run"] = true
+	}
+	writeJSON(c, http.StatusOK, response)
+}
+
+// BatchGetProducts fetches the products listed in the body in one call,
+// in the order they were listed, reporting the IDs with no product as
+// missing.
+func (h *ProductHandler) BatchGetProducts(c *gin.Context) {
+	var req models.BatchGetRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.GetProductsByIDs(c.Request.Context(), req.IDs)
+	h.writeBatchGet(c, result, err)
+}
+
+// BatchGetProductsBySKU is BatchGetProducts for products referenced by SKU.
+// The missing SKUs are reported trimmed.
+func (h *ProductHandler) BatchGetProductsBySKU(c *gin.Context) {
+	var req models.BatchGetBySKURequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	result, err := h.service.GetProductsBySKUs(c.Request.Context(), req.SKUs)
+	h.writeBatchGet(c, result, err)
+}
+
+func (h *ProductHandler) writeBatchGet(c *gin.Context, result *models.BatchGetResult, err error) {
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid batch get",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to get products", err)
+		return
+	}
+
+	writeJSON(c, http.StatusOK, gin.H{
+		"products": h.productViews(c, result.Products),
+		"missing":  result.Missing,
+		"count":    len(result.Products),
+	})
+}
+
+// BulkUpsertProducts creates or updates each listed product by SKU and
+// reports per item what happened. Failed items do not fail the request.
+// Items are not run through the binding rules, which would reject the whole
+// batch for one bad item; the service validates each one instead.
+func (h *ProductHandler) BulkUpsertProducts(c *gin.Context) {
+	var reqs []models.CreateProductRequest
+	if !h.bindJSONItems(c, &reqs) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	results, err := h.service.BulkUpsertProducts(mutationContext(c, dryRun), reqs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid bulk upsert",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to upsert products", err)
+		return
+	}
+
+	counts := make(map[string]int)
+	for _, result := range results {
+		counts[result.Status]++
+	}
+	response := gin.H{
+		"results":   results,
+		"created":   counts[models.UpsertCreated],
+		"updated":   counts[models.UpsertUpdated],
+		"unchanged": counts[models.UpsertUnchanged],
+		"failed":    counts[models.UpsertFailed],
+	}
+	if dryRun {
+		response["dry_run"] = true
+	}
+	writeJSON(c, http.StatusOK, response)
+}
+
+func (h *ProductHandler) BulkSetStock(c *gin.Context) {
+	var updates []models.StockUpdate
+	if !h.bindJSON(c, &updates) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	results, err := h.service.BulkSetStock(mutationContext(c, dryRun), updates)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid bulk stock update",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to update stock", err)
+		return
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Status == models.StockFailed {
+			failed++
+		}
+	}
+	response := gin.H{
+		"results": results,
+		"failed":  failed,
+	}
+	if dryRun {
+		response["dry_run"] = true
+	}
+	writeJSON(c, http.StatusOK, response)
+}
+
+// ClearStock zeroes a product's stock, recording the reason given in the
+// body. Unlike an update it needs no computed delta, and it is safe against
+// concurrent stock changes.
+func (h *ProductHandler) ClearStock(c *gin.Context) {
+	id := c.Param("id")
+	var req models.ClearStockRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.ClearStock(ctx, id, req)
+	})
+}
+
+// BulkSetStatus activates or deactivates every product in a category or in
+// a list of IDs.
+func (h *ProductHandler) BulkSetStatus(c *gin.Context) {
+	var req models.BulkStatusRequest
+	if !h.bindJSON(c, &req) {
+		return
+	}
+
+	dryRun := isDryRun(c)
+	result, err := h.service.BulkSetStatus(mutationContext(c, dryRun), req)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid bulk status update",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to update product status", err)
+		return
+	}
+
+	if dryRun {
+		writeJSON(c, http.StatusOK, gin.H{
+			"dry_run": true,
+			"result":  result,
+		})
+		return
+	}
+	writeJSON(c, http.StatusOK, result)
+}
+
+// SetTranslation stores the product's name and description for the locale
+// in the path.
+func (h *ProductHandler) SetTranslation(c *gin.Context) {
+	id, locale := c.Param("id"), c.Param("locale")
+
+	var translation models.ProductTranslation
+	if !h.bindJSON(c, &translation) {
+		return
+	}
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.SetTranslation(ctx, id, locale, translation)
+	})
+}
+
+func (h *ProductHandler) RemoveTranslation(c *gin.Context) {
+	id, locale := c.Param("id"), c.Param("locale")
+
+	h.update(c, func(ctx context.Context) (*models.Product, error) {
+		return h.service.RemoveTranslation(ctx, id, locale)
+	})
+}
+
+func (h *ProductHandler) HealthCheck(c *gin.Context) {
+	writeJSON(c, http.StatusOK, gin.H{
+		"status":  "healthy",
+		"service": "product-service",
+	})
+}
+
+// ReadinessCheck reports whether the products table can serve traffic. A
+// table that is being created or updated is reported as degraded but still
+// answers 200; a missing, deleted or unreachable table answers 503.
+func (h *ProductHandler) ReadinessCheck(c *gin.Context) {
+	if h.tables == nil {
+		h.HealthCheck(c)
+		return
+	}
+
+	table, err := h.tables.DescribeTable(c.Request.Context())
+	if err != nil {
+		writeJSON(c, http.StatusServiceUnavailable, gin.H{
+			"status":  "unavailable",
+			"service": "product-service",
+			"details": err.Error(),
+		})
+		return
+	}
+
+	code, status := http.StatusOK, "healthy"
+	switch table.Status {
+	case dynamodb.TableStatusActive:
+	case dynamodb.TableStatusCreating, dynamodb.TableStatusUpdating:
+		status = "degraded"
+	default:
+		code, status = http.StatusServiceUnavailable, "unavailable"
+	}
+	writeJSON(c, code, gin.H{
+		"status":  status,
+		"service": "product-service",
+		"table":   table,
+	})
+}
+
+// invalidProductBody builds the 400 response for a validation failure,
+// naming the offending field when the service reported one.
+func invalidProductBody(err error) gin.H {
+	body := gin.H{
+		"error":   "Invalid product data",
+		"code":    errorCode(err),
+		"details": err.Error(),
+	}
+	var fieldErr *service.FieldError
+	if errors.As(err, &fieldErr) {
+		body["field"] = fieldErr.Field
+	}
+	return body
+}
+
+// duplicateSKUBody builds the 409 response for a SKU another product
+// already has, naming that product so the client can update it instead.
+func (h *ProductHandler) duplicateSKUBody(err error) gin.H {
+	body := gin.H{
+		"error":   "Product SKU already exists",
+		"code":    errorCode(err),
+		"details": err.Error(),
+	}
+	var duplicate *service.DuplicateSKUError
+	if errors.As(err, &duplicate) {
+		body["sku"] = duplicate.SKU
+		body["existing_id"] = duplicate.ExistingID
+		body["existing_url"] = h.productLocation(duplicate.ExistingID)
+	}
+	return body
+}
+
+// concurrentModificationBody builds the 409 response for a write that kept
+// racing other writes to the product; the client can simply retry.
+func concurrentModificationBody(err error) gin.H {
+	return gin.H{
+		"error":   "Product was modified concurrently; retry the request",
+		"code":    errorCode(err),
+		"details": err.Error(),
+	}
+}
+
+// productNotFoundBody builds the 404 response for a missing product,
+// echoing the ID or slug the request asked for.
+func productNotFoundBody(c *gin.Context) gin.H {
+	body := gin.H{
+		"error":    "Product not found",
+		"code":     errorCode(service.ErrProductNotFound),
+		"resource": "product",
+	}
+	if id := c.Param("id"); id != "" {
+		body["id"] = id
+	} else if slug := c.Param("slug"); slug != "" {
+		body["slug"] = slug
+	} else if sku := c.Param("sku"); sku != "" {
+		body["sku"] = sku
+	}
+	return body
+}
+
+// isDryRun reports whether the caller asked for a dry run via the dry_run
+// query parameter or the X-Dry-Run header.
+func isDryRun(c *gin.Context) bool {
+	value := c.Query("dry_run")
+	if value == "" {
+		value = c.GetHeader("X-Dry-Run")
+	}
+	dryRun, _ := strconv.ParseBool(value)
+	return dryRun
+}
+
+// listOptions reads the listing preferences from the query string.
+func listOptions(c *gin.Context) (models.ListOptions, error) {
+	opts := models.ListOptions{
+		Sort:   c.Query("sort"),
+		Cursor: c.Query("cursor"),
+	}
+	if raw := c.Query("limit"); raw != "" {
+		limit, err := strconv.Atoi(raw)
+		if err != nil {
+			return models.ListOptions{}, fmt.Errorf("limit: %w", err)
+		}
+		opts.Limit = limit
+	}
+	return opts, nil
+}
+
+// listResponse builds the body shared by the listing endpoints, including
+// the pagination object. Paginated listings also get a top-level
+// next_cursor, kept for older clients, and, when enabled, the
+// X-Page-Limit, X-Total-Count and Link headers.
+func (h *ProductHandler) listResponse(c *gin.Context, list *models.ProductList) gin.H {
+	return h.pagedResponse(c, list, h.productViews(c, list.Products))
+}
+
+// listStatus is the status of a listing response: 429 when the scan ran out
+// of its capacity budget and the body holds partial results, 200 otherwise.
+func listStatus(list *models.ProductList) int {
+	if list.BudgetExceeded {
+		return http.StatusTooManyRequests
+	}
+	return http.StatusOK
+}
+
+// pagedResponse is listResponse with the products already rendered, for
+// listings that use another representation.
+func (h *ProductHandler) pagedResponse(c *gin.Context, list *models.ProductList, products any) gin.H {
+	response := gin.H{
+		"products":   products,
+		"count":      len(list.Products),
+		"truncated":  list.Truncated,
+		"pagination": list.PageMeta(),
+	}
+	if list.BudgetExceeded {
+		response["budget_exceeded"] = true
+	}
+	if list.Limit == 0 {
+		return response
+	}
+
+	if list.NextCursor != "" {
+		response["next_cursor"] = list.NextCursor
+	}
+
+	if !h.paginationHeaders {
+		return response
+	}
+	c.Header("X-Page-Limit", strconv.Itoa(list.Limit))
+	h.setTotalCount(c, list)
+	if list.NextCursor != "" {
+		next := *c.Request.URL
+		query := next.Query()
+		query.Set("cursor", list.NextCursor)
+		next.RawQuery = query.Encode()
+		c.Header("Link", fmt.Sprintf(`<%s>; rel="next"`, next.RequestURI()))
+	}
+	return response
+}
+
+// productFilter reads the filter endpoint's query parameters. Absent
+// parameters leave the corresponding criterion unset.
+func productFilter(c *gin.Context) (models.ProductFilter, error) {
+	filter := models.ProductFilter{
+		Category: c.Query("category"),
+		Tag:      c.Query("tag"),
+		Name:     c.Query("name"),
+	}
+
+	var err error
+	if filter.MinPrice, err = floatQuery(c, "min_price"); err != nil {
+		return models.ProductFilter{}, err
+	}
+	if filter.MaxPrice, err = floatQuery(c, "max_price"); err != nil {
+		return models.ProductFilter{}, err
+	}
+
+	if raw := c.Query("in_stock"); raw != "" {
+		v, err := strconv.ParseBool(raw)
+		if err != nil {
+			return models.ProductFilter{}, fmt.Errorf("in_stock: %w", err)
+		}
+		filter.InStock = &v
+	}
+
+	return filter, nil
+}
+
+// includeSubcategories parses the optional include_subcategories flag of
+// category listings.
+func includeSubcategories(c *gin.Context) (bool, error) {
+	raw := c.Query("include_subcategories")
+	if raw == "" {
+		return false, nil
+	}
+	v, err := strconv.ParseBool(raw)
+	if err != nil {
+		return false, fmt.Errorf("include_subcategories: %w", err)
+	}
+	return v, nil
+}
+
+// floatQuery parses an optional numeric query parameter.
+func floatQuery(c *gin.Context, param string) (*float64, error) {
+	raw := c.Query(param)
+	if raw == "" {
+		return nil, nil
+	}
+	v, err := strconv.ParseFloat(raw, 64)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", param, err)
+	}
+	// ParseFloat accepts "NaN" and "Inf", which no price can match.
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return nil, fmt.Errorf("%s: must be a finite number", param)
+	}
+	return &v, nil
+}
+
+// expectedVersion reads the product version a delete is conditional on,
+// from an If-Match header carrying the version as an entity tag ("3") or
+// from the expected_version query parameter. "If-Match: *" only requires
+// the product to exist, which every delete already does.
+func expectedVersion(c *gin.Context) (int64, bool, error) {
+	value := c.Query("expected_version")
+	if value == "" {
+		value = strings.TrimSpace(c.GetHeader("If-Match"))
+		if value == "" || value == "*" {
+			return 0, false, nil
+		}
+		// If-Match uses the strong comparison, so weak tags never match.
+		if strings.HasPrefix(value, "W/") {
+			return 0, false, fmt.Errorf("If-Match: weak entity tags are not supported")
+		}
+		value = strings.Trim(value, `"`)
+	}
+	version, err := strconv.ParseInt(value, 10, 64)
+	if err != nil || version < 0 {
+		return 0, false, fmt.Errorf("version %q is not a non-negative integer", value)
+	}
+	return version, true, nil
+}
+
+// returnOld reports whether the caller asked for the product's prior state
+// via ?return=old or a Prefer: return=representation header.
+func returnOld(c *gin.Context) bool {
+	if c.Query("return") == "old" {
+		return true
+	}
+	return prefers(c, "return=representation")
+}
+
+func mutationContext(c *gin.Context, dryRun bool) context.Context {
+	ctx := c.Request.Context()
+	if dryRun {
+		ctx = service.WithDryRun(ctx)
+	}
+	if region := c.GetHeader("X-Region"); region != "" {
+		ctx = service.WithRegion(ctx, region)
+	}
+	return ctx
+}
@@ -1,196 +1,2978 @@
 package handlers
 
 import (
+	"bufio"
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"product-service/internal/auth"
+	"product-service/internal/database"
+	"product-service/internal/features"
 	"product-service/internal/models"
+	"product-service/internal/repository"
 	"product-service/internal/service"
 )
 
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
 type MockProductService struct {
 	mock.Mock
 }
 
-func (m *MockProductService) CreateProduct(req models.CreateProductRequest) (*models.Product, error) {
-	args := m.Called(req)
-	return args.Get(0).(*models.Product), args.Error(1)
-}
+func (m *MockProductService) CreateProduct(ctx context.Context, req models.CreateProductRequest) (*models.Product, error) {
+	args := m.Called(req)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProduct(ctx context.Context, id string) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) ValidateProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.ValidationResult, error) {
+	args := m.Called(reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ValidationResult), args.Error(1)
+}
+
+func (m *MockProductService) GetShipping(ctx context.Context, id string) (*models.ShippingInfo, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ShippingInfo), args.Error(1)
+}
+
+func (m *MockProductService) GetBundle(ctx context.Context, id string) (*models.ProductBundle, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductBundle), args.Error(1)
+}
+
+func (m *MockProductService) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	args := m.Called(sku)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProductBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	args := m.Called(slug)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) ProductExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	args := m.Called(sku)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductService) GetAllProducts(ctx context.Context, opts models.ListOptions) (*models.ProductList, error) {
+	args := m.Called(opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductService) GetProductsByCategory(ctx context.Context, category string, opts models.ListOptions) (*models.ProductList, error) {
+	args := m.Called(category, opts)
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductService) GetProductChanges(ctx context.Context, since time.Time, opts models.ListOptions) (*models.ProductList, error) {
+	args := m.Called(since, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductService) FilterProducts(ctx context.Context, filter models.ProductFilter, opts models.ListOptions) (*models.ProductList, error) {
+	args := m.Called(filter, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductService) GetTrendingProducts(ctx context.Context, limit int) ([]*models.Product, error) {
+	args := m.Called(limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProductsByIDs(ctx context.Context, ids []string) (*models.BatchGetResult, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchGetResult), args.Error(1)
+}
+
+func (m *MockProductService) CreateDraft(ctx context.Context, req models.CreateProductRequest) (*models.Product, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) PublishProduct(ctx context.Context, id string) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetDraftProducts(ctx context.Context) ([]*models.Product, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetProductsBySKUs(ctx context.Context, skus []string) (*models.BatchGetResult, error) {
+	args := m.Called(skus)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BatchGetResult), args.Error(1)
+}
+
+func (m *MockProductService) GetFeaturedProducts(ctx context.Context) ([]*models.Product, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) SetFeatured(ctx context.Context, id string, rank int) (*models.Product, error) {
+	args := m.Called(id, rank)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) UnsetFeatured(ctx context.Context, id string) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) ReorderFeatured(ctx context.Context, ids []string) ([]*models.Product, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) GetInventoryValuation(ctx context.Context) (*models.InventoryValuation, error) {
+	args := m.Called()
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.InventoryValuation), args.Error(1)
+}
+
+func (m *MockProductService) ExportProducts(ctx context.Context, fn func(page []*models.Product) error) error {
+	args := m.Called()
+	for _, page := range args.Get(0).([][]*models.Product) {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductService) RenameCategory(ctx context.Context, from, to string) (int, error) {
+	args := m.Called(from, to)
+	return args.Int(0), args.Error(1)
+}
+
+func (m *MockProductService) PatchProduct(ctx context.Context, id string, ops []models.PatchOperation) (*models.Product, error) {
+	args := m.Called(id, ops)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) BulkSetStock(ctx context.Context, updates []models.StockUpdate) ([]models.StockUpdateResult, error) {
+	args := m.Called(updates)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.StockUpdateResult), args.Error(1)
+}
+
+func (m *MockProductService) ClearStock(ctx context.Context, id string, req models.ClearStockRequest) (*models.Product, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) BulkUpsertProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.UpsertResult, error) {
+	args := m.Called(reqs)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.UpsertResult), args.Error(1)
+}
+
+func (m *MockProductService) SuggestProducts(ctx context.Context, prefix string, limit int) ([]models.ProductSuggestion, error) {
+	args := m.Called(prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]models.ProductSuggestion), args.Error(1)
+}
+
+func (m *MockProductService) SearchProducts(ctx context.Context, query string, opts models.ListOptions) (*models.ProductList, error) {
+	args := m.Called(query, opts)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductService) BulkSetStatus(ctx context.Context, req models.BulkStatusRequest) (*models.BulkStatusResult, error) {
+	args := m.Called(req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.BulkStatusResult), args.Error(1)
+}
+
+func (m *MockProductService) SetTranslation(ctx context.Context, id, locale string, translation models.ProductTranslation) (*models.Product, error) {
+	args := m.Called(id, locale, translation)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) RemoveTranslation(ctx context.Context, id, locale string) (*models.Product, error) {
+	args := m.Called(id, locale)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) AddProductImage(ctx context.Context, id, contentType string, body io.ReadSeeker) (*models.Product, string, error) {
+	args := m.Called(id, contentType)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).(*models.Product), args.String(1), args.Error(2)
+}
+
+func (m *MockProductService) ReconcileProduct(ctx context.Context, id string) (*models.ReconcileResult, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReconcileResult), args.Error(1)
+}
+
+func (m *MockProductService) ReserveStock(ctx context.Context, id string, req models.ReserveStockRequest) (*models.Reservation, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Reservation), args.Error(1)
+}
+
+func (m *MockProductService) ReleaseReservation(ctx context.Context, id, reservationID string) error {
+	args := m.Called(id, reservationID)
+	return args.Error(0)
+}
+
+func (m *MockProductService) Reindex(ctx context.Context, cursor string, limit int) (*models.ReindexResult, error) {
+	args := m.Called(cursor, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.ReindexResult), args.Error(1)
+}
+
+func (m *MockProductService) UpdateProduct(ctx context.Context, id string, req models.UpdateProductRequest) (*models.Product, error) {
+	args := m.Called(id, req)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) DeleteProduct(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProductService) SoftDeleteProduct(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProductService) RestoreProduct(ctx context.Context, id string) (*models.Product, error) {
+	args := m.Called(id)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductService) RateProduct(ctx context.Context, id string, rating int) (*models.Product, error) {
+	args := m.Called(id, rating)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func setupRouter(handler *ProductHandler) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+
+	api := router.Group("/api/v1")
+	api.GET("/health", handler.HealthCheck)
+	api.GET("/health/ready", handler.ReadinessCheck)
+	api.GET("/errors", handler.ListErrorCodes)
+
+	products := api.Group("/products")
+	{
+		products.POST("", handler.CreateProduct)
+		products.POST("/drafts", handler.CreateDraft)
+		products.POST("/validate", handler.ValidateProducts)
+		products.GET("", handler.GetAllProducts)
+		products.GET("/category", handler.GetProductsByCategory)
+		products.POST("/category/rename", handler.RenameCategory)
+		products.GET("/filter", handler.FilterProducts)
+		products.GET("/trending", handler.GetTrendingProducts)
+		products.GET("/featured", handler.GetFeaturedProducts)
+		products.PUT("/featured/order", handler.ReorderFeatured)
+		products.GET("/changes", handler.GetProductChanges)
+		products.GET("/suggest", handler.SuggestProducts)
+		products.GET("/search", handler.SearchProducts)
+		products.GET("/stats/valuation", handler.GetInventoryValuation)
+		products.GET("/export", handler.ExportProducts)
+		products.POST("/batch-get", handler.BatchGetProducts)
+		products.POST("/batch-get-by-sku", handler.BatchGetProductsBySKU)
+		products.POST("/stock/bulk", handler.BulkSetStock)
+		products.PUT("/bulk-upsert", handler.BulkUpsertProducts)
+		products.POST("/bulk-status", handler.BulkSetStatus)
+		products.HEAD("/sku/:sku", handler.ProductExistsBySKU)
+		products.GET("/slug/:slug", handler.GetProductBySlug)
+		products.GET("/lookup", handler.LookupProduct)
+		products.GET("/:id", handler.GetProduct)
+		products.GET("/:id/shipping", handler.GetShipping)
+		products.GET("/:id/bundle", handler.GetBundle)
+		products.PUT("/:id/featured", handler.SetFeatured)
+		products.DELETE("/:id/featured", handler.UnsetFeatured)
+		products.PUT("/:id", handler.UpdateProduct)
+		products.PATCH("/:id", handler.PatchProduct)
+		products.DELETE("/:id", handler.DeleteProduct)
+		products.POST("/:id/ratings", handler.RateProduct)
+		products.POST("/:id/restore", handler.RestoreProduct)
+		products.PUT("/:id/translations/:locale", handler.SetTranslation)
+		products.DELETE("/:id/translations/:locale", handler.RemoveTranslation)
+		products.POST("/:id/images/upload", handler.UploadImage)
+		products.POST("/:id/reconcile", handler.ReconcileProduct)
+		products.POST("/:id/publish", handler.PublishProduct)
+		products.POST("/:id/stock/clear", handler.ClearStock)
+		products.POST("/:id/reservations", handler.ReserveStock)
+		products.DELETE("/:id/reservations/:reservation_id", handler.ReleaseReservation)
+	}
+
+	return router
+}
+
+func TestProductHandler_CreateProduct_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	req := models.CreateProductRequest{
+		Name:        "Test Product",
+		Description: "A test product",
+		Price:       99.99,
+		Category:    "electronics",
+		SKU:         "TEST-001",
+		Stock:       floatPtr(10),
+	}
+
+	product := &models.Product{
+		ID:          "test-id",
+		Name:        req.Name,
+		Description: req.Description,
+		Price:       req.Price,
+		Category:    req.Category,
+		SKU:         req.SKU,
+		Stock:       *req.Stock,
+		IsActive:    true,
+	}
+
+	mockService.On("CreateProduct", req).Return(product, nil)
+
+	reqBody, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "/api/v1/products/test-id", w.Header().Get("Location"))
+
+	var response models.Product
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, product.ID, response.ID)
+	assert.Equal(t, product.Name, response.Name)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_CreateProduct_InvalidJSON(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBuffer([]byte("invalid json")))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_CreateProduct_OutOfRangePrice(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	body := `{"name":"Pen","price":1e400,"category":"office","sku":"PEN-1","stock":1}`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertNotCalled(t, "CreateProduct", mock.Anything)
+}
+
+func TestProductHandler_CreateProduct_OptionalStock(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	omitted := models.CreateProductRequest{Name: "Pen", Price: 1.5, Category: "office", SKU: "PEN-1"}
+	zero := models.CreateProductRequest{Name: "Pen", Price: 1.5, Category: "office", SKU: "PEN-2", Stock: floatPtr(0)}
+	mockService.On("CreateProduct", omitted).Return(&models.Product{ID: "1", SKU: "PEN-1", Stock: 7}, nil)
+	mockService.On("CreateProduct", zero).Return(&models.Product{ID: "2", SKU: "PEN-2"}, nil)
+
+	for _, body := range []string{
+		`{"name":"Pen","price":1.5,"category":"office","sku":"PEN-1"}`,
+		`{"name":"Pen","price":1.5,"category":"office","sku":"PEN-2","stock":0}`,
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusCreated, w.Code, body)
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", strings.NewReader(`{"name":"Pen","price":1.5,"category":"office","sku":"PEN-3","stock":-1}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_UpdateProduct_UnknownFieldLenient(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	name := "Renamed"
+	mockService.On("UpdateProduct", "test-id", models.UpdateProductRequest{Name: &name}).
+		Return(&models.Product{ID: "test-id", Name: name}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"Renamed","stok":5}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_UpdateProduct_UnknownFieldStrict(t *testing.T) {
+	for name, tc := range map[string]struct {
+		opts   []HandlerOption
+		prefer string
+	}{
+		"config": {opts: []HandlerOption{WithStrictJSON(true)}},
+		"prefer": {prefer: "strict"},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService, tc.opts...)
+			router := setupRouter(handler)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"Renamed","stok":5,"Pric":1}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			if tc.prefer != "" {
+				httpReq.Header.Set("Prefer", tc.prefer)
+			}
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, []interface{}{"Pric", "stok"}, response["unknown_fields"])
+			mockService.AssertNotCalled(t, "UpdateProduct", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+// flagSet turns on the flags it holds for every request.
+type flagSet map[string]bool
+
+func (f flagSet) Enabled(flag string, req features.Request) bool {
+	return f[flag]
+}
+
+func TestProductHandler_UpdateProduct_StrictJSONFeatureFlag(t *testing.T) {
+	for name, tc := range map[string]struct {
+		flags features.Flags
+		want  int
+	}{
+		"off": {flags: flagSet{}, want: http.StatusOK},
+		"on":  {flags: flagSet{features.StrictJSON: true}, want: http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService, WithFeatureFlags(tc.flags))
+			router := setupRouter(handler)
+
+			name := "Renamed"
+			mockService.On("UpdateProduct", "test-id", models.UpdateProductRequest{Name: &name}).Return(&models.Product{ID: "test-id", Name: name}, nil).Maybe()
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"Renamed","stok":5}`))
+			httpReq.Header.Set("Content-Type", "application/json")
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tc.want, w.Code)
+		})
+	}
+}
+
+func TestProductHandler_StrictJSONFeatureFlag_OptIn(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithFeatureFlags(features.Static{features.StrictJSON: {features.OptIn}}))
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"stok":5}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set(features.Header, "other, strict_json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"unknown_fields":["stok"]`)
+}
+
+func TestProductHandler_CreateProduct_StrictKnownFields(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithStrictJSON(true))
+	router := setupRouter(handler)
+
+	req := models.CreateProductRequest{Name: "Mouse", Price: 24.99, Category: "electronics", SKU: "ELEC-1", Stock: floatPtr(5)}
+	mockService.On("CreateProduct", req).Return(&models.Product{ID: "test-id"}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBufferString(`{"name":"Mouse","Price":24.99,"category":"electronics","sku":"ELEC-1","stock":5}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	// Binding validation still applies in strict mode.
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products", bytes.NewBufferString(`{"name":"Mouse"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ValidateProducts(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	reqs := []models.CreateProductRequest{
+		{Name: "Kite", Price: 12, Category: "toys", SKU: "TOY-001", Stock: floatPtr(4)},
+		{Name: "Lamp", Price: 30, Category: "home", SKU: "HOME-001", Stock: floatPtr(2)},
+		{Price: 5, Category: "toys", SKU: "TOY-002", Stock: floatPtr(1)},
+	}
+	mockService.On("ValidateProducts", reqs).Return([]models.ValidationResult{
+		{Index: 0, SKU: "TOY-001", Valid: true},
+		{Index: 1, SKU: "HOME-001", Errors: []models.ValidationError{{Field: "stock", Message: "product stock cannot exceed 1"}}},
+		{Index: 2, SKU: "TOY-002", Errors: []models.ValidationError{{Field: "name", Message: "product name is required"}}},
+	}, nil)
+
+	body, _ := json.Marshal(reqs)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/validate", bytes.NewBuffer(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"valid": 1,
+		"invalid": 2,
+		"results": [
+			{"index": 0, "sku": "TOY-001", "valid": true},
+			{"index": 1, "sku": "HOME-001", "valid": false, "errors": [{"field": "stock", "message": "product stock cannot exceed 1"}]},
+			{"index": 2, "sku": "TOY-002", "valid": false, "errors": [{"field": "name", "message": "product name is required"}]}
+		]
+	}`, w.Body.String())
+
+	// An item a create would reject at binding is reported with the
+	// binding error rather than failing the batch.
+	negativeStock := []models.CreateProductRequest{{Name: "Kite", Price: 12, Category: "toys", SKU: "TOY-003", Stock: floatPtr(-1)}}
+	mockService.On("ValidateProducts", negativeStock).Return([]models.ValidationResult{{Index: 0, SKU: "TOY-003", Valid: true}}, nil)
+
+	body, _ = json.Marshal(negativeStock)
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/validate", bytes.NewBuffer(body))
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{
+		"valid": 0,
+		"invalid": 1,
+		"results": [{"index": 0, "sku": "TOY-003", "valid": false, "errors": [{"field": "stock", "message": "product stock must be at least 0"}]}]
+	}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/validate", strings.NewReader(`{"name":"not an array"}`))
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ValidateProducts_UnknownFieldStrict(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService))
+
+	body := `[{"name":"Kite","price":12,"category":"toys","sku":"TOY-001","stok":4}]`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/validate", strings.NewReader(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Prefer", "strict")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []interface{}{"[0].stok"}, response["unknown_fields"])
+	mockService.AssertNotCalled(t, "ValidateProducts", mock.Anything)
+}
+
+func TestProductHandler_CreateProduct_FieldError(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	req := models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(1000),
+	}
+
+	fieldErr := &service.FieldError{Field: "stock", Message: "product stock cannot exceed 100"}
+	mockService.On("CreateProduct", req).Return((*models.Product)(nil), fmt.Errorf("%w: %w", service.ErrInvalidProduct, fieldErr))
+
+	reqBody, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "stock", response["field"])
+	assert.Contains(t, response["details"], "cannot exceed 100")
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_CreateProduct_LocationBaseURL(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithPublicBaseURL("https://api.example.com/"))
+	router := setupRouter(handler)
+
+	mockService.On("CreateProduct", mock.Anything).Return(&models.Product{ID: "a b"}, nil)
+
+	reqBody := `{"name":"Test","price":1,"category":"c","sku":"S","stock":1}`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", strings.NewReader(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, "https://api.example.com/api/v1/products/a%20b", w.Header().Get("Location"))
+}
+
+func TestProductHandler_GetProduct_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	product := &models.Product{
+		ID:    "test-id",
+		Name:  "Test Product",
+		Price: 99.99,
+	}
+
+	mockService.On("GetProduct", "test-id").Return(product, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Product
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, product.ID, response.ID)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProduct_BrokenImages(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	product := &models.Product{
+		ID:           "test-id",
+		Images:       []string{"https://cdn.example.com/a.jpg", "https://cdn.example.com/b.jpg"},
+		BrokenImages: []string{"https://cdn.example.com/b.jpg"},
+	}
+
+	mockService.On("GetProduct", "test-id").Return(product, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Product
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, product.BrokenImages, response.BrokenImages)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProduct_FieldAccess(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithFieldAccess(FieldAccess{
+		"created_by": {"admin"},
+		"updated_by": {"admin"},
+	}))
+	router := setupRouter(handler)
+
+	product := &models.Product{ID: "test-id", Name: "Mouse", CreatedBy: "user-1", UpdatedBy: "user-2"}
+	mockService.On("GetProduct", "test-id").Return(product, nil)
+
+	get := func(principal *auth.Principal, accept string) map[string]any {
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+		if principal != nil {
+			httpReq = httpReq.WithContext(auth.WithPrincipal(httpReq.Context(), *principal))
+		}
+		if accept != "" {
+			httpReq.Header.Set("Accept", accept)
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var response map[string]any
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		return response
+	}
+
+	admin := get(&auth.Principal{ID: "user-9", Role: "admin"}, "")
+	assert.Equal(t, "user-1", admin["created_by"])
+	assert.Equal(t, "user-2", admin["updated_by"])
+
+	for _, principal := range []*auth.Principal{nil, {ID: "user-9"}, {ID: "user-9", Role: "viewer"}} {
+		public := get(principal, "")
+		assert.Equal(t, "Mouse", public["name"])
+		assert.NotContains(t, public, "created_by")
+		assert.NotContains(t, public, "updated_by")
+	}
+
+	// The v2 audit object is filtered too.
+	v2 := get(nil, MediaTypeProductV2)
+	audit := v2["audit"].(map[string]any)
+	assert.Contains(t, audit, "created_at")
+	assert.NotContains(t, audit, "created_by")
+}
+
+func TestProductHandler_FeaturedProducts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	for _, id := range []string{"a", "b"} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: id, IsActive: true}))
+	}
+
+	for id, body := range map[string]string{"a": `{"rank":2}`, "b": `{"rank":1}`} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", "/api/v1/products/"+id+"/featured", bytes.NewBufferString(body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/featured", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Products []models.Product `json:"products"`
+		Count    int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Equal(t, 2, response.Count)
+	assert.Equal(t, "b", response.Products[0].ID)
+	assert.True(t, response.Products[0].Featured)
+	assert.Equal(t, 1, *response.Products[0].FeaturedRank)
+	assert.Equal(t, "a", response.Products[1].ID)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/featured/order", bytes.NewBufferString(`{"ids":["a","missing"]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("DELETE", "/api/v1/products/a/featured", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	a, _ := repo.GetByID(context.Background(), "a")
+	assert.False(t, a.Featured)
+}
+
+func TestProductHandler_GetProduct_Pretty(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetProduct", "test-id").Return(&models.Product{ID: "test-id", Name: "Mouse"}, nil)
+
+	get := func(target, prefer string) string {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", target, nil)
+		if prefer != "" {
+			httpReq.Header.Set("Prefer", prefer)
+		}
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Body.String()
+	}
+
+	compact := get("/api/v1/products/test-id", "")
+	assert.NotContains(t, compact, "\n")
+
+	for _, pretty := range []string{
+		get("/api/v1/products/test-id?pretty=true", ""),
+		get("/api/v1/products/test-id", "strict, pretty"),
+	} {
+		assert.True(t, strings.HasPrefix(pretty, "{\n    \"id\": \"test-id\",\n"), pretty)
+		assert.JSONEq(t, compact, pretty)
+	}
+}
+
+func TestProductHandler_GetProduct_NotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetProduct", "nonexistent-id").Return(nil, service.ErrProductNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/nonexistent-id", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","id":"nonexistent-id"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProduct_Saturated(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService))
+
+	mockService.On("GetProduct", "test-id").Return(nil, fmt.Errorf("failed to get product: %w", database.ErrSaturated))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.Equal(t, "1", w.Header().Get("Retry-After"))
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "SERVICE_SATURATED", response["code"])
+}
+
+func TestProductHandler_DeleteProduct_IfMatch(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget", IsActive: true, Version: 3}))
+	router := setupRouter(NewProductHandler(service.NewProductService(repo)))
+
+	for header, want := range map[string]int{
+		`W/"3"`: http.StatusBadRequest,
+		`"two"`: http.StatusBadRequest,
+		`"2"`:   http.StatusPreconditionFailed,
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id", nil)
+		httpReq.Header.Set("If-Match", header)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, want, w.Code, header)
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id?expected_version=4", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusPreconditionFailed, w.Code)
+	assert.JSONEq(t, `{"error":"Product version does not match","code":"PRECONDITION_FAILED","id":"test-id","version":4}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("DELETE", "/api/v1/products/test-id", nil)
+	httpReq.Header.Set("If-Match", `"3"`)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	stored, err := repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestProductHandler_GetProductBySlug(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	product := &models.Product{ID: "test-id", Name: "Blue Widget", Slug: "blue-widget"}
+
+	mockService.On("GetProductBySlug", "blue-widget").Return(product, nil)
+	mockService.On("GetProductBySlug", "red-widget").Return(nil, service.ErrProductNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/slug/blue-widget", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "test-id", response["id"])
+	assert.Equal(t, "blue-widget", response["slug"])
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/slug/red-widget", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","slug":"red-widget"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_LookupProduct(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	product := &models.Product{ID: "test-id", Name: "Blue Widget", SKU: "WID-001"}
+
+	mockService.On("GetProduct", "test-id").Return(product, nil)
+	mockService.On("GetProduct", "missing").Return(nil, service.ErrProductNotFound)
+	mockService.On("GetProductBySKU", "WID-001").Return(product, nil)
+	mockService.On("GetProductBySKU", "WID-404").Return(nil, service.ErrProductNotFound)
+
+	for _, query := range []string{"id=test-id", "sku=WID-001"} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/lookup?"+query, nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code, query)
+		var response map[string]interface{}
+		json.Unmarshal(w.Body.Bytes(), &response)
+		assert.Equal(t, "test-id", response["id"], query)
+		assert.Equal(t, "WID-001", response["sku"], query)
+	}
+
+	for query, body := range map[string]string{
+		"id=missing":  `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","id":"missing"}`,
+		"sku=WID-404": `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","sku":"WID-404"}`,
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/lookup?"+query, nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, query)
+		assert.JSONEq(t, body, w.Body.String(), query)
+	}
+
+	for _, query := range []string{"id=test-id&sku=WID-001", "", "id=&sku="} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/lookup?"+query, nil)
+
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		assert.JSONEq(t, `{"error":"Invalid query","code":"INVALID_QUERY","details":"exactly one of id or sku is required"}`, w.Body.String(), query)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetShipping(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	product := &models.Product{ID: "test-id", SKU: "SHIP-001", WeightGrams: 500, LengthMM: 300, WidthMM: 200, HeightMM: 100}
+	shipping := product.Shipping()
+
+	mockService.On("GetShipping", "test-id").Return(&shipping, nil)
+	mockService.On("GetShipping", "missing").Return(nil, service.ErrProductNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id/shipping", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"product_id":"test-id","sku":"SHIP-001","weight_grams":500,"length_mm":300,"width_mm":200,"height_mm":100,"volumetric_weight_grams":1200,"chargeable_weight_grams":1200}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/missing/shipping", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","id":"missing"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetBundle(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	bundle := &models.Product{ID: "kit", Name: "Desk Kit", Price: 50, IsActive: true, BundleItems: []models.BundleItem{
+		{ProductID: "lamp", Quantity: 1},
+		{ProductID: "gone", Quantity: 2},
+	}}
+	lamp := &models.Product{ID: "lamp", Name: "Lamp", Price: 30, Stock: 4, IsActive: true}
+	expanded := models.NewProductBundle(bundle, []models.BundleComponent{
+		{ProductID: "lamp", Quantity: 1, Product: lamp},
+		{ProductID: "gone", Quantity: 2},
+	})
+
+	mockService.On("GetBundle", "kit").Return(expanded, nil)
+	mockService.On("GetBundle", "lamp").Return(nil, service.ErrNotBundle)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/kit/bundle", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Product struct {
+			ID          string              `json:"id"`
+			BundleItems []models.BundleItem `json:"bundle_items"`
+		} `json:"product"`
+		Components []struct {
+			ProductID string          `json:"product_id"`
+			Quantity  int             `json:"quantity"`
+			Product   *models.Product `json:"product"`
+		} `json:"components"`
+		ComponentsPrice float64 `json:"components_price"`
+		AvailableStock  float64 `json:"available_stock"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "kit", response.Product.ID)
+	assert.Equal(t, bundle.BundleItems, response.Product.BundleItems)
+	require.Len(t, response.Components, 2)
+	assert.Equal(t, "Lamp", response.Components[0].Product.Name)
+	assert.Nil(t, response.Components[1].Product)
+	assert.Equal(t, 30.0, response.ComponentsPrice)
+	assert.Zero(t, response.AvailableStock)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/lamp/bundle", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product is not a bundle","code":"NOT_A_BUNDLE"}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+type fakeImageStore struct{}
+
+func (fakeImageStore) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	return nil
+}
+
+func (fakeImageStore) SignedURL(ctx context.Context, key string) (string, error) {
+	return "https://images.example.com/" + key + "?signature=abc", nil
+}
+
+func TestProductHandler_GetProduct_SignsImages(t *testing.T) {
+	product := &models.Product{ID: "test-id", Name: "Shoe", Images: []string{"products/test-id/front.jpg"}}
+
+	for _, tt := range []struct {
+		name string
+		opts []HandlerOption
+		want string
+	}{
+		{name: "raw keys", want: "products/test-id/front.jpg"},
+		{name: "signed", opts: []HandlerOption{WithImageStore(fakeImageStore{})}, want: "https://images.example.com/products/test-id/front.jpg?signature=abc"},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			router := setupRouter(NewProductHandler(mockService, tt.opts...))
+
+			mockService.On("GetProduct", "test-id").Return(product, nil)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			assert.Equal(t, []interface{}{tt.want}, response["images"])
+			assert.Equal(t, "products/test-id/front.jpg", product.Images[0])
+		})
+	}
+}
+
+func TestProductHandler_GetProduct_SignsOnlyOwnImageKeys(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService, WithImageStore(fakeImageStore{})))
+
+	product := &models.Product{ID: "test-id", Name: "Shoe", Images: []string{
+		"products/test-id/front.jpg", "products/other-id/back.jpg", "https://cdn.example.com/side.jpg",
+	}}
+	mockService.On("GetProduct", "test-id").Return(product, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []interface{}{
+		"https://images.example.com/products/test-id/front.jpg?signature=abc",
+		"products/other-id/back.jpg",
+		"https://cdn.example.com/side.jpg",
+	}, response["images"])
+}
+
+// pngHeader is enough of a PNG file for content sniffing.
+var pngHeader = []byte("\x89PNG\r\n\x1a\n\x00\x00\x00\rIHDR\x00\x00\x00\x01\x00\x00\x00\x01\x08\x06\x00\x00\x00")
+
+func newImageUpload(t *testing.T, content []byte) *http.Request {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	part, err := writer.CreateFormFile("image", "front.png")
+	require.NoError(t, err)
+	part.Write(content)
+	require.NoError(t, writer.Close())
+
+	req, _ := http.NewRequest("POST", "/api/v1/products/test-id/images/upload", &body)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestProductHandler_UploadImage(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService, WithImageStore(fakeImageStore{})))
+
+	key := "products/test-id/01.png"
+	product := &models.Product{ID: "test-id", Name: "Shoe", Images: []string{key}}
+	mockService.On("AddProductImage", "test-id", "image/png").Return(product, key, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, newImageUpload(t, pngHeader))
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, key, response["key"])
+	assert.Equal(t, "https://images.example.com/"+key+"?signature=abc", response["url"])
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_UploadImage_Rejected(t *testing.T) {
+	tests := []struct {
+		name    string
+		opts    []HandlerOption
+		content []byte
+		want    int
+	}{
+		{name: "not an image", opts: []HandlerOption{WithImageStore(fakeImageStore{})}, content: []byte("just some text"), want: http.StatusUnsupportedMediaType},
+		{name: "too large", opts: []HandlerOption{WithImageStore(fakeImageStore{}), WithMaxImageSize(16)}, content: pngHeader, want: http.StatusRequestEntityTooLarge},
+		{name: "no image store", content: pngHeader, want: http.StatusNotImplemented},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			router := setupRouter(NewProductHandler(mockService, tt.opts...))
+
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, newImageUpload(t, tt.content))
+
+			assert.Equal(t, tt.want, w.Code)
+			mockService.AssertNotCalled(t, "AddProductImage", mock.Anything, mock.Anything)
+		})
+	}
+}
+
+func TestProductHandler_ReconcileProduct(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("ReconcileProduct", "test-id").Return(&models.ReconcileResult{
+		ProductID: "test-id",
+		Before:    models.ProductCounts{RatingSum: 20, RatingCount: 2},
+		After:     models.ProductCounts{RatingSum: 10, RatingCount: 2},
+		Corrected: true,
+	}, nil)
+	mockService.On("ReconcileProduct", "missing").Return(nil, service.ErrProductNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/test-id/reconcile", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response models.ReconcileResult
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.True(t, response.Corrected)
+	assert.Equal(t, int64(10), response.After.RatingSum)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/missing/reconcile", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ReserveStock(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	expiresAt := time.Date(2024, 6, 1, 12, 15, 0, 0, time.UTC)
+	mockService.On("ReserveStock", "test-id", models.ReserveStockRequest{Quantity: 2}).Return(&models.Reservation{
+		ID:        "res-1",
+		ProductID: "test-id",
+		Quantity:  2,
+		ExpiresAt: expiresAt,
+	}, nil)
+	mockService.On("ReserveStock", "test-id", models.ReserveStockRequest{Quantity: 50}).
+		Return(nil, fmt.Errorf("%w: 50 requested, 2 available", service.ErrInsufficientStock))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/test-id/reservations", strings.NewReader(`{"quantity":2}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+	var reservation models.Reservation
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &reservation))
+	assert.Equal(t, "res-1", reservation.ID)
+	assert.Equal(t, expiresAt, reservation.ExpiresAt)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/test-id/reservations", strings.NewReader(`{"quantity":50}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ReleaseReservation(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("ReleaseReservation", "test-id", "res-1").Return(nil)
+	mockService.On("ReleaseReservation", "test-id", "gone").Return(service.ErrReservationNotFound)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id/reservations/res-1", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("DELETE", "/api/v1/products/test-id/reservations/gone", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "RESERVATION_NOT_FOUND", response["code"])
+	assert.Equal(t, "gone", response["id"])
+	assert.Equal(t, "test-id", response["product_id"])
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ProductExistsBySKU(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("ProductExistsBySKU", "TEST-001").Return(true, nil)
+	mockService.On("ProductExistsBySKU", "MISSING-001").Return(false, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("HEAD", "/api/v1/products/sku/TEST-001", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("HEAD", "/api/v1/products/sku/MISSING-001", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Empty(t, w.Body.String())
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ProductExistsBySKU_PaddedSKU(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget", SKU: "ELEC-001", IsActive: true}))
+	router := setupRouter(NewProductHandler(service.NewProductService(repo)))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("HEAD", "/api/v1/products/sku/%20ELEC-001", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// The lookup finds the same product.
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/lookup?sku=%20ELEC-001", nil)
+	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestProductHandler_GetAllProducts_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	products := []*models.Product{
+		{ID: "1", Name: "Product 1"},
+		{ID: "2", Name: "Product 2"},
+	}
+
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(&models.ProductList{Products: products}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(2), response["count"])
+	assert.Equal(t, false, response["truncated"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetAllProducts_ETag(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	updated := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	products := []*models.Product{{ID: "1", Name: "Product 1", UpdatedAt: updated}}
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(&models.ProductList{Products: products}, nil)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+		if ifNoneMatch != "" {
+			httpReq.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	first := get("")
+	etag := first.Header().Get("ETag")
+	assert.Equal(t, http.StatusOK, first.Code)
+	assert.True(t, strings.HasPrefix(etag, `W/"`))
+
+	unchanged := get(etag)
+	assert.Equal(t, http.StatusNotModified, unchanged.Code)
+	assert.Empty(t, unchanged.Body.String())
+	assert.Equal(t, etag, unchanged.Header().Get("ETag"))
+
+	// Views do not invalidate the ETag, but an update does.
+	products[0].ViewCount = 100
+	assert.Equal(t, http.StatusNotModified, get(etag).Code)
+
+	products[0].UpdatedAt = updated.Add(time.Second)
+	changed := get(etag)
+	assert.Equal(t, http.StatusOK, changed.Code)
+	assert.NotEqual(t, etag, changed.Header().Get("ETag"))
+
+	// A deleted product changes the ETag too.
+	products = products[:0]
+	mockService.ExpectedCalls = nil
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(&models.ProductList{Products: products}, nil)
+	assert.Equal(t, http.StatusOK, get(changed.Header().Get("ETag")).Code)
+}
+
+func TestProductHandler_GetAllProducts_ETagFollowsAvailability(t *testing.T) {
+	mockService := new(MockProductService)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(now)
+	router := setupRouter(NewProductHandler(mockService, WithClock(clock)))
+
+	release := now.Add(time.Hour)
+	products := []*models.Product{{ID: "1", Name: "Console", Stock: 5, AvailableFrom: &release}}
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(&models.ProductList{Products: products}, nil)
+
+	get := func(ifNoneMatch string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+		httpReq.Header.Set("If-None-Match", ifNoneMatch)
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
+
+	etag := get("").Header().Get("ETag")
+
+	assert.Equal(t, http.StatusNotModified, get(etag).Code)
+
+	// The release date passing changes availability without a write.
+	clock.Advance(2 * time.Hour)
+	assert.Equal(t, http.StatusOK, get(etag).Code)
+}
+
+func TestProductHandler_GetProduct_PreorderExpiresAtRelease(t *testing.T) {
+	mockService := new(MockProductService)
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(now)
+	handler := NewProductHandler(mockService, WithClock(clock))
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(func(c *gin.Context) { c.Header("Cache-Control", "public, max-age=300") })
+	router.GET("/api/v1/products/:id", handler.GetProduct)
+
+	release := now.Add(90 * time.Second)
+	mockService.On("GetProduct", "preorder").Return(&models.Product{ID: "preorder", Stock: 5, AvailableFrom: &release}, nil)
+	mockService.On("GetProduct", "on-sale").Return(&models.Product{ID: "on-sale", Stock: 5}, nil)
+
+	cacheControl := func(id string) string {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/"+id, nil)
+		router.ServeHTTP(w, httpReq)
+		require.Equal(t, http.StatusOK, w.Code)
+		return w.Header().Get("Cache-Control")
+	}
+
+	assert.Equal(t, "public, max-age=90", cacheControl("preorder"))
+	assert.Equal(t, "public, max-age=300", cacheControl("on-sale"))
+
+	// Once released it is cached like any other product.
+	clock.Advance(2 * time.Minute)
+	assert.Equal(t, "public, max-age=300", cacheControl("preorder"))
+}
+
+func TestEtagMatches(t *testing.T) {
+	assert.True(t, etagMatches(`W/"abc"`, `W/"abc"`))
+	assert.True(t, etagMatches(`"xyz", "abc"`, `W/"abc"`))
+	assert.True(t, etagMatches("*", `W/"abc"`))
+	assert.False(t, etagMatches(`W/"abd"`, `W/"abc"`))
+	assert.False(t, etagMatches("", `W/"abc"`))
+}
+
+func TestProductHandler_GetAllProducts_Truncated(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	list := &models.ProductList{
+		Products:  []*models.Product{{ID: "1", Name: "Product 1"}},
+		Truncated: true,
+	}
+
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, true, response["truncated"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetAllProducts_BudgetExceeded(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	list := &models.ProductList{
+		Products:       []*models.Product{{ID: "1", Name: "Product 1"}},
+		BudgetExceeded: true,
+	}
+
+	mockService.On("GetAllProducts", models.ListOptions{}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, true, response["budget_exceeded"])
+	assert.Equal(t, float64(1), response["count"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetAllProducts_Sort(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetAllProducts", models.ListOptions{Sort: "price:desc"}).Return(&models.ProductList{}, nil)
+	mockService.On("GetAllProducts", models.ListOptions{Sort: "color"}).Return(nil, fmt.Errorf("%w: unsupported sort field", service.ErrInvalidQuery))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products?sort=price:desc", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products?sort=color", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetAllProducts_PaginationHeaders(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	list := &models.ProductList{
+		Products:   []*models.Product{{ID: "1"}, {ID: "2"}},
+		Limit:      2,
+		Total:      5,
+		NextCursor: "Mg",
+	}
+	mockService.On("GetAllProducts", models.ListOptions{Sort: "name", Limit: 2}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products?limit=2&sort=name", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Page-Limit"))
+	assert.Equal(t, "5", w.Header().Get("X-Total-Count"))
+	assert.Equal(t, `</api/v1/products?cursor=Mg&limit=2&sort=name>; rel="next"`, w.Header().Get("Link"))
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "Mg", response["next_cursor"])
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListingPageMeta(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	for i := 1; i <= 5; i++ {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{
+			ID: fmt.Sprintf("id-%d", i), Name: fmt.Sprintf("Product %d", i), Category: "books", IsActive: true,
+		}))
+	}
+	router := setupRouter(NewProductHandler(service.NewProductService(repo)))
+
+	for _, path := range []string{"/api/v1/products?limit=2&sort=name", "/api/v1/products/category?category=books&limit=2&sort=name"} {
+		var pages []models.PageMeta
+		next := path
+		for next != "" {
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", next, nil)
+			router.ServeHTTP(w, httpReq)
+			require.Equal(t, http.StatusOK, w.Code)
+
+			var response struct {
+				Pagination models.PageMeta `json:"pagination"`
+			}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			pages = append(pages, response.Pagination)
+
+			next = ""
+			if response.Pagination.HasMore {
+				next = path + "&cursor=" + response.Pagination.NextCursor
+			}
+		}
+
+		require.Len(t, pages, 3, path)
+		assert.Equal(t, models.PageMeta{Limit: 2, Returned: 2, HasMore: true, NextCursor: pages[0].NextCursor}, pages[0])
+		assert.NotEmpty(t, pages[0].NextCursor)
+		assert.Equal(t, 2, pages[1].Returned)
+		assert.True(t, pages[1].HasMore)
+		assert.Equal(t, models.PageMeta{Limit: 2, Returned: 1}, pages[2])
+	}
+}
+
+func TestProductHandler_GetAllProducts_LastPageHeaders(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	list := &models.ProductList{
+		Products:  []*models.Product{{ID: "5"}},
+		Limit:     2,
+		Total:     5,
+		Truncated: true,
+	}
+	mockService.On("GetAllProducts", models.ListOptions{Limit: 2, Cursor: "NA"}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products?limit=2&cursor=NA", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "2", w.Header().Get("X-Page-Limit"))
+	assert.Empty(t, w.Header().Get("X-Total-Count"), "a truncated scan has no reliable total")
+	assert.Empty(t, w.Header().Get("Link"))
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.NotContains(t, response, "next_cursor")
+}
+
+func TestProductHandler_GetAllProducts_TotalCountStrategies(t *testing.T) {
+	tables := stubTableStatus{status: database.TableStatus{Status: "ACTIVE", ItemCount: 42}}
+	tests := []struct {
+		name string
+		opts []HandlerOption
+		list models.ProductList
+		want string
+	}{
+		{"exact", nil, models.ProductList{Total: 5}, "5"},
+		{"exact truncated", nil, models.ProductList{Total: 2, Truncated: true}, ""},
+		{"exact truncated and counted", nil, models.ProductList{Total: 9, Matched: 9, Truncated: true}, "9"},
+		{"estimate", []HandlerOption{WithTotalCount(TotalCountEstimate), WithTableStatus(tables)}, models.ProductList{Total: 5}, "42"},
+		{"estimate without table status", []HandlerOption{WithTotalCount(TotalCountEstimate)}, models.ProductList{Total: 5}, ""},
+		{"estimate describe fails", []HandlerOption{WithTotalCount(TotalCountEstimate), WithTableStatus(stubTableStatus{err: errors.New("throttled")})}, models.ProductList{Total: 5}, ""},
+		{"none", []HandlerOption{WithTotalCount(TotalCountNone)}, models.ProductList{Total: 5}, ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			router := setupRouter(NewProductHandler(mockService, tt.opts...))
+
+			list := tt.list
+			list.Products = []*models.Product{{ID: "1"}, {ID: "2"}}
+			list.Limit = 2
+			mockService.On("GetAllProducts", models.ListOptions{Limit: 2}).Return(&list, nil)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/products?limit=2", nil)
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+			assert.Equal(t, "2", w.Header().Get("X-Page-Limit"))
+			assert.Equal(t, tt.want, w.Header().Get("X-Total-Count"))
+		})
+	}
+}
+
+func TestParseTotalCount(t *testing.T) {
+	for value, want := range map[string]TotalCount{"exact": TotalCountExact, "Estimate": TotalCountEstimate, "none": TotalCountNone} {
+		got, ok := ParseTotalCount(value)
+		assert.True(t, ok, value)
+		assert.Equal(t, want, got, value)
+	}
+
+	_, ok := ParseTotalCount("approximate")
+	assert.False(t, ok)
+}
+
+func TestProductHandler_GetAllProducts_PaginationHeadersDisabled(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithPaginationHeaders(false))
+	router := setupRouter(handler)
+
+	list := &models.ProductList{Products: []*models.Product{{ID: "1"}}, Limit: 1, Total: 3, NextCursor: "MQ"}
+	mockService.On("GetAllProducts", models.ListOptions{Limit: 1}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products?limit=1", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("X-Page-Limit"))
+	assert.Empty(t, w.Header().Get("Link"))
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products?limit=ten", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_GetProductsByCategory_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	products := []*models.Product{
+		{ID: "1", Name: "Product 1", Category: "electronics"},
+	}
+
+	mockService.On("GetProductsByCategory", "electronics", models.ListOptions{}).Return(&models.ProductList{Products: products}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/category?category=electronics", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "electronics", response["category"])
+	assert.Equal(t, float64(1), response["count"])
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProductsByCategory_IncludeSubcategories(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	products := []*models.Product{
+		{ID: "1", Category: "phones", CategoryPath: []string{"electronics", "phones"}},
+	}
+	mockService.On("GetProductsByCategory", "electronics", models.ListOptions{Subcategories: true}).
+		Return(&models.ProductList{Products: products}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/category?category=electronics&include_subcategories=true", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Products []map[string]interface{} `json:"products"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Products, 1)
+	assert.Equal(t, []interface{}{"electronics", "phones"}, response.Products[0]["category_path"])
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/category?category=electronics&include_subcategories=maybe", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProductsByCategory_MissingCategory(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/category", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_FilterProducts(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	min := 10.0
+	inStock := true
+	filter := models.ProductFilter{Category: "electronics", MinPrice: &min, InStock: &inStock, Tag: "sale"}
+	mockService.On("FilterProducts", filter, models.ListOptions{}).
+		Return(&models.ProductList{Products: []*models.Product{{ID: "1"}}}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/filter?category=electronics&min_price=10&in_stock=true&tag=sale", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(1), response["count"])
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/filter?max_price=cheap", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	for _, raw := range []string{"NaN", "Inf", "-Infinity"} {
+		w = httptest.NewRecorder()
+		httpReq, _ = http.NewRequest("GET", "/api/v1/products/filter?min_price="+raw, nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, raw)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetTrendingProducts(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	products := []*models.Product{
+		{ID: "1", Name: "Product 1", ViewCount: 50},
+		{ID: "2", Name: "Product 2", ViewCount: 10},
+	}
+
+	mockService.On("GetTrendingProducts", 5).Return(products, nil)
+	mockService.On("GetTrendingProducts", 1000).Return(nil, fmt.Errorf("%w: limit too large", service.ErrInvalidQuery))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/trending?limit=5", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response map[string]interface{}
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, float64(2), response["count"])
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/trending?limit=1000", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/trending?limit=abc", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProductChanges(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	since := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := since.Add(time.Hour)
+	list := &models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Name: "Product 1", UpdatedAt: since},
+			{ID: "2", Name: "Product 2", UpdatedAt: deletedAt, DeletedAt: &deletedAt},
+		},
+		Limit: 20,
+		Total: 2,
+	}
+
+	mockService.On("GetProductChanges", since, models.ListOptions{}).Return(list, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/changes?since=2024-01-01T00:00:00Z", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Count    int `json:"count"`
+		Products []struct {
+			ID      string `json:"id"`
+			Deleted bool   `json:"deleted"`
+		} `json:"products"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, 2, response.Count)
+	if assert.Len(t, response.Products, 2) {
+		assert.False(t, response.Products[0].Deleted)
+		assert.True(t, response.Products[1].Deleted)
+	}
+
+	for _, query := range []string{"", "?since=yesterday", "?since=2024-01-01T00:00:00Z&limit=abc"} {
+		w = httptest.NewRecorder()
+		httpReq, _ = http.NewRequest("GET", "/api/v1/products/changes"+query, nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ListQueryErrors_HaveCode(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService))
+
+	for _, path := range []string{
+		"/api/v1/products?limit=ten",
+		"/api/v1/products/category?category=electronics&limit=ten",
+		"/api/v1/products/category?category=electronics&include_subcategories=maybe",
+		"/api/v1/products/filter?in_stock=maybe",
+		"/api/v1/products/filter?limit=ten",
+		"/api/v1/products/search?q=phone&limit=ten",
+		"/api/v1/products/trending?limit=ten",
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, path)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), path)
+		assert.Equal(t, "INVALID_QUERY", response["code"], path)
+	}
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_SearchProducts(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService, WithPaginationHeaders(true))
+	router := setupRouter(handler)
+
+	list := &models.ProductList{
+		Products:   []*models.Product{{ID: "1", Name: "Desk Lamp"}, {ID: "3", Name: "Floor Lamp"}},
+		Limit:      2,
+		NextCursor: "Mw",
+		Uncounted:  true,
+	}
+	mockService.On("SearchProducts", "Lamp", models.ListOptions{Limit: 2, Cursor: "MQ"}).Return(list, nil)
+	mockService.On("SearchProducts", "", models.ListOptions{}).Return(nil, fmt.Errorf("%w: query is required", service.ErrInvalidQuery))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/search?q=Lamp&limit=2&cursor=MQ", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["count"])
+	assert.Equal(t, "Mw", response["next_cursor"])
+	assert.Equal(t, "2", w.Header().Get("X-Page-Limit"))
+	assert.Contains(t, w.Header().Get("Link"), "cursor=Mw")
+	assert.Empty(t, w.Header().Get("X-Total-Count"))
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/search", nil)
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_SuggestProducts(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("SuggestProducts", "wid", defaultSuggestLimit).Return([]models.ProductSuggestion{
+		{ID: "1", Name: "widget"},
+	}, nil)
+	mockService.On("SuggestProducts", "", defaultSuggestLimit).Return(nil, fmt.Errorf("%w: prefix is required", service.ErrInvalidQuery))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/suggest?prefix=wid", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"suggestions":[{"id":"1","name":"widget"}],"count":1}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/suggest", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetInventoryValuation(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("GetInventoryValuation").Return(&models.InventoryValuation{
+		Currency:     "USD",
+		Total:        150,
+		ProductCount: 2,
+		Categories: map[string]models.CategoryValuation{
+			"electronics": {Total: 150, ProductCount: 2},
+		},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/stats/valuation", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"currency":"USD","total":150,"product_count":2,"categories":{"electronics":{"total":150,"product_count":2}}}`, w.Body.String())
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ExportProducts_NDJSON(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	pages := [][]*models.Product{
+		{{ID: "1", Name: "Product 1"}, {ID: "2", Name: "Product 2"}},
+		{{ID: "3", Name: "Product 3"}},
+	}
+	mockService.On("ExportProducts").Return(pages, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/export?format=ndjson", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+
+	var ids []string
+	scanner := bufio.NewScanner(w.Body)
+	for scanner.Scan() {
+		var product models.Product
+		require.NoError(t, json.Unmarshal(scanner.Bytes(), &product))
+		ids = append(ids, product.ID)
+	}
+	assert.Equal(t, []string{"1", "2", "3"}, ids)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ExportProducts_Errors(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/export?format=xml", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.On("ExportProducts").Return([][]*models.Product{}, errors.New("scan failed"))
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products/export", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_ExportProducts_FailureMidStream(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService))
+
+	pages := [][]*models.Product{{{ID: "1", Name: "Product 1"}}}
+	mockService.On("ExportProducts").Return(pages, errors.New("scan failed"))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("GET", "/api/v1/products/export", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	lines := strings.Split(strings.TrimSpace(w.Body.String()), "\n")
+	require.Len(t, lines, 2)
+	var product models.Product
+	require.NoError(t, json.Unmarshal([]byte(lines[0]), &product))
+	assert.Equal(t, "1", product.ID)
+	assert.JSONEq(t, `{"error":"Failed to export products","details":"scan failed"}`, lines[1])
+}
+
+func TestProductHandler_RenameCategory(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	mockService.On("RenameCategory", "electronic", "electronics").Return(3, nil)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/category/rename", bytes.NewBufferString(`{"from":"electronic","to":"electronics"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"from":"electronic","to":"electronics","updated":3}`, w.Body.String())
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/category/rename", bytes.NewBufferString(`{"from":"electronic"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_BulkSetStock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "a", Stock: 1, IsActive: true}))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "b", Stock: 2, IsActive: true}))
+
+	body := `[{"id":"a","stock":10},{"id":"missing","stock":3},{"id":"b","stock":20}]`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/stock/bulk", bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Results []models.StockUpdateResult `json:"results"`
+		Failed  int                        `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, models.StockUpdated, response.Results[0].Status)
+	assert.Equal(t, models.StockFailed, response.Results[1].Status)
+	assert.Equal(t, models.StockUpdated, response.Results[2].Status)
+	assert.Equal(t, 1, response.Failed)
+
+	b, _ := repo.GetByID(context.Background(), "b")
+	assert.Equal(t, float64(20), b.Stock)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/stock/bulk", bytes.NewBufferString(`[]`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_ClearStock(t *testing.T) {
+	var logs bytes.Buffer
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo,
+		service.WithAutoDeactivateOutOfStock(true),
+		service.WithLogger(slog.New(slog.NewJSONHandler(&logs, nil))),
+	))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "mugs", Name: "Mugs", Stock: 40, IsActive: true}))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/mugs/stock/clear", bytes.NewBufferString(`{"reason":"damaged in transit"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response models.Product
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(0), response.Stock)
+	assert.False(t, response.IsActive)
+
+	stored, _ := repo.GetByID(context.Background(), "mugs")
+	assert.Equal(t, float64(0), stored.Stock)
+	assert.True(t, stored.StockDeactivated)
+
+	var record map[string]any
+	for _, line := range strings.Split(strings.TrimSpace(logs.String()), "\n") {
+		var entry map[string]any
+		require.NoError(t, json.Unmarshal([]byte(line), &entry))
+		if entry["msg"] == "product stock cleared" {
+			record = entry
+		}
+	}
+	require.NotNil(t, record)
+	assert.Equal(t, "damaged in transit", record["reason"])
+	assert.Equal(t, "mugs", record["product_id"])
+	assert.Equal(t, float64(40), record["previous_stock"])
+
+	// A reason is required.
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/mugs/stock/clear", bytes.NewBufferString(`{}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/missing/stock/clear", bytes.NewBufferString(`{"reason":"lost"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestProductHandler_CreateProduct_DuplicateSKU(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "lamp-1", Name: "Lamp", SKU: "LAMP-001", IsActive: true}))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBufferString(`{"name":"Desk lamp","price":25,"category":"lighting","sku":"LAMP-001"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	var response map[string]any
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, "DUPLICATE_SKU", response["code"])
+	assert.Equal(t, "lamp-1", response["existing_id"])
+	assert.Equal(t, "/api/v1/products/lamp-1", response["existing_url"])
+	assert.Equal(t, "LAMP-001", response["sku"])
+	assert.Equal(t, "Product SKU already exists", response["error"])
+
+	// Moving another product onto the SKU conflicts the same way.
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "lamp-2", Name: "Lamp", Price: 25, SKU: "LAMP-002", IsActive: true}))
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/lamp-2", bytes.NewBufferString(`{"sku":"LAMP-001"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"existing_id":"lamp-1"`)
+}
+
+func TestProductHandler_BatchGetProducts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	for _, id := range []string{"a", "b", "c"} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: id, IsActive: true}))
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/batch-get", bytes.NewBufferString(`{"ids":["c","missing","a"]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Products []models.Product `json:"products"`
+		Missing  []string         `json:"missing"`
+		Count    int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Products, 2)
+	assert.Equal(t, "c", response.Products[0].ID)
+	assert.Equal(t, "a", response.Products[1].ID)
+	assert.Equal(t, []string{"missing"}, response.Missing)
+	assert.Equal(t, 2, response.Count)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/batch-get", bytes.NewBufferString(`{"ids":[]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_BatchEndpoints_RejectOversizedBatches(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo, service.WithMaxBatchSize(2)))
+	router := setupRouter(handler)
+
+	for _, tc := range []struct {
+		method, path, body string
+	}{
+		{"POST", "/api/v1/products/batch-get", `{"ids":["a","b","c"]}`},
+		{"POST", "/api/v1/products/batch-get-by-sku", `{"skus":["A","B","C"]}`},
+		{"POST", "/api/v1/products/stock/bulk", `[{"id":"a","stock":1},{"id":"b","stock":1},{"id":"c","stock":1}]`},
+		{"PUT", "/api/v1/products/bulk-upsert", `[{"sku":"A"},{"sku":"B"},{"sku":"C"}]`},
+		{"POST", "/api/v1/products/bulk-status", `{"ids":["a","b","c"],"active":false}`},
+		{"PUT", "/api/v1/products/featured/order", `{"ids":["a","b","c"]}`},
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest(tc.method, tc.path, bytes.NewBufferString(tc.body))
+		httpReq.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, tc.path)
+		assert.Contains(t, w.Body.String(), " 2 products", tc.path)
+	}
+}
+
+func TestProductHandler_BatchGetProductsBySKU(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	for id, sku := range map[string]string{"a": "ELEC-001", "b": "ELEC-002", "c": "BOOK-001"} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: id, SKU: sku, IsActive: true}))
+	}
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/batch-get-by-sku", bytes.NewBufferString(`{"skus":["BOOK-001"," ELEC-001 ","TOY-404","ELEC-001"]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Products []models.Product `json:"products"`
+		Missing  []string         `json:"missing"`
+		Count    int              `json:"count"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Products, 2)
+	assert.Equal(t, "c", response.Products[0].ID)
+	assert.Equal(t, "a", response.Products[1].ID)
+	assert.Equal(t, []string{"TOY-404"}, response.Missing)
+	assert.Equal(t, 2, response.Count)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/batch-get-by-sku", bytes.NewBufferString(`{"skus":["  "]}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_DraftThenPublish(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/drafts", bytes.NewBufferString(`{"name":"Lamp","category":"lighting"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	require.Equal(t, http.StatusCreated, w.Code)
+	var draft models.Product
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &draft))
+	assert.Equal(t, models.StatusDraft, draft.Status)
+	assert.False(t, draft.IsActive)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), draft.ID)
+
+	// Publishing needs a price and SKU, which the draft lacks.
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/"+draft.ID+"/publish", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"price"`)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/"+draft.ID, bytes.NewBufferString(`{"price":40,"sku":"LAMP-001"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/"+draft.ID+"/publish", nil)
+	router.ServeHTTP(w, httpReq)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var published models.Product
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &published))
+	assert.Equal(t, models.StatusPublished, published.Status)
+	assert.True(t, published.IsActive)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("GET", "/api/v1/products", nil)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Contains(t, w.Body.String(), draft.ID)
+}
+
+func TestProductHandler_BulkUpsertProducts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "a", Name: "Old Mouse", Price: 10, Category: "electronics", SKU: "ELEC-0001", Stock: 1, IsActive: true,
+	}))
+
+	body := `[{"name":"Mouse","price":24.99,"category":"electronics","sku":"ELEC-0001"},
+		{"name":"Keyboard","price":49.99,"category":"electronics","sku":"ELEC-0002","stock":5},
+		{"name":"","price":1,"category":"electronics","sku":"ELEC-0003"}]`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/bulk-upsert", bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Results []models.UpsertResult `json:"results"`
+		Created int                   `json:"created"`
+		Updated int                   `json:"updated"`
+		Failed  int                   `json:"failed"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	require.Len(t, response.Results, 3)
+	assert.Equal(t, models.UpsertUpdated, response.Results[0].Status)
+	assert.Equal(t, "a", response.Results[0].ID)
+	assert.Equal(t, models.UpsertCreated, response.Results[1].Status)
+	assert.Equal(t, models.UpsertFailed, response.Results[2].Status)
+	assert.Equal(t, 1, response.Created)
+	assert.Equal(t, 1, response.Updated)
+	assert.Equal(t, 1, response.Failed)
+
+	a, _ := repo.GetByID(context.Background(), "a")
+	assert.Equal(t, "Mouse", a.Name)
+	assert.Equal(t, float64(1), a.Stock)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/bulk-upsert", bytes.NewBufferString(`[]`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestProductHandler_BulkUpsertProducts_UnknownFieldStrict(t *testing.T) {
+	mockService := new(MockProductService)
+	router := setupRouter(NewProductHandler(mockService, WithStrictJSON(true)))
+
+	body := `[{"name":"Mouse","price":24.99,"category":"electronics","sku":"ELEC-0001","stok":5}]`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/bulk-upsert", bytes.NewBufferString(body))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, []interface{}{"[0].stok"}, response["unknown_fields"])
+	mockService.AssertNotCalled(t, "BulkUpsertProducts", mock.Anything)
+}
+
+func TestProductHandler_UpdateProduct_Success(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	newName := "Updated Product"
+	req := models.UpdateProductRequest{
+		Name: &newName,
+	}
+
+	updatedProduct := &models.Product{
+		ID:   "test-id",
+		Name: newName,
+	}
+
+	mockService.On("UpdateProduct", "test-id", req).Return(updatedProduct, nil)
+
+	reqBody, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response models.Product
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, updatedProduct.Name, response.Name)
+
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_UpdateProduct_Changes(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	router := setupRouter(NewProductHandler(service.NewProductService(repo)))
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", Name: "Widget", Price: 10, Stock: 3, Unit: models.UnitEach, IsActive: true,
+	}))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"Widget","price":12,"stock":3}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	var response struct {
+		Price   float64              `json:"price"`
+		Changes []models.FieldChange `json:"changes"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(12), response.Price)
+	assert.Equal(t, []models.FieldChange{{Field: "price", Before: float64(10), After: float64(12)}}, response.Changes)
+
+	// Repeating the update changes nothing.
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"price":12}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	require.Equal(t, http.StatusOK, w.Code)
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Empty(t, response.Changes)
+	assert.Contains(t, w.Body.String(), `"changes":[]`)
+}
+
+func TestProductHandler_UpdateProduct_NotFound(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	req := models.UpdateProductRequest{}
+
+	mockService.On("UpdateProduct", "nonexistent-id", req).Return(nil, service.ErrProductNotFound)
 
-func (m *MockProductService) GetProduct(id string) (*models.Product, error) {
-	args := m.Called(id)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
-	}
-	return args.Get(0).(*models.Product), args.Error(1)
-}
+	reqBody, _ := json.Marshal(req)
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/nonexistent-id", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
 
-func (m *MockProductService) GetAllProducts() ([]*models.Product, error) {
-	args := m.Called()
-	return args.Get(0).([]*models.Product), args.Error(1)
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","id":"nonexistent-id"}`, w.Body.String())
+	mockService.AssertExpectations(t)
 }
 
-func (m *MockProductService) GetProductsByCategory(category string) ([]*models.Product, error) {
-	args := m.Called(category)
-	return args.Get(0).([]*models.Product), args.Error(1)
+func TestProductHandler_UpdateProduct_ConcurrentModification(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	req := models.UpdateProductRequest{}
+	mockService.On("UpdateProduct", "test-id", req).Return(nil, fmt.Errorf("%w: %w", service.ErrConcurrentModification, repository.ErrVersionConflict))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"CONCURRENT_MODIFICATION"`)
+	mockService.AssertExpectations(t)
 }
 
-func (m *MockProductService) UpdateProduct(id string, req models.UpdateProductRequest) (*models.Product, error) {
-	args := m.Called(id, req)
-	if args.Get(0) == nil {
-		return nil, args.Error(1)
+func TestProductHandler_UpdateProduct_IfUnmodifiedSince(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	updatedAt := time.Date(2024, 3, 1, 12, 0, 0, 500, time.UTC)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget", Price: 10, Unit: models.UnitEach, UpdatedAt: updatedAt}))
+
+	update := func(since time.Time, name string) int {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"`+name+`"}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+		httpReq.Header.Set("If-Unmodified-Since", since.Format(http.TimeFormat))
+		router.ServeHTTP(w, httpReq)
+		return w.Code
 	}
-	return args.Get(0).(*models.Product), args.Error(1)
+
+	// Stale: the product changed an hour after the caller's copy.
+	assert.Equal(t, http.StatusPreconditionFailed, update(updatedAt.Add(-time.Hour), "Stale"))
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, "Widget", stored.Name)
+
+	// Fresh: the header matches UpdatedAt to the second.
+	assert.Equal(t, http.StatusOK, update(updatedAt, "Fresh"))
+	stored, _ = repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, "Fresh", stored.Name)
 }
 
-func (m *MockProductService) DeleteProduct(id string) error {
-	args := m.Called(id)
-	return args.Error(0)
+type returnOldResponse struct {
+	Old     map[string]interface{} `json:"old"`
+	New     map[string]interface{} `json:"new"`
+	Changes []models.FieldChange   `json:"changes"`
 }
 
-func setupRouter(handler *ProductHandler) *gin.Engine {
-	gin.SetMode(gin.TestMode)
-	router := gin.New()
-	
-	api := router.Group("/api/v1")
-	api.GET("/health", handler.HealthCheck)
-	
-	products := api.Group("/products")
-	{
-		products.POST("", handler.CreateProduct)
-		products.GET("", handler.GetAllProducts)
-		products.GET("/category", handler.GetProductsByCategory)
-		products.GET("/:id", handler.GetProduct)
-		products.PUT("/:id", handler.UpdateProduct)
-		products.DELETE("/:id", handler.DeleteProduct)
+func TestProductHandler_UpdateProduct_ReturnOld(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget", Price: 10, Unit: models.UnitEach}))
+
+	for _, tc := range []struct {
+		url    string
+		prefer string
+	}{
+		{url: "/api/v1/products/test-id?return=old"},
+		{url: "/api/v1/products/test-id", prefer: "return=representation"},
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PUT", tc.url, bytes.NewBufferString(`{"price":12}`))
+		httpReq.Header.Set("Content-Type", "application/json")
+		if tc.prefer != "" {
+			httpReq.Header.Set("Prefer", tc.prefer)
+		}
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var response returnOldResponse
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, float64(12), response.New["price"])
+		assert.NotNil(t, response.Old["price"])
 	}
-	
-	return router
+
+	// The first update moved the price from 10 to 12.
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id?return=old", bytes.NewBufferString(`{"price":15}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	var response returnOldResponse
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(12), response.Old["price"])
+	assert.Equal(t, float64(15), response.New["price"])
+	assert.Equal(t, []models.FieldChange{{Field: "price", Before: float64(12), After: float64(15)}}, response.Changes)
 }
 
-func TestProductHandler_CreateProduct_Success(t *testing.T) {
-	mockService := new(MockProductService)
-	handler := NewProductHandler(mockService)
+func TestProductHandler_DeleteProduct_ReturnOld(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
 	router := setupRouter(handler)
 
-	req := models.CreateProductRequest{
-		Name:        "Test Product",
-		Description: "A test product",
-		Price:       99.99,
-		Category:    "electronics",
-		SKU:         "TEST-001",
-		Stock:       10,
-	}
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget"}))
 
-	product := &models.Product{
-		ID:          "test-id",
-		Name:        req.Name,
-		Description: req.Description,
-		Price:       req.Price,
-		Category:    req.Category,
-		SKU:         req.SKU,
-		Stock:       req.Stock,
-		IsActive:    true,
-	}
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id?return=old", nil)
+	router.ServeHTTP(w, httpReq)
 
-	mockService.On("CreateProduct", req).Return(product, nil)
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	deleted, ok := response["deleted"].(map[string]interface{})
+	require.True(t, ok)
+	assert.Equal(t, "Widget", deleted["name"])
 
-	reqBody, _ := json.Marshal(req)
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Nil(t, stored)
+}
+
+func TestProductHandler_PatchProduct_JSONPatch(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", Name: "Widget", Price: 10, Category: "tools", SKU: "TOOL-1", Stock: 5, Unit: models.UnitEach, IsActive: true,
+	}))
+
+	patch := `[
+		{"op":"test","path":"/price","value":10},
+		{"op":"replace","path":"/price","value":12.5},
+		{"op":"add","path":"/tags/-","value":"sale"}
+	]`
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBuffer(reqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq, _ := http.NewRequest("PATCH", "/api/v1/products/test-id", bytes.NewBufferString(patch))
+	httpReq.Header.Set("Content-Type", "application/json-patch+json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, 12.5, stored.Price)
+	assert.Equal(t, []string{"sale"}, stored.Tags)
+	assert.Equal(t, "Widget", stored.Name)
+}
+
+func TestProductHandler_PatchProduct_FailingTest(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
 
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", Name: "Widget", Price: 10, Category: "tools", SKU: "TOOL-1", Unit: models.UnitEach, IsActive: true,
+	}))
+
+	patch := `[
+		{"op":"test","path":"/name","value":"Gadget"},
+		{"op":"replace","path":"/price","value":12.5}
+	]`
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PATCH", "/api/v1/products/test-id", bytes.NewBufferString(patch))
+	httpReq.Header.Set("Content-Type", "application/json-patch+json")
 	router.ServeHTTP(w, httpReq)
 
-	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, float64(10), stored.Price)
+}
 
-	var response models.Product
-	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, product.ID, response.ID)
-	assert.Equal(t, product.Name, response.Name)
+func TestProductHandler_PatchProduct_Rejected(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
 
-	mockService.AssertExpectations(t)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", Name: "Widget", Price: 10, Category: "tools", SKU: "TOOL-1", Unit: models.UnitEach, IsActive: true,
+	}))
+
+	for patch, field := range map[string]string{
+		`[{"op":"replace","path":"/id","value":"other"}]`:   "id",
+		`[{"op":"replace","path":"/price","value":-1}]`:     "price",
+		`[{"op":"remove","path":"/name"}]`:                  "name",
+		`[{"op":"replace","path":"/stock","value":"many"}]`: "stock",
+	} {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("PATCH", "/api/v1/products/test-id", bytes.NewBufferString(patch))
+		httpReq.Header.Set("Content-Type", "application/json-patch+json")
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, patch)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, field, response["field"], patch)
+	}
 }
 
-func TestProductHandler_CreateProduct_InvalidJSON(t *testing.T) {
+func TestProductHandler_PatchProduct_MergePatch(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
+	name := "Renamed"
+	mockService.On("UpdateProduct", "test-id", models.UpdateProductRequest{Name: &name}).
+		Return(&models.Product{ID: "test-id", Name: name}, nil)
+
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("POST", "/api/v1/products", bytes.NewBuffer([]byte("invalid json")))
+	httpReq, _ := http.NewRequest("PATCH", "/api/v1/products/test-id", bytes.NewBufferString(`{"name":"Renamed"}`))
+	httpReq.Header.Set("Content-Type", "application/merge-patch+json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
+
+func TestProductHandler_GetProduct_AcceptLanguage(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	handler := NewProductHandler(service.NewProductService(repo))
+	router := setupRouter(handler)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe", IsActive: true}))
+
+	w := httptest.NewRecorder()
+	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id/translations/fr", bytes.NewBufferString(`{"name":"Chaussure"}`))
 	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	for acceptLanguage, want := range map[string]string{
+		"fr-BE, en;q=0.8": "Chaussure",
+		"ja":              "Shoe",
+		"":                "Shoe",
+	} {
+		w = httptest.NewRecorder()
+		httpReq, _ = http.NewRequest("GET", "/api/v1/products/test-id", nil)
+		if acceptLanguage != "" {
+			httpReq.Header.Set("Accept-Language", acceptLanguage)
+		}
+		router.ServeHTTP(w, httpReq)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Values("Vary"), "Accept-Language")
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, want, response["name"], acceptLanguage)
+		if want == "Chaussure" {
+			assert.Equal(t, "fr", w.Header().Get("Content-Language"))
+		} else {
+			assert.Empty(t, w.Header().Get("Content-Language"))
+		}
+	}
 
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("DELETE", "/api/v1/products/test-id/translations/fr", nil)
 	router.ServeHTTP(w, httpReq)
+	assert.Equal(t, http.StatusOK, w.Code)
 
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("PUT", "/api/v1/products/test-id/translations/@@", bytes.NewBufferString(`{"name":"Chaussure"}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 }
 
-func TestProductHandler_GetProduct_Success(t *testing.T) {
+func TestProductHandler_DeleteProduct_Success(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	product := &models.Product{
-		ID:    "test-id",
-		Name:  "Test Product",
-		Price: 99.99,
-	}
-
-	mockService.On("GetProduct", "test-id").Return(product, nil)
+	mockService.On("DeleteProduct", "test-id").Return(nil)
 
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id", nil)
 
 	router.ServeHTTP(w, httpReq)
 
 	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response models.Product
+	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, product.ID, response.ID)
+	assert.Contains(t, response["message"], "deleted successfully")
 
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_GetProduct_NotFound(t *testing.T) {
+func TestProductHandler_DeleteProduct_NotFound(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("GetProduct", "nonexistent-id").Return(nil, service.ErrProductNotFound)
+	mockService.On("DeleteProduct", "nonexistent-id").Return(service.ErrProductNotFound)
 
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("GET", "/api/v1/products/nonexistent-id", nil)
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/nonexistent-id", nil)
 
 	router.ServeHTTP(w, httpReq)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Product not found","code":"PRODUCT_NOT_FOUND","resource":"product","id":"nonexistent-id"}`, w.Body.String())
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_GetAllProducts_Success(t *testing.T) {
+func TestProductHandler_CreateProduct_DryRun(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	products := []*models.Product{
-		{ID: "1", Name: "Product 1"},
-		{ID: "2", Name: "Product 2"},
+	req := models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(10),
 	}
+	product := &models.Product{ID: "test-id", Name: req.Name}
 
-	mockService.On("GetAllProducts").Return(products, nil)
+	mockService.On("CreateProduct", req).Return(product, nil)
 
+	reqBody, _ := json.Marshal(req)
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("GET", "/api/v1/products", nil)
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products?dry_run=true", bytes.NewBuffer(reqBody))
+	httpReq.Header.Set("Content-Type", "application/json")
 
 	router.ServeHTTP(w, httpReq)
 
@@ -198,24 +2980,22 @@ func TestProductHandler_GetAllProducts_Success(t *testing.T) {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, float64(2), response["count"])
+	assert.Equal(t, true, response["dry_run"])
+	assert.Equal(t, "test-id", response["product"].(map[string]interface{})["id"])
 
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_GetProductsByCategory_Success(t *testing.T) {
+func TestProductHandler_DeleteProduct_DryRunHeader(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	products := []*models.Product{
-		{ID: "1", Name: "Product 1", Category: "electronics"},
-	}
-
-	mockService.On("GetProductsByCategory", "electronics").Return(products, nil)
+	mockService.On("DeleteProduct", "test-id").Return(nil)
 
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("GET", "/api/v1/products/category?category=electronics", nil)
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id", nil)
+	httpReq.Header.Set("X-Dry-Run", "true")
 
 	router.ServeHTTP(w, httpReq)
 
@@ -223,109 +3003,262 @@ func TestProductHandler_GetProductsByCategory_Success(t *testing.T) {
 
 	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, "electronics", response["category"])
-	assert.Equal(t, float64(1), response["count"])
+	assert.Equal(t, true, response["dry_run"])
+	assert.Equal(t, "Product would be deleted", response["message"])
 
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_GetProductsByCategory_MissingCategory(t *testing.T) {
+func TestProductHandler_GetProduct_AcceptVersion(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("GET", "/api/v1/products/category", nil)
+	product := &models.Product{ID: "test-id", Name: "Parcel", Price: 9.99, Category: "toys", Stock: 3, Unit: models.UnitEach, WeightGrams: 500, IsActive: true}
+	mockService.On("GetProduct", "test-id").Return(product, nil)
 
-	router.ServeHTTP(w, httpReq)
+	get := func(accept string) *httptest.ResponseRecorder {
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+		if accept != "" {
+			httpReq.Header.Set("Accept", accept)
+		}
+		router.ServeHTTP(w, httpReq)
+		return w
+	}
 
-	assert.Equal(t, http.StatusBadRequest, w.Code)
+	for _, accept := range []string{"", "application/json", "*/*", MediaTypeProductV1, "application/vnd.product.v9+json"} {
+		w := get(accept)
+
+		assert.Equal(t, http.StatusOK, w.Code, accept)
+		assert.Contains(t, w.Header().Values("Vary"), "Accept", accept)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), accept)
+		assert.Equal(t, float64(3), response["stock"], accept)
+		assert.Equal(t, "toys", response["category"], accept)
+		assert.NotContains(t, response, "inventory", accept)
+	}
+	assert.Equal(t, MediaTypeProductV1+"; charset=utf-8", get(MediaTypeProductV1).Header().Get("Content-Type"))
+	assert.Equal(t, "application/json; charset=utf-8", get("application/json").Header().Get("Content-Type"))
+
+	for _, accept := range []string{MediaTypeProductV2, "application/json, " + MediaTypeProductV1 + ";q=0.5, " + MediaTypeProductV2 + ";q=0.9"} {
+		w := get(accept)
+
+		assert.Equal(t, http.StatusOK, w.Code, accept)
+		assert.Equal(t, MediaTypeProductV2+"; charset=utf-8", w.Header().Get("Content-Type"), accept)
+		var response struct {
+			Stock    *float64 `json:"stock"`
+			Category struct {
+				Name string   `json:"name"`
+				Path []string `json:"path"`
+			} `json:"category"`
+			Inventory struct {
+				Stock        float64 `json:"stock"`
+				Availability string  `json:"availability"`
+			} `json:"inventory"`
+			Shipping struct {
+				WeightGrams int `json:"weight_grams"`
+			} `json:"shipping"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response), accept)
+		assert.Nil(t, response.Stock, accept)
+		assert.Equal(t, "toys", response.Category.Name, accept)
+		assert.Equal(t, []string{"toys"}, response.Category.Path, accept)
+		assert.Equal(t, float64(3), response.Inventory.Stock, accept)
+		assert.Equal(t, models.InStock, response.Inventory.Availability, accept)
+		assert.Equal(t, 500, response.Shipping.WeightGrams, accept)
+	}
+
+	// A client that ranks v2 below v1 gets v1.
+	w := get(MediaTypeProductV2 + ";q=0.2, " + MediaTypeProductV1)
+	assert.Equal(t, MediaTypeProductV1+"; charset=utf-8", w.Header().Get("Content-Type"))
 }
 
-func TestProductHandler_UpdateProduct_Success(t *testing.T) {
-	mockService := new(MockProductService)
-	handler := NewProductHandler(mockService)
-	router := setupRouter(handler)
+func TestProductHandler_GetProduct_FieldNaming(t *testing.T) {
+	product := &models.Product{
+		ID:       "test-id",
+		Name:     "Test Product",
+		Price:    99.99,
+		IsActive: true,
+	}
 
-	newName := "Updated Product"
-	req := models.UpdateProductRequest{
-		Name: &newName,
+	tests := []struct {
+		name    string
+		opts    []HandlerOption
+		header  string
+		present []string
+		absent  []string
+	}{
+		{
+			name:    "snake case by default",
+			present: []string{"is_active", "created_at", "description"},
+			absent:  []string{"isActive", "createdAt"},
+		},
+		{
+			name:    "camel case from config",
+			opts:    []HandlerOption{WithFieldNaming(CamelCase)},
+			present: []string{"isActive", "createdAt", "updatedBy"},
+			absent:  []string{"is_active", "created_at"},
+		},
+		{
+			name:    "camel case from header",
+			header:  "camel",
+			present: []string{"isActive", "createdAt"},
+			absent:  []string{"is_active", "created_at"},
+		},
+		{
+			name:    "header overrides config",
+			opts:    []HandlerOption{WithFieldNaming(CamelCase)},
+			header:  "snake",
+			present: []string{"is_active", "created_at"},
+			absent:  []string{"isActive", "createdAt"},
+		},
+		{
+			name:    "omit empty optional fields",
+			opts:    []HandlerOption{WithOmitEmpty(true)},
+			present: []string{"id", "is_active", "price"},
+			absent:  []string{"description", "created_by", "price_updated_at"},
+		},
 	}
 
-	updatedProduct := &models.Product{
-		ID:   "test-id",
-		Name: newName,
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockService := new(MockProductService)
+			handler := NewProductHandler(mockService, tt.opts...)
+			router := setupRouter(handler)
+
+			mockService.On("GetProduct", "test-id").Return(product, nil)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+			if tt.header != "" {
+				httpReq.Header.Set("X-Field-Naming", tt.header)
+			}
+
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, http.StatusOK, w.Code)
+
+			var response map[string]interface{}
+			json.Unmarshal(w.Body.Bytes(), &response)
+			for _, key := range tt.present {
+				assert.Contains(t, response, key)
+			}
+			for _, key := range tt.absent {
+				assert.NotContains(t, response, key)
+			}
+		})
 	}
+}
 
-	mockService.On("UpdateProduct", "test-id", req).Return(updatedProduct, nil)
+func TestProductHandler_GetProduct_Availability(t *testing.T) {
+	tests := []struct {
+		stock        float64
+		inStock      bool
+		availability string
+	}{
+		{stock: 0, inStock: false, availability: models.OutOfStock},
+		{stock: 4, inStock: true, availability: models.LowStock},
+		{stock: 50, inStock: true, availability: models.InStock},
+	}
+
+	for _, tt := range tests {
+		mockService := new(MockProductService)
+		handler := NewProductHandler(mockService, WithLowStockThreshold(5))
+		router := setupRouter(handler)
+		mockService.On("GetProduct", "test-id").Return(&models.Product{ID: "test-id", Stock: tt.stock}, nil)
+
+		w := httptest.NewRecorder()
+		httpReq, _ := http.NewRequest("GET", "/api/v1/products/test-id", nil)
+		router.ServeHTTP(w, httpReq)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var response map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+		assert.Equal(t, tt.inStock, response["in_stock"], "stock %g", tt.stock)
+		assert.Equal(t, tt.availability, response["availability"], "stock %g", tt.stock)
+	}
+}
+
+func TestProductHandler_RateProduct(t *testing.T) {
+	mockService := new(MockProductService)
+	handler := NewProductHandler(mockService)
+	router := setupRouter(handler)
+
+	rated := &models.Product{ID: "test-id", RatingSum: 9, RatingCount: 2}
+	mockService.On("RateProduct", "test-id", 4).Return(rated, nil)
+	mockService.On("RateProduct", "test-id", 9).Return(nil,
+		fmt.Errorf("%w: %w", service.ErrInvalidProduct, &service.FieldError{Field: "rating", Message: "rating must be between 1 and 5"}))
 
-	reqBody, _ := json.Marshal(req)
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/test-id", bytes.NewBuffer(reqBody))
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/test-id/ratings", bytes.NewBufferString(`{"rating":4}`))
 	httpReq.Header.Set("Content-Type", "application/json")
-
 	router.ServeHTTP(w, httpReq)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-
-	var response models.Product
+	var response map[string]interface{}
 	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Equal(t, updatedProduct.Name, response.Name)
+	assert.Equal(t, 4.5, response["average_rating"])
+	assert.Equal(t, float64(2), response["rating_count"])
 
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/test-id/ratings", bytes.NewBufferString(`{"rating":9}`))
+	httpReq.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, httpReq)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	json.Unmarshal(w.Body.Bytes(), &response)
+	assert.Equal(t, "rating", response["field"])
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_UpdateProduct_NotFound(t *testing.T) {
+func TestProductHandler_DeleteProduct_Soft(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	req := models.UpdateProductRequest{}
-
-	mockService.On("UpdateProduct", "nonexistent-id", req).Return(nil, service.ErrProductNotFound)
+	mockService.On("SoftDeleteProduct", "test-id").Return(nil)
 
-	reqBody, _ := json.Marshal(req)
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("PUT", "/api/v1/products/nonexistent-id", bytes.NewBuffer(reqBody))
-	httpReq.Header.Set("Content-Type", "application/json")
-
+	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id?soft=true", nil)
 	router.ServeHTTP(w, httpReq)
 
-	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
 	mockService.AssertExpectations(t)
+	mockService.AssertNotCalled(t, "DeleteProduct", mock.Anything)
 }
 
-func TestProductHandler_DeleteProduct_Success(t *testing.T) {
+func TestProductHandler_DeleteProduct_HardDeleteDisabled(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("DeleteProduct", "test-id").Return(nil)
+	mockService.On("DeleteProduct", "test-id").Return(service.ErrHardDeleteDisabled)
 
 	w := httptest.NewRecorder()
 	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/test-id", nil)
-
 	router.ServeHTTP(w, httpReq)
 
-	assert.Equal(t, http.StatusOK, w.Code)
-
-	var response map[string]interface{}
-	json.Unmarshal(w.Body.Bytes(), &response)
-	assert.Contains(t, response["message"], "deleted successfully")
-
+	assert.Equal(t, http.StatusForbidden, w.Code)
 	mockService.AssertExpectations(t)
 }
 
-func TestProductHandler_DeleteProduct_NotFound(t *testing.T) {
+func TestProductHandler_RestoreProduct(t *testing.T) {
 	mockService := new(MockProductService)
 	handler := NewProductHandler(mockService)
 	router := setupRouter(handler)
 
-	mockService.On("DeleteProduct", "nonexistent-id").Return(service.ErrProductNotFound)
+	mockService.On("RestoreProduct", "test-id").Return(&models.Product{ID: "test-id", IsActive: true}, nil)
+	mockService.On("RestoreProduct", "missing").Return(nil, service.ErrProductNotFound)
 
 	w := httptest.NewRecorder()
-	httpReq, _ := http.NewRequest("DELETE", "/api/v1/products/nonexistent-id", nil)
+	httpReq, _ := http.NewRequest("POST", "/api/v1/products/test-id/restore", nil)
+	router.ServeHTTP(w, httpReq)
 
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = httptest.NewRecorder()
+	httpReq, _ = http.NewRequest("POST", "/api/v1/products/missing/restore", nil)
 	router.ServeHTTP(w, httpReq)
 
 	assert.Equal(t, http.StatusNotFound, w.Code)
@@ -348,4 +3281,50 @@ func TestProductHandler_HealthCheck(t *testing.T) {
 	json.Unmarshal(w.Body.Bytes(), &response)
 	assert.Equal(t, "healthy", response["status"])
 	assert.Equal(t, "product-service", response["service"])
-}
\ No newline at end of file
+}
+
+type stubTableStatus struct {
+	status database.TableStatus
+	err    error
+}
+
+func (s stubTableStatus) DescribeTable(ctx context.Context) (database.TableStatus, error) {
+	return s.status, s.err
+}
+
+func TestProductHandler_ReadinessCheck(t *testing.T) {
+	tests := []struct {
+		name       string
+		tables     stubTableStatus
+		wantCode   int
+		wantStatus string
+	}{
+		{"active", stubTableStatus{status: database.TableStatus{Status: "ACTIVE", ItemCount: 7}}, http.StatusOK, "healthy"},
+		{"creating", stubTableStatus{status: database.TableStatus{Status: "CREATING"}}, http.StatusOK, "degraded"},
+		{"updating", stubTableStatus{status: database.TableStatus{Status: "UPDATING"}}, http.StatusOK, "degraded"},
+		{"deleting", stubTableStatus{status: database.TableStatus{Status: "DELETING"}}, http.StatusServiceUnavailable, "unavailable"},
+		{"missing", stubTableStatus{status: database.TableStatus{Status: database.TableStatusMissing}}, http.StatusServiceUnavailable, "unavailable"},
+		{"unreachable", stubTableStatus{err: errors.New("connection refused")}, http.StatusServiceUnavailable, "unavailable"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			handler := NewProductHandler(new(MockProductService), WithTableStatus(tt.tables))
+			router := setupRouter(handler)
+
+			w := httptest.NewRecorder()
+			httpReq, _ := http.NewRequest("GET", "/api/v1/health/ready", nil)
+			router.ServeHTTP(w, httpReq)
+
+			assert.Equal(t, tt.wantCode, w.Code)
+			var response map[string]interface{}
+			require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+			assert.Equal(t, tt.wantStatus, response["status"])
+			if tt.tables.err == nil {
+				table := response["table"].(map[string]interface{})
+				assert.Equal(t, tt.tables.status.Status, table["status"])
+				assert.Equal(t, float64(tt.tables.status.ItemCount), table["item_count"])
+			}
+		})
+	}
+}
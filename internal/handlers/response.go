@@ -0,0 +1,272 @@
+package handlers
+
+import (
+	"bytes"
+	"encoding/json"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+)
+
+// FieldNaming selects the key style used when serializing products.
+type FieldNaming int
+
+const (
+	SnakeCase FieldNaming = iota
+	CamelCase
+)
+
+// fieldNamingHeader lets a client override the configured naming per request.
+const fieldNamingHeader = "X-Field-Naming"
+
+// ParseFieldNaming accepts "snake" or "camel" (and their _case/Case forms).
+func ParseFieldNaming(value string) (FieldNaming, bool) {
+	switch strings.ToLower(value) {
+	case "snake", "snake_case":
+		return SnakeCase, true
+	case "camel", "camelcase":
+		return CamelCase, true
+	}
+	return SnakeCase, false
+}
+
+// productField is a single wire field. Keys are written in snake_case and
+// converted when the response uses another naming style.
+type productField struct {
+	key      string
+	value    any
+	optional bool
+}
+
+// productDTO is the wire representation of a product. It keeps the storage
+// model's tags out of the API contract and preserves field order.
+type productDTO []productField
+
+func (d productDTO) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+	buf.WriteByte('{')
+	for i, f := range d {
+		if i > 0 {
+			buf.WriteByte(',')
+		}
+		key, err := json.Marshal(f.key)
+		if err != nil {
+			return nil, err
+		}
+		value, err := json.Marshal(f.value)
+		if err != nil {
+			return nil, err
+		}
+		buf.Write(key)
+		buf.WriteByte(':')
+		buf.Write(value)
+	}
+	buf.WriteByte('}')
+	return buf.Bytes(), nil
+}
+
+func newProductDTO(p *models.Product, naming FieldNaming, omitEmpty bool, lowStock float64, now time.Time) productDTO {
+	fields := []productField{
+		{key: "id", value: p.ID},
+		{key: "name", value: p.Name},
+		{key: "description", value: p.Description, optional: true},
+		{key: "price", value: p.Price},
+		{key: "category", value: p.Category},
+		{key: "category_path", value: categoryPath(p)},
+		{key: "sku", value: p.SKU},
+		{key: "slug", value: p.Slug, optional: true},
+		{key: "stock", value: p.Stock},
+		{key: "unit", value: p.Unit},
+		{key: "is_active", value: p.IsActive},
+		{key: "status", value: p.CurrentStatus()},
+		{key: "in_stock", value: p.InStock(now)},
+		{key: "availability", value: p.Availability(lowStock, now)},
+		{key: "available_from", value: p.AvailableFrom, optional: true},
+		{key: "weight_grams", value: p.WeightGrams, optional: true},
+		{key: "length_mm", value: p.LengthMM, optional: true},
+		{key: "width_mm", value: p.WidthMM, optional: true},
+		{key: "height_mm", value: p.HeightMM, optional: true},
+		{key: "bundle_items", value: bundleItemsView(p.BundleItems, naming, omitEmpty), optional: true},
+		{key: "featured", value: p.Featured},
+		{key: "featured_rank", value: p.FeaturedRank, optional: true},
+		{key: "tags", value: nonNilTags(p.Tags), optional: true},
+		{key: "images", value: nonNilTags(p.Images), optional: true},
+		{key: "broken_images", value: nonNilTags(p.BrokenImages), optional: true},
+		{key: "translations", value: nonNilTranslations(p.Translations), optional: true},
+		{key: "view_count", value: p.ViewCount},
+		{key: "average_rating", value: p.AverageRating()},
+		{key: "rating_count", value: p.RatingCount},
+		{key: "created_at", value: p.CreatedAt},
+		{key: "updated_at", value: p.UpdatedAt},
+		{key: "created_by", value: p.CreatedBy, optional: true},
+		{key: "updated_by", value: p.UpdatedBy, optional: true},
+		{key: "version", value: p.Version},
+		{key: "price_updated_at", value: p.PriceUpdatedAt, optional: true},
+		{key: "stock_updated_at", value: p.StockUpdatedAt, optional: true},
+		{key: "deleted_at", value: p.DeletedAt, optional: true},
+	}
+	return newDTO(fields, naming, omitEmpty)
+}
+
+// newDTO applies the response's naming style and omit-empty setting to
+// fields.
+func newDTO(fields []productField, naming FieldNaming, omitEmpty bool) productDTO {
+	dto := make(productDTO, 0, len(fields))
+	for _, f := range fields {
+		if omitEmpty && f.optional && isZero(f.value) {
+			continue
+		}
+		if naming == CamelCase {
+			f.key = snakeToCamel(f.key)
+		}
+		dto = append(dto, f)
+	}
+	return dto
+}
+
+// bundleItemsView renders a bundle's items in the response's naming style.
+// A product sold on its own renders them as [] rather than null.
+func bundleItemsView(items []models.BundleItem, naming FieldNaming, omitEmpty bool) []productDTO {
+	views := make([]productDTO, 0, len(items))
+	for _, item := range items {
+		views = append(views, newDTO([]productField{
+			{key: "product_id", value: item.ProductID},
+			{key: "quantity", value: item.Quantity},
+		}, naming, omitEmpty))
+	}
+	return views
+}
+
+// nonNilTags keeps an untagged product's tags rendering as [] rather than null.
+func nonNilTags(tags []string) []string {
+	if tags == nil {
+		return []string{}
+	}
+	return tags
+}
+
+// categoryPath renders a product outside any category hierarchy as the
+// one-element path of its own category.
+func categoryPath(p *models.Product) []string {
+	if len(p.CategoryPath) == 0 {
+		return []string{p.Category}
+	}
+	return p.CategoryPath
+}
+
+// nonNilTranslations renders a product without translations as {}.
+func nonNilTranslations(translations map[string]models.ProductTranslation) map[string]models.ProductTranslation {
+	if translations == nil {
+		return map[string]models.ProductTranslation{}
+	}
+	return translations
+}
+
+func isZero(value any) bool {
+	switch v := value.(type) {
+	case string:
+		return v == ""
+	case []string:
+		return len(v) == 0
+	case map[string]models.ProductTranslation:
+		return len(v) == 0
+	case []productDTO:
+		return len(v) == 0
+	case *time.Time:
+		return v == nil
+	case *int:
+		return v == nil
+	case nil:
+		return true
+	}
+	return false
+}
+
+func snakeToCamel(key string) string {
+	parts := strings.Split(key, "_")
+	for i := 1; i < len(parts); i++ {
+		if parts[i] != "" {
+			parts[i] = strings.ToUpper(parts[i][:1]) + parts[i][1:]
+		}
+	}
+	return strings.Join(parts, "")
+}
+
+// writeJSON renders obj as the response body. It is compact unless the
+// client asked for indented output, for reading with curl, with
+// ?pretty=true or a Prefer: pretty header.
+func writeJSON(c *gin.Context, status int, obj any) {
+	if pretty, _ := strconv.ParseBool(c.Query("pretty")); pretty || prefers(c, "pretty") {
+		c.IndentedJSON(status, obj)
+		return
+	}
+	c.JSON(status, obj)
+}
+
+// fieldNaming returns the naming requested by the client, falling back to
+// the handler's configured default.
+func (h *ProductHandler) fieldNaming(c *gin.Context) FieldNaming {
+	if naming, ok := ParseFieldNaming(c.GetHeader(fieldNamingHeader)); ok {
+		return naming
+	}
+	return h.naming
+}
+
+// productView renders p in the response version the client negotiated.
+func (h *ProductHandler) productView(c *gin.Context, p *models.Product) productDTO {
+	return h.encoder(c)(p, h.fieldNaming(c), h.omitEmpty, h.lowStock, h.clock.Now())
+}
+
+// bundleView renders a bundle and its component products like any other
+// product response. A deleted component renders as a null product.
+func (h *ProductHandler) bundleView(c *gin.Context, bundle *models.ProductBundle) productDTO {
+	naming := h.fieldNaming(c)
+	encode := h.encoder(c)
+	now := h.clock.Now()
+	components := make([]productDTO, 0, len(bundle.Components))
+	for _, component := range bundle.Components {
+		var product any
+		if component.Product != nil {
+			product = encode(component.Product, naming, h.omitEmpty, h.lowStock, now)
+		}
+		components = append(components, newDTO([]productField{
+			{key: "product_id", value: component.ProductID},
+			{key: "quantity", value: component.Quantity},
+			{key: "product", value: product},
+		}, naming, h.omitEmpty))
+	}
+	return newDTO([]productField{
+		{key: "product", value: encode(bundle.Product, naming, h.omitEmpty, h.lowStock, now)},
+		{key: "components", value: components},
+		{key: "components_price", value: bundle.ComponentsPrice},
+		{key: "available_stock", value: bundle.AvailableStock},
+	}, naming, h.omitEmpty)
+}
+
+// changesView names changed fields in the response's naming style.
+func (h *ProductHandler) changesView(c *gin.Context, changes []models.FieldChange) []models.FieldChange {
+	if h.fieldNaming(c) != CamelCase {
+		return changes
+	}
+	views := make([]models.FieldChange, 0, len(changes))
+	for _, change := range changes {
+		change.Field = snakeToCamel(change.Field)
+		views = append(views, change)
+	}
+	return views
+}
+
+func (h *ProductHandler) productViews(c *gin.Context, products []*models.Product) []productDTO {
+	naming := h.fieldNaming(c)
+	encode := h.encoder(c)
+	now := h.clock.Now()
+	views := make([]productDTO, 0, len(products))
+	for _, p := range products {
+		views = append(views, encode(p, naming, h.omitEmpty, h.lowStock, now))
+	}
+	return views
+}
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/models"
+)
+
+// TotalCount selects how paginated listings fill X-Total-Count. The
+// strategies trade accuracy for cost:
+//
+//   - TotalCountExact, the zero value, reports how many products the
+//     listing matched. Listings already scan every match, so this is free
+//     unless the scan stopped at the scan cap; the repository then finishes
+//     with a Select COUNT scan, which still reads the rest of the table.
+//     Enable that with repository.WithExactCounts, bounded by
+//     repository.WithScanCapacityBudget, or the header is omitted for
+//     truncated listings. The service is configured with TotalCountNone
+//     unless PAGINATION_TOTAL_COUNT asks otherwise.
+//   - TotalCountEstimate reports the table's item count from DescribeTable.
+//     It costs no reads, but DynamoDB refreshes it only about every six
+//     hours and it counts every product, whatever the listing's filter.
+//   - TotalCountNone never sends the header.
+type TotalCount int
+
+const (
+	TotalCountExact TotalCount = iota
+	TotalCountEstimate
+	TotalCountNone
+)
+
+// ParseTotalCount accepts "exact", "estimate" or "none".
+func ParseTotalCount(value string) (TotalCount, bool) {
+	switch strings.ToLower(value) {
+	case "exact":
+		return TotalCountExact, true
+	case "estimate":
+		return TotalCountEstimate, true
+	case "none":
+		return TotalCountNone, true
+	}
+	return TotalCountExact, false
+}
+
+// WithTotalCount sets how X-Total-Count is computed. Estimates need
+// WithTableStatus; without it no estimate is sent.
+func WithTotalCount(strategy TotalCount) HandlerOption {
+	return func(h *ProductHandler) {
+		h.totalCount = strategy
+	}
+}
+
+// setTotalCount sets X-Total-Count according to the configured strategy,
+// leaving it out when the total is not known.
+func (h *ProductHandler) setTotalCount(c *gin.Context, list *models.ProductList) {
+	switch h.totalCount {
+	case TotalCountExact:
+		// A truncated scan did not see every match unless the repository
+		// counted the rest.
+		if !list.Uncounted && (!list.Truncated || list.Matched > 0) {
+			c.Header("X-Total-Count", strconv.Itoa(list.Total))
+		}
+	case TotalCountEstimate:
+		if h.tables == nil {
+			return
+		}
+		status, err := h.tables.DescribeTable(c.Request.Context())
+		if err == nil {
+			c.Header("X-Total-Count", strconv.FormatInt(status.ItemCount, 10))
+		}
+	}
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"reflect"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/gin-gonic/gin/binding"
+	"github.com/go-playground/validator/v10"
+
+	"product-service/internal/models"
+	"product-service/internal/service"
+)
+
+// ValidateProducts checks a batch of create requests, typically an import
+// file, and reports for each item whether a create would accept it. Nothing
+// is created. Items are checked against the request binding rules as well
+// as the service's validation, so a malformed item is reported on its own
+// rather than failing the whole batch.
+func (h *ProductHandler) ValidateProducts(c *gin.Context) {
+	var reqs []models.CreateProductRequest
+	if !h.bindJSONItems(c, &reqs) {
+		return
+	}
+
+	results, err := h.service.ValidateProducts(c.Request.Context(), reqs)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidQuery) {
+			writeJSON(c, http.StatusBadRequest, gin.H{
+				"error":   "Invalid validation batch",
+				"code":    errorCode(err),
+				"details": err.Error(),
+			})
+			return
+		}
+		writeServerError(c, "Failed to validate products", err)
+		return
+	}
+
+	invalid := 0
+	for i := range results {
+		// A create rejects a request that fails binding before the service
+		// sees it, so those errors replace the service's.
+		if err := binding.Validator.ValidateStruct(&reqs[i]); err != nil {
+			results[i].Errors = bindingErrors(err)
+			results[i].Valid = false
+		}
+		if !results[i].Valid {
+			invalid++
+		}
+	}
+
+	writeJSON(c, http.StatusOK, gin.H{
+		"results": results,
+		"valid":   len(results) - invalid,
+		"invalid": invalid,
+	})
+}
+
+// bindingErrors describes the binding rules a create request broke, naming
+// fields as they appear in JSON.
+func bindingErrors(err error) []models.ValidationError {
+	var fieldErrs validator.ValidationErrors
+	if !errors.As(err, &fieldErrs) {
+		return []models.ValidationError{{Message: err.Error()}}
+	}
+
+	reqType := reflect.TypeOf(models.CreateProductRequest{})
+	errs := make([]models.ValidationError, 0, len(fieldErrs))
+	for _, fieldErr := range fieldErrs {
+		field := fieldErr.Field()
+		if structField, ok := reqType.FieldByName(fieldErr.StructField()); ok {
+			field, _, _ = strings.Cut(structField.Tag.Get("json"), ",")
+		}
+
+		message := fmt.Sprintf("product %s is invalid", field)
+		switch fieldErr.Tag() {
+		case "required":
+			message = fmt.Sprintf("product %s is required", field)
+		case "gt":
+			message = fmt.Sprintf("product %s must be greater than %s", field, fieldErr.Param())
+		case "gte":
+			message = fmt.Sprintf("product %s must be at least %s", field, fieldErr.Param())
+		}
+		errs = append(errs, models.ValidationError{Field: field, Message: message})
+	}
+	return errs
+}
@@ -0,0 +1,150 @@
+package handlers
+
+import (
+	"mime"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// Product responses are versioned by media type rather than by URL. A
+// client asks for a version with, for example,
+// "Accept: application/vnd.product.v2+json"; anything else gets v1.
+const (
+	MediaTypeProductV1 = "application/vnd.product.v1+json"
+	MediaTypeProductV2 = "application/vnd.product.v2+json"
+)
+
+// productEncoder renders a product in one response version.
+type productEncoder func(p *models.Product, naming FieldNaming, omitEmpty bool, lowStock float64, now time.Time) productDTO
+
+// responseVersions maps each supported media type to its encoder.
+var responseVersions = map[string]productEncoder{
+	MediaTypeProductV1: newProductDTO,
+	MediaTypeProductV2: newProductDTOV2,
+}
+
+// negotiateVersion picks the supported media type the Accept header
+// prefers, by quality and then by order. It reports false when the header
+// names none, in which case v1 is served under the plain JSON type.
+func negotiateVersion(accept string) (string, bool) {
+	best, bestQuality := "", 0.0
+	for _, candidate := range strings.Split(accept, ",") {
+		mediaType, params, err := mime.ParseMediaType(strings.TrimSpace(candidate))
+		if err != nil {
+			continue
+		}
+		if _, ok := responseVersions[mediaType]; !ok {
+			continue
+		}
+		quality := 1.0
+		if raw, ok := params["q"]; ok {
+			if quality, err = strconv.ParseFloat(raw, 64); err != nil {
+				continue
+			}
+		}
+		if quality > bestQuality {
+			best, bestQuality = mediaType, quality
+		}
+	}
+	return best, best != ""
+}
+
+// encoder negotiates the response version for c and hides the fields the
+// caller's role may not see.
+func (h *ProductHandler) encoder(c *gin.Context) productEncoder {
+	encode := h.versionEncoder(c)
+	hidden := h.fieldAccess.hidden(auth.Role(c.Request.Context()))
+	if len(hidden) == 0 {
+		return encode
+	}
+	return func(p *models.Product, naming FieldNaming, omitEmpty bool, lowStock float64, now time.Time) productDTO {
+		return encode(p, naming, omitEmpty, lowStock, now).without(hidden)
+	}
+}
+
+// versionEncoder negotiates the response version for c. When the client
+// asked for a versioned media type the response is labelled with it, unless
+// the handler has already chosen another content type, as the export does.
+func (h *ProductHandler) versionEncoder(c *gin.Context) productEncoder {
+	header := c.Writer.Header()
+	if !slices.Contains(header.Values("Vary"), "Accept") {
+		header.Add("Vary", "Accept")
+	}
+
+	mediaType, ok := negotiateVersion(c.GetHeader("Accept"))
+	if !ok {
+		return newProductDTO
+	}
+	if header.Get("Content-Type") == "" {
+		header.Set("Content-Type", mediaType+"; charset=utf-8")
+	}
+	return responseVersions[mediaType]
+}
+
+// newProductDTOV2 groups the flat v1 fields into category, inventory,
+// shipping, rating and audit objects.
+func newProductDTOV2(p *models.Product, naming FieldNaming, omitEmpty bool, lowStock float64, now time.Time) productDTO {
+	var shipping any
+	if p.WeightGrams != 0 || p.LengthMM != 0 || p.WidthMM != 0 || p.HeightMM != 0 {
+		shipping = newDTO([]productField{
+			{key: "weight_grams", value: p.WeightGrams},
+			{key: "length_mm", value: p.LengthMM},
+			{key: "width_mm", value: p.WidthMM},
+			{key: "height_mm", value: p.HeightMM},
+			{key: "volumetric_weight_grams", value: p.VolumetricWeight()},
+		}, naming, omitEmpty)
+	}
+
+	fields := []productField{
+		{key: "id", value: p.ID},
+		{key: "name", value: p.Name},
+		{key: "description", value: p.Description, optional: true},
+		{key: "sku", value: p.SKU},
+		{key: "slug", value: p.Slug, optional: true},
+		{key: "price", value: p.Price},
+		{key: "category", value: newDTO([]productField{
+			{key: "name", value: p.Category},
+			{key: "path", value: categoryPath(p)},
+		}, naming, omitEmpty)},
+		{key: "inventory", value: newDTO([]productField{
+			{key: "stock", value: p.Stock},
+			{key: "unit", value: p.Unit},
+			{key: "in_stock", value: p.InStock(now)},
+			{key: "availability", value: p.Availability(lowStock, now)},
+			{key: "available_from", value: p.AvailableFrom, optional: true},
+		}, naming, omitEmpty)},
+		{key: "shipping", value: shipping, optional: true},
+		{key: "bundle_items", value: bundleItemsView(p.BundleItems, naming, omitEmpty), optional: true},
+		{key: "is_active", value: p.IsActive},
+		{key: "status", value: p.CurrentStatus()},
+		{key: "featured", value: p.Featured},
+		{key: "featured_rank", value: p.FeaturedRank, optional: true},
+		{key: "tags", value: nonNilTags(p.Tags), optional: true},
+		{key: "images", value: nonNilTags(p.Images), optional: true},
+		{key: "broken_images", value: nonNilTags(p.BrokenImages), optional: true},
+		{key: "translations", value: nonNilTranslations(p.Translations), optional: true},
+		{key: "rating", value: newDTO([]productField{
+			{key: "average", value: p.AverageRating()},
+			{key: "count", value: p.RatingCount},
+		}, naming, omitEmpty)},
+		{key: "view_count", value: p.ViewCount},
+		{key: "audit", value: newDTO([]productField{
+			{key: "created_at", value: p.CreatedAt},
+			{key: "created_by", value: p.CreatedBy, optional: true},
+			{key: "updated_at", value: p.UpdatedAt},
+			{key: "updated_by", value: p.UpdatedBy, optional: true},
+			{key: "price_updated_at", value: p.PriceUpdatedAt, optional: true},
+			{key: "stock_updated_at", value: p.StockUpdatedAt, optional: true},
+			{key: "deleted_at", value: p.DeletedAt, optional: true},
+		}, naming, omitEmpty)},
+		{key: "version", value: p.Version},
+	}
+	return newDTO(fields, naming, omitEmpty)
+}
@@ -0,0 +1,112 @@
+package httpserver
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"product-service/internal/auth"
+)
+
+// CachePolicy sets how long caches may reuse public GET responses, by
+// endpoint class. Everything else, such as mutations, admin and health
+// endpoints and failed requests, is marked no-store.
+type CachePolicy struct {
+	// ListingMaxAge applies to product listings. Listings carry an ETag,
+	// so once it passes a cache revalidates cheaply with If-None-Match; 0
+	// makes it revalidate on every use.
+	ListingMaxAge time.Duration
+	// ProductMaxAge applies to responses about a single product. 0 makes
+	// caches revalidate on every use.
+	ProductMaxAge time.Duration
+}
+
+// DefaultCachePolicy keeps listings, which change with any product, fresher
+// than individual products.
+var DefaultCachePolicy = CachePolicy{
+	ListingMaxAge: 30 * time.Second,
+	ProductMaxAge: 5 * time.Minute,
+}
+
+// productRoutes are the GET routes that answer about a single product.
+var productRoutes = map[string]bool{
+	"/api/v1/products/:id":          true,
+	"/api/v1/products/:id/shipping": true,
+	"/api/v1/products/:id/bundle":   true,
+	"/api/v1/products/slug/:slug":   true,
+	"/api/v1/products/sku/:sku":     true,
+	"/api/v1/products/lookup":       true,
+}
+
+// listingRoutes are the GET routes that list products. Other product
+// routes, such as the change feed, the export and the valuation report, are
+// read by clients that need them current and are marked no-store.
+var listingRoutes = map[string]bool{
+	"/api/v1/products":          true,
+	"/api/v1/products/category": true,
+	"/api/v1/products/filter":   true,
+	"/api/v1/products/trending": true,
+	"/api/v1/products/featured": true,
+	"/api/v1/products/suggest":  true,
+	"/api/v1/products/search":   true,
+}
+
+// cacheControlMiddleware sets Cache-Control on every response according to
+// policy. Responses to callers who identify themselves are private, since
+// field access and feature flags can tailor them to the caller.
+func cacheControlMiddleware(policy CachePolicy) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		c.Header("Cache-Control", policy.cacheControl(c))
+
+		c.Writer = &cacheControlWriter{ResponseWriter: c.Writer}
+		c.Next()
+	}
+}
+
+const noStore = "no-store"
+
+// cacheControl picks the Cache-Control value for the route c matched.
+func (p CachePolicy) cacheControl(c *gin.Context) string {
+	method := c.Request.Method
+	if method != http.MethodGet && method != http.MethodHead {
+		return noStore
+	}
+	var age time.Duration
+	switch path := c.FullPath(); {
+	case productRoutes[path]:
+		age = p.ProductMaxAge
+	case listingRoutes[path]:
+		age = p.ListingMaxAge
+	default:
+		return noStore
+	}
+
+	scope := "public"
+	if _, ok := auth.PrincipalFromContext(c.Request.Context()); ok {
+		scope = "private"
+	}
+	if age <= 0 {
+		return scope + ", no-cache"
+	}
+	return fmt.Sprintf("%s, max-age=%d", scope, int(age.Seconds()))
+}
+
+// cacheControlWriter marks error responses no-store, so a cache never holds
+// on to a transient failure or a 404 for a product about to be created.
+type cacheControlWriter struct {
+	gin.ResponseWriter
+}
+
+func (w *cacheControlWriter) WriteHeader(code int) {
+	if code >= http.StatusBadRequest {
+		w.Header().Set("Cache-Control", noStore)
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *cacheControlWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
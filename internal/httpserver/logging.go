@@ -0,0 +1,138 @@
+package httpserver
+
+import (
+	"log/slog"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// redactedValue replaces sensitive header and query parameter values in
+// request logs.
+const redactedValue = "***"
+
+// Default sensitive fields, always redacted. LOG_REDACT_HEADERS and
+// LOG_REDACT_QUERY_PARAMS add to them.
+var (
+	DefaultRedactedHeaders     = []string{"Authorization", "Proxy-Authorization", "X-API-Key", "Cookie", "Set-Cookie"}
+	DefaultRedactedQueryParams = []string{"api_key", "token", "access_token"}
+)
+
+// redaction names the headers and query parameters whose values must not
+// reach the logs. Both are matched case-insensitively.
+type redaction struct {
+	headers map[string]bool
+	params  map[string]bool
+}
+
+func newRedaction(headers, params []string) redaction {
+	r := redaction{
+		headers: make(map[string]bool, len(headers)),
+		params:  make(map[string]bool, len(params)),
+	}
+	for _, h := range headers {
+		r.headers[http.CanonicalHeaderKey(strings.TrimSpace(h))] = true
+	}
+	for _, p := range params {
+		r.params[strings.ToLower(strings.TrimSpace(p))] = true
+	}
+	return r
+}
+
+// extendedRedaction redacts the default sensitive fields and the given
+// ones. Configuration can only add to the defaults, so setting it never
+// stops Authorization from being redacted.
+func extendedRedaction(headers, params []string) redaction {
+	return newRedaction(
+		append(slices.Clone(DefaultRedactedHeaders), headers...),
+		append(slices.Clone(DefaultRedactedQueryParams), params...),
+	)
+}
+
+// header returns the request headers as they may be logged, one entry per
+// header with repeated values joined.
+func (r redaction) header(h http.Header) map[string]string {
+	logged := make(map[string]string, len(h))
+	for name, values := range h {
+		if r.headers[http.CanonicalHeaderKey(name)] {
+			logged[name] = redactedValue
+			continue
+		}
+		logged[name] = strings.Join(values, ", ")
+	}
+	return logged
+}
+
+// query returns the raw query as it may be logged. A query that cannot be
+// parsed is redacted whole, since its sensitive parts cannot be found.
+func (r redaction) query(raw string) string {
+	if raw == "" {
+		return ""
+	}
+	values, err := url.ParseQuery(raw)
+	if err != nil {
+		return redactedValue
+	}
+
+	keys := make([]string, 0, len(values))
+	for key := range values {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var b strings.Builder
+	for _, key := range keys {
+		for _, value := range values[key] {
+			if b.Len() > 0 {
+				b.WriteByte('&')
+			}
+			b.WriteString(url.QueryEscape(key))
+			b.WriteByte('=')
+			if r.params[strings.ToLower(key)] {
+				b.WriteString(redactedValue)
+			} else {
+				b.WriteString(url.QueryEscape(value))
+			}
+		}
+	}
+	return b.String()
+}
+
+// requestLogMiddleware logs one line per request once it completes. It
+// replaces gin's default logger, which writes the raw query string.
+//
+// Only sampleRate, a fraction between 0 and 1, of successful requests are
+// logged. Requests answered with a 4xx or 5xx status, or that recorded a
+// gin error, are always logged. Sampling only decides whether the line is
+// written; the request is handled the same either way.
+func requestLogMiddleware(logger *slog.Logger, redact redaction, sampleRate float64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+		method := c.Request.Method
+		path := c.Request.URL.Path
+		query := redact.query(c.Request.URL.RawQuery)
+		header := redact.header(c.Request.Header)
+
+		c.Next()
+
+		failed := c.Writer.Status() >= http.StatusBadRequest || len(c.Errors) > 0
+		if !failed && sampleRate < 1 && rand.Float64() >= sampleRate {
+			return
+		}
+		logger.InfoContext(c.Request.Context(), "request",
+			"method", method,
+			"path", path,
+			"query", query,
+			"status", c.Writer.Status(),
+			"latency", time.Since(start),
+			"client_ip", c.ClientIP(),
+			"headers", header,
+		)
+	}
+}
@@ -0,0 +1,287 @@
+package httpserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/gin-gonic/gin"
+
+	"github.com/google/uuid"
+
+	"product-service/internal/auth"
+	"product-service/internal/requestid"
+)
+
+// userIDHeader identifies the caller until a real authentication scheme is in
+// place.
+const userIDHeader = "X-User-ID"
+
+// principalMiddleware attaches the caller named in the X-User-ID header to the
+// request context so the service can attribute changes to it. The caller
+// has the admin role only when the request also bears adminToken; roles are
+// never taken from the client's say-so.
+func principalMiddleware(adminToken string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if id := strings.TrimSpace(c.GetHeader(userIDHeader)); id != "" {
+			principal := auth.Principal{ID: id}
+			if hasBearerToken(c, adminToken) {
+				principal.Role = auth.RoleAdmin
+			}
+			c.Request = c.Request.WithContext(auth.WithPrincipal(c.Request.Context(), principal))
+		}
+		c.Next()
+	}
+}
+
+// maxRequestIDLength bounds client-supplied request IDs so they cannot bloat
+// logs and events.
+const maxRequestIDLength = 128
+
+// requestIDMiddleware gives every request an ID, keeping one sent by the
+// client in X-Request-ID or generating one. The ID is echoed in the response
+// and carried in the request context so logs and published events can refer
+// to it.
+func requestIDMiddleware() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := strings.TrimSpace(c.GetHeader(requestid.Header))
+		if id == "" || len(id) > maxRequestIDLength || strings.ContainsFunc(id, isControl) {
+			id = uuid.NewString()
+			c.Request.Header.Set(requestid.Header, id)
+		}
+		c.Header(requestid.Header, id)
+		c.Request = c.Request.WithContext(requestid.With(c.Request.Context(), id))
+		c.Next()
+	}
+}
+
+func isControl(r rune) bool {
+	return r < ' ' || r == 0x7f
+}
+
+// adminAuthMiddleware admits only requests bearing the admin token in an
+// "Authorization: Bearer" header.
+func adminAuthMiddleware(token string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !hasBearerToken(c, token) {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{
+				"error": "Admin token required",
+			})
+			return
+		}
+		c.Next()
+	}
+}
+
+// hasBearerToken reports whether the request carries token in an
+// "Authorization: Bearer" header. An empty token matches nothing.
+func hasBearerToken(c *gin.Context, token string) bool {
+	got := []byte(c.GetHeader("Authorization"))
+	return token != "" && subtle.ConstantTimeCompare(got, []byte("Bearer "+token)) == 1
+}
+
+// methodNotAllowed answers a request for a known path with a method it does
+// not support. gin has already set the Allow header.
+func methodNotAllowed(c *gin.Context) {
+	c.JSON(http.StatusMethodNotAllowed, gin.H{
+		"error":   "Method not allowed",
+		"details": fmt.Sprintf("%s is not supported for %s; allowed methods: %s", c.Request.Method, c.Request.URL.Path, c.Writer.Header().Get("Allow")),
+	})
+}
+
+// defaultGzipMinSize is the smallest response body worth compressing; below
+// it the gzip framing overhead outweighs the savings.
+const defaultGzipMinSize = 1024
+
+// gzipMiddleware compresses response bodies of at least minSize bytes when the
+// client advertises gzip support. Requests whose path starts with one of
+// skipPaths are passed through untouched.
+func gzipMiddleware(minSize int, skipPaths ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !acceptsGzip(c.Request) || hasPrefix(c.Request.URL.Path, skipPaths) {
+			c.Next()
+			return
+		}
+
+		w := &gzipWriter{ResponseWriter: c.Writer, minSize: minSize}
+		c.Writer = w
+		defer func() {
+			c.Writer = w.ResponseWriter
+		}()
+
+		c.Next()
+		w.finish()
+	}
+}
+
+func acceptsGzip(r *http.Request) bool {
+	for _, part := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		encoding, _, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if strings.EqualFold(encoding, "gzip") {
+			return true
+		}
+	}
+	return false
+}
+
+func hasPrefix(path string, prefixes []string) bool {
+	for _, prefix := range prefixes {
+		if strings.HasPrefix(path, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// gzipWriter buffers the response so the compression decision can be made
+// once the full body size is known. A handler that flushes mid-response opts
+// out of buffering and is streamed uncompressed.
+type gzipWriter struct {
+	gin.ResponseWriter
+	minSize     int
+	buf         bytes.Buffer
+	passthrough bool
+}
+
+func (w *gzipWriter) Write(data []byte) (int, error) {
+	if w.passthrough {
+		return w.ResponseWriter.Write(data)
+	}
+	return w.buf.Write(data)
+}
+
+func (w *gzipWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *gzipWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+func (w *gzipWriter) Flush() {
+	if !w.passthrough {
+		w.passthrough = true
+		w.writeBuffered()
+	}
+	w.ResponseWriter.Flush()
+}
+
+func (w *gzipWriter) finish() {
+	if w.passthrough {
+		return
+	}
+
+	if w.buf.Len() < w.minSize || w.Header().Get("Content-Encoding") != "" {
+		w.writeBuffered()
+		return
+	}
+
+	header := w.Header()
+	header.Set("Content-Encoding", "gzip")
+	header.Add("Vary", "Accept-Encoding")
+	header.Del("Content-Length")
+
+	gz := gzip.NewWriter(w.ResponseWriter)
+	_, _ = gz.Write(w.buf.Bytes())
+	_ = gz.Close()
+}
+
+func (w *gzipWriter) writeBuffered() {
+	if w.buf.Len() == 0 {
+		w.ResponseWriter.WriteHeaderNow()
+		return
+	}
+	_, _ = w.ResponseWriter.Write(w.buf.Bytes())
+	w.buf.Reset()
+}
+
+// defaultRequestTimeout is used when REQUEST_TIMEOUT is not set.
+const defaultRequestTimeout = 10 * time.Second
+
+// timeoutMiddleware gives each request a deadline. Handlers and the
+// repository observe it through the request context; once it passes, any
+// response the handler still tries to write is replaced with a 504. A
+// handler that ignores its context is not interrupted. Zero disables the
+// deadline, as does matching one of skipRoutes, for streaming routes that
+// manage their own.
+func timeoutMiddleware(timeout time.Duration, skipRoutes ...string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if timeout <= 0 || slices.Contains(skipRoutes, c.FullPath()) {
+			c.Next()
+			return
+		}
+
+		ctx, cancel := context.WithTimeout(c.Request.Context(), timeout)
+		defer cancel()
+		c.Request = c.Request.WithContext(ctx)
+
+		w := &timeoutWriter{ResponseWriter: c.Writer, ctx: ctx}
+		c.Writer = w
+		c.Next()
+		c.Writer = w.ResponseWriter
+
+		if w.timedOut && !w.ResponseWriter.Written() {
+			c.AbortWithStatusJSON(http.StatusGatewayTimeout, gin.H{
+				"error": "Request timed out",
+			})
+		}
+	}
+}
+
+// timeoutWriter drops writes that start after the request deadline so the
+// middleware can answer with a 504 instead. A response already under way
+// when the deadline passes is left to finish.
+type timeoutWriter struct {
+	gin.ResponseWriter
+	ctx      context.Context
+	timedOut bool
+}
+
+func (w *timeoutWriter) discard() bool {
+	if w.ResponseWriter.Written() {
+		return false
+	}
+	if errors.Is(w.ctx.Err(), context.DeadlineExceeded) {
+		w.timedOut = true
+	}
+	return w.timedOut
+}
+
+func (w *timeoutWriter) WriteHeader(code int) {
+	if w.discard() {
+		return
+	}
+	w.ResponseWriter.WriteHeader(code)
+}
+
+func (w *timeoutWriter) WriteHeaderNow() {
+	if w.discard() {
+		return
+	}
+	w.ResponseWriter.WriteHeaderNow()
+}
+
+func (w *timeoutWriter) Write(data []byte) (int, error) {
+	if w.discard() {
+		return len(data), nil
+	}
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *timeoutWriter) WriteString(s string) (int, error) {
+	return w.Write([]byte(s))
+}
+
+// Unwrap lets http.ResponseController reach the connection.
+func (w *timeoutWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
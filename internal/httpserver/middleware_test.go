@@ -0,0 +1,477 @@
+package httpserver
+
+import (
+	"bytes"
+	"compress/gzip"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/auth"
+	"product-service/internal/handlers"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/requestid"
+	"product-service/internal/service"
+)
+
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+func newTestServer(t *testing.T, products int) *Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+
+	repo := repository.NewMemoryProductRepository()
+	for i := 0; i < products; i++ {
+		product := models.NewProduct(models.CreateProductRequest{
+			Name:        fmt.Sprintf("Product %d", i),
+			Description: "A product used to pad out the listing response",
+			Price:       9.99,
+			Category:    "electronics",
+			SKU:         fmt.Sprintf("SKU-%04d", i),
+			Stock:       floatPtr(10),
+		}, models.SystemClock)
+		require.NoError(t, repo.Create(context.Background(), product))
+	}
+
+	return newServer(handlers.NewProductHandler(service.NewProductService(repo)), "", defaultRequestTimeout, DefaultCachePolicy,
+		requestLogMiddleware(slog.New(slog.DiscardHandler), newRedaction(DefaultRedactedHeaders, DefaultRedactedQueryParams), 1))
+}
+
+func TestGzipMiddleware_CompressesLargeListResponse(t *testing.T) {
+	server := newTestServer(t, 50)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/products", nil)
+	req.Header.Set("Accept-Encoding", "gzip, deflate")
+
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "gzip", w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Header().Values("Vary"), "Accept-Encoding")
+
+	gz, err := gzip.NewReader(w.Body)
+	require.NoError(t, err)
+	body, err := io.ReadAll(gz)
+	require.NoError(t, err)
+
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(body, &response))
+	assert.Equal(t, float64(50), response["count"])
+}
+
+func TestGzipMiddleware_SkipsSmallResponse(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/products", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.Contains(t, w.Body.String(), `"count":0`)
+}
+
+func TestGzipMiddleware_RequiresAcceptEncoding(t *testing.T) {
+	server := newTestServer(t, 50)
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/products", nil)
+
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+}
+
+func TestGzipMiddleware_SkipsHealthEndpoint(t *testing.T) {
+	router := gin.New()
+	router.Use(gzipMiddleware(0, "/api/v1/health"))
+	router.GET("/api/v1/health", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"status": "healthy"})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/health", nil)
+	req.Header.Set("Accept-Encoding", "gzip")
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Empty(t, w.Header().Get("Content-Encoding"))
+	assert.JSONEq(t, `{"status":"healthy"}`, w.Body.String())
+}
+
+func TestPrincipalMiddleware_AttributesCreate(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	body := `{"name":"Mouse","price":24.99,"category":"electronics","sku":"ELEC-0001","stock":5}`
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("POST", "/api/v1/products", strings.NewReader(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("X-User-ID", "user-42")
+
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	var product models.Product
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &product))
+	assert.Equal(t, "user-42", product.CreatedBy)
+	assert.Equal(t, "user-42", product.UpdatedBy)
+}
+
+func TestPrincipalMiddleware_AdminRoleRequiresToken(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(principalMiddleware("s3cret"))
+	router.GET("/whoami", func(c *gin.Context) { c.String(http.StatusOK, auth.Role(c.Request.Context())) })
+
+	for _, tc := range []struct {
+		headers map[string]string
+		want    string
+	}{
+		{headers: map[string]string{"X-User-ID": "user-42", "X-User-Role": "admin"}, want: ""},
+		{headers: map[string]string{"X-User-ID": "user-42", "Authorization": "Bearer wrong"}, want: ""},
+		{headers: map[string]string{"X-User-ID": "user-42", "Authorization": "Bearer s3cret"}, want: auth.RoleAdmin},
+		{headers: map[string]string{"Authorization": "Bearer s3cret"}, want: ""},
+	} {
+		req := httptest.NewRequest("GET", "/whoami", nil)
+		for name, value := range tc.headers {
+			req.Header.Set(name, value)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.want, w.Body.String(), tc.headers)
+	}
+}
+
+func newTimeoutRouter(timeout time.Duration, handler gin.HandlerFunc) *gin.Engine {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(timeoutMiddleware(timeout))
+	router.GET("/work", handler)
+	return router
+}
+
+func TestTimeoutMiddleware_SlowHandler(t *testing.T) {
+	router := newTimeoutRouter(20*time.Millisecond, func(c *gin.Context) {
+		// Behaves like a handler whose repository call is cancelled by
+		// the deadline and reports the failure.
+		<-c.Request.Context().Done()
+		c.JSON(http.StatusInternalServerError, gin.H{"error": c.Request.Context().Err().Error()})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/work", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusGatewayTimeout, w.Code)
+	var body map[string]string
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+	assert.Equal(t, "Request timed out", body["error"])
+}
+
+func TestTimeoutMiddleware_FastHandler(t *testing.T) {
+	router := newTimeoutRouter(time.Second, func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": hasDeadline})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/work", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"deadline":true}`, w.Body.String())
+}
+
+func TestTimeoutMiddleware_SkipsStreamingRoutes(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(timeoutMiddleware(time.Second, "/stream"))
+	report := func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": hasDeadline})
+	}
+	router.GET("/stream", report)
+	router.GET("/work", report)
+
+	for path, want := range map[string]string{"/stream": `{"deadline":false}`, "/work": `{"deadline":true}`} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("GET", path, nil)
+		router.ServeHTTP(w, req)
+
+		assert.JSONEq(t, want, w.Body.String(), path)
+	}
+}
+
+func TestMiddleware_WriteDeadlineReachesConnection(t *testing.T) {
+	server := newTestServer(t, 0)
+	server.router.GET("/api/v1/products/deadline", func(c *gin.Context) {
+		err := http.NewResponseController(c.Writer).SetWriteDeadline(time.Now().Add(time.Minute))
+		c.JSON(http.StatusOK, gin.H{"supported": err == nil})
+	})
+	ts := httptest.NewServer(server.router)
+	defer ts.Close()
+
+	resp, err := http.Get(ts.URL + "/api/v1/products/deadline")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"supported":true}`, string(body))
+}
+
+func TestTimeoutMiddleware_Disabled(t *testing.T) {
+	router := newTimeoutRouter(0, func(c *gin.Context) {
+		_, hasDeadline := c.Request.Context().Deadline()
+		c.JSON(http.StatusOK, gin.H{"deadline": hasDeadline})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/work", nil)
+	router.ServeHTTP(w, req)
+
+	assert.JSONEq(t, `{"deadline":false}`, w.Body.String())
+}
+
+func TestRequestLogMiddleware_RedactsSensitiveValues(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	router := gin.New()
+	router.Use(requestLogMiddleware(logger, newRedaction([]string{"authorization", "X-API-Key"}, []string{"token"}), 1))
+
+	var seen string
+	router.GET("/items", func(c *gin.Context) {
+		seen = c.GetHeader("Authorization")
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("GET", "/items?token=query-secret&page=2", nil)
+	req.Header.Set("Authorization", "Bearer header-secret")
+	req.Header.Set("X-Api-Key", "key-secret")
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNoContent, w.Code)
+	assert.Equal(t, "Bearer header-secret", seen)
+
+	output := logs.String()
+	for _, secret := range []string{"header-secret", "key-secret", "query-secret"} {
+		assert.NotContains(t, output, secret)
+	}
+	assert.Contains(t, output, `"query":"page=2&token=***"`)
+	assert.Contains(t, output, `"Authorization":"***"`)
+	assert.Contains(t, output, `"X-Request-Id":"req-1"`)
+}
+
+func TestRequestLogMiddleware_AlwaysLogsErrors(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	var logs bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&logs, nil))
+	router := gin.New()
+	// A zero sample rate drops every successful request.
+	router.Use(requestLogMiddleware(logger, newRedaction(nil, nil), 0))
+	router.GET("/ok", func(c *gin.Context) { c.Status(http.StatusOK) })
+	router.GET("/missing", func(c *gin.Context) { c.Status(http.StatusNotFound) })
+	router.GET("/broken", func(c *gin.Context) { c.Status(http.StatusInternalServerError) })
+
+	for _, path := range []string{"/ok", "/missing", "/ok", "/broken"} {
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("GET", path, nil))
+	}
+
+	output := logs.String()
+	assert.Equal(t, 2, strings.Count(output, `"msg":"request"`))
+	assert.Contains(t, output, `"path":"/missing"`)
+	assert.Contains(t, output, `"path":"/broken"`)
+	assert.NotContains(t, output, `"path":"/ok"`)
+}
+
+func TestRedaction_UnparseableQuery(t *testing.T) {
+	redact := newRedaction(nil, []string{"token"})
+
+	assert.Equal(t, redactedValue, redact.query("token=%zz"))
+	assert.Empty(t, redact.query(""))
+}
+
+func TestExtendedRedaction_KeepsDefaults(t *testing.T) {
+	redact := extendedRedaction([]string{"X-Session"}, []string{"sig"})
+
+	logged := redact.header(http.Header{
+		"Authorization": {"Bearer secret"},
+		"X-Session":     {"session-secret"},
+		"Accept":        {"*/*"},
+	})
+	assert.Equal(t, map[string]string{"Authorization": "***", "X-Session": "***", "Accept": "*/*"}, logged)
+	assert.Equal(t, "access_token=***&sig=***", redact.query("access_token=a&sig=b"))
+}
+
+func TestRequestIDMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(requestIDMiddleware())
+	var seen string
+	router.GET("/items", func(c *gin.Context) {
+		seen = requestid.FromContext(c.Request.Context())
+		c.Status(http.StatusNoContent)
+	})
+
+	req := httptest.NewRequest("GET", "/items", nil)
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "req-1", seen)
+	assert.Equal(t, "req-1", w.Header().Get("X-Request-ID"))
+
+	// Missing or unusable IDs are replaced with a generated one.
+	for _, header := range []string{"", "bad\nid", strings.Repeat("x", maxRequestIDLength+1)} {
+		req := httptest.NewRequest("GET", "/items", nil)
+		req.Header.Set("X-Request-ID", header)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.NotEmpty(t, seen)
+		assert.NotEqual(t, header, seen)
+		assert.Equal(t, seen, w.Header().Get("X-Request-ID"))
+	}
+}
+
+func TestAdminAuthMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(adminAuthMiddleware("s3cret"))
+	router.POST("/admin", func(c *gin.Context) { c.Status(http.StatusNoContent) })
+
+	for header, want := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"s3cret":        http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusNoContent,
+	} {
+		req := httptest.NewRequest("POST", "/admin", nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		w := httptest.NewRecorder()
+
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, want, w.Code, header)
+	}
+}
+
+func TestAdminRoutes_ProductListingRequiresToken(t *testing.T) {
+	server := newTestServer(t, 2)
+	server.setupAdminRoutes(handlers.NewAdminHandler(nil, nil), "s3cret")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/admin/products", nil)
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/admin/products", nil)
+	req.Header.Set("Authorization", "Bearer s3cret")
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	var response map[string]interface{}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &response))
+	assert.Equal(t, float64(2), response["count"])
+}
+
+func TestCacheControlMiddleware(t *testing.T) {
+	server := newTestServer(t, 1)
+	server.setupAdminRoutes(handlers.NewAdminHandler(nil, nil), "s3cret")
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/products", nil)
+	server.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "public, max-age=30", w.Header().Get("Cache-Control"))
+
+	var list struct {
+		Products []models.Product `json:"products"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &list))
+	require.Len(t, list.Products, 1)
+	id := list.Products[0].ID
+
+	// Revalidating with the listing's ETag keeps the caching directives.
+	etag := w.Header().Get("ETag")
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("GET", "/api/v1/products", nil)
+	req.Header.Set("If-None-Match", etag)
+	server.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotModified, w.Code)
+	assert.Equal(t, "public, max-age=30", w.Header().Get("Cache-Control"))
+
+	for _, tt := range []struct {
+		method, path, body string
+		headers            map[string]string
+		want               string
+	}{
+		{method: "GET", path: "/api/v1/products/" + id, want: "public, max-age=300"},
+		{method: "GET", path: "/api/v1/products/" + id, headers: map[string]string{"X-User-ID": "u1"}, want: "private, max-age=300"},
+		{method: "GET", path: "/api/v1/products/missing", want: "no-store"},
+		{method: "PUT", path: "/api/v1/products/" + id, body: `{"price":12}`, want: "no-store"},
+		{method: "GET", path: "/api/v1/admin/products", headers: map[string]string{"Authorization": "Bearer s3cret"}, want: "no-store"},
+		{method: "GET", path: "/api/v1/health", want: "no-store"},
+		{method: "GET", path: "/api/v1/products/search?q=product", want: "public, max-age=30"},
+		{method: "GET", path: "/api/v1/products/changes?since=2024-01-01T00:00:00Z", want: "no-store"},
+		{method: "GET", path: "/api/v1/products/export", want: "no-store"},
+		{method: "GET", path: "/api/v1/products/stats/valuation", want: "no-store"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest(tt.method, tt.path, strings.NewReader(tt.body))
+		req.Header.Set("Content-Type", "application/json")
+		for key, value := range tt.headers {
+			req.Header.Set(key, value)
+		}
+
+		server.ServeHTTP(w, req)
+
+		assert.Equal(t, tt.want, w.Header().Get("Cache-Control"), "%s %s", tt.method, tt.path)
+	}
+}
+
+func TestCacheControlMiddleware_ZeroMaxAgeRevalidates(t *testing.T) {
+	router := gin.New()
+	router.Use(cacheControlMiddleware(CachePolicy{ProductMaxAge: time.Minute}))
+	router.GET("/api/v1/products", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{})
+	})
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/products", nil)
+	router.ServeHTTP(w, req)
+
+	assert.Equal(t, "public, no-cache", w.Header().Get("Cache-Control"))
+}
@@ -10,7 +10,7 @@ func Routes(logger *slog.Logger) http.Handler {
 	mux := http.NewServeMux()
 
 	//health endpoint
-	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r http.Request){
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
 		w.Header().Set("Content-Type", "text/plain")
 		w.WriteHeader(http.StatusOK)
 		_, _ = w.Write([]byte("ok"))
@@ -22,12 +22,12 @@ func Routes(logger *slog.Logger) http.Handler {
 
 func loggingMiddleware(l *slog.Logger, next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-			start := time.Now()
-			next.ServeHTTP(w, r)
-			l.Info("http_request",
-				"method", r.Method,
-				"path", r.URL.Path,
-				"duration_ms", time.Since(start).Milliseconds(),
-			)
+		start := time.Now()
+		next.ServeHTTP(w, r)
+		l.Info("http_request",
+			"method", r.Method,
+			"path", r.URL.Path,
+			"duration_ms", time.Since(start).Milliseconds(),
+		)
 	})
-}
\ No newline at end of file
+}
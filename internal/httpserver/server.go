@@ -1,60 +1,375 @@
 package httpserver
 
 import (
+	"context"
+	"fmt"
 	"log"
+	"net/http"
+	"sync"
+	"time"
 
 	"github.com/gin-gonic/gin"
 
+	"product-service/internal/config"
 	"product-service/internal/database"
+	"product-service/internal/features"
 	"product-service/internal/handlers"
+	"product-service/internal/models"
 	"product-service/internal/repository"
 	"product-service/internal/service"
+	"product-service/internal/storage"
+	"product-service/pkg/logging"
 )
 
+// shutdownTimeout bounds how long Run waits for in-flight requests to finish.
+const shutdownTimeout = 15 * time.Second
+
+// exportRoute streams the whole catalog, so it runs without the request
+// timeout.
+const exportRoute = "/api/v1/products/export"
+
+// Limits are the HTTP server's connection timeouts; see http.Server for
+// each field. A zero timeout is disabled.
+type Limits struct {
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	WriteTimeout      time.Duration
+	IdleTimeout       time.Duration
+	KeepAlive         bool
+}
+
+// DefaultLimits bound slow clients and idle connections while leaving
+// requests enough time to finish before their REQUEST_TIMEOUT response.
+var DefaultLimits = Limits{
+	ReadHeaderTimeout: 5 * time.Second,
+	ReadTimeout:       30 * time.Second,
+	WriteTimeout:      30 * time.Second,
+	IdleTimeout:       2 * time.Minute,
+	KeepAlive:         true,
+}
+
 type Server struct {
 	router  *gin.Engine
 	handler *handlers.ProductHandler
+	limits  Limits
+
+	// background jobs run for the lifetime of Run.
+	background []func(ctx context.Context)
+
+	// shutdown hooks run once the HTTP server has stopped accepting
+	// requests and in-flight ones have finished.
+	shutdown []func(ctx context.Context) error
 }
 
 func NewServer() (*Server, error) {
+	cfg, err := config.FromEnv()
+	if err != nil {
+		return nil, err
+	}
+
 	db, err := database.NewDynamoDBClient()
 	if err != nil {
 		return nil, err
 	}
+	db.Client = database.LimitConcurrency(db.Client, cfg.DynamoMaxConcurrency, cfg.DynamoFailFast)
+
+	totalCount, ok := handlers.ParseTotalCount(cfg.PaginationTotalCount)
+	if !ok {
+		return nil, fmt.Errorf("invalid PAGINATION_TOTAL_COUNT %q", cfg.PaginationTotalCount)
+	}
+
+	repo := repository.NewProductRepository(db,
+		repository.WithScanMaxItems(cfg.ScanMaxItems),
+		repository.WithScanSegments(cfg.ScanSegments),
+		repository.WithExactCounts(totalCount == handlers.TotalCountExact),
+		repository.WithScanCapacityBudget(cfg.ScanCapacityBudget),
+	)
+	var cache *repository.CachingRepository
+	if cfg.CacheTTL > 0 {
+		cache = repository.NewCachingRepository(repo, cfg.CacheTTL)
+		repo = cache
+	}
+	sanitizeMode, ok := service.ParseSanitizeMode(cfg.SanitizeMode)
+	if !ok {
+		return nil, fmt.Errorf("invalid SANITIZE_MODE %q", cfg.SanitizeMode)
+	}
+	defaultSort, err := service.ParseSort(cfg.DefaultSort)
+	if err != nil {
+		return nil, fmt.Errorf("invalid DEFAULT_SORT: %w", err)
+	}
+	idScheme, ok := models.ParseIDScheme(cfg.IDScheme)
+	if !ok {
+		return nil, fmt.Errorf("invalid ID_SCHEME %q", cfg.IDScheme)
+	}
+	taxonomy, err := models.NewCategoryTaxonomy(cfg.CategoryParents)
+	if err != nil {
+		return nil, fmt.Errorf("invalid CATEGORY_PARENTS: %w", err)
+	}
+	var skuSequences repository.SequenceRepository
+	if cfg.SKUSequenceTable != "" {
+		skuSequences = repository.NewSequenceRepository(db, cfg.SKUSequenceTable)
+	}
+	var images storage.ImageStore
+	if cfg.ImageBucket != "" {
+		s3, err := storage.NewS3API()
+		if err != nil {
+			return nil, err
+		}
+		images = storage.NewS3ImageStore(cfg.ImageBucket, cfg.ImageURLExpiry, s3)
+	}
+
+	var events service.EventPublisher
+	var batcher *service.BatchingPublisher
+	if cfg.PublishEvents {
+		publisher := service.NewLogPublisher(logging.New())
+		events = publisher
+		if cfg.EventBatchWindow > 0 {
+			batcher = service.NewBatchingPublisher(publisher, cfg.EventBatchWindow, logging.New())
+			events = batcher
+		}
+	}
+
+	var imageChecker *service.ImageChecker
+	if cfg.CheckImageURLs {
+		imageChecker = service.NewImageChecker(repo, cfg.ImageCheckRate, logging.New())
+	}
+
+	svc := service.NewProductService(repo,
+		service.WithLogger(logging.New()),
+		service.WithEventPublisher(events),
+		service.WithMaxStock(cfg.MaxStock),
+		service.WithDefaultStock(cfg.DefaultStock),
+		service.WithMinNameLength(cfg.MinNameLen),
+		service.WithMaxDescriptionLength(cfg.MaxDescriptionLen),
+		service.WithTagLimits(cfg.MaxTags, cfg.MaxTagLen),
+		service.WithCategoryMinPrice(cfg.CategoryMinPrice),
+		service.WithCategorySKUPrefix(cfg.CategorySKUPrefix),
+		service.WithSKUSequences(skuSequences),
+		service.WithCategoryTaxonomy(taxonomy),
+		service.WithCurrencyRules(models.NewCurrencyRules(cfg.DefaultCurrency, cfg.CurrencyDecimals)),
+		service.WithRegionCurrencies(cfg.RegionCurrencies, cfg.DefaultRegion),
+		service.WithMaxBatchSize(cfg.MaxBatchSize),
+		service.WithSanitizeMode(sanitizeMode),
+		service.WithDefaultSort(defaultSort),
+		service.WithIDScheme(idScheme),
+		service.WithSlugRegeneration(cfg.RegenerateSlug),
+		service.WithAutoDeactivateOutOfStock(cfg.AutoDeactivateOOS),
+		service.WithHardDeleteDisabled(cfg.DisableHardDelete),
+		service.WithImageStore(images),
+		service.WithImageChecker(imageChecker),
+	)
+
+	naming, ok := handlers.ParseFieldNaming(cfg.JSONFieldNaming)
+	if !ok {
+		return nil, fmt.Errorf("invalid JSON_FIELD_NAMING %q", cfg.JSONFieldNaming)
+	}
+	handler := handlers.NewProductHandler(svc,
+		handlers.WithFieldNaming(naming),
+		handlers.WithOmitEmpty(cfg.JSONOmitEmpty),
+		handlers.WithStrictJSON(cfg.StrictJSON),
+		handlers.WithPaginationHeaders(cfg.PaginationHeaders),
+		handlers.WithTotalCount(totalCount),
+		handlers.WithLowStockThreshold(cfg.LowStockThreshold),
+		handlers.WithImageStore(images),
+		handlers.WithMaxImageSize(cfg.MaxImageSize),
+		handlers.WithPublicBaseURL(cfg.PublicBaseURL),
+		handlers.WithFieldAccess(cfg.FieldAccess),
+		handlers.WithFeatureFlags(features.Static(cfg.FeatureFlags)),
+		handlers.WithTableStatus(db),
+	)
+
+	cachePolicy := CachePolicy{ListingMaxAge: cfg.ListingCacheMaxAge, ProductMaxAge: cfg.ProductCacheMaxAge}
+	server := newServer(handler, cfg.AdminToken, cfg.RequestTimeout, cachePolicy,
+		requestLogMiddleware(logging.New(), extendedRedaction(cfg.LogRedactHeaders, cfg.LogRedactQueryParams), cfg.LogSampleRate))
+	server.limits = Limits{
+		ReadHeaderTimeout: cfg.ReadHeaderTimeout,
+		ReadTimeout:       cfg.ReadTimeout,
+		WriteTimeout:      cfg.WriteTimeout,
+		IdleTimeout:       cfg.IdleTimeout,
+		KeepAlive:         cfg.KeepAlive,
+	}
+
+	// Admin routes exist only when there is a token to guard them.
+	if cfg.AdminToken != "" {
+		var adminCache handlers.Cache
+		if cache != nil {
+			adminCache = cache
+		}
+		server.setupAdminRoutes(handlers.NewAdminHandler(adminCache, svc), cfg.AdminToken)
+	}
+
+	if batcher != nil {
+		server.shutdown = append(server.shutdown, batcher.Flush)
+	}
+
+	if imageChecker != nil {
+		server.background = append(server.background, imageChecker.Run)
+	}
+	if cfg.ArchiveInactiveAfter > 0 {
+		archiver := service.NewArchiver(repo, cfg.ArchiveInterval, cfg.ArchiveInactiveAfter)
+		server.background = append(server.background, archiver.Run)
+	}
+	if cfg.ReconcileInterval > 0 {
+		reconciler := service.NewReconciler(svc, repo, cfg.ReconcileInterval)
+		server.background = append(server.background, reconciler.Run)
+	}
+	if cfg.ReservationSweepInterval > 0 {
+		sweeper := service.NewReservationSweeper(repo, cfg.ReservationSweepInterval, cfg.AutoDeactivateOOS, cfg.MaxStock)
+		server.background = append(server.background, sweeper.Run)
+	}
 
-	repo := repository.NewProductRepository(db)
-	svc := service.NewProductService(repo)
-	handler := handlers.NewProductHandler(svc)
+	return server, nil
+}
 
-	router := gin.Default()
+func newServer(handler *handlers.ProductHandler, adminToken string, requestTimeout time.Duration, cachePolicy CachePolicy, requestLog gin.HandlerFunc) *Server {
+	router := gin.New()
+	// Known paths requested with an unsupported method get a 405 with an
+	// Allow header, which gin fills in, rather than a 404.
+	router.HandleMethodNotAllowed = true
+	router.NoMethod(methodNotAllowed)
+	router.Use(requestIDMiddleware(), requestLog, gin.Recovery())
+	router.Use(principalMiddleware(adminToken))
+	router.Use(cacheControlMiddleware(cachePolicy))
+	router.Use(gzipMiddleware(defaultGzipMinSize, "/api/v1/health", "/healthz", "/metrics"))
+	router.Use(timeoutMiddleware(requestTimeout, exportRoute))
 
 	server := &Server{
 		router:  router,
 		handler: handler,
+		limits:  DefaultLimits,
 	}
 
 	server.setupRoutes()
-	return server, nil
+	return server
 }
 
 func (s *Server) setupRoutes() {
 	api := s.router.Group("/api/v1")
-	
+
 	api.GET("/health", s.handler.HealthCheck)
-	
+	api.GET("/health/ready", s.handler.ReadinessCheck)
+	api.GET("/errors", s.handler.ListErrorCodes)
+
 	products := api.Group("/products")
 	{
 		products.POST("", s.handler.CreateProduct)
+		products.POST("/drafts", s.handler.CreateDraft)
+		products.POST("/validate", s.handler.ValidateProducts)
 		products.GET("", s.handler.GetAllProducts)
 		products.GET("/category", s.handler.GetProductsByCategory)
+		products.POST("/category/rename", s.handler.RenameCategory)
+		products.GET("/filter", s.handler.FilterProducts)
+		products.GET("/trending", s.handler.GetTrendingProducts)
+		products.GET("/featured", s.handler.GetFeaturedProducts)
+		products.PUT("/featured/order", s.handler.ReorderFeatured)
+		products.GET("/changes", s.handler.GetProductChanges)
+		products.GET("/suggest", s.handler.SuggestProducts)
+		products.GET("/search", s.handler.SearchProducts)
+		products.GET("/stats/valuation", s.handler.GetInventoryValuation)
+		products.GET("/export", s.handler.ExportProducts)
+		products.POST("/batch-get", s.handler.BatchGetProducts)
+		products.POST("/batch-get-by-sku", s.handler.BatchGetProductsBySKU)
+		products.POST("/stock/bulk", s.handler.BulkSetStock)
+		products.PUT("/bulk-upsert", s.handler.BulkUpsertProducts)
+		products.POST("/bulk-status", s.handler.BulkSetStatus)
+		products.HEAD("/sku/:sku", s.handler.ProductExistsBySKU)
+		products.GET("/slug/:slug", s.handler.GetProductBySlug)
+		products.GET("/lookup", s.handler.LookupProduct)
 		products.GET("/:id", s.handler.GetProduct)
+		products.GET("/:id/shipping", s.handler.GetShipping)
+		products.GET("/:id/bundle", s.handler.GetBundle)
+		products.PUT("/:id/featured", s.handler.SetFeatured)
+		products.DELETE("/:id/featured", s.handler.UnsetFeatured)
 		products.PUT("/:id", s.handler.UpdateProduct)
+		products.PATCH("/:id", s.handler.PatchProduct)
 		products.DELETE("/:id", s.handler.DeleteProduct)
+		products.POST("/:id/ratings", s.handler.RateProduct)
+		products.POST("/:id/restore", s.handler.RestoreProduct)
+		products.PUT("/:id/translations/:locale", s.handler.SetTranslation)
+		products.DELETE("/:id/translations/:locale", s.handler.RemoveTranslation)
+		products.POST("/:id/images/upload", s.handler.UploadImage)
+		products.POST("/:id/reconcile", s.handler.ReconcileProduct)
+		products.POST("/:id/publish", s.handler.PublishProduct)
+		products.POST("/:id/stock/clear", s.handler.ClearStock)
+		products.POST("/:id/reservations", s.handler.ReserveStock)
+		products.DELETE("/:id/reservations/:reservation_id", s.handler.ReleaseReservation)
+	}
+}
+
+func (s *Server) setupAdminRoutes(admin *handlers.AdminHandler, token string) {
+	group := s.router.Group("/api/v1/admin", adminAuthMiddleware(token))
+	{
+		group.GET("/products", s.handler.AdminListProducts)
+		group.GET("/products/drafts", s.handler.AdminListDrafts)
+		group.POST("/reindex", admin.Reindex)
+		if admin.HasCache() {
+			group.POST("/cache/flush", admin.FlushCache)
+			group.DELETE("/cache/:id", admin.EvictCache)
+		}
 	}
 }
 
-func (s *Server) Run(addr string) error {
-	log.Printf("Starting server on %s", addr)
-	return s.router.Run(addr)
+// ServeHTTP lets the server be exercised directly with httptest.
+func (s *Server) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	s.router.ServeHTTP(w, r)
+}
+
+// httpServer returns the http.Server Run listens with, configured with the
+// server's limits.
+func (s *Server) httpServer(addr string) *http.Server {
+	srv := &http.Server{
+		Addr:              addr,
+		Handler:           s.router,
+		ReadHeaderTimeout: s.limits.ReadHeaderTimeout,
+		ReadTimeout:       s.limits.ReadTimeout,
+		WriteTimeout:      s.limits.WriteTimeout,
+		IdleTimeout:       s.limits.IdleTimeout,
+	}
+	srv.SetKeepAlivesEnabled(s.limits.KeepAlive)
+	return srv
 }
 
+// Run serves HTTP on addr and runs the background jobs until ctx is
+// cancelled, then shuts both down gracefully.
+func (s *Server) Run(ctx context.Context, addr string) error {
+	ctx, cancel := context.WithCancel(ctx)
+
+	var wg sync.WaitGroup
+	for _, job := range s.background {
+		wg.Add(1)
+		go func(job func(context.Context)) {
+			defer wg.Done()
+			job(ctx)
+		}(job)
+	}
+	defer func() {
+		cancel()
+		wg.Wait()
+	}()
+
+	srv := s.httpServer(addr)
+
+	errCh := make(chan error, 1)
+	go func() {
+		log.Printf("Starting server on %s", addr)
+		errCh <- srv.ListenAndServe()
+	}()
+
+	select {
+	case err := <-errCh:
+		return err
+	case <-ctx.Done():
+	}
+
+	log.Printf("Shutting down server")
+	shutdownCtx, shutdownCancel := context.WithTimeout(context.Background(), shutdownTimeout)
+	defer shutdownCancel()
+
+	err := srv.Shutdown(shutdownCtx)
+	for _, hook := range s.shutdown {
+		if hookErr := hook(shutdownCtx); hookErr != nil {
+			log.Printf("Shutdown hook failed: %v", hookErr)
+		}
+	}
+	return err
+}
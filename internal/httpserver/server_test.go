@@ -0,0 +1,121 @@
+package httpserver
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServer_RunStopsBackgroundJobsOnShutdown(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	stopped := make(chan struct{})
+	server.background = append(server.background, func(ctx context.Context) {
+		<-ctx.Done()
+		close(stopped)
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, "127.0.0.1:0")
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+
+	select {
+	case <-stopped:
+	default:
+		t.Fatal("background job was not stopped")
+	}
+}
+
+func TestServer_RunCallsShutdownHooks(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	called := make(chan struct{})
+	server.shutdown = append(server.shutdown, func(ctx context.Context) error {
+		close(called)
+		return nil
+	})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.Run(ctx, "127.0.0.1:0")
+	}()
+
+	cancel()
+
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(5 * time.Second):
+		t.Fatal("Run did not return after context cancellation")
+	}
+
+	select {
+	case <-called:
+	default:
+		t.Fatal("shutdown hook was not called")
+	}
+}
+
+func TestServer_MethodNotAllowed(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	for path, allowed := range map[string][]string{
+		"/api/v1/products":         {"GET", "POST"},
+		"/api/v1/products/test-id": {"GET", "PUT", "PATCH", "DELETE"},
+	} {
+		w := httptest.NewRecorder()
+		req, _ := http.NewRequest("OPTIONS", path, nil)
+
+		server.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusMethodNotAllowed, w.Code, path)
+		assert.ElementsMatch(t, allowed, strings.Split(w.Header().Get("Allow"), ", "), path)
+		assert.Contains(t, w.Body.String(), `"error":"Method not allowed"`, path)
+		assert.NotEmpty(t, w.Header().Get("X-Request-ID"), path)
+	}
+
+	w := httptest.NewRecorder()
+	req, _ := http.NewRequest("GET", "/api/v1/nothing-here", nil)
+
+	server.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestServer_HTTPServerLimits(t *testing.T) {
+	server := newTestServer(t, 0)
+
+	srv := server.httpServer("127.0.0.1:0")
+
+	assert.Equal(t, "127.0.0.1:0", srv.Addr)
+	assert.Equal(t, DefaultLimits.ReadHeaderTimeout, srv.ReadHeaderTimeout)
+	assert.Equal(t, DefaultLimits.ReadTimeout, srv.ReadTimeout)
+	assert.Equal(t, DefaultLimits.WriteTimeout, srv.WriteTimeout)
+	assert.Equal(t, DefaultLimits.IdleTimeout, srv.IdleTimeout)
+	assert.NotZero(t, srv.ReadHeaderTimeout)
+
+	server.limits = Limits{ReadHeaderTimeout: time.Second, ReadTimeout: 2 * time.Second, WriteTimeout: 3 * time.Second, IdleTimeout: 4 * time.Second}
+	srv = server.httpServer("127.0.0.1:0")
+
+	assert.Equal(t, time.Second, srv.ReadHeaderTimeout)
+	assert.Equal(t, 2*time.Second, srv.ReadTimeout)
+	assert.Equal(t, 3*time.Second, srv.WriteTimeout)
+	assert.Equal(t, 4*time.Second, srv.IdleTimeout)
+}
@@ -0,0 +1,55 @@
+package models
+
+import "math"
+
+// BundleItem is one component of a bundle: Quantity units of the product
+// with ProductID.
+type BundleItem struct {
+	ProductID string `json:"product_id" dynamodbav:"product_id"`
+	Quantity  int    `json:"quantity" dynamodbav:"quantity"`
+}
+
+// IsBundle reports whether the product is sold as a bundle of other
+// products.
+func (p *Product) IsBundle() bool {
+	return len(p.BundleItems) > 0
+}
+
+// BundleComponent is a BundleItem with its product loaded. Product is nil
+// when the component no longer exists.
+type BundleComponent struct {
+	ProductID string   `json:"product_id"`
+	Quantity  int      `json:"quantity"`
+	Product   *Product `json:"product"`
+}
+
+// ProductBundle is a bundle expanded into its components. Price and Stock
+// on Product are the bundle's own, explicitly set values; ComponentsPrice
+// and AvailableStock are derived from the components.
+type ProductBundle struct {
+	Product         *Product          `json:"product"`
+	Components      []BundleComponent `json:"components"`
+	ComponentsPrice float64           `json:"components_price"`
+	AvailableStock  float64           `json:"available_stock"`
+}
+
+// NewProductBundle derives the bundle's component price and availability.
+// The component price sums each component's price times its quantity. The
+// bundle can be assembled as many whole times as its scarcest component
+// allows; a missing or inactive component makes it unavailable.
+func NewProductBundle(bundle *Product, components []BundleComponent) *ProductBundle {
+	result := &ProductBundle{Product: bundle, Components: components}
+	available := math.Inf(1)
+	for _, c := range components {
+		if c.Product == nil || !c.Product.IsActive {
+			available = 0
+			continue
+		}
+		result.ComponentsPrice += c.Product.Price * float64(c.Quantity)
+		available = min(available, math.Floor(max(c.Product.Stock, 0)/float64(c.Quantity)))
+	}
+	if !math.IsInf(available, 1) {
+		result.AvailableStock = available
+	}
+	return result
+}
@@ -0,0 +1,31 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewProductBundle(t *testing.T) {
+	bundle := &Product{ID: "kit", Price: 45}
+	lamp := &Product{ID: "lamp", Price: 30, Stock: 7, IsActive: true}
+	pad := &Product{ID: "pad", Price: 10, Stock: 5, IsActive: true}
+
+	result := NewProductBundle(bundle, []BundleComponent{
+		{ProductID: "lamp", Quantity: 1, Product: lamp},
+		{ProductID: "pad", Quantity: 2, Product: pad},
+	})
+	assert.Equal(t, 50.0, result.ComponentsPrice)
+	assert.Equal(t, 2.0, result.AvailableStock)
+
+	pad.IsActive = false
+	result = NewProductBundle(bundle, []BundleComponent{
+		{ProductID: "lamp", Quantity: 1, Product: lamp},
+		{ProductID: "pad", Quantity: 2, Product: pad},
+	})
+	assert.Zero(t, result.AvailableStock)
+
+	result = NewProductBundle(bundle, []BundleComponent{{ProductID: "gone", Quantity: 1}})
+	assert.Zero(t, result.ComponentsPrice)
+	assert.Zero(t, result.AvailableStock)
+}
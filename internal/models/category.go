@@ -0,0 +1,76 @@
+package models
+
+import (
+	"fmt"
+	"slices"
+)
+
+// CategoryTaxonomy arranges categories in a tree, such as electronics >
+// phones > accessories. Categories it does not list are roots without
+// children. The zero value is a flat taxonomy.
+type CategoryTaxonomy struct {
+	parents  map[string]string
+	children map[string][]string
+}
+
+// NewCategoryTaxonomy builds a taxonomy from each category's parent. It
+// rejects empty names and parent chains that loop back on themselves.
+func NewCategoryTaxonomy(parents map[string]string) (CategoryTaxonomy, error) {
+	t := CategoryTaxonomy{
+		parents:  make(map[string]string, len(parents)),
+		children: make(map[string][]string),
+	}
+	for category, parent := range parents {
+		if category == "" || parent == "" {
+			return CategoryTaxonomy{}, fmt.Errorf("category %q has an empty name or parent", category)
+		}
+		t.parents[category] = parent
+		t.children[parent] = append(t.children[parent], category)
+	}
+	for category := range t.parents {
+		seen := map[string]bool{category: true}
+		for parent, ok := t.parents[category]; ok; parent, ok = t.parents[parent] {
+			if seen[parent] {
+				return CategoryTaxonomy{}, fmt.Errorf("category %q is its own ancestor", category)
+			}
+			seen[parent] = true
+		}
+	}
+	for _, children := range t.children {
+		slices.Sort(children)
+	}
+	return t, nil
+}
+
+// IsFlat reports whether the taxonomy has no hierarchy.
+func (t CategoryTaxonomy) IsFlat() bool {
+	return len(t.parents) == 0
+}
+
+// Contains reports whether the taxonomy places category, as a child or as a
+// parent of another category.
+func (t CategoryTaxonomy) Contains(category string) bool {
+	_, child := t.parents[category]
+	_, parent := t.children[category]
+	return child || parent
+}
+
+// Path returns category's ancestors from the root down, ending with category
+// itself.
+func (t CategoryTaxonomy) Path(category string) []string {
+	path := []string{category}
+	for parent, ok := t.parents[category]; ok; parent, ok = t.parents[parent] {
+		path = append(path, parent)
+	}
+	slices.Reverse(path)
+	return path
+}
+
+// Subtree returns category followed by all of its descendants, depth first.
+func (t CategoryTaxonomy) Subtree(category string) []string {
+	subtree := []string{category}
+	for _, child := range t.children[category] {
+		subtree = append(subtree, t.Subtree(child)...)
+	}
+	return subtree
+}
@@ -0,0 +1,41 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCategoryTaxonomy(t *testing.T) {
+	taxonomy, err := NewCategoryTaxonomy(map[string]string{
+		"phones":      "electronics",
+		"laptops":     "electronics",
+		"accessories": "phones",
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, []string{"electronics", "phones", "accessories"}, taxonomy.Path("accessories"))
+	assert.Equal(t, []string{"electronics"}, taxonomy.Path("electronics"))
+	assert.Equal(t, []string{"garden"}, taxonomy.Path("garden"))
+
+	assert.Equal(t, []string{"electronics", "laptops", "phones", "accessories"}, taxonomy.Subtree("electronics"))
+	assert.Equal(t, []string{"phones", "accessories"}, taxonomy.Subtree("phones"))
+	assert.Equal(t, []string{"garden"}, taxonomy.Subtree("garden"))
+	assert.True(t, taxonomy.Contains("electronics"))
+	assert.True(t, taxonomy.Contains("accessories"))
+	assert.False(t, taxonomy.Contains("garden"))
+	assert.False(t, taxonomy.IsFlat())
+	assert.True(t, CategoryTaxonomy{}.IsFlat())
+}
+
+func TestNewCategoryTaxonomy_RejectsCycles(t *testing.T) {
+	_, err := NewCategoryTaxonomy(map[string]string{"a": "b", "b": "c", "c": "a"})
+	assert.Error(t, err)
+
+	_, err = NewCategoryTaxonomy(map[string]string{"a": "a"})
+	assert.Error(t, err)
+
+	_, err = NewCategoryTaxonomy(map[string]string{"a": ""})
+	assert.Error(t, err)
+}
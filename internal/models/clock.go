@@ -0,0 +1,55 @@
+package models
+
+import (
+	"sync"
+	"time"
+)
+
+// Clock tells the time. Product timestamps are taken from a Clock so tests
+// can control them.
+type Clock interface {
+	Now() time.Time
+}
+
+// SystemClock reads the wall clock, in UTC. Timestamps are stored as
+// RFC 3339 strings and compared as strings, which only orders them when
+// they share a zone.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time {
+	return time.Now().UTC()
+}
+
+// FakeClock is a Clock that only moves when told to. It is safe for
+// concurrent use.
+type FakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFakeClock returns a FakeClock reading now.
+func NewFakeClock(now time.Time) *FakeClock {
+	return &FakeClock{now: now}
+}
+
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// Advance moves the clock forward by d.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// Set moves the clock to now.
+func (c *FakeClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSystemClock_ReadsUTC(t *testing.T) {
+	local := time.Local
+	time.Local = time.FixedZone("UTC+2", 2*60*60)
+	defer func() { time.Local = local }()
+
+	now := SystemClock.Now()
+
+	assert.Equal(t, time.UTC, now.Location())
+	assert.True(t, strings.HasSuffix(now.Format(time.RFC3339Nano), "Z"))
+}
@@ -0,0 +1,97 @@
+package models
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+)
+
+// DefaultCurrency is the currency prices are held in unless configured
+// otherwise.
+const DefaultCurrency = "USD"
+
+// defaultDecimals is the number of minor-unit digits assumed for currencies
+// not listed in currencyDecimals.
+const defaultDecimals = 2
+
+// currencyDecimals lists the ISO 4217 currencies whose minor unit is not
+// two decimal places.
+var currencyDecimals = map[string]int{
+	"BHD": 3,
+	"CLP": 0,
+	"ISK": 0,
+	"JOD": 3,
+	"JPY": 0,
+	"KRW": 0,
+	"KWD": 3,
+	"OMR": 3,
+	"TND": 3,
+	"VND": 0,
+}
+
+// CurrencyRules decides how amounts in each currency are rounded. The zero
+// value rounds every currency to two decimals.
+type CurrencyRules struct {
+	// Default is the currency used when none is named.
+	Default string
+
+	decimals map[string]int
+}
+
+// NewCurrencyRules returns rules using ISO 4217 minor units, with overrides
+// taking precedence. Currency codes are case-insensitive.
+func NewCurrencyRules(defaultCurrency string, overrides map[string]int) CurrencyRules {
+	decimals := make(map[string]int, len(currencyDecimals)+len(overrides))
+	for code, n := range currencyDecimals {
+		decimals[code] = n
+	}
+	for code, n := range overrides {
+		decimals[strings.ToUpper(code)] = n
+	}
+	return CurrencyRules{
+		Default:  strings.ToUpper(defaultCurrency),
+		decimals: decimals,
+	}
+}
+
+// Decimals returns how many decimal places amounts in code are rounded to.
+// An empty code means the default currency.
+func (r CurrencyRules) Decimals(code string) int {
+	if code == "" {
+		code = r.Default
+	}
+	if n, ok := r.decimals[strings.ToUpper(code)]; ok {
+		return n
+	}
+	return defaultDecimals
+}
+
+// Round rounds amount to code's minor unit, half away from zero. Rounding
+// works on amount's shortest decimal form, so 1.005 rounds to 1.01 even
+// though the nearest float64 is slightly below it.
+func (r CurrencyRules) Round(amount float64, code string) float64 {
+	return roundDecimal(decimalRat(amount), r.Decimals(code))
+}
+
+// decimalRat returns f exactly as its shortest decimal representation.
+func decimalRat(f float64) *big.Rat {
+	rat, _ := new(big.Rat).SetString(strconv.FormatFloat(f, 'f', -1, 64))
+	return rat
+}
+
+func roundDecimal(x *big.Rat, decimals int) float64 {
+	scale := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(decimals)), nil)
+	scaled := new(big.Rat).Mul(x, new(big.Rat).SetInt(scale))
+
+	// Half away from zero: add or subtract one half, then truncate.
+	half := big.NewRat(1, 2)
+	if scaled.Sign() < 0 {
+		scaled.Sub(scaled, half)
+	} else {
+		scaled.Add(scaled, half)
+	}
+	units := new(big.Int).Quo(scaled.Num(), scaled.Denom())
+
+	result, _ := new(big.Rat).SetFrac(units, scale).Float64()
+	return result
+}
@@ -0,0 +1,38 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCurrencyRules_Round(t *testing.T) {
+	rules := NewCurrencyRules("usd", nil)
+
+	tests := []struct {
+		amount   float64
+		currency string
+		want     float64
+	}{
+		{1.005, "USD", 1.01},
+		{2.675, "eur", 2.68},
+		{-1.005, "USD", -1.01},
+		{19.994, "", 19.99},
+		{1234.5, "JPY", 1235},
+		{1234.49, "jpy", 1234},
+		{1.0005, "KWD", 1.001},
+	}
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, rules.Round(tt.amount, tt.currency), "%v %s", tt.amount, tt.currency)
+	}
+}
+
+func TestCurrencyRules_Overrides(t *testing.T) {
+	rules := NewCurrencyRules("jpy", map[string]int{"usd": 3})
+
+	assert.Equal(t, "JPY", rules.Default)
+	assert.Equal(t, 0, rules.Decimals(""))
+	assert.Equal(t, 3, rules.Decimals("USD"))
+	assert.Equal(t, 2, rules.Decimals("GBP"))
+	assert.Equal(t, 2, CurrencyRules{}.Decimals("GBP"))
+}
@@ -0,0 +1,67 @@
+package models
+
+import (
+	"maps"
+	"slices"
+)
+
+// FieldChange is one field an update changed, with its values before and
+// after in their JSON form.
+type FieldChange struct {
+	Field  string `json:"field"`
+	Before any    `json:"before"`
+	After  any    `json:"after"`
+}
+
+// diffFields lists the fields Diff compares: those a client edits, directly
+// or through derived values such as the slug. Bookkeeping fields such as
+// UpdatedAt and Version change on every write and are left out.
+var diffFields = []struct {
+	name  string
+	value func(p *Product) any
+	equal func(a, b *Product) bool
+}{
+	{"name", func(p *Product) any { return p.Name }, func(a, b *Product) bool { return a.Name == b.Name }},
+	{"description", func(p *Product) any { return p.Description }, func(a, b *Product) bool { return a.Description == b.Description }},
+	{"price", func(p *Product) any { return p.Price }, func(a, b *Product) bool { return a.Price == b.Price }},
+	{"category", func(p *Product) any { return p.Category }, func(a, b *Product) bool { return a.Category == b.Category }},
+	{"category_path", func(p *Product) any { return p.CategoryPath }, func(a, b *Product) bool { return slices.Equal(a.CategoryPath, b.CategoryPath) }},
+	{"sku", func(p *Product) any { return p.SKU }, func(a, b *Product) bool { return a.SKU == b.SKU }},
+	{"slug", func(p *Product) any { return p.Slug }, func(a, b *Product) bool { return a.Slug == b.Slug }},
+	{"stock", func(p *Product) any { return p.Stock }, func(a, b *Product) bool { return a.Stock == b.Stock }},
+	{"unit", func(p *Product) any { return p.Unit }, func(a, b *Product) bool { return a.Unit == b.Unit }},
+	{"is_active", func(p *Product) any { return p.IsActive }, func(a, b *Product) bool { return a.IsActive == b.IsActive }},
+	{"status", func(p *Product) any { return p.CurrentStatus() }, func(a, b *Product) bool { return a.CurrentStatus() == b.CurrentStatus() }},
+	{"tags", func(p *Product) any { return p.Tags }, func(a, b *Product) bool { return slices.Equal(a.Tags, b.Tags) }},
+	{"images", func(p *Product) any { return p.Images }, func(a, b *Product) bool { return slices.Equal(a.Images, b.Images) }},
+	{"weight_grams", func(p *Product) any { return p.WeightGrams }, func(a, b *Product) bool { return a.WeightGrams == b.WeightGrams }},
+	{"length_mm", func(p *Product) any { return p.LengthMM }, func(a, b *Product) bool { return a.LengthMM == b.LengthMM }},
+	{"width_mm", func(p *Product) any { return p.WidthMM }, func(a, b *Product) bool { return a.WidthMM == b.WidthMM }},
+	{"height_mm", func(p *Product) any { return p.HeightMM }, func(a, b *Product) bool { return a.HeightMM == b.HeightMM }},
+	{"bundle_items", func(p *Product) any { return p.BundleItems }, func(a, b *Product) bool { return slices.Equal(a.BundleItems, b.BundleItems) }},
+	{"available_from", func(p *Product) any { return p.AvailableFrom }, func(a, b *Product) bool { return timesEqual(a.AvailableFrom, b.AvailableFrom) }},
+	{"featured", func(p *Product) any { return p.Featured }, func(a, b *Product) bool { return a.Featured == b.Featured }},
+	{"featured_rank", func(p *Product) any { return p.FeaturedRank }, func(a, b *Product) bool {
+		return (a.FeaturedRank == nil) == (b.FeaturedRank == nil) && (a.FeaturedRank == nil || *a.FeaturedRank == *b.FeaturedRank)
+	}},
+	{"translations", func(p *Product) any { return p.Translations }, func(a, b *Product) bool { return maps.Equal(a.Translations, b.Translations) }},
+	{"deleted_at", func(p *Product) any { return p.DeletedAt }, func(a, b *Product) bool {
+		return (a.DeletedAt == nil) == (b.DeletedAt == nil) && (a.DeletedAt == nil || a.DeletedAt.Equal(*b.DeletedAt))
+	}},
+}
+
+// Diff returns the fields that differ between before and after, in a fixed
+// order. Empty and nil lists compare equal.
+func Diff(before, after *Product) []FieldChange {
+	changes := []FieldChange{}
+	for _, field := range diffFields {
+		if !field.equal(before, after) {
+			changes = append(changes, FieldChange{
+				Field:  field.name,
+				Before: field.value(before),
+				After:  field.value(after),
+			})
+		}
+	}
+	return changes
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiff(t *testing.T) {
+	before := &Product{Name: "Widget", Price: 10, Stock: 5, Unit: UnitEach, IsActive: true, UpdatedAt: testEpoch, Version: 1}
+	after := *before
+	after.Price = 12
+	after.Tags = []string{}
+	after.UpdatedAt = testEpoch.Add(1)
+	after.Version = 2
+
+	assert.Equal(t, []FieldChange{{Field: "price", Before: 10.0, After: 12.0}}, Diff(before, &after))
+
+	after.Tags = []string{"sale"}
+	after.IsActive = false
+	assert.Equal(t, []FieldChange{
+		{Field: "price", Before: 10.0, After: 12.0},
+		{Field: "is_active", Before: true, After: false},
+		{Field: "status", Before: StatusPublished, After: StatusArchived},
+		{Field: "tags", Before: []string(nil), After: []string{"sale"}},
+	}, Diff(before, &after))
+
+	assert.Empty(t, Diff(before, before))
+}
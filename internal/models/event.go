@@ -0,0 +1,20 @@
+package models
+
+import "time"
+
+// Product change event types.
+const (
+	EventProductCreated = "product.created"
+	EventProductUpdated = "product.updated"
+	EventProductDeleted = "product.deleted"
+)
+
+// ProductEvent tells downstream consumers that a product changed. RequestID
+// is the ID of the API request that made the change, when there was one.
+type ProductEvent struct {
+	Type       string    `json:"type"`
+	ProductID  string    `json:"product_id"`
+	Actor      string    `json:"actor"`
+	RequestID  string    `json:"request_id,omitempty"`
+	OccurredAt time.Time `json:"occurred_at"`
+}
@@ -0,0 +1,35 @@
+package models
+
+import (
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+)
+
+// IDScheme selects how new product IDs are generated. Existing products keep
+// whatever ID they were created with; IDs are opaque to the repository.
+type IDScheme string
+
+const (
+	IDSchemeUUID IDScheme = "uuid"
+	// IDSchemeULID produces IDs that sort lexicographically by creation time.
+	IDSchemeULID IDScheme = "ulid"
+)
+
+// ParseIDScheme maps a configuration value to an IDScheme.
+func ParseIDScheme(value string) (IDScheme, bool) {
+	switch scheme := IDScheme(strings.ToLower(value)); scheme {
+	case IDSchemeUUID, IDSchemeULID:
+		return scheme, true
+	}
+	return IDSchemeUUID, false
+}
+
+// NewID returns a fresh ID. The zero IDScheme generates UUIDs.
+func (s IDScheme) NewID() string {
+	if s == IDSchemeULID {
+		return ulid.Make().String()
+	}
+	return uuid.New().String()
+}
@@ -0,0 +1,39 @@
+package models
+
+import (
+	"sort"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/oklog/ulid/v2"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIDScheme_NewID(t *testing.T) {
+	_, err := uuid.Parse(IDScheme("").NewID())
+	assert.NoError(t, err, "zero scheme should default to UUID")
+
+	_, err = uuid.Parse(IDSchemeUUID.NewID())
+	assert.NoError(t, err)
+
+	_, err = ulid.ParseStrict(IDSchemeULID.NewID())
+	assert.NoError(t, err)
+}
+
+func TestIDScheme_ULIDsSortByCreation(t *testing.T) {
+	ids := make([]string, 100)
+	for i := range ids {
+		ids[i] = NewProductWithID(IDSchemeULID.NewID(), CreateProductRequest{Name: "p"}, SystemClock).ID
+	}
+
+	assert.True(t, sort.StringsAreSorted(ids), "ULIDs generated in sequence should sort in creation order")
+}
+
+func TestParseIDScheme(t *testing.T) {
+	scheme, ok := ParseIDScheme("ULID")
+	assert.True(t, ok)
+	assert.Equal(t, IDSchemeULID, scheme)
+
+	_, ok = ParseIDScheme("snowflake")
+	assert.False(t, ok)
+}
@@ -0,0 +1,64 @@
+package models
+
+import (
+	"sort"
+
+	"golang.org/x/text/language"
+)
+
+// ProductTranslation holds a product's name and description in one locale.
+// An empty description falls back to the product's own.
+type ProductTranslation struct {
+	Name        string `json:"name" dynamodbav:"name" binding:"required"`
+	Description string `json:"description,omitempty" dynamodbav:"description,omitempty"`
+}
+
+// CanonicalLocale validates a BCP 47 language tag and returns it in
+// canonical form, so "en-us" and "en-US" address the same translation.
+func CanonicalLocale(tag string) (string, error) {
+	parsed, err := language.Parse(tag)
+	if err != nil {
+		return "", err
+	}
+	return parsed.String(), nil
+}
+
+// Localized returns a copy of p whose name and description come from the
+// translation best matching an Accept-Language header value, together with
+// that translation's locale. When nothing matches, p itself is returned
+// with an empty locale.
+func (p *Product) Localized(acceptLanguage string) (*Product, string) {
+	if len(p.Translations) == 0 {
+		return p, ""
+	}
+	preferred, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(preferred) == 0 {
+		return p, ""
+	}
+
+	locales := make([]string, 0, len(p.Translations))
+	for locale := range p.Translations {
+		locales = append(locales, locale)
+	}
+	sort.Strings(locales)
+
+	// The undetermined tag stands for the untranslated base fields and is
+	// what the matcher falls back to.
+	supported := []language.Tag{language.Und}
+	for _, locale := range locales {
+		supported = append(supported, language.Make(locale))
+	}
+	_, index, confidence := language.NewMatcher(supported).Match(preferred...)
+	if index == 0 || confidence == language.No {
+		return p, ""
+	}
+
+	locale := locales[index-1]
+	translation := p.Translations[locale]
+	localized := *p
+	localized.Name = translation.Name
+	if translation.Description != "" {
+		localized.Description = translation.Description
+	}
+	return &localized, locale
+}
@@ -0,0 +1,70 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCanonicalLocale(t *testing.T) {
+	locale, err := CanonicalLocale("en-us")
+	require.NoError(t, err)
+	assert.Equal(t, "en-US", locale)
+
+	locale, err = CanonicalLocale("zh-hant-tw")
+	require.NoError(t, err)
+	assert.Equal(t, "zh-Hant-TW", locale)
+
+	for _, tag := range []string{"", "not a locale", "en_US!"} {
+		_, err := CanonicalLocale(tag)
+		assert.Error(t, err, tag)
+	}
+}
+
+func TestProduct_Localized(t *testing.T) {
+	product := &Product{
+		Name:        "Shoe",
+		Description: "A comfortable shoe",
+		Translations: map[string]ProductTranslation{
+			"fr":     {Name: "Chaussure", Description: "Une chaussure confortable"},
+			"de-CH":  {Name: "Schuh"},
+			"es-419": {Name: "Zapato"},
+		},
+	}
+
+	tests := []struct {
+		acceptLanguage string
+		locale         string
+		name           string
+		description    string
+	}{
+		{"fr", "fr", "Chaussure", "Une chaussure confortable"},
+		{"fr-CA", "fr", "Chaussure", "Une chaussure confortable"},
+		{"de-CH", "de-CH", "Schuh", "A comfortable shoe"},
+		{"es-MX", "es-419", "Zapato", "A comfortable shoe"},
+		{"ja, fr;q=0.5", "fr", "Chaussure", "Une chaussure confortable"},
+		{"ja", "", "Shoe", "A comfortable shoe"},
+		{"", "", "Shoe", "A comfortable shoe"},
+		{"!!", "", "Shoe", "A comfortable shoe"},
+	}
+	for _, tt := range tests {
+		localized, locale := product.Localized(tt.acceptLanguage)
+
+		assert.Equal(t, tt.locale, locale, tt.acceptLanguage)
+		assert.Equal(t, tt.name, localized.Name, tt.acceptLanguage)
+		assert.Equal(t, tt.description, localized.Description, tt.acceptLanguage)
+	}
+
+	// The stored product is never modified.
+	assert.Equal(t, "Shoe", product.Name)
+}
+
+func TestProduct_Localized_NoTranslations(t *testing.T) {
+	product := &Product{Name: "Shoe"}
+
+	localized, locale := product.Localized("fr")
+
+	assert.Same(t, product, localized)
+	assert.Empty(t, locale)
+}
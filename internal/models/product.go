@@ -1,9 +1,10 @@
 package models
 
 import (
+	"encoding/json"
+	"maps"
+	"slices"
 	"time"
-
-	"github.com/google/uuid"
 )
 
 type Product struct {
@@ -13,50 +14,590 @@ type Product struct {
 	Price       float64   `json:"price" dynamodbav:"price"`
 	Category    string    `json:"category" dynamodbav:"category"`
 	SKU         string    `json:"sku" dynamodbav:"sku"`
-	Stock       int       `json:"stock" dynamodbav:"stock"`
+	Stock       float64   `json:"stock" dynamodbav:"stock"`
+	Unit        string    `json:"unit" dynamodbav:"unit"`
 	IsActive    bool      `json:"is_active" dynamodbav:"is_active"`
+	Tags        []string  `json:"tags,omitempty" dynamodbav:"tags,omitempty"`
+	ViewCount   int64     `json:"view_count" dynamodbav:"view_count"`
 	CreatedAt   time.Time `json:"created_at" dynamodbav:"created_at"`
 	UpdatedAt   time.Time `json:"updated_at" dynamodbav:"updated_at"`
+	CreatedBy   string    `json:"created_by" dynamodbav:"created_by"`
+	UpdatedBy   string    `json:"updated_by" dynamodbav:"updated_by"`
+
+	// Version starts at 1 and is incremented by the repository on every
+	// write of the product's fields, so a client can make a change
+	// conditional on the version it last read. Products stored before
+	// versioning read as version 0.
+	Version int64 `json:"version" dynamodbav:"version"`
+
+	// PriceUpdatedAt and StockUpdatedAt record the last time the price or
+	// stock actually changed. They stay nil until the first change.
+	PriceUpdatedAt *time.Time `json:"price_updated_at,omitempty" dynamodbav:"price_updated_at,omitempty"`
+	StockUpdatedAt *time.Time `json:"stock_updated_at,omitempty" dynamodbav:"stock_updated_at,omitempty"`
+
+	// RatingSum and RatingCount are incremented together by the repository
+	// so the average can be derived without a read-modify-write.
+	RatingSum   int64 `json:"-" dynamodbav:"rating_sum"`
+	RatingCount int   `json:"rating_count" dynamodbav:"rating_count"`
+
+	// DeletedAt is set when the product is soft deleted. Soft-deleted
+	// products are inactive and can be restored.
+	DeletedAt *time.Time `json:"deleted_at,omitempty" dynamodbav:"deleted_at,omitempty"`
+
+	// Translations holds localized names and descriptions keyed by canonical
+	// BCP 47 locale.
+	Translations map[string]ProductTranslation `json:"translations,omitempty" dynamodbav:"translations,omitempty"`
+
+	// Slug is a unique, URL-friendly name derived from Name on create. It is
+	// omitted from storage when empty because the slug index rejects empty
+	// keys.
+	Slug string `json:"slug,omitempty" dynamodbav:"slug,omitempty"`
+
+	// Images holds the product's images in display order: image URLs, or
+	// the keys of images uploaded to the image store, which always start
+	// with the product's own "products/<id>/" prefix.
+	Images []string `json:"images,omitempty" dynamodbav:"images,omitempty"`
+
+	// BrokenImages lists the image URLs that failed the last reachability
+	// check. It is maintained in the background and never set by clients.
+	BrokenImages []string `json:"broken_images,omitempty" dynamodbav:"broken_images,omitempty"`
+
+	// CategoryPath is Category's position in the category taxonomy, from
+	// the root down to Category itself. It is empty while categories are
+	// flat. It is recorded on write, so a reindex brings it up to date after
+	// the taxonomy changes.
+	CategoryPath []string `json:"category_path,omitempty" dynamodbav:"category_path,omitempty"`
+
+	// WeightGrams and the dimensions in millimetres feed shipping
+	// estimates. Zero means not recorded.
+	WeightGrams int `json:"weight_grams,omitempty" dynamodbav:"weight_grams,omitempty"`
+	LengthMM    int `json:"length_mm,omitempty" dynamodbav:"length_mm,omitempty"`
+	WidthMM     int `json:"width_mm,omitempty" dynamodbav:"width_mm,omitempty"`
+	HeightMM    int `json:"height_mm,omitempty" dynamodbav:"height_mm,omitempty"`
+
+	// BundleItems lists the products a bundle is made of. It is empty for
+	// products sold on their own.
+	BundleItems []BundleItem `json:"bundle_items,omitempty" dynamodbav:"bundle_items,omitempty"`
+
+	// Featured products are curated for the homepage and listed by
+	// FeaturedRank, 1 first. FeaturedRank is nil while the product is not
+	// featured.
+	Featured     bool `json:"featured,omitempty" dynamodbav:"featured,omitempty"`
+	FeaturedRank *int `json:"featured_rank,omitempty" dynamodbav:"featured_rank,omitempty"`
+
+	// AvailableFrom is when a pre-order product goes on sale. Until then
+	// the product is reported as a pre-order and is not in stock, whatever
+	// its stock. nil means available now.
+	AvailableFrom *time.Time `json:"available_from,omitempty" dynamodbav:"available_from,omitempty"`
+
+	// Status is the product's place in its lifecycle: a draft being built,
+	// published for sale, or archived. Only published products can be
+	// active. Products stored before statuses existed have none; see
+	// CurrentStatus.
+	Status string `json:"status,omitempty" dynamodbav:"status,omitempty"`
+
+	// StockDeactivated records that the product was deactivated because
+	// its stock ran out, so that a restock can reactivate it. It is never
+	// set for a product deactivated by hand.
+	StockDeactivated bool `json:"-" dynamodbav:"stock_deactivated,omitempty"`
+
+	// Reservations holds the product's outstanding stock reservations keyed
+	// by reservation ID. Reserved quantities are already excluded from Stock.
+	Reservations map[string]Reservation `json:"-" dynamodbav:"reservations,omitempty"`
+}
+
+// Product lifecycle statuses.
+const (
+	StatusDraft     = "draft"
+	StatusPublished = "published"
+	StatusArchived  = "archived"
+)
+
+// CurrentStatus returns the product's status. Products stored before
+// statuses existed are published while active, or deactivated because
+// their stock ran out, and archived otherwise.
+func (p *Product) CurrentStatus() string {
+	switch {
+	case p.Status != "":
+		return p.Status
+	case p.IsActive || p.StockDeactivated:
+		return StatusPublished
+	default:
+		return StatusArchived
+	}
+}
+
+// IsDraft reports whether the product is an unpublished draft.
+func (p *Product) IsDraft() bool {
+	return p.Status == StatusDraft
+}
+
+// Rating bounds accepted by RateProduct.
+const (
+	MinRating = 1
+	MaxRating = 5
+)
+
+type RatingRequest struct {
+	Rating int `json:"rating" binding:"required"`
+}
+
+// SetFeaturedRequest features a product at Rank.
+type SetFeaturedRequest struct {
+	Rank int `json:"rank" binding:"required"`
+}
+
+// ReorderFeaturedRequest ranks the listed featured products 1, 2, ... in
+// order.
+type ReorderFeaturedRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// RenameCategoryRequest moves every product in From to To.
+type RenameCategoryRequest struct {
+	From string `json:"from" binding:"required"`
+	To   string `json:"to" binding:"required"`
+}
+
+// StockUpdate sets one product's stock as part of a bulk update.
+type StockUpdate struct {
+	ID    string   `json:"id"`
+	Stock *float64 `json:"stock"`
+}
+
+// Outcomes of a single StockUpdate.
+const (
+	StockUpdated   = "updated"
+	StockUnchanged = "unchanged"
+	StockFailed    = "failed"
+)
+
+// StockUpdateResult reports what happened to one StockUpdate. Stock is the
+// product's stock afterwards and is omitted on failure.
+type StockUpdateResult struct {
+	ID     string   `json:"id"`
+	Status string   `json:"status"`
+	Stock  *float64 `json:"stock,omitempty"`
+	Error  string   `json:"error,omitempty"`
+}
+
+// ClearStockRequest zeroes a product's stock, e.g. when inventory is
+// written off as damaged. Reason is recorded with the change.
+type ClearStockRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+// BatchGetRequest lists the products to fetch in one call.
+type BatchGetRequest struct {
+	IDs []string `json:"ids" binding:"required"`
+}
+
+// BatchGetBySKURequest lists the SKUs of the products to fetch in one call.
+type BatchGetBySKURequest struct {
+	SKUs []string `json:"skus" binding:"required"`
+}
+
+// BatchGetResult holds the products a batch get found, in the order they
+// were asked for, and the requested IDs or SKUs that have no product.
+type BatchGetResult struct {
+	Products []*Product
+	Missing  []string
+}
+
+// Outcomes of a single bulk upsert item.
+const (
+	UpsertCreated   = "created"
+	UpsertUpdated   = "updated"
+	UpsertUnchanged = "unchanged"
+	UpsertFailed    = "failed"
+)
+
+// UpsertResult reports what a bulk upsert did with the item at Index. ID is
+// the product created or updated and is omitted on failure.
+type UpsertResult struct {
+	Index  int    `json:"index"`
+	SKU    string `json:"sku"`
+	ID     string `json:"id,omitempty"`
+	Status string `json:"status"`
+	Error  string `json:"error,omitempty"`
+}
+
+// ValidationResult reports whether one item of a validation batch would be
+// accepted by a create. Index is the item's position in the batch.
+type ValidationResult struct {
+	Index  int               `json:"index"`
+	SKU    string            `json:"sku,omitempty"`
+	Valid  bool              `json:"valid"`
+	Errors []ValidationError `json:"errors,omitempty"`
+}
+
+// ValidationError is one reason a ValidationResult is invalid. Field is the
+// request field at fault, when there is one.
+type ValidationError struct {
+	Field   string `json:"field,omitempty"`
+	Message string `json:"message"`
+}
+
+// BulkStatusRequest activates or deactivates every product matched by
+// Category or IDs; exactly one of the two is set. Confirm must be set to
+// change more products than a single unconfirmed call allows.
+type BulkStatusRequest struct {
+	Category string   `json:"category,omitempty"`
+	IDs      []string `json:"ids,omitempty"`
+	Active   *bool    `json:"active" binding:"required"`
+	Confirm  bool     `json:"confirm,omitempty"`
+}
+
+// BulkStatusResult reports how many products a BulkStatusRequest matched,
+// how many of them changed status, and which ones could not be updated.
+type BulkStatusResult struct {
+	Matched  int                 `json:"matched"`
+	Changed  int                 `json:"changed"`
+	Failures []BulkStatusFailure `json:"failures,omitempty"`
+}
+
+// BulkStatusFailure is one product a BulkStatusRequest could not update.
+type BulkStatusFailure struct {
+	ID    string `json:"id"`
+	Error string `json:"error"`
+}
+
+// ProductCounts are the counters a product accumulates through atomic
+// increments rather than updates.
+type ProductCounts struct {
+	ViewCount   int64 `json:"view_count"`
+	RatingSum   int64 `json:"rating_sum"`
+	RatingCount int   `json:"rating_count"`
+}
+
+// ReconcileResult reports the counters found on a product and what they
+// were corrected to. After equals Before when nothing needed correcting.
+type ReconcileResult struct {
+	ProductID string        `json:"product_id"`
+	Before    ProductCounts `json:"before"`
+	After     ProductCounts `json:"after"`
+	Corrected bool          `json:"corrected"`
+}
+
+// ReindexResult reports one batch of a reindex run. Processed counts the
+// products visited and Updated those whose derived fields were rewritten.
+// Skipped counts products written by someone else while the batch ran; a
+// later run picks them up. NextCursor resumes the run; it is empty once
+// Done.
+type ReindexResult struct {
+	Processed  int    `json:"processed"`
+	Updated    int    `json:"updated"`
+	Skipped    int    `json:"skipped"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Done       bool   `json:"done"`
+}
+
+// Counts returns the product's counters.
+func (p *Product) Counts() ProductCounts {
+	return ProductCounts{
+		ViewCount:   p.ViewCount,
+		RatingSum:   p.RatingSum,
+		RatingCount: p.RatingCount,
+	}
+}
+
+// Availability values derived from a product's stock and AvailableFrom.
+const (
+	InStock    = "in_stock"
+	LowStock   = "low_stock"
+	OutOfStock = "out_of_stock"
+	Preorder   = "preorder"
+)
+
+// IsPreorder reports whether the product is not yet on sale at now.
+func (p *Product) IsPreorder(now time.Time) bool {
+	return p.AvailableFrom != nil && p.AvailableFrom.After(now)
+}
+
+// InStock reports whether any stock is left and the product is on sale at
+// now.
+func (p *Product) InStock(now time.Time) bool {
+	return p.Stock > 0 && !p.IsPreorder(now)
+}
+
+// Availability classifies the product at now. A product not yet on sale is
+// a Preorder; otherwise stock at or below lowStock, but above zero, is
+// LowStock, and a lowStock of zero never reports it.
+func (p *Product) Availability(lowStock float64, now time.Time) string {
+	switch {
+	case p.IsPreorder(now):
+		return Preorder
+	case !p.InStock(now):
+		return OutOfStock
+	case p.Stock <= lowStock:
+		return LowStock
+	default:
+		return InStock
+	}
+}
+
+// EffectivePrice is the price a customer pays. The catalog has no price
+// adjustments yet, so it is Price.
+func (p *Product) EffectivePrice() float64 {
+	return p.Price
+}
+
+// AverageRating returns the mean of all submitted ratings, or zero when the
+// product has not been rated.
+func (p *Product) AverageRating() float64 {
+	if p.RatingCount == 0 {
+		return 0
+	}
+	return float64(p.RatingSum) / float64(p.RatingCount)
+}
+
+// ProductList is the result of a listing. Truncated is set when the listing
+// stopped at the configured scan cap before reaching the end of the table.
+type ProductList struct {
+	Products  []*Product
+	Truncated bool
+
+	// Set when the listing was paginated. Total counts the matches across
+	// all pages; NextCursor is empty on the last page.
+	Limit      int
+	Total      int
+	NextCursor string
+
+	// Matched counts every product a truncated scan matched, including those
+	// past the scan cap. It is zero unless the repository counted them.
+	Matched int
+
+	// Uncounted is set by listings that page through the table without
+	// counting their matches, such as search. Total is then zero.
+	Uncounted bool
+
+	// BudgetExceeded is set when the scan stopped early because it consumed
+	// more read capacity than its budget allowed. Products holds the partial
+	// results read until then.
+	BudgetExceeded bool
+}
+
+// PageMeta describes the page a listing returned. Limit is omitted when the
+// listing was not paginated.
+type PageMeta struct {
+	Limit      int    `json:"limit,omitempty"`
+	Returned   int    `json:"returned"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor,omitempty"`
+}
+
+// PageMeta returns the pagination metadata for the list.
+func (l *ProductList) PageMeta() PageMeta {
+	return PageMeta{
+		Limit:      l.Limit,
+		Returned:   len(l.Products),
+		HasMore:    l.NextCursor != "",
+		NextCursor: l.NextCursor,
+	}
+}
+
+// ListOptions holds the caller's listing preferences. Sort is "field" or
+// "field:asc|desc"; empty uses the service default. Limit and Cursor are
+// optional; without either the whole listing is returned.
+type ListOptions struct {
+	Sort   string
+	Limit  int
+	Cursor string
+
+	// Subcategories makes a category listing include products in the
+	// category's descendants.
+	Subcategories bool
+}
+
+// ProductSuggestion is the lightweight result of a name prefix search.
+type ProductSuggestion struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
 }
 
 type CreateProductRequest struct {
-	Name        string  `json:"name" binding:"required"`
-	Description string  `json:"description"`
-	Price       float64 `json:"price" binding:"required,gt=0"`
-	Category    string  `json:"category" binding:"required"`
-	SKU         string  `json:"sku" binding:"required"`
-	Stock       int     `json:"stock" binding:"required,gte=0"`
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description"`
+	Price       float64      `json:"price" binding:"required,gt=0"`
+	Category    string       `json:"category" binding:"required"`
+	SKU         string       `json:"sku"`                             // optional when SKU sequences are enabled
+	Stock       *float64     `json:"stock" binding:"omitempty,gte=0"` // nil takes the configured default
+	Unit        string       `json:"unit"`
+	Tags        []string     `json:"tags"`
+	Images      []string     `json:"images"`
+	WeightGrams int          `json:"weight_grams"`
+	LengthMM    int          `json:"length_mm"`
+	WidthMM     int          `json:"width_mm"`
+	HeightMM    int          `json:"height_mm"`
+	BundleItems []BundleItem `json:"bundle_items"`
+
+	AvailableFrom *time.Time `json:"available_from"`
+}
+
+// CreateDraftRequest is CreateProductRequest with only the name required,
+// for building a product up before it is published.
+type CreateDraftRequest struct {
+	Name        string       `json:"name" binding:"required"`
+	Description string       `json:"description"`
+	Price       float64      `json:"price" binding:"gte=0"`
+	Category    string       `json:"category"`
+	SKU         string       `json:"sku"`
+	Stock       *float64     `json:"stock" binding:"omitempty,gte=0"`
+	Unit        string       `json:"unit"`
+	Tags        []string     `json:"tags"`
+	Images      []string     `json:"images"`
+	WeightGrams int          `json:"weight_grams"`
+	LengthMM    int          `json:"length_mm"`
+	WidthMM     int          `json:"width_mm"`
+	HeightMM    int          `json:"height_mm"`
+	BundleItems []BundleItem `json:"bundle_items"`
+
+	AvailableFrom *time.Time `json:"available_from"`
 }
 
 type UpdateProductRequest struct {
-	Name        *string  `json:"name,omitempty"`
-	Description *string  `json:"description,omitempty"`
-	Price       *float64 `json:"price,omitempty"`
-	Category    *string  `json:"category,omitempty"`
-	SKU         *string  `json:"sku,omitempty"`
-	Stock       *int     `json:"stock,omitempty"`
-	IsActive    *bool    `json:"is_active,omitempty"`
+	Name        *string       `json:"name,omitempty"`
+	Description *string       `json:"description,omitempty"`
+	Price       *float64      `json:"price,omitempty"`
+	Category    *string       `json:"category,omitempty"`
+	SKU         *string       `json:"sku,omitempty"`
+	Stock       *float64      `json:"stock,omitempty"`
+	Unit        *string       `json:"unit,omitempty"`
+	IsActive    *bool         `json:"is_active,omitempty"`
+	Tags        *[]string     `json:"tags,omitempty"`
+	Images      *[]string     `json:"images,omitempty"`
+	WeightGrams *int          `json:"weight_grams,omitempty"`
+	LengthMM    *int          `json:"length_mm,omitempty"`
+	WidthMM     *int          `json:"width_mm,omitempty"`
+	HeightMM    *int          `json:"height_mm,omitempty"`
+	BundleItems *[]BundleItem `json:"bundle_items,omitempty"`
+
+	// AvailableFrom makes the product a pre-order until the given time.
+	// A time in the past makes it available at once.
+	AvailableFrom *time.Time `json:"available_from,omitempty"`
 }
 
-func NewProduct(req CreateProductRequest) *Product {
-	now := time.Now()
+// PatchOperation is one step of an RFC 6902 JSON Patch document. Value is
+// kept raw so it can be decoded against the field the path addresses.
+type PatchOperation struct {
+	Op    string          `json:"op" binding:"required"`
+	Path  string          `json:"path"`
+	Value json.RawMessage `json:"value,omitempty"`
+}
+
+// ProductFilter combines optional listing criteria. Zero-valued fields do not
+// constrain the result.
+type ProductFilter struct {
+	Category   string
+	Categories []string // any of these categories
+	MinPrice   *float64
+	MaxPrice   *float64
+	InStock    *bool
+	Tag        string
+	Name       string    // substring of the product name, case-sensitive
+	AsOf       time.Time // when InStock judges pre-orders, by the caller's clock
+}
+
+// StockOrZero is the requested stock, or zero when none was given.
+func (r CreateProductRequest) StockOrZero() float64 {
+	if r.Stock == nil {
+		return 0
+	}
+	return *r.Stock
+}
+
+// NewProduct builds an active product from req, timestamped by clock.
+func NewProduct(req CreateProductRequest, clock Clock) *Product {
+	return NewProductWithID(IDSchemeUUID.NewID(), req, clock)
+}
+
+// NewProductWithID is NewProduct with a caller-chosen ID, typically from
+// an IDScheme.
+func NewProductWithID(id string, req CreateProductRequest, clock Clock) *Product {
+	now := clock.Now()
 	return &Product{
-		ID:          uuid.New().String(),
+		ID:          id,
 		Name:        req.Name,
 		Description: req.Description,
 		Price:       req.Price,
 		Category:    req.Category,
 		SKU:         req.SKU,
-		Stock:       req.Stock,
+		Stock:       req.StockOrZero(),
+		Unit:        NormalizeUnit(req.Unit),
+		Tags:        req.Tags,
+		Images:      req.Images,
+		WeightGrams: req.WeightGrams,
+		LengthMM:    req.LengthMM,
+		WidthMM:     req.WidthMM,
+		HeightMM:    req.HeightMM,
+		BundleItems: req.BundleItems,
 		IsActive:    true,
-		CreatedAt:   now,
-		UpdatedAt:   now,
+		Status:      StatusPublished,
+
+		AvailableFrom: normalizeAvailableFrom(req.AvailableFrom),
+		CreatedAt:     now,
+		UpdatedAt:     now,
+		Version:       1,
 	}
 }
 
-func (p *Product) Update(req UpdateProductRequest) {
-	now := time.Now()
-	
+// IsDeleted reports whether the product has been soft deleted.
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+// Clone returns a copy of the product that shares no slices, maps or
+// pointers with it, so either can be modified without affecting the other.
+func (p *Product) Clone() *Product {
+	clone := *p
+	clone.Tags = slices.Clone(p.Tags)
+	clone.Images = slices.Clone(p.Images)
+	clone.BrokenImages = slices.Clone(p.BrokenImages)
+	clone.CategoryPath = slices.Clone(p.CategoryPath)
+	clone.BundleItems = slices.Clone(p.BundleItems)
+	clone.Translations = maps.Clone(p.Translations)
+	clone.Reservations = maps.Clone(p.Reservations)
+	clone.PriceUpdatedAt = clonePointer(p.PriceUpdatedAt)
+	clone.StockUpdatedAt = clonePointer(p.StockUpdatedAt)
+	clone.DeletedAt = clonePointer(p.DeletedAt)
+	clone.FeaturedRank = clonePointer(p.FeaturedRank)
+	clone.AvailableFrom = clonePointer(p.AvailableFrom)
+	return &clone
+}
+
+func clonePointer[T any](p *T) *T {
+	if p == nil {
+		return nil
+	}
+	v := *p
+	return &v
+}
+
+// Changes reports whether applying req would modify the product.
+func (p *Product) Changes(req UpdateProductRequest) bool {
+	return (req.Name != nil && *req.Name != p.Name) ||
+		(req.Description != nil && *req.Description != p.Description) ||
+		(req.Price != nil && *req.Price != p.Price) ||
+		(req.Category != nil && *req.Category != p.Category) ||
+		(req.SKU != nil && *req.SKU != p.SKU) ||
+		(req.Stock != nil && *req.Stock != p.Stock) ||
+		(req.Unit != nil && NormalizeUnit(*req.Unit) != NormalizeUnit(p.Unit)) ||
+		(req.IsActive != nil && (*req.IsActive != p.IsActive || p.StockDeactivated)) ||
+		(req.Tags != nil && !slices.Equal(*req.Tags, p.Tags)) ||
+		(req.Images != nil && !slices.Equal(*req.Images, p.Images)) ||
+		(req.WeightGrams != nil && *req.WeightGrams != p.WeightGrams) ||
+		(req.LengthMM != nil && *req.LengthMM != p.LengthMM) ||
+		(req.WidthMM != nil && *req.WidthMM != p.WidthMM) ||
+		(req.HeightMM != nil && *req.HeightMM != p.HeightMM) ||
+		(req.BundleItems != nil && !slices.Equal(*req.BundleItems, p.BundleItems)) ||
+		(req.AvailableFrom != nil && !timesEqual(normalizeAvailableFrom(req.AvailableFrom), p.AvailableFrom))
+}
+
+// Update applies the fields set in req, stamping UpdatedAt, and the price
+// and stock timestamps when those values change, with clock's time.
+func (p *Product) Update(req UpdateProductRequest, clock Clock) {
+	now := clock.Now()
+
 	if req.Name != nil {
 		p.Name = *req.Name
 	}
@@ -64,6 +605,9 @@ func (p *Product) Update(req UpdateProductRequest) {
 		p.Description = *req.Description
 	}
 	if req.Price != nil {
+		if *req.Price != p.Price {
+			p.PriceUpdatedAt = &now
+		}
 		p.Price = *req.Price
 	}
 	if req.Category != nil {
@@ -73,11 +617,72 @@ func (p *Product) Update(req UpdateProductRequest) {
 		p.SKU = *req.SKU
 	}
 	if req.Stock != nil {
+		if *req.Stock != p.Stock {
+			p.StockUpdatedAt = &now
+		}
 		p.Stock = *req.Stock
 	}
+	if req.Unit != nil {
+		p.Unit = NormalizeUnit(*req.Unit)
+	}
 	if req.IsActive != nil {
+		// Setting the status by hand overrides an out-of-stock deactivation.
+		// Drafts stay drafts until they are published.
 		p.IsActive = *req.IsActive
+		p.StockDeactivated = false
+		if !p.IsDraft() {
+			p.Status = StatusArchived
+			if p.IsActive {
+				p.Status = StatusPublished
+			}
+		}
+	}
+	if req.Tags != nil {
+		p.Tags = *req.Tags
+	}
+	if req.Images != nil {
+		p.Images = *req.Images
+		// Flags on images that were removed no longer apply; new images
+		// are unflagged until they are checked.
+		p.BrokenImages = slices.DeleteFunc(slices.Clone(p.BrokenImages), func(image string) bool {
+			return !slices.Contains(p.Images, image)
+		})
+		if len(p.BrokenImages) == 0 {
+			p.BrokenImages = nil
+		}
+	}
+	if req.WeightGrams != nil {
+		p.WeightGrams = *req.WeightGrams
+	}
+	if req.LengthMM != nil {
+		p.LengthMM = *req.LengthMM
+	}
+	if req.WidthMM != nil {
+		p.WidthMM = *req.WidthMM
+	}
+	if req.HeightMM != nil {
+		p.HeightMM = *req.HeightMM
+	}
+	if req.BundleItems != nil {
+		p.BundleItems = *req.BundleItems
 	}
-	
+	if req.AvailableFrom != nil {
+		p.AvailableFrom = normalizeAvailableFrom(req.AvailableFrom)
+	}
+
 	p.UpdatedAt = now
-}
\ No newline at end of file
+}
+
+// normalizeAvailableFrom stores t in UTC to the second, so stored times
+// share one format and compare correctly as strings in DynamoDB filters.
+func normalizeAvailableFrom(t *time.Time) *time.Time {
+	if t == nil {
+		return nil
+	}
+	normalized := t.UTC().Truncate(time.Second)
+	return &normalized
+}
+
+func timesEqual(a, b *time.Time) bool {
+	return (a == nil) == (b == nil) && (a == nil || a.Equal(*b))
+}
@@ -7,6 +7,13 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
+var testEpoch = time.Date(2024, time.March, 1, 12, 0, 0, 0, time.UTC)
+
 func TestNewProduct(t *testing.T) {
 	req := CreateProductRequest{
 		Name:        "Test Product",
@@ -14,10 +21,10 @@ func TestNewProduct(t *testing.T) {
 		Price:       99.99,
 		Category:    "electronics",
 		SKU:         "TEST-001",
-		Stock:       10,
+		Stock:       floatPtr(10),
 	}
 
-	product := NewProduct(req)
+	product := NewProduct(req, NewFakeClock(testEpoch))
 
 	assert.NotEmpty(t, product.ID)
 	assert.Equal(t, req.Name, product.Name)
@@ -25,13 +32,15 @@ func TestNewProduct(t *testing.T) {
 	assert.Equal(t, req.Price, product.Price)
 	assert.Equal(t, req.Category, product.Category)
 	assert.Equal(t, req.SKU, product.SKU)
-	assert.Equal(t, req.Stock, product.Stock)
+	assert.Equal(t, *req.Stock, product.Stock)
+	assert.Equal(t, UnitEach, product.Unit)
 	assert.True(t, product.IsActive)
-	assert.False(t, product.CreatedAt.IsZero())
-	assert.False(t, product.UpdatedAt.IsZero())
+	assert.Equal(t, testEpoch, product.CreatedAt)
+	assert.Equal(t, testEpoch, product.UpdatedAt)
 }
 
 func TestProduct_Update(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
 	product := &Product{
 		ID:          "test-id",
 		Name:        "Original Name",
@@ -41,15 +50,14 @@ func TestProduct_Update(t *testing.T) {
 		SKU:         "ORIG-001",
 		Stock:       5,
 		IsActive:    true,
-		CreatedAt:   time.Now().Add(-time.Hour),
-		UpdatedAt:   time.Now().Add(-time.Hour),
+		CreatedAt:   testEpoch,
+		UpdatedAt:   testEpoch,
 	}
-
-	originalUpdatedAt := product.UpdatedAt
+	clock.Advance(time.Hour)
 
 	newName := "Updated Name"
 	newPrice := 75.00
-	newStock := 15
+	newStock := 15.0
 	isActive := false
 
 	updateReq := UpdateProductRequest{
@@ -59,7 +67,7 @@ func TestProduct_Update(t *testing.T) {
 		IsActive: &isActive,
 	}
 
-	product.Update(updateReq)
+	product.Update(updateReq, clock)
 
 	assert.Equal(t, newName, product.Name)
 	assert.Equal(t, "Original Description", product.Description)
@@ -68,10 +76,14 @@ func TestProduct_Update(t *testing.T) {
 	assert.Equal(t, "ORIG-001", product.SKU)
 	assert.Equal(t, newStock, product.Stock)
 	assert.Equal(t, isActive, product.IsActive)
-	assert.True(t, product.UpdatedAt.After(originalUpdatedAt))
+	assert.Equal(t, testEpoch, product.CreatedAt)
+	assert.Equal(t, testEpoch.Add(time.Hour), product.UpdatedAt)
+	assert.Equal(t, testEpoch.Add(time.Hour), *product.PriceUpdatedAt)
+	assert.Equal(t, testEpoch.Add(time.Hour), *product.StockUpdatedAt)
 }
 
 func TestProduct_UpdateWithNilValues(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
 	product := &Product{
 		ID:          "test-id",
 		Name:        "Original Name",
@@ -81,14 +93,15 @@ func TestProduct_UpdateWithNilValues(t *testing.T) {
 		SKU:         "ORIG-001",
 		Stock:       5,
 		IsActive:    true,
-		CreatedAt:   time.Now().Add(-time.Hour),
-		UpdatedAt:   time.Now().Add(-time.Hour),
+		CreatedAt:   testEpoch,
+		UpdatedAt:   testEpoch,
 	}
 
 	originalValues := *product
 	updateReq := UpdateProductRequest{}
 
-	product.Update(updateReq)
+	clock.Advance(time.Minute)
+	product.Update(updateReq, clock)
 
 	assert.Equal(t, originalValues.Name, product.Name)
 	assert.Equal(t, originalValues.Description, product.Description)
@@ -97,5 +110,155 @@ func TestProduct_UpdateWithNilValues(t *testing.T) {
 	assert.Equal(t, originalValues.SKU, product.SKU)
 	assert.Equal(t, originalValues.Stock, product.Stock)
 	assert.Equal(t, originalValues.IsActive, product.IsActive)
-	assert.True(t, product.UpdatedAt.After(originalValues.UpdatedAt))
-}
\ No newline at end of file
+	assert.Equal(t, testEpoch.Add(time.Minute), product.UpdatedAt)
+	assert.Nil(t, product.PriceUpdatedAt)
+	assert.Nil(t, product.StockUpdatedAt)
+}
+
+func TestProduct_UpdateFieldTimestamps(t *testing.T) {
+	clock := NewFakeClock(testEpoch)
+	product := NewProduct(CreateProductRequest{
+		Name:     "Test Product",
+		Price:    10,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(5),
+	}, clock)
+	assert.Nil(t, product.PriceUpdatedAt)
+	assert.Nil(t, product.StockUpdatedAt)
+
+	stockChangedAt := testEpoch.Add(time.Minute)
+	clock.Set(stockChangedAt)
+	samePrice := 10.0
+	newStock := 6.0
+	product.Update(UpdateProductRequest{Price: &samePrice, Stock: &newStock}, clock)
+
+	assert.Nil(t, product.PriceUpdatedAt, "unchanged price must not move its timestamp")
+	if assert.NotNil(t, product.StockUpdatedAt) {
+		assert.Equal(t, stockChangedAt, *product.StockUpdatedAt)
+	}
+	assert.Equal(t, stockChangedAt, product.UpdatedAt)
+
+	priceChangedAt := testEpoch.Add(2 * time.Minute)
+	clock.Set(priceChangedAt)
+	newPrice := 12.0
+	name := "Renamed"
+	product.Update(UpdateProductRequest{Price: &newPrice, Stock: &newStock, Name: &name}, clock)
+
+	if assert.NotNil(t, product.PriceUpdatedAt) {
+		assert.Equal(t, priceChangedAt, *product.PriceUpdatedAt)
+	}
+	assert.Equal(t, stockChangedAt, *product.StockUpdatedAt, "unchanged stock must not move its timestamp")
+
+	clock.Advance(time.Minute)
+	product.Update(UpdateProductRequest{Name: &name}, clock)
+
+	assert.Equal(t, testEpoch.Add(3*time.Minute), product.UpdatedAt)
+	assert.Equal(t, priceChangedAt, *product.PriceUpdatedAt)
+	assert.Equal(t, stockChangedAt, *product.StockUpdatedAt)
+	assert.Equal(t, testEpoch, product.CreatedAt)
+}
+
+func TestProduct_Changes(t *testing.T) {
+	product := &Product{Name: "Widget", Price: 10, Stock: 5, Unit: UnitKilogram, IsActive: true}
+
+	name := "Widget"
+	price := 10.0
+	unit := "kg"
+	active := true
+	assert.False(t, product.Changes(UpdateProductRequest{}))
+	assert.False(t, product.Changes(UpdateProductRequest{Name: &name, Price: &price, Unit: &unit, IsActive: &active}))
+
+	newPrice := 11.0
+	inactive := false
+	assert.True(t, product.Changes(UpdateProductRequest{Name: &name, Price: &newPrice}))
+	assert.True(t, product.Changes(UpdateProductRequest{IsActive: &inactive}))
+}
+
+func TestNewProduct_FractionalUnit(t *testing.T) {
+	req := CreateProductRequest{
+		Name:     "Coffee Beans",
+		Price:    18.50,
+		Category: "grocery",
+		SKU:      "GROC-001",
+		Stock:    floatPtr(12.5),
+		Unit:     UnitKilogram,
+	}
+
+	product := NewProduct(req, SystemClock)
+
+	assert.Equal(t, 12.5, product.Stock)
+	assert.Equal(t, UnitKilogram, product.Unit)
+}
+
+func TestProduct_Availability(t *testing.T) {
+	tests := []struct {
+		stock    float64
+		lowStock float64
+		want     string
+	}{
+		{stock: 0, lowStock: 5, want: OutOfStock},
+		{stock: -1, lowStock: 5, want: OutOfStock},
+		{stock: 3, lowStock: 5, want: LowStock},
+		{stock: 5, lowStock: 5, want: LowStock},
+		{stock: 6, lowStock: 5, want: InStock},
+		{stock: 0.5, lowStock: 0, want: InStock},
+		{stock: 0, lowStock: 0, want: OutOfStock},
+	}
+
+	now := time.Now()
+	for _, tt := range tests {
+		p := &Product{Stock: tt.stock}
+		assert.Equal(t, tt.want, p.Availability(tt.lowStock, now), "stock %g, threshold %g", tt.stock, tt.lowStock)
+		assert.Equal(t, tt.stock > 0, p.InStock(now))
+	}
+}
+
+func TestProduct_Availability_Preorder(t *testing.T) {
+	release := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+	p := &Product{Stock: 10, AvailableFrom: &release}
+
+	before := release.Add(-time.Hour)
+	assert.True(t, p.IsPreorder(before))
+	assert.False(t, p.InStock(before))
+	assert.Equal(t, Preorder, p.Availability(5, before))
+
+	// On sale from the release time itself.
+	assert.False(t, p.IsPreorder(release))
+	assert.True(t, p.InStock(release))
+	assert.Equal(t, InStock, p.Availability(5, release))
+
+	p.Stock = 0
+	assert.Equal(t, Preorder, p.Availability(5, before))
+	assert.Equal(t, OutOfStock, p.Availability(5, release))
+}
+
+func TestProduct_CurrentStatus(t *testing.T) {
+	assert.Equal(t, StatusDraft, (&Product{Status: StatusDraft}).CurrentStatus())
+	assert.Equal(t, StatusArchived, (&Product{Status: StatusArchived, IsActive: true}).CurrentStatus())
+
+	// Products stored before statuses existed take theirs from IsActive.
+	assert.Equal(t, StatusPublished, (&Product{IsActive: true}).CurrentStatus())
+	assert.Equal(t, StatusPublished, (&Product{StockDeactivated: true}).CurrentStatus())
+	assert.Equal(t, StatusArchived, (&Product{}).CurrentStatus())
+}
+
+func TestProduct_Clone(t *testing.T) {
+	rank := 1
+	original := &Product{
+		ID:           "1",
+		Tags:         []string{"red"},
+		Translations: map[string]ProductTranslation{"fr": {Name: "Chaussure"}},
+		FeaturedRank: &rank,
+	}
+
+	clone := original.Clone()
+	clone.Tags[0] = "blue"
+	clone.Translations["fr"] = ProductTranslation{Name: "Botte"}
+	*clone.FeaturedRank = 2
+
+	assert.Equal(t, []string{"red"}, original.Tags)
+	assert.Equal(t, "Chaussure", original.Translations["fr"].Name)
+	assert.Equal(t, 1, *original.FeaturedRank)
+	assert.Nil(t, clone.Images)
+}
@@ -0,0 +1,25 @@
+package models
+
+import "time"
+
+// Reservation holds part of a product's stock aside, for example while a
+// checkout completes. The quantity is taken off the product's stock when the
+// reservation is made and returned when it is released or expires.
+type Reservation struct {
+	ID        string    `json:"id" dynamodbav:"id"`
+	ProductID string    `json:"product_id" dynamodbav:"product_id"`
+	Quantity  float64   `json:"quantity" dynamodbav:"quantity"`
+	ExpiresAt time.Time `json:"expires_at" dynamodbav:"expires_at"`
+}
+
+// Expired reports whether the reservation has lapsed at now.
+func (r Reservation) Expired(now time.Time) bool {
+	return !now.Before(r.ExpiresAt)
+}
+
+// ReserveStockRequest reserves Quantity of a product for TTLSeconds, or the
+// service default when zero.
+type ReserveStockRequest struct {
+	Quantity   float64 `json:"quantity" binding:"required"`
+	TTLSeconds int     `json:"ttl_seconds"`
+}
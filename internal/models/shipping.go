@@ -0,0 +1,65 @@
+package models
+
+// VolumetricDivisor converts a parcel's volume into its volumetric weight:
+// the carriers' common 5000 cm³ per kilogram, which is 5000 mm³ per gram.
+const VolumetricDivisor = 5000
+
+// ShippingInfo is the shipping-relevant subset of a product.
+type ShippingInfo struct {
+	ProductID             string `json:"product_id"`
+	SKU                   string `json:"sku"`
+	WeightGrams           int    `json:"weight_grams"`
+	LengthMM              int    `json:"length_mm"`
+	WidthMM               int    `json:"width_mm"`
+	HeightMM              int    `json:"height_mm"`
+	VolumetricWeightGrams int    `json:"volumetric_weight_grams"`
+	ChargeableWeightGrams int    `json:"chargeable_weight_grams"`
+}
+
+// Shipping returns the product's shipping attributes and derived weights.
+func (p *Product) Shipping() ShippingInfo {
+	return ShippingInfo{
+		ProductID:             p.ID,
+		SKU:                   p.SKU,
+		WeightGrams:           p.WeightGrams,
+		LengthMM:              p.LengthMM,
+		WidthMM:               p.WidthMM,
+		HeightMM:              p.HeightMM,
+		VolumetricWeightGrams: p.VolumetricWeight(),
+		ChargeableWeightGrams: p.ChargeableWeight(),
+	}
+}
+
+// VolumetricWeight is the weight in grams carriers bill a parcel of the
+// product's dimensions at, rounded up. It is zero until all three
+// dimensions are known.
+func (p *Product) VolumetricWeight() int {
+	volume := int64(p.LengthMM) * int64(p.WidthMM) * int64(p.HeightMM)
+	return int((volume + VolumetricDivisor - 1) / VolumetricDivisor)
+}
+
+// ChargeableWeight is the greater of the product's actual and volumetric
+// weights, in grams.
+func (p *Product) ChargeableWeight() int {
+	return max(p.WeightGrams, p.VolumetricWeight())
+}
+
+// NegativeShippingAttribute returns the JSON name of the first negative
+// shipping attribute, or "" when none is. Nil attributes are not being set
+// and are skipped.
+func NegativeShippingAttribute(weightGrams, lengthMM, widthMM, heightMM *int) string {
+	for _, attr := range []struct {
+		name  string
+		value *int
+	}{
+		{"weight_grams", weightGrams},
+		{"length_mm", lengthMM},
+		{"width_mm", widthMM},
+		{"height_mm", heightMM},
+	} {
+		if attr.value != nil && *attr.value < 0 {
+			return attr.name
+		}
+	}
+	return ""
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProduct_VolumetricWeight(t *testing.T) {
+	tests := []struct {
+		length, width, height int
+		want                  int
+	}{
+		{length: 300, width: 200, height: 100, want: 1200},
+		{length: 10, width: 10, height: 10, want: 1},
+		{length: 100, width: 51, height: 101, want: 104},
+		{length: 300, width: 200, height: 0, want: 0},
+		{length: 0, width: 0, height: 0, want: 0},
+		{length: 2000, width: 2000, height: 2000, want: 1600000},
+	}
+
+	for _, tt := range tests {
+		p := &Product{LengthMM: tt.length, WidthMM: tt.width, HeightMM: tt.height}
+		assert.Equal(t, tt.want, p.VolumetricWeight(), "%dx%dx%d mm", tt.length, tt.width, tt.height)
+	}
+}
+
+func TestProduct_ChargeableWeight(t *testing.T) {
+	bulky := &Product{WeightGrams: 500, LengthMM: 300, WidthMM: 200, HeightMM: 100}
+	assert.Equal(t, 1200, bulky.ChargeableWeight())
+
+	dense := &Product{WeightGrams: 5000, LengthMM: 300, WidthMM: 200, HeightMM: 100}
+	assert.Equal(t, 5000, dense.ChargeableWeight())
+
+	unmeasured := &Product{WeightGrams: 250}
+	assert.Equal(t, 250, unmeasured.ChargeableWeight())
+}
+
+func TestProduct_Shipping(t *testing.T) {
+	p := &Product{ID: "test-id", SKU: "TEST-001", WeightGrams: 500, LengthMM: 300, WidthMM: 200, HeightMM: 100}
+
+	assert.Equal(t, ShippingInfo{
+		ProductID:             "test-id",
+		SKU:                   "TEST-001",
+		WeightGrams:           500,
+		LengthMM:              300,
+		WidthMM:               200,
+		HeightMM:              100,
+		VolumetricWeightGrams: 1200,
+		ChargeableWeightGrams: 1200,
+	}, p.Shipping())
+}
+
+func TestNegativeShippingAttribute(t *testing.T) {
+	negative, zero, positive := -1, 0, 10
+
+	assert.Empty(t, NegativeShippingAttribute(nil, nil, nil, nil))
+	assert.Empty(t, NegativeShippingAttribute(&zero, &positive, &zero, &positive))
+	assert.Equal(t, "weight_grams", NegativeShippingAttribute(&negative, nil, nil, nil))
+	assert.Equal(t, "width_mm", NegativeShippingAttribute(&positive, nil, &negative, &negative))
+	assert.Equal(t, "height_mm", NegativeShippingAttribute(nil, &zero, &positive, &negative))
+}
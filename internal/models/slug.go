@@ -0,0 +1,50 @@
+package models
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// fallbackSlug is used for names with no letters or digits to slug.
+const fallbackSlug = "product"
+
+// maxSlugLength keeps slugs readable in URLs; longer names are cut at a
+// word boundary where possible.
+const maxSlugLength = 80
+
+// Slugify turns a product name into a URL-friendly slug: lowercase ASCII
+// letters and digits separated by single hyphens. Accents are dropped, so
+// "Café Crème" becomes "cafe-creme".
+func Slugify(name string) string {
+	var b strings.Builder
+	hyphen := false
+	for _, r := range norm.NFKD.String(name) {
+		switch {
+		case unicode.Is(unicode.Mn, r):
+			// Combining marks left over from decomposing accented letters.
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)):
+			if hyphen && b.Len() > 0 {
+				b.WriteByte('-')
+			}
+			hyphen = false
+			b.WriteRune(unicode.ToLower(r))
+		default:
+			hyphen = true
+		}
+	}
+
+	slug := b.String()
+	if len(slug) > maxSlugLength {
+		slug = slug[:maxSlugLength]
+		if cut := strings.LastIndexByte(slug, '-'); cut > 0 {
+			slug = slug[:cut]
+		}
+		slug = strings.TrimSuffix(slug, "-")
+	}
+	if slug == "" {
+		return fallbackSlug
+	}
+	return slug
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSlugify(t *testing.T) {
+	tests := map[string]string{
+		"Blue Widget":            "blue-widget",
+		"  Blue   Widget!! ":     "blue-widget",
+		"USB-C Cable (2m)":       "usb-c-cable-2m",
+		"Café Crème":             "cafe-creme",
+		"Ångström 3000":          "angstrom-3000",
+		"50% off -- today only!": "50-off-today-only",
+		"日本語":                    "product",
+		"":                       "product",
+	}
+	for name, want := range tests {
+		assert.Equal(t, want, Slugify(name), name)
+	}
+}
+
+func TestSlugify_Long(t *testing.T) {
+	slug := Slugify(strings.Repeat("widget ", 20))
+
+	assert.LessOrEqual(t, len(slug), maxSlugLength)
+	assert.False(t, strings.HasSuffix(slug, "-"))
+	assert.True(t, strings.HasSuffix(slug, "widget"))
+}
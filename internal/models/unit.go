@@ -0,0 +1,53 @@
+package models
+
+import "math"
+
+// Units of measure a product's stock can be counted in. Products created
+// before units existed have no unit stored and are treated as UnitEach.
+const (
+	UnitEach       = "each"
+	UnitKilogram   = "kg"
+	UnitGram       = "g"
+	UnitPound      = "lb"
+	UnitLiter      = "liter"
+	UnitMilliliter = "ml"
+)
+
+var fractionalUnits = map[string]bool{
+	UnitKilogram:   true,
+	UnitGram:       true,
+	UnitPound:      true,
+	UnitLiter:      true,
+	UnitMilliliter: true,
+}
+
+// FractionalUnits lists the units whose stock may be non-integer, in a
+// stable order.
+func FractionalUnits() []string {
+	return []string{UnitKilogram, UnitGram, UnitPound, UnitLiter, UnitMilliliter}
+}
+
+// NormalizeUnit maps an empty unit to UnitEach.
+func NormalizeUnit(unit string) string {
+	if unit == "" {
+		return UnitEach
+	}
+	return unit
+}
+
+// IsValidUnit reports whether unit is a supported unit of measure.
+func IsValidUnit(unit string) bool {
+	unit = NormalizeUnit(unit)
+	return unit == UnitEach || fractionalUnits[unit]
+}
+
+// AllowsFractionalStock reports whether stock in unit may be non-integer,
+// e.g. 1.5 kg. Countable units such as UnitEach must hold whole quantities.
+func AllowsFractionalStock(unit string) bool {
+	return fractionalUnits[NormalizeUnit(unit)]
+}
+
+// IsValidStockForUnit reports whether stock is a permissible quantity for unit.
+func IsValidStockForUnit(stock float64, unit string) bool {
+	return AllowsFractionalStock(unit) || stock == math.Trunc(stock)
+}
@@ -0,0 +1,37 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsValidUnit(t *testing.T) {
+	assert.True(t, IsValidUnit(""))
+	assert.True(t, IsValidUnit(UnitEach))
+	assert.True(t, IsValidUnit(UnitKilogram))
+	assert.True(t, IsValidUnit(UnitLiter))
+	assert.False(t, IsValidUnit("bushel"))
+}
+
+func TestIsValidStockForUnit(t *testing.T) {
+	tests := []struct {
+		name  string
+		stock float64
+		unit  string
+		want  bool
+	}{
+		{name: "whole quantity each", stock: 3, unit: UnitEach, want: true},
+		{name: "fractional quantity each", stock: 1.5, unit: UnitEach, want: false},
+		{name: "fractional quantity defaults to each", stock: 0.25, unit: "", want: false},
+		{name: "fractional quantity kg", stock: 1.5, unit: UnitKilogram, want: true},
+		{name: "whole quantity kg", stock: 2, unit: UnitKilogram, want: true},
+		{name: "fractional quantity liter", stock: 0.75, unit: UnitLiter, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, IsValidStockForUnit(tt.stock, tt.unit))
+		})
+	}
+}
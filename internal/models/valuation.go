@@ -0,0 +1,18 @@
+package models
+
+// InventoryValuation totals price × stock over active products, overall and
+// per category. Truncated is set when the underlying scan hit its cap, in
+// which case the totals cover only the products that were read.
+type InventoryValuation struct {
+	Currency     string                       `json:"currency"`
+	Total        float64                      `json:"total"`
+	ProductCount int                          `json:"product_count"`
+	Categories   map[string]CategoryValuation `json:"categories"`
+	Truncated    bool                         `json:"truncated,omitempty"`
+}
+
+// CategoryValuation is one category's share of an InventoryValuation.
+type CategoryValuation struct {
+	Total        float64 `json:"total"`
+	ProductCount int     `json:"product_count"`
+}
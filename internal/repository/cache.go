@@ -0,0 +1,167 @@
+package repository
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// CachingRepository serves GetByID from memory for up to ttl and passes
+// everything else through. Writes made through it evict the product they
+// touch, but changes made elsewhere, by another instance or directly in
+// DynamoDB, are only seen once the entry expires or is evicted by hand.
+//
+// Cached products are copied on the way in and out, so callers may modify
+// what they get. A write evicts before and after it runs, and a read that
+// overlapped any eviction is not cached, so a read racing a write cannot
+// leave the replaced product in the cache.
+//
+// View count increments do not evict, so cached view counts may lag by up
+// to ttl; evicting on every view would make the cache useless for the
+// products that are read most.
+type CachingRepository struct {
+	ProductRepository
+
+	ttl time.Duration
+	now func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]cacheEntry
+	// evictions counts evictions and flushes, so GetByID can tell whether
+	// one happened while it read through.
+	evictions uint64
+}
+
+type cacheEntry struct {
+	product *models.Product
+	expires time.Time
+}
+
+func NewCachingRepository(repo ProductRepository, ttl time.Duration) *CachingRepository {
+	return &CachingRepository{
+		ProductRepository: repo,
+		ttl:               ttl,
+		now:               time.Now,
+		entries:           make(map[string]cacheEntry),
+	}
+}
+
+// GetByID returns a copy of the cached product, reading through to the
+// wrapped repository on a miss. Missing products are not cached.
+func (r *CachingRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	r.mu.Lock()
+	entry, ok := r.entries[id]
+	evictions := r.evictions
+	r.mu.Unlock()
+	if ok && r.now().Before(entry.expires) {
+		return entry.product.Clone(), nil
+	}
+
+	product, err := r.ProductRepository.GetByID(ctx, id)
+	if err != nil || product == nil {
+		return product, err
+	}
+
+	r.mu.Lock()
+	if r.evictions == evictions {
+		r.entries[id] = cacheEntry{product: product.Clone(), expires: r.now().Add(r.ttl)}
+	}
+	r.mu.Unlock()
+	return product, nil
+}
+
+// Flush empties the cache and returns how many entries it held.
+func (r *CachingRepository) Flush() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	n := len(r.entries)
+	r.entries = make(map[string]cacheEntry)
+	r.evictions++
+	return n
+}
+
+// Evict drops the entry for id and reports whether there was one.
+func (r *CachingRepository) Evict(id string) bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	_, ok := r.entries[id]
+	delete(r.entries, id)
+	r.evictions++
+	return ok
+}
+
+// evictAround evicts id and returns a func that evicts it again, for a
+// write to defer.
+func (r *CachingRepository) evictAround(id string) func() {
+	r.Evict(id)
+	return func() { r.Evict(id) }
+}
+
+func (r *CachingRepository) Update(ctx context.Context, product *models.Product) error {
+	defer r.evictAround(product.ID)()
+	return r.ProductRepository.Update(ctx, product)
+}
+
+func (r *CachingRepository) Delete(ctx context.Context, id string) error {
+	defer r.evictAround(id)()
+	return r.ProductRepository.Delete(ctx, id)
+}
+
+func (r *CachingRepository) DeleteVersion(ctx context.Context, id string, version int64) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.DeleteVersion(ctx, id, version)
+}
+
+func (r *CachingRepository) AddRating(ctx context.Context, id string, rating int) (*models.Product, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.AddRating(ctx, id, rating)
+}
+
+func (r *CachingRepository) SetStock(ctx context.Context, id string, stock float64, followStock bool, actor string, now time.Time) (*models.Product, bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetStock(ctx, id, stock, followStock, actor, now)
+}
+
+func (r *CachingRepository) SetStockActivation(ctx context.Context, id string, active bool) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetStockActivation(ctx, id, active)
+}
+
+func (r *CachingRepository) ClearStock(ctx context.Context, id string, version int64, deactivate bool, actor string, now time.Time) (*models.Product, bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.ClearStock(ctx, id, version, deactivate, actor, now)
+}
+
+func (r *CachingRepository) SetCounts(ctx context.Context, id string, from, to models.ProductCounts) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetCounts(ctx, id, from, to)
+}
+
+func (r *CachingRepository) SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetDerivedFields(ctx, id, version, slug, categoryPath)
+}
+
+func (r *CachingRepository) SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetCategory(ctx, id, version, category, categoryPath, actor, now)
+}
+
+func (r *CachingRepository) SetBrokenImages(ctx context.Context, id string, images, broken []string) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.SetBrokenImages(ctx, id, images, broken)
+}
+
+func (r *CachingRepository) ReserveStock(ctx context.Context, reservation models.Reservation, now time.Time) (*models.Product, bool, error) {
+	defer r.evictAround(reservation.ProductID)()
+	return r.ProductRepository.ReserveStock(ctx, reservation, now)
+}
+
+func (r *CachingRepository) ReleaseReservation(ctx context.Context, id, reservationID string, version int64, stock float64, now time.Time) (bool, error) {
+	defer r.evictAround(id)()
+	return r.ProductRepository.ReleaseReservation(ctx, id, reservationID, version, stock, now)
+}
@@ -0,0 +1,145 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+)
+
+// countingRepository counts the reads that reach the backing repository.
+type countingRepository struct {
+	ProductRepository
+	gets int
+}
+
+func (r *countingRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	r.gets++
+	return r.ProductRepository.GetByID(ctx, id)
+}
+
+func newCachedTestRepository(t *testing.T) (*CachingRepository, *countingRepository) {
+	t.Helper()
+	backing := &countingRepository{ProductRepository: NewMemoryProductRepository()}
+	require.NoError(t, backing.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe"}))
+	return NewCachingRepository(backing, time.Minute), backing
+}
+
+func TestCachingRepository_ServesRepeatReadsFromCache(t *testing.T) {
+	cache, backing := newCachedTestRepository(t)
+
+	for i := 0; i < 3; i++ {
+		product, err := cache.GetByID(context.Background(), "test-id")
+		require.NoError(t, err)
+		assert.Equal(t, "Shoe", product.Name)
+	}
+
+	assert.Equal(t, 1, backing.gets)
+}
+
+func TestCachingRepository_FlushForcesRead(t *testing.T) {
+	cache, backing := newCachedTestRepository(t)
+	_, _ = cache.GetByID(context.Background(), "test-id")
+
+	// An out-of-band change the cache cannot see.
+	require.NoError(t, backing.ProductRepository.Update(context.Background(), &models.Product{ID: "test-id", Name: "Boot"}))
+
+	stale, _ := cache.GetByID(context.Background(), "test-id")
+	assert.Equal(t, "Shoe", stale.Name)
+
+	assert.Equal(t, 1, cache.Flush())
+
+	fresh, err := cache.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, "Boot", fresh.Name)
+	assert.Equal(t, 2, backing.gets)
+}
+
+func TestCachingRepository_Evict(t *testing.T) {
+	cache, backing := newCachedTestRepository(t)
+	_, _ = cache.GetByID(context.Background(), "test-id")
+
+	assert.True(t, cache.Evict("test-id"))
+	assert.False(t, cache.Evict("test-id"))
+
+	_, _ = cache.GetByID(context.Background(), "test-id")
+	assert.Equal(t, 2, backing.gets)
+}
+
+func TestCachingRepository_WritesEvict(t *testing.T) {
+	cache, backing := newCachedTestRepository(t)
+	_, _ = cache.GetByID(context.Background(), "test-id")
+
+	require.NoError(t, cache.Update(context.Background(), &models.Product{ID: "test-id", Name: "Boot"}))
+
+	product, _ := cache.GetByID(context.Background(), "test-id")
+	assert.Equal(t, "Boot", product.Name)
+	assert.Equal(t, 2, backing.gets)
+}
+
+func TestCachingRepository_Expires(t *testing.T) {
+	cache, backing := newCachedTestRepository(t)
+	now := time.Now()
+	cache.now = func() time.Time { return now }
+	_, _ = cache.GetByID(context.Background(), "test-id")
+
+	now = now.Add(time.Minute)
+	_, _ = cache.GetByID(context.Background(), "test-id")
+
+	assert.Equal(t, 2, backing.gets)
+}
+
+func TestCachingRepository_CopiesProducts(t *testing.T) {
+	cache, _ := newCachedTestRepository(t)
+	ctx := context.Background()
+	require.NoError(t, cache.ProductRepository.Update(ctx, &models.Product{ID: "test-id", Name: "Shoe", Tags: []string{"red"}}))
+
+	first, err := cache.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	first.Tags[0] = "blue"
+
+	second, err := cache.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"red"}, second.Tags)
+	second.Tags[0] = "green"
+
+	third, err := cache.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"red"}, third.Tags)
+}
+
+// racingRepository lets a write through the cache land just after a read
+// has fetched the product, as if the two had raced.
+type racingRepository struct {
+	ProductRepository
+	cache *CachingRepository
+	raced bool
+}
+
+func (r *racingRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	product, err := r.ProductRepository.GetByID(ctx, id)
+	if err != nil || r.raced {
+		return product, err
+	}
+	r.raced = true
+	return product, r.cache.Update(ctx, &models.Product{ID: id, Name: "Boot"})
+}
+
+func TestCachingRepository_DoesNotCacheReadRacingWrite(t *testing.T) {
+	backing := &racingRepository{ProductRepository: NewMemoryProductRepository()}
+	require.NoError(t, backing.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe"}))
+	cache := NewCachingRepository(backing, time.Minute)
+	backing.cache = cache
+
+	stale, err := cache.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, "Shoe", stale.Name)
+
+	fresh, err := cache.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, "Boot", fresh.Name)
+}
@@ -0,0 +1,40 @@
+package repository
+
+import (
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// capacityBudget accumulates the read capacity a scan consumes across its
+// pages. A nil budget is unlimited and never exceeded.
+type capacityBudget struct {
+	limit float64
+	used  float64
+}
+
+// capacityBudget returns the budget for one scan, asking DynamoDB to report
+// the capacity each page of input consumes. It returns nil when no budget is
+// configured, leaving input untouched.
+func (r *productRepository) capacityBudget(input *dynamodb.ScanInput) *capacityBudget {
+	if r.budget <= 0 {
+		return nil
+	}
+	input.ReturnConsumedCapacity = aws.String(dynamodb.ReturnConsumedCapacityTotal)
+	return &capacityBudget{limit: r.budget}
+}
+
+// consume adds a page's consumed capacity and reports whether the budget is
+// now exceeded.
+func (b *capacityBudget) consume(consumed *dynamodb.ConsumedCapacity) bool {
+	if b == nil {
+		return false
+	}
+	if consumed != nil {
+		b.used += aws.Float64Value(consumed.CapacityUnits)
+	}
+	return b.exceeded()
+}
+
+func (b *capacityBudget) exceeded() bool {
+	return b != nil && b.used > b.limit
+}
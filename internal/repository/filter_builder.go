@@ -0,0 +1,210 @@
+package repository
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+)
+
+// reservedWords lists the DynamoDB reserved words that product attributes
+// collide with or are likely to. Attributes named here are referenced through
+// ExpressionAttributeNames. The full list is in the DynamoDB developer guide.
+var reservedWords = map[string]bool{
+	"comment":   true,
+	"count":     true,
+	"data":      true,
+	"date":      true,
+	"key":       true,
+	"name":      true,
+	"order":     true,
+	"region":    true,
+	"size":      true,
+	"source":    true,
+	"status":    true,
+	"stock":     true,
+	"timestamp": true,
+	"type":      true,
+	"unit":      true,
+	"value":     true,
+	"year":      true,
+}
+
+// filterBuilder accumulates conditions joined with AND and produces the
+// expression together with its attribute names and values. Value
+// placeholders are derived from the attribute name, so callers never pick
+// them by hand.
+type filterBuilder struct {
+	conditions []string
+	projection []string
+	names      map[string]*string
+	values     map[string]*dynamodb.AttributeValue
+}
+
+func newFilterBuilder() *filterBuilder {
+	return &filterBuilder{
+		names:  make(map[string]*string),
+		values: make(map[string]*dynamodb.AttributeValue),
+	}
+}
+
+// equal adds "attr = value".
+func (b *filterBuilder) equal(attr string, value *dynamodb.AttributeValue) *filterBuilder {
+	return b.compare(attr, "=", value)
+}
+
+// compare adds "attr op value" for a comparison operator such as >=.
+func (b *filterBuilder) compare(attr, op string, value *dynamodb.AttributeValue) *filterBuilder {
+	b.conditions = append(b.conditions, b.comparison(attr, op, value))
+	return b
+}
+
+// comparison returns "attr op value" without adding it, for use in anyOf.
+func (b *filterBuilder) comparison(attr, op string, value *dynamodb.AttributeValue) string {
+	return fmt.Sprintf("%s %s %s", b.name(attr), op, b.value(attr, value))
+}
+
+// notExists returns "attribute_not_exists(attr)" without adding it, for use
+// in anyOf.
+func (b *filterBuilder) notExists(attr string) string {
+	return fmt.Sprintf("attribute_not_exists(%s)", b.name(attr))
+}
+
+// anyOf adds "(c1 OR c2 ...)".
+func (b *filterBuilder) anyOf(conditions ...string) *filterBuilder {
+	b.conditions = append(b.conditions, "("+strings.Join(conditions, " OR ")+")")
+	return b
+}
+
+// contains adds "contains(attr, value)", which matches substrings of strings
+// and members of lists and sets.
+func (b *filterBuilder) contains(attr string, value *dynamodb.AttributeValue) *filterBuilder {
+	b.conditions = append(b.conditions, fmt.Sprintf("contains(%s, %s)", b.name(attr), b.value(attr, value)))
+	return b
+}
+
+// beginsWith adds "begins_with(attr, value)", a case-sensitive prefix match.
+func (b *filterBuilder) beginsWith(attr string, value *dynamodb.AttributeValue) *filterBuilder {
+	b.conditions = append(b.conditions, fmt.Sprintf("begins_with(%s, %s)", b.name(attr), b.value(attr, value)))
+	return b
+}
+
+// exists adds "attribute_exists(attr)".
+func (b *filterBuilder) exists(attr string) *filterBuilder {
+	b.conditions = append(b.conditions, fmt.Sprintf("attribute_exists(%s)", b.name(attr)))
+	return b
+}
+
+// missing adds "attribute_not_exists(attr)".
+func (b *filterBuilder) missing(attr string) *filterBuilder {
+	b.conditions = append(b.conditions, b.notExists(attr))
+	return b
+}
+
+// equalOrAbsent adds "(attribute_not_exists(attr) OR attr = value)", for
+// attributes that older items may not have.
+func (b *filterBuilder) equalOrAbsent(attr string, value *dynamodb.AttributeValue) *filterBuilder {
+	name := b.name(attr)
+	b.conditions = append(b.conditions, fmt.Sprintf("(attribute_not_exists(%s) OR %s = %s)", name, name, b.value(attr, value)))
+	return b
+}
+
+// atVersion adds a condition that the item is at version. Items written
+// before versioning have no version attribute and count as version 0.
+func (b *filterBuilder) atVersion(version int64) *filterBuilder {
+	if version == 0 {
+		return b.equalOrAbsent("version", numberValue(0))
+	}
+	return b.equal("version", numberValue(float64(version)))
+}
+
+// in adds "attr IN (v1, v2, ...)".
+func (b *filterBuilder) in(attr string, values ...*dynamodb.AttributeValue) *filterBuilder {
+	placeholders := make([]string, 0, len(values))
+	for _, value := range values {
+		placeholders = append(placeholders, b.value(attr, value))
+	}
+	b.conditions = append(b.conditions, fmt.Sprintf("%s IN (%s)", b.name(attr), strings.Join(placeholders, ", ")))
+	return b
+}
+
+// project limits the attributes a scan returns. Reserved words are aliased
+// the same way as in conditions.
+func (b *filterBuilder) project(attrs ...string) *filterBuilder {
+	for _, attr := range attrs {
+		b.projection = append(b.projection, b.name(attr))
+	}
+	return b
+}
+
+// build returns the expression and its maps. The maps are nil when empty,
+// since DynamoDB rejects empty ExpressionAttributeNames/Values.
+func (b *filterBuilder) build() (string, map[string]*string, map[string]*dynamodb.AttributeValue) {
+	names, values := b.names, b.values
+	if len(names) == 0 {
+		names = nil
+	}
+	if len(values) == 0 {
+		values = nil
+	}
+	return strings.Join(b.conditions, " AND "), names, values
+}
+
+// apply sets the built filter and projection on a scan. Parts that were not
+// used are left untouched.
+func (b *filterBuilder) apply(input *dynamodb.ScanInput) *dynamodb.ScanInput {
+	expression, names, values := b.build()
+	if expression != "" {
+		input.FilterExpression = aws.String(expression)
+	}
+	if len(b.projection) > 0 {
+		input.ProjectionExpression = aws.String(strings.Join(b.projection, ", "))
+	}
+	input.ExpressionAttributeNames = names
+	input.ExpressionAttributeValues = values
+	return input
+}
+
+// name returns how attr is referenced in an expression, aliasing reserved
+// words.
+func (b *filterBuilder) name(attr string) string {
+	if !reservedWords[strings.ToLower(attr)] {
+		return attr
+	}
+	alias := "#" + attr
+	b.names[alias] = aws.String(attr)
+	return alias
+}
+
+// alias references key through the name placeholder "#"+placeholder, for
+// names that cannot appear in an expression as written, such as map keys.
+func (b *filterBuilder) alias(placeholder, key string) string {
+	alias := "#" + placeholder
+	b.names[alias] = aws.String(key)
+	return alias
+}
+
+// value registers a value and returns its placeholder, numbering repeats of
+// the same attribute (":price", ":price_2", ...).
+func (b *filterBuilder) value(attr string, value *dynamodb.AttributeValue) string {
+	placeholder := ":" + attr
+	for n := 2; b.values[placeholder] != nil; n++ {
+		placeholder = fmt.Sprintf(":%s_%d", attr, n)
+	}
+	b.values[placeholder] = value
+	return placeholder
+}
+
+func stringValue(s string) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{S: aws.String(s)}
+}
+
+func numberValue(f float64) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{N: aws.String(strconv.FormatFloat(f, 'f', -1, 64))}
+}
+
+func boolValue(v bool) *dynamodb.AttributeValue {
+	return &dynamodb.AttributeValue{BOOL: aws.Bool(v)}
+}
@@ -0,0 +1,97 @@
+package repository
+
+import (
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFilterBuilder_Empty(t *testing.T) {
+	expression, names, values := newFilterBuilder().build()
+
+	assert.Empty(t, expression)
+	assert.Nil(t, names)
+	assert.Nil(t, values)
+
+	input := newFilterBuilder().apply(&dynamodb.ScanInput{TableName: aws.String("t")})
+	assert.Nil(t, input.FilterExpression)
+	assert.Nil(t, input.ExpressionAttributeValues)
+}
+
+func TestFilterBuilder_ReservedWords(t *testing.T) {
+	expression, names, values := newFilterBuilder().
+		equal("name", stringValue("Widget")).
+		compare("stock", ">", numberValue(0)).
+		equal("sku", stringValue("W-1")).
+		build()
+
+	assert.Equal(t, "#name = :name AND #stock > :stock AND sku = :sku", expression)
+	assert.Equal(t, map[string]*string{
+		"#name":  aws.String("name"),
+		"#stock": aws.String("stock"),
+	}, names)
+	assert.Equal(t, "Widget", *values[":name"].S)
+	assert.Equal(t, "0", *values[":stock"].N)
+	assert.Equal(t, "W-1", *values[":sku"].S)
+}
+
+func TestFilterBuilder_RepeatedAttribute(t *testing.T) {
+	expression, names, values := newFilterBuilder().
+		compare("price", ">=", numberValue(1)).
+		compare("price", "<=", numberValue(2.5)).
+		compare("price", "<>", numberValue(2)).
+		build()
+
+	assert.Equal(t, "price >= :price AND price <= :price_2 AND price <> :price_3", expression)
+	assert.Nil(t, names)
+	assert.Equal(t, "1", *values[":price"].N)
+	assert.Equal(t, "2.5", *values[":price_2"].N)
+	assert.Equal(t, "2", *values[":price_3"].N)
+}
+
+func TestFilterBuilder_Contains(t *testing.T) {
+	expression, _, values := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		contains("tags", stringValue("sale")).
+		build()
+
+	assert.Equal(t, "is_active = :is_active AND contains(tags, :tags)", expression)
+	assert.True(t, *values[":is_active"].BOOL)
+	assert.Equal(t, "sale", *values[":tags"].S)
+}
+
+func TestFilterBuilder_BeginsWith(t *testing.T) {
+	expression, names, values := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		beginsWith("name", stringValue("Wid")).
+		build()
+
+	assert.Equal(t, "is_active = :is_active AND begins_with(#name, :name)", expression)
+	assert.Equal(t, map[string]*string{"#name": aws.String("name")}, names)
+	assert.Equal(t, "Wid", *values[":name"].S)
+}
+
+func TestFilterBuilder_ExistsAndIn(t *testing.T) {
+	expression, names, values := newFilterBuilder().
+		exists("id").
+		in("unit", stringValue("kg"), stringValue("g")).
+		build()
+
+	assert.Equal(t, "attribute_exists(id) AND #unit IN (:unit, :unit_2)", expression)
+	assert.Equal(t, map[string]*string{"#unit": aws.String("unit")}, names)
+	assert.Equal(t, "kg", *values[":unit"].S)
+	assert.Equal(t, "g", *values[":unit_2"].S)
+}
+
+func TestFilterBuilder_Projection(t *testing.T) {
+	input := newFilterBuilder().
+		equal("sku", stringValue("W-1")).
+		project("id", "name").
+		apply(&dynamodb.ScanInput{TableName: aws.String("t")})
+
+	assert.Equal(t, "id, #name", *input.ProjectionExpression)
+	assert.Equal(t, "name", *input.ExpressionAttributeNames["#name"])
+	assert.Equal(t, "sku = :sku", *input.FilterExpression)
+}
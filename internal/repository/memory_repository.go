@@ -0,0 +1,519 @@
+package repository
+
+import (
+	"context"
+	"maps"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// memoryRepository is an in-process ProductRepository used by tests and local
+// tooling. It returns copies so callers cannot mutate stored products.
+type memoryRepository struct {
+	mu       sync.RWMutex
+	products map[string]*models.Product
+	order    []string
+}
+
+func NewMemoryProductRepository() ProductRepository {
+	return &memoryRepository{
+		products: make(map[string]*models.Product),
+	}
+}
+
+func (r *memoryRepository) Create(ctx context.Context, product *models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.products[product.ID]; !ok {
+		r.order = append(r.order, product.ID)
+	}
+	stored := *product
+	r.products[product.ID] = &stored
+	return nil
+}
+
+func (r *memoryRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *product
+	return &found, nil
+}
+
+func (r *memoryRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	products := make([]*models.Product, 0, len(ids))
+	for _, id := range ids {
+		if product, ok := r.products[id]; ok {
+			found := *product
+			products = append(products, &found)
+		}
+	}
+	return products, nil
+}
+
+func (r *memoryRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range r.order {
+		if product := r.products[id]; product.SKU == sku {
+			found := *product
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+	wanted := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		wanted[sku] = true
+	}
+	return r.filter(func(p *models.Product) bool {
+		return wanted[p.SKU]
+	}), nil
+}
+
+func (r *memoryRepository) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, id := range r.order {
+		if product := r.products[id]; product.Slug == slug {
+			found := *product
+			return &found, nil
+		}
+	}
+	return nil, nil
+}
+
+func (r *memoryRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	product, err := r.GetBySKU(ctx, sku)
+	return product != nil, err
+}
+
+func (r *memoryRepository) GetAll(ctx context.Context) (*models.ProductList, error) {
+	return &models.ProductList{
+		Products: r.filter(func(p *models.Product) bool {
+			return p.IsActive
+		}),
+	}, nil
+}
+
+// ScanActive delivers every active product as a single page.
+func (r *memoryRepository) ScanActive(ctx context.Context, fn func(page []*models.Product) error) error {
+	page := r.filter(func(p *models.Product) bool {
+		return p.IsActive
+	})
+	if len(page) == 0 {
+		return nil
+	}
+	return fn(page)
+}
+
+func (r *memoryRepository) GetFeatured(ctx context.Context) ([]*models.Product, error) {
+	return r.filter(func(p *models.Product) bool {
+		return p.IsActive && p.Featured
+	}), nil
+}
+
+func (r *memoryRepository) GetInactive(ctx context.Context) ([]*models.Product, error) {
+	return r.filter(func(p *models.Product) bool {
+		return !p.IsActive
+	}), nil
+}
+
+func (r *memoryRepository) GetUpdatedSince(ctx context.Context, since time.Time) (*models.ProductList, error) {
+	return &models.ProductList{
+		Products: r.filter(func(p *models.Product) bool {
+			return !p.UpdatedAt.Before(since)
+		}),
+	}, nil
+}
+
+func (r *memoryRepository) GetByCategory(ctx context.Context, category string) (*models.ProductList, error) {
+	return &models.ProductList{
+		Products: r.filter(func(p *models.Product) bool {
+			return p.IsActive && p.Category == category
+		}),
+	}, nil
+}
+
+func (r *memoryRepository) Filter(ctx context.Context, filter models.ProductFilter) (*models.ProductList, error) {
+	return &models.ProductList{
+		Products: r.filter(func(p *models.Product) bool {
+			return p.IsActive && matchesFilter(p, filter)
+		}),
+	}, nil
+}
+
+func (r *memoryRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*models.Product, error) {
+	var products []*models.Product
+	for _, product := range r.filter(func(p *models.Product) bool {
+		return p.IsActive && strings.HasPrefix(p.Name, prefix)
+	}) {
+		if len(products) == limit {
+			break
+		}
+		products = append(products, &models.Product{ID: product.ID, Name: product.Name})
+	}
+	return products, nil
+}
+
+func (r *memoryRepository) SearchByName(ctx context.Context, query, after string, limit int) ([]*models.Product, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start := 0
+	if after != "" {
+		start = slices.Index(r.order, after) + 1
+	}
+
+	var products []*models.Product
+	for i, id := range r.order[start:] {
+		product := r.products[id]
+		if !product.IsActive || !strings.Contains(product.Name, query) {
+			continue
+		}
+		found := *product
+		products = append(products, &found)
+		if len(products) == limit {
+			if start+i == len(r.order)-1 {
+				return products, "", nil
+			}
+			return products, id, nil
+		}
+	}
+	return products, "", nil
+}
+
+func matchesFilter(p *models.Product, filter models.ProductFilter) bool {
+	if filter.Category != "" && p.Category != filter.Category {
+		return false
+	}
+	if len(filter.Categories) > 0 && !slices.Contains(filter.Categories, p.Category) {
+		return false
+	}
+	if filter.MinPrice != nil && p.Price < *filter.MinPrice {
+		return false
+	}
+	if filter.MaxPrice != nil && p.Price > *filter.MaxPrice {
+		return false
+	}
+	if filter.InStock != nil && p.InStock(filter.AsOf) != *filter.InStock {
+		return false
+	}
+	if filter.Tag != "" && !slices.Contains(p.Tags, filter.Tag) {
+		return false
+	}
+	if filter.Name != "" && !strings.Contains(p.Name, filter.Name) {
+		return false
+	}
+	return true
+}
+
+func (r *memoryRepository) Update(ctx context.Context, product *models.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	current, ok := r.products[product.ID]
+	if !ok || current.Version != product.Version {
+		return ErrVersionConflict
+	}
+	stored := *product
+	stored.ViewCount = current.ViewCount
+	stored.RatingSum, stored.RatingCount = current.RatingSum, current.RatingCount
+	stored.Reservations = current.Reservations
+	stored.Version++
+	r.products[product.ID] = &stored
+	*product = stored
+	return nil
+}
+
+func (r *memoryRepository) Delete(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.delete(id)
+	return nil
+}
+
+func (r *memoryRepository) DeleteVersion(ctx context.Context, id string, version int64) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Version != version {
+		return false, nil
+	}
+	r.delete(id)
+	return true, nil
+}
+
+// delete removes id; callers hold mu.
+func (r *memoryRepository) delete(id string) {
+	if _, ok := r.products[id]; !ok {
+		return
+	}
+	delete(r.products, id)
+	for i, existing := range r.order {
+		if existing == id {
+			r.order = append(r.order[:i], r.order[i+1:]...)
+			break
+		}
+	}
+}
+
+func (r *memoryRepository) IncrementViewCount(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if product, ok := r.products[id]; ok {
+		product.ViewCount++
+	}
+	return nil
+}
+
+func (r *memoryRepository) AddRating(ctx context.Context, id string, rating int) (*models.Product, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, nil
+	}
+	product.RatingSum += int64(rating)
+	product.RatingCount++
+	found := *product
+	return &found, nil
+}
+
+func (r *memoryRepository) SetStock(ctx context.Context, id string, stock float64, followStock bool, actor string, now time.Time) (*models.Product, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return nil, false, nil
+	}
+	restock := followStock && stock > 0
+	settable := product.IsActive || restock && product.StockDeactivated && product.DeletedAt == nil
+	if !settable || product.Stock == stock || !models.IsValidStockForUnit(stock, product.Unit) {
+		found := *product
+		return &found, false, nil
+	}
+	switch {
+	case restock:
+		product.IsActive, product.StockDeactivated = true, false
+	case followStock:
+		product.IsActive, product.StockDeactivated = false, true
+	}
+	product.Stock = stock
+	product.UpdatedAt = now
+	product.StockUpdatedAt = &now
+	product.UpdatedBy = actor
+	product.Version++
+	found := *product
+	return &found, true, nil
+}
+
+func (r *memoryRepository) SetStockActivation(ctx context.Context, id string, active bool) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok {
+		return false, nil
+	}
+	switch {
+	case active && product.StockDeactivated && product.Stock > 0 && product.DeletedAt == nil:
+		product.IsActive, product.StockDeactivated = true, false
+	case !active && product.IsActive && product.Stock <= 0:
+		product.IsActive, product.StockDeactivated = false, true
+	default:
+		return false, nil
+	}
+	product.Version++
+	return true, nil
+}
+
+func (r *memoryRepository) ClearStock(ctx context.Context, id string, version int64, deactivate bool, actor string, now time.Time) (*models.Product, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Version != version {
+		return nil, false, nil
+	}
+	product.Stock = 0
+	product.UpdatedAt = now
+	product.StockUpdatedAt = &now
+	product.UpdatedBy = actor
+	if deactivate {
+		product.IsActive, product.StockDeactivated = false, true
+	}
+	product.Version++
+	found := *product
+	return &found, true, nil
+}
+
+func (r *memoryRepository) SetCounts(ctx context.Context, id string, from, to models.ProductCounts) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Counts() != from {
+		return false, nil
+	}
+	product.ViewCount = to.ViewCount
+	product.RatingSum = to.RatingSum
+	product.RatingCount = to.RatingCount
+	return true, nil
+}
+
+func (r *memoryRepository) SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Version != version {
+		return false, nil
+	}
+	product.Slug = slug
+	product.CategoryPath = slices.Clone(categoryPath)
+	product.Version++
+	return true, nil
+}
+
+func (r *memoryRepository) SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Version != version {
+		return false, nil
+	}
+	product.Category = category
+	product.CategoryPath = slices.Clone(categoryPath)
+	product.UpdatedAt = now
+	product.UpdatedBy = actor
+	product.Version++
+	return true, nil
+}
+
+func (r *memoryRepository) SetBrokenImages(ctx context.Context, id string, images, broken []string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || !slices.Equal(product.Images, images) {
+		return false, nil
+	}
+	product.BrokenImages = nil
+	if len(broken) > 0 {
+		product.BrokenImages = slices.Clone(broken)
+	}
+	return true, nil
+}
+
+func (r *memoryRepository) ReserveStock(ctx context.Context, reservation models.Reservation, now time.Time) (*models.Product, bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[reservation.ProductID]
+	if !ok {
+		return nil, false, nil
+	}
+	if !product.IsActive || product.Stock < reservation.Quantity {
+		found := *product
+		return &found, false, nil
+	}
+	// Replace rather than modify the map, which earlier copies share.
+	reservations := maps.Clone(product.Reservations)
+	if reservations == nil {
+		reservations = make(map[string]models.Reservation)
+	}
+	reservations[reservation.ID] = reservation
+	product.Reservations = reservations
+	product.Stock -= reservation.Quantity
+	product.UpdatedAt = now
+	product.StockUpdatedAt = &now
+	product.Version++
+	found := *product
+	return &found, true, nil
+}
+
+func (r *memoryRepository) ReleaseReservation(ctx context.Context, id, reservationID string, version int64, stock float64, now time.Time) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	product, ok := r.products[id]
+	if !ok || product.Version != version {
+		return false, nil
+	}
+	if _, ok := product.Reservations[reservationID]; !ok {
+		return false, nil
+	}
+	reservations := maps.Clone(product.Reservations)
+	delete(reservations, reservationID)
+	product.Reservations = reservations
+	product.Stock = stock
+	product.UpdatedAt = now
+	product.StockUpdatedAt = &now
+	product.Version++
+	return true, nil
+}
+
+func (r *memoryRepository) GetReserved(ctx context.Context) ([]*models.Product, error) {
+	return r.filter(func(p *models.Product) bool {
+		return p.Reservations != nil
+	}), nil
+}
+
+func (r *memoryRepository) ScanPage(ctx context.Context, after string, limit int) ([]*models.Product, string, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	start := 0
+	if after != "" {
+		start = slices.Index(r.order, after) + 1
+	}
+	end := min(start+limit, len(r.order))
+
+	var page []*models.Product
+	for _, id := range r.order[start:end] {
+		found := *r.products[id]
+		page = append(page, &found)
+	}
+	var next string
+	if end < len(r.order) {
+		next = r.order[end-1]
+	}
+	return page, next, nil
+}
+
+func (r *memoryRepository) filter(match func(*models.Product) bool) []*models.Product {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var products []*models.Product
+	for _, id := range r.order {
+		if product := r.products[id]; match(product) {
+			found := *product
+			products = append(products, &found)
+		}
+	}
+	return products
+}
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"product-service/internal/models"
+)
+
+func TestMemoryRepository_CRUD(t *testing.T) {
+	repo := NewMemoryProductRepository()
+
+	product := createTestProduct()
+	assert.NoError(t, repo.Create(context.Background(), product))
+
+	found, err := repo.GetByID(context.Background(), product.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, product.Name, found.Name)
+
+	found.Name = "Mutated"
+	stored, _ := repo.GetByID(context.Background(), product.ID)
+	assert.Equal(t, "Test Product", stored.Name)
+
+	bySKU, err := repo.GetBySKU(context.Background(), "TEST-001")
+	assert.NoError(t, err)
+	assert.Equal(t, product.ID, bySKU.ID)
+
+	found.IsActive = false
+	assert.NoError(t, repo.Update(context.Background(), found))
+	all, err := repo.GetAll(context.Background())
+	assert.NoError(t, err)
+	assert.Empty(t, all.Products)
+
+	assert.NoError(t, repo.Delete(context.Background(), product.ID))
+	missing, err := repo.GetByID(context.Background(), product.ID)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryRepository_Update_RequiresReadVersion(t *testing.T) {
+	repo := NewMemoryProductRepository()
+	ctx := context.Background()
+	assert.NoError(t, repo.Create(ctx, createTestProduct()))
+
+	first, _ := repo.GetByID(ctx, "test-id")
+	second, _ := repo.GetByID(ctx, "test-id")
+	assert.NoError(t, repo.IncrementViewCount(ctx, "test-id"))
+
+	first.Name = "First"
+	assert.NoError(t, repo.Update(ctx, first))
+	assert.Equal(t, int64(1), first.Version)
+	assert.Equal(t, int64(1), first.ViewCount)
+
+	second.Name = "Second"
+	assert.ErrorIs(t, repo.Update(ctx, second), ErrVersionConflict)
+
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Equal(t, "First", stored.Name)
+	assert.Equal(t, int64(1), stored.ViewCount)
+
+	missing := createTestProduct()
+	missing.ID = "missing"
+	assert.ErrorIs(t, repo.Update(ctx, missing), ErrVersionConflict)
+}
+
+func TestMemoryRepository_GetByCategory(t *testing.T) {
+	repo := NewMemoryProductRepository()
+
+	electronics := createTestProduct()
+	electronics.ID = "id-1"
+	books := createTestProduct()
+	books.ID = "id-2"
+	books.Category = "books"
+
+	repo.Create(context.Background(), electronics)
+	repo.Create(context.Background(), books)
+
+	results, err := repo.GetByCategory(context.Background(), "books")
+
+	assert.NoError(t, err)
+	assert.Len(t, results.Products, 1)
+	assert.Equal(t, "id-2", results.Products[0].ID)
+}
+
+func TestMemoryRepository_IncrementViewCount(t *testing.T) {
+	repo := NewMemoryProductRepository()
+	repo.Create(context.Background(), createTestProduct())
+
+	repo.IncrementViewCount(context.Background(), "test-id")
+	repo.IncrementViewCount(context.Background(), "test-id")
+
+	product, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, int64(2), product.ViewCount)
+}
+
+func TestMemoryRepository_Filter(t *testing.T) {
+	repo := NewMemoryProductRepository()
+	assert.NoError(t, repo.Create(context.Background(), &models.Product{ID: "1", Category: "toys", Price: 5, Stock: 0, IsActive: true, Tags: []string{"sale"}}))
+	assert.NoError(t, repo.Create(context.Background(), &models.Product{ID: "2", Category: "toys", Price: 15, Stock: 3, IsActive: true}))
+	assert.NoError(t, repo.Create(context.Background(), &models.Product{ID: "3", Category: "books", Price: 15, Stock: 3, IsActive: true, Tags: []string{"sale"}}))
+
+	min := 10.0
+	inStock := true
+	list, err := repo.Filter(context.Background(), models.ProductFilter{MinPrice: &min, InStock: &inStock})
+	assert.NoError(t, err)
+	assert.Len(t, list.Products, 2)
+
+	list, err = repo.Filter(context.Background(), models.ProductFilter{Category: "toys", Tag: "sale"})
+	assert.NoError(t, err)
+	if assert.Len(t, list.Products, 1) {
+		assert.Equal(t, "1", list.Products[0].ID)
+	}
+}
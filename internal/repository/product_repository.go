@@ -1,9 +1,18 @@
 package repository
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 
@@ -12,25 +21,131 @@ import (
 )
 
 type ProductRepository interface {
-	Create(product *models.Product) error
-	GetByID(id string) (*models.Product, error)
-	GetAll() ([]*models.Product, error)
-	GetByCategory(category string) ([]*models.Product, error)
-	Update(product *models.Product) error
-	Delete(id string) error
+	Create(ctx context.Context, product *models.Product) error
+	GetByID(ctx context.Context, id string) (*models.Product, error)
+	GetByIDs(ctx context.Context, ids []string) ([]*models.Product, error)
+	GetBySKU(ctx context.Context, sku string) (*models.Product, error)
+	GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error)
+	GetBySlug(ctx context.Context, slug string) (*models.Product, error)
+	ExistsBySKU(ctx context.Context, sku string) (bool, error)
+	GetAll(ctx context.Context) (*models.ProductList, error)
+	ScanActive(ctx context.Context, fn func(page []*models.Product) error) error
+	GetInactive(ctx context.Context) ([]*models.Product, error)
+	GetFeatured(ctx context.Context) ([]*models.Product, error)
+	GetUpdatedSince(ctx context.Context, since time.Time) (*models.ProductList, error)
+	GetByCategory(ctx context.Context, category string) (*models.ProductList, error)
+	Filter(ctx context.Context, filter models.ProductFilter) (*models.ProductList, error)
+	SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*models.Product, error)
+	SearchByName(ctx context.Context, query, after string, limit int) ([]*models.Product, string, error)
+	Update(ctx context.Context, product *models.Product) error
+	Delete(ctx context.Context, id string) error
+	DeleteVersion(ctx context.Context, id string, version int64) (bool, error)
+	SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error)
+	SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error)
+	IncrementViewCount(ctx context.Context, id string) error
+	AddRating(ctx context.Context, id string, rating int) (*models.Product, error)
+	SetStock(ctx context.Context, id string, stock float64, followStock bool, actor string, now time.Time) (*models.Product, bool, error)
+	SetStockActivation(ctx context.Context, id string, active bool) (bool, error)
+	ClearStock(ctx context.Context, id string, version int64, deactivate bool, actor string, now time.Time) (*models.Product, bool, error)
+	SetCounts(ctx context.Context, id string, from, to models.ProductCounts) (bool, error)
+	SetBrokenImages(ctx context.Context, id string, images, broken []string) (bool, error)
+	ReserveStock(ctx context.Context, reservation models.Reservation, now time.Time) (*models.Product, bool, error)
+	ReleaseReservation(ctx context.Context, id, reservationID string, version int64, stock float64, now time.Time) (bool, error)
+	GetReserved(ctx context.Context) ([]*models.Product, error)
+	ScanPage(ctx context.Context, after string, limit int) ([]*models.Product, string, error)
 }
 
+// ErrVersionConflict is returned by Update when the product is missing or
+// has been written since it was read.
+var ErrVersionConflict = errors.New("product was written since it was read")
+
+// productAttributes names the attribute of every models.Product field, in
+// field order, so Update can remove the ones a product no longer has.
+var productAttributes = func() []string {
+	t := reflect.TypeFor[models.Product]()
+	attrs := make([]string, 0, t.NumField())
+	for i := range t.NumField() {
+		attr, _, _ := strings.Cut(t.Field(i).Tag.Get("dynamodbav"), ",")
+		if attr != "" && attr != "-" {
+			attrs = append(attrs, attr)
+		}
+	}
+	return attrs
+}()
+
+// updateSkips are the attributes Update never writes: the key, the version
+// it maintains itself, and attributes with their own atomic writes.
+var updateSkips = map[string]bool{
+	"id":           true,
+	"version":      true,
+	"view_count":   true,
+	"rating_sum":   true,
+	"rating_count": true,
+	"reservations": true,
+}
+
+// DefaultScanMaxItems bounds how many products a single listing scan returns.
+const DefaultScanMaxItems = 10000
+
 type productRepository struct {
-	db *database.DynamoDBClient
+	db          *database.DynamoDBClient
+	maxItems    int
+	segments    int
+	exactCounts bool
+	budget      float64
 }
 
-func NewProductRepository(db *database.DynamoDBClient) ProductRepository {
-	return &productRepository{
-		db: db,
+// RepositoryOption configures optional productRepository behavior.
+type RepositoryOption func(*productRepository)
+
+// WithScanMaxItems caps the number of products a listing scan collects before
+// it stops paging. Zero removes the cap.
+func WithScanMaxItems(max int) RepositoryOption {
+	return func(r *productRepository) {
+		r.maxItems = max
 	}
 }
 
-func (r *productRepository) Create(product *models.Product) error {
+// WithScanSegments splits ScanActive into n parallel scan segments. One, the
+// default, scans sequentially.
+func WithScanSegments(n int) RepositoryOption {
+	return func(r *productRepository) {
+		r.segments = n
+	}
+}
+
+// WithExactCounts makes listing scans that stop at the scan cap count the
+// remaining matches with a Select COUNT scan and report them in
+// ProductList.Matched. The count still reads the rest of the table.
+func WithExactCounts(enabled bool) RepositoryOption {
+	return func(r *productRepository) {
+		r.exactCounts = enabled
+	}
+}
+
+// WithScanCapacityBudget caps the read capacity units a single listing scan
+// may consume across its pages. A scan that goes over stops paging and
+// returns what it has collected with BudgetExceeded set. Zero removes the
+// cap.
+func WithScanCapacityBudget(units float64) RepositoryOption {
+	return func(r *productRepository) {
+		r.budget = units
+	}
+}
+
+func NewProductRepository(db *database.DynamoDBClient, opts ...RepositoryOption) ProductRepository {
+	r := &productRepository{
+		db:       db,
+		maxItems: DefaultScanMaxItems,
+		segments: 1,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+func (r *productRepository) Create(ctx context.Context, product *models.Product) error {
 	item, err := dynamodbattribute.MarshalMap(product)
 	if err != nil {
 		return fmt.Errorf("failed to marshal product: %w", err)
@@ -41,7 +156,7 @@ func (r *productRepository) Create(product *models.Product) error {
 		Item:      item,
 	}
 
-	_, err = r.db.Client.PutItem(input)
+	_, err = r.db.Client.PutItemWithContext(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to create product: %w", err)
 	}
@@ -49,7 +164,7 @@ func (r *productRepository) Create(product *models.Product) error {
 	return nil
 }
 
-func (r *productRepository) GetByID(id string) (*models.Product, error) {
+func (r *productRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
 	input := &dynamodb.GetItemInput{
 		TableName: aws.String(r.db.TableName),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -59,7 +174,7 @@ func (r *productRepository) GetByID(id string) (*models.Product, error) {
 		},
 	}
 
-	result, err := r.db.Client.GetItem(input)
+	result, err := r.db.Client.GetItemWithContext(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
@@ -77,87 +192,681 @@ func (r *productRepository) GetByID(id string) (*models.Product, error) {
 	return &product, nil
 }
 
-func (r *productRepository) GetAll() ([]*models.Product, error) {
-	input := &dynamodb.ScanInput{
-		TableName: aws.String(r.db.TableName),
-		FilterExpression: aws.String("is_active = :active"),
+// maxBatchGetKeys is the most keys DynamoDB accepts in one BatchGetItem.
+const maxBatchGetKeys = 100
+
+// batchGetRetries bounds how many times keys DynamoDB leaves unprocessed,
+// typically under throttling, are requested again.
+const batchGetRetries = 5
+
+// GetByIDs fetches the products with the given IDs, which must not repeat,
+// in batches of up to 100. Like BatchGetItem it returns them in no
+// particular order and leaves out IDs with no product.
+func (r *productRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Product, error) {
+	products := make([]*models.Product, 0, len(ids))
+	for start := 0; start < len(ids); start += maxBatchGetKeys {
+		keys := make([]map[string]*dynamodb.AttributeValue, 0, maxBatchGetKeys)
+		for _, id := range ids[start:min(start+maxBatchGetKeys, len(ids))] {
+			keys = append(keys, map[string]*dynamodb.AttributeValue{"id": stringValue(id)})
+		}
+
+		request := map[string]*dynamodb.KeysAndAttributes{r.db.TableName: {Keys: keys}}
+		for attempt := 0; len(request) > 0; attempt++ {
+			if attempt > batchGetRetries {
+				return nil, fmt.Errorf("failed to batch get products: keys still unprocessed after %d retries", batchGetRetries)
+			}
+			if attempt > 0 {
+				// Unprocessed keys mean the table is throttling; back off.
+				select {
+				case <-ctx.Done():
+					return nil, fmt.Errorf("failed to batch get products: %w", ctx.Err())
+				case <-time.After(time.Duration(attempt) * 50 * time.Millisecond):
+				}
+			}
+
+			result, err := r.db.Client.BatchGetItemWithContext(ctx, &dynamodb.BatchGetItemInput{RequestItems: request})
+			if err != nil {
+				return nil, fmt.Errorf("failed to batch get products: %w", err)
+			}
+			for _, item := range result.Responses[r.db.TableName] {
+				var product models.Product
+				if err := dynamodbattribute.UnmarshalMap(item, &product); err != nil {
+					return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+				}
+				products = append(products, &product)
+			}
+			request = result.UnprocessedKeys
+		}
+	}
+	return products, nil
+}
+
+// maxInOperands is the most values DynamoDB accepts in one IN comparison.
+const maxInOperands = 100
+
+// GetBySKUs fetches the products with the given SKUs, which must not repeat.
+// There is no SKU index, so each batch of up to 100 SKUs is one scan
+// filtered with IN. Products come back in no particular order, and SKUs
+// with no product are left out.
+func (r *productRepository) GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+	var products []*models.Product
+	for start := 0; start < len(skus); start += maxInOperands {
+		values := make([]*dynamodb.AttributeValue, 0, maxInOperands)
+		for _, sku := range skus[start:min(start+maxInOperands, len(skus))] {
+			values = append(values, stringValue(sku))
+		}
+		input := newFilterBuilder().
+			in("sku", values...).
+			apply(&dynamodb.ScanInput{
+				TableName: aws.String(r.db.TableName),
+			})
+
+		for {
+			result, err := r.db.Client.ScanWithContext(ctx, input)
+			if err != nil {
+				return nil, fmt.Errorf("failed to scan products by sku: %w", err)
+			}
+
+			var page []*models.Product
+			if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal products: %w", err)
+			}
+			products = append(products, page...)
+
+			if len(result.LastEvaluatedKey) == 0 {
+				break
+			}
+			input.ExclusiveStartKey = result.LastEvaluatedKey
+		}
+	}
+	return products, nil
+}
+
+func (r *productRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	input := newFilterBuilder().
+		equal("sku", stringValue(sku)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan products by sku: %w", err)
+		}
+
+		if len(result.Items) > 0 {
+			var product models.Product
+			err = dynamodbattribute.UnmarshalMap(result.Items[0], &product)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+			}
+			return &product, nil
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// GetBySlug queries the slug index. Slugs are meant to be unique, but the
+// index cannot enforce it, so the first match is returned.
+func (r *productRepository) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	input := &dynamodb.QueryInput{
+		TableName:              aws.String(r.db.TableName),
+		IndexName:              aws.String(database.SlugIndex),
+		KeyConditionExpression: aws.String("slug = :slug"),
 		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":active": {
-				BOOL: aws.Bool(true),
-			},
+			":slug": stringValue(slug),
 		},
+		Limit: aws.Int64(1),
 	}
 
-	result, err := r.db.Client.Scan(input)
+	result, err := r.db.Client.QueryWithContext(ctx, input)
 	if err != nil {
-		return nil, fmt.Errorf("failed to scan products: %w", err)
+		return nil, fmt.Errorf("failed to query product by slug: %w", err)
+	}
+	if len(result.Items) == 0 {
+		return nil, nil
 	}
 
-	var products []*models.Product
-	for _, item := range result.Items {
-		var product models.Product
-		err = dynamodbattribute.UnmarshalMap(item, &product)
+	var product models.Product
+	if err := dynamodbattribute.UnmarshalMap(result.Items[0], &product); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, nil
+}
+
+// ExistsBySKU projects only the key attribute so the check reads as little
+// data as possible.
+func (r *productRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	input := newFilterBuilder().
+		equal("sku", stringValue(sku)).
+		project("id").
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+			return false, fmt.Errorf("failed to check product sku: %w", err)
+		}
+
+		if len(result.Items) > 0 {
+			return true, nil
 		}
-		products = append(products, &product)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return false, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
 	}
+}
 
-	return products, nil
+func (r *productRepository) GetAll(ctx context.Context) (*models.ProductList, error) {
+	input := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	list, err := r.scanProducts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan products: %w", err)
+	}
+
+	return list, nil
+}
+
+// ScanActive calls fn with each page of active products as it arrives from
+// DynamoDB, stopping at the first error. Unlike the listing methods it is
+// not bounded by the scan cap, so callers must not hold on to the pages.
+//
+// With more than one scan segment the segments are scanned in parallel.
+// fn is still called for one page at a time, but pages arrive in no
+// particular order; callers that need an order must sort afterwards. The
+// first segment to fail cancels the others, and its error is returned once
+// they have stopped.
+func (r *productRepository) ScanActive(ctx context.Context, fn func(page []*models.Product) error) error {
+	newInput := func() *dynamodb.ScanInput {
+		return newFilterBuilder().
+			equal("is_active", boolValue(true)).
+			apply(&dynamodb.ScanInput{
+				TableName: aws.String(r.db.TableName),
+			})
+	}
+
+	if r.segments <= 1 {
+		return r.scanPages(ctx, newInput(), fn)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+	deliver := func(page []*models.Product) error {
+		mu.Lock()
+		defer mu.Unlock()
+		if firstErr != nil {
+			return firstErr
+		}
+		return fn(page)
+	}
+	for segment := 0; segment < r.segments; segment++ {
+		input := newInput()
+		input.Segment = aws.Int64(int64(segment))
+		input.TotalSegments = aws.Int64(int64(r.segments))
+
+		wg.Add(1)
+		go func(segment int) {
+			defer wg.Done()
+			if err := r.scanPages(ctx, input, deliver); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = fmt.Errorf("segment %d: %w", segment, err)
+					cancel()
+				}
+				mu.Unlock()
+			}
+		}(segment)
+	}
+	wg.Wait()
+
+	return firstErr
 }
 
-func (r *productRepository) GetByCategory(category string) ([]*models.Product, error) {
+// ScanPage returns up to limit products of any status, starting after the
+// product with ID after, or at the beginning of the table when after is
+// empty. It also returns the ID to pass as after for the next page, which is
+// empty once the table is exhausted.
+func (r *productRepository) ScanPage(ctx context.Context, after string, limit int) ([]*models.Product, string, error) {
 	input := &dynamodb.ScanInput{
 		TableName: aws.String(r.db.TableName),
-		FilterExpression: aws.String("category = :category AND is_active = :active"),
-		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
-			":category": {
-				S: aws.String(category),
-			},
-			":active": {
-				BOOL: aws.Bool(true),
+		Limit:     aws.Int64(int64(limit)),
+	}
+	if after != "" {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(after),
 			},
-		},
+		}
+	}
+
+	result, err := r.db.Client.ScanWithContext(ctx, input)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to scan products: %w", err)
+	}
+
+	var page []*models.Product
+	if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+		return nil, "", fmt.Errorf("failed to unmarshal products: %w", err)
+	}
+
+	var next string
+	if key := result.LastEvaluatedKey["id"]; key != nil && key.S != nil {
+		next = *key.S
+	}
+	return page, next, nil
+}
+
+// scanPages runs input to the end of its table or segment, calling fn with
+// each non-empty page.
+func (r *productRepository) scanPages(ctx context.Context, input *dynamodb.ScanInput, fn func(page []*models.Product) error) error {
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
+		if err != nil {
+			return fmt.Errorf("failed to scan products: %w", err)
+		}
+
+		var page []*models.Product
+		if err := dynamodbattribute.UnmarshalListOfMaps(result.Items, &page); err != nil {
+			return fmt.Errorf("failed to unmarshal products: %w", err)
+		}
+		if len(page) > 0 {
+			if err := fn(page); err != nil {
+				return err
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+func (r *productRepository) GetInactive(ctx context.Context) ([]*models.Product, error) {
+	input := newFilterBuilder().
+		equal("is_active", boolValue(false)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	list, err := r.scanProducts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan inactive products: %w", err)
 	}
 
-	result, err := r.db.Client.Scan(input)
+	return list.Products, nil
+}
+
+// GetFeatured returns every featured active product. Featured products are
+// few, so unlike the listing methods it is not bounded by the scan cap,
+// which would drop those stored past it.
+func (r *productRepository) GetFeatured(ctx context.Context) ([]*models.Product, error) {
+	input := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		equal("featured", boolValue(true)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	var products []*models.Product
+	err := r.scanPages(ctx, input, func(page []*models.Product) error {
+		products = append(products, page...)
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan featured products: %w", err)
+	}
+
+	return products, nil
+}
+
+// GetUpdatedSince returns the products of any status, soft-deleted ones
+// included, that were last updated at or after since.
+//
+// Timestamps are stored as RFC 3339 strings, which only compare correctly
+// when they share a layout: a fractional second sorts before the whole
+// second it belongs to. The scan therefore filters from the second before
+// since, which can only let extra products through, and the exact bound is
+// applied to what it returns. The comparison relies on timestamps being
+// written in UTC.
+func (r *productRepository) GetUpdatedSince(ctx context.Context, since time.Time) (*models.ProductList, error) {
+	bound := since.UTC().Truncate(time.Second).Add(-time.Second).Format(time.RFC3339)
+	input := newFilterBuilder().
+		compare("updated_at", ">=", stringValue(bound)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	list, err := r.scanProducts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan updated products: %w", err)
+	}
+
+	list.Products = slices.DeleteFunc(list.Products, func(p *models.Product) bool {
+		return p.UpdatedAt.Before(since)
+	})
+	return list, nil
+}
+
+func (r *productRepository) GetByCategory(ctx context.Context, category string) (*models.ProductList, error) {
+	input := newFilterBuilder().
+		equal("category", stringValue(category)).
+		equal("is_active", boolValue(true)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	list, err := r.scanProducts(ctx, input)
 	if err != nil {
 		return nil, fmt.Errorf("failed to scan products by category: %w", err)
 	}
 
+	return list, nil
+}
+
+// Filter returns the active products matching every criterion set in filter.
+func (r *productRepository) Filter(ctx context.Context, filter models.ProductFilter) (*models.ProductList, error) {
+	input := productFilter(filter).apply(&dynamodb.ScanInput{
+		TableName: aws.String(r.db.TableName),
+	})
+
+	list, err := r.scanProducts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan filtered products: %w", err)
+	}
+
+	return list, nil
+}
+
+// SearchByNamePrefix returns up to limit active products whose name starts
+// with prefix, with only their ID and name read. The table has no index on
+// name, so this scans with a begins_with filter and stops as soon as limit
+// matches are found; the matches are therefore not the alphabetically first
+// ones.
+func (r *productRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*models.Product, error) {
+	input := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		beginsWith("name", stringValue(prefix)).
+		project("id", "name").
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	var products []*models.Product
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
+		if err != nil {
+			return nil, fmt.Errorf("failed to scan products by name prefix: %w", err)
+		}
+
+		for _, item := range result.Items {
+			var product models.Product
+			if err := dynamodbattribute.UnmarshalMap(item, &product); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+			}
+			products = append(products, &product)
+			if len(products) == limit {
+				return products, nil
+			}
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return products, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// SearchByName returns up to limit active products whose name contains
+// query, case-sensitively, in scan order, starting after the product with ID
+// after. The scan follows LastEvaluatedKey across pages until limit matches
+// are found. It also returns the ID to pass as after for the next page: the
+// last match returned, or empty once the table is exhausted.
+func (r *productRepository) SearchByName(ctx context.Context, query, after string, limit int) ([]*models.Product, string, error) {
+	input := newFilterBuilder().
+		equal("is_active", boolValue(true)).
+		contains("name", stringValue(query)).
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+	if after != "" {
+		input.ExclusiveStartKey = map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(after),
+			},
+		}
+	}
+
 	var products []*models.Product
-	for _, item := range result.Items {
-		var product models.Product
-		err = dynamodbattribute.UnmarshalMap(item, &product)
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
 		if err != nil {
-			return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+			return nil, "", fmt.Errorf("failed to scan products by name: %w", err)
+		}
+
+		for i, item := range result.Items {
+			var product models.Product
+			if err := dynamodbattribute.UnmarshalMap(item, &product); err != nil {
+				return nil, "", fmt.Errorf("failed to unmarshal product: %w", err)
+			}
+			products = append(products, &product)
+			if len(products) == limit {
+				// Resuming after the last match, rather than at the page's
+				// LastEvaluatedKey, keeps the rest of this page's matches.
+				if i == len(result.Items)-1 && len(result.LastEvaluatedKey) == 0 {
+					return products, "", nil
+				}
+				return products, product.ID, nil
+			}
 		}
-		products = append(products, &product)
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return products, "", nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
 	}
+}
 
-	return products, nil
+// productFilter builds the scan filter for a ProductFilter. The is_active
+// condition is always present, so the expression is never empty.
+func productFilter(filter models.ProductFilter) *filterBuilder {
+	b := newFilterBuilder().equal("is_active", boolValue(true))
+
+	if filter.Category != "" {
+		b.equal("category", stringValue(filter.Category))
+	}
+	if len(filter.Categories) > 0 {
+		var categories []*dynamodb.AttributeValue
+		for _, category := range filter.Categories {
+			categories = append(categories, stringValue(category))
+		}
+		b.in("category", categories...)
+	}
+	if filter.MinPrice != nil {
+		b.compare("price", ">=", numberValue(*filter.MinPrice))
+	}
+	if filter.MaxPrice != nil {
+		b.compare("price", "<=", numberValue(*filter.MaxPrice))
+	}
+	if filter.InStock != nil {
+		// Pre-orders count as out of stock until their available_from,
+		// which is stored as an RFC 3339 UTC string and so compares in order.
+		now := stringValue(filter.AsOf.UTC().Format(time.RFC3339))
+		if *filter.InStock {
+			b.compare("stock", ">", numberValue(0))
+			b.anyOf(b.notExists("available_from"), b.comparison("available_from", "<=", now))
+		} else {
+			b.anyOf(b.comparison("stock", "<=", numberValue(0)), b.comparison("available_from", ">", now))
+		}
+	}
+	if filter.Tag != "" {
+		b.contains("tags", stringValue(filter.Tag))
+	}
+	if filter.Name != "" {
+		b.contains("name", stringValue(filter.Name))
+	}
+
+	return b
 }
 
-func (r *productRepository) Update(product *models.Product) error {
+// scanProducts follows LastEvaluatedKey until the table is exhausted or
+// maxItems matches have been collected, in which case the list is marked
+// truncated. With a capacity budget it also stops once the pages read so
+// far have consumed more than the budget, marking the list BudgetExceeded.
+func (r *productRepository) scanProducts(ctx context.Context, input *dynamodb.ScanInput) (*models.ProductList, error) {
+	list := &models.ProductList{}
+	budget := r.capacityBudget(input)
+	for {
+		result, err := r.db.Client.ScanWithContext(ctx, input)
+		if err != nil {
+			return nil, err
+		}
+		exceeded := budget.consume(result.ConsumedCapacity)
+
+		for i, item := range result.Items {
+			if r.maxItems > 0 && len(list.Products) == r.maxItems {
+				list.Truncated = true
+				return r.countRest(ctx, list, input, budget, len(result.Items)-i, result.LastEvaluatedKey)
+			}
+
+			var product models.Product
+			err = dynamodbattribute.UnmarshalMap(item, &product)
+			if err != nil {
+				return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+			}
+			list.Products = append(list.Products, &product)
+		}
+
+		if len(result.LastEvaluatedKey) == 0 {
+			return list, nil
+		}
+		if r.maxItems > 0 && len(list.Products) == r.maxItems {
+			list.Truncated = true
+			return r.countRest(ctx, list, input, budget, 0, result.LastEvaluatedKey)
+		}
+		if exceeded {
+			list.BudgetExceeded = true
+			return list, nil
+		}
+		input.ExclusiveStartKey = result.LastEvaluatedKey
+	}
+}
+
+// countRest sets Matched on a truncated list when exact counts are enabled:
+// the products collected, the pending matches of the page the cap was hit
+// in, and those counted by a Select COUNT scan resuming at next. The count
+// draws on the same capacity budget as the scan; when that runs out, Matched
+// is left unset and the list is marked BudgetExceeded.
+func (r *productRepository) countRest(ctx context.Context, list *models.ProductList, input *dynamodb.ScanInput, budget *capacityBudget, pending int, next map[string]*dynamodb.AttributeValue) (*models.ProductList, error) {
+	if !r.exactCounts {
+		return list, nil
+	}
+
+	matched := len(list.Products) + pending
+	count := *input
+	count.Select = aws.String(dynamodb.SelectCount)
+	for len(next) > 0 {
+		if budget.exceeded() {
+			list.BudgetExceeded = true
+			return list, nil
+		}
+		count.ExclusiveStartKey = next
+		result, err := r.db.Client.ScanWithContext(ctx, &count)
+		if err != nil {
+			return nil, fmt.Errorf("failed to count products: %w", err)
+		}
+		budget.consume(result.ConsumedCapacity)
+		matched += int(aws.Int64Value(result.Count))
+		next = result.LastEvaluatedKey
+	}
+	list.Matched = matched
+	return list, nil
+}
+
+// Update writes the product provided it is still at the version it was read
+// at, and increments its Version. The view and rating counters and the
+// reservations are left out, since they have their own atomic writes that an
+// edit must not undo. It returns
+// ErrVersionConflict when the product is missing or has been written since
+// it was read, so the caller can re-read and retry; otherwise product is
+// refreshed from the stored item.
+func (r *productRepository) Update(ctx context.Context, product *models.Product) error {
 	item, err := dynamodbattribute.MarshalMap(product)
 	if err != nil {
 		return fmt.Errorf("failed to marshal product: %w", err)
 	}
 
-	input := &dynamodb.PutItemInput{
+	condition := newFilterBuilder().exists("id").atVersion(product.Version)
+	var set, remove []string
+	for _, attr := range productAttributes {
+		if updateSkips[attr] {
+			continue
+		}
+		if value, ok := item[attr]; ok {
+			set = append(set, condition.name(attr)+" = "+condition.value(attr, value))
+		} else {
+			remove = append(remove, condition.name(attr))
+		}
+	}
+	set = append(set, "version = "+condition.value("version", numberValue(float64(product.Version+1))))
+	update := "SET " + strings.Join(set, ", ")
+	if len(remove) > 0 {
+		update += " REMOVE " + strings.Join(remove, ", ")
+	}
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
 		TableName: aws.String(r.db.TableName),
-		Item:      item,
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(product.ID),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
 	}
 
-	_, err = r.db.Client.PutItem(input)
+	result, err := r.db.Client.UpdateItemWithContext(ctx, input)
 	if err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return ErrVersionConflict
+		}
 		return fmt.Errorf("failed to update product: %w", err)
 	}
 
+	var stored models.Product
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &stored); err != nil {
+		return fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	*product = stored
 	return nil
 }
 
-func (r *productRepository) Delete(id string) error {
+func (r *productRepository) Delete(ctx context.Context, id string) error {
 	input := &dynamodb.DeleteItemInput{
 		TableName: aws.String(r.db.TableName),
 		Key: map[string]*dynamodb.AttributeValue{
@@ -167,10 +876,655 @@ func (r *productRepository) Delete(id string) error {
 		},
 	}
 
-	_, err := r.db.Client.DeleteItem(input)
+	_, err := r.db.Client.DeleteItemWithContext(ctx, input)
 	if err != nil {
 		return fmt.Errorf("failed to delete product: %w", err)
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// DeleteVersion deletes the product only while it is still at version. It
+// reports false when the product is missing or has been written since.
+func (r *productRepository) DeleteVersion(ctx context.Context, id string, version int64) (bool, error) {
+	condition := newFilterBuilder().exists("id").atVersion(version)
+	expression, names, values := condition.build()
+
+	input := &dynamodb.DeleteItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.DeleteItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to delete product: %w", err)
+	}
+	return true, nil
+}
+
+// SetDerivedFields stores the product's slug and category path, removing
+// either when empty, in a single update conditional on the product still
+// being at version. The version is bumped so an edit that read the old
+// values re-reads rather than writing them back. It reports false when the
+// product is missing or has been written since.
+func (r *productRepository) SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error) {
+	condition := newFilterBuilder().exists("id").atVersion(version)
+	var set, remove []string
+	if slug != "" {
+		set = append(set, "slug = "+condition.value("slug", stringValue(slug)))
+	} else {
+		remove = append(remove, "slug")
+	}
+	if len(categoryPath) > 0 {
+		path, err := dynamodbattribute.Marshal(categoryPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal category path: %w", err)
+		}
+		set = append(set, "category_path = "+condition.value("category_path", path))
+	} else {
+		remove = append(remove, "category_path")
+	}
+	set = append(set, "version = "+condition.value("version", numberValue(float64(version+1))))
+	update := "SET " + strings.Join(set, ", ")
+	if len(remove) > 0 {
+		update += " REMOVE " + strings.Join(remove, ", ")
+	}
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set derived fields: %w", err)
+	}
+	return true, nil
+}
+
+// SetCategory moves the product to category, recording its category path
+// or removing it when empty, in a single update conditional on the product
+// still being at version. Nothing else on the item is written. It reports
+// false when the product is missing or has been written since.
+func (r *productRepository) SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error) {
+	timestamp, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	condition := newFilterBuilder().exists("id").atVersion(version)
+	set := []string{
+		"category = " + condition.value("category", stringValue(category)),
+	}
+	var remove string
+	if len(categoryPath) > 0 {
+		path, err := dynamodbattribute.Marshal(categoryPath)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal category path: %w", err)
+		}
+		set = append(set, "category_path = "+condition.value("category_path", path))
+	} else {
+		remove = " REMOVE category_path"
+	}
+	set = append(set,
+		"updated_at = "+condition.value("updated_at", timestamp),
+		"updated_by = "+condition.value("updated_by", stringValue(actor)),
+		"version = "+condition.value("version", numberValue(float64(version+1))),
+	)
+	update := "SET " + strings.Join(set, ", ") + remove
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set category: %w", err)
+	}
+	return true, nil
+}
+
+// IncrementViewCount atomically adds one to the product's view count. The
+// condition keeps the update from creating an item for an unknown ID.
+func (r *productRepository) IncrementViewCount(ctx context.Context, id string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:    aws.String("ADD view_count :one"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {
+				N: aws.String("1"),
+			},
+		},
+	}
+
+	_, err := r.db.Client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		return fmt.Errorf("failed to increment view count: %w", err)
+	}
+
+	return nil
+}
+
+// AddRating adds rating to the product's running sum and count in a single
+// atomic update and returns the updated product. It returns nil when the
+// product does not exist.
+func (r *productRepository) AddRating(ctx context.Context, id string, rating int) (*models.Product, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:    aws.String("ADD rating_sum :rating, rating_count :one"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":rating": {
+				N: aws.String(strconv.Itoa(rating)),
+			},
+			":one": {
+				N: aws.String("1"),
+			},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	result, err := r.db.Client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		var aerr awserr.Error
+		if errors.As(err, &aerr) && aerr.Code() == dynamodb.ErrCodeConditionalCheckFailedException {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to add rating: %w", err)
+	}
+
+	var product models.Product
+	err = dynamodbattribute.UnmarshalMap(result.Attributes, &product)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+
+	return &product, nil
+}
+
+// SetStock sets an active product's stock in a single conditional update,
+// without reading it first. It reports whether the stock was written and
+// returns the product as stored afterwards. The write is skipped when the
+// product is inactive, already holds stock, or counts in whole units while
+// stock is fractional; the product is then returned unchanged so the caller
+// can tell which. A missing product yields nil.
+//
+// With followStock the out-of-stock policy is applied in the same write:
+// selling an active product out deactivates it as sold out, and restocking
+// a product deactivated that way reactivates it.
+func (r *productRepository) SetStock(ctx context.Context, id string, stock float64, followStock bool, actor string, now time.Time) (*models.Product, bool, error) {
+	timestamp, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	condition := newFilterBuilder().exists("id")
+	restock := followStock && stock > 0
+	if restock {
+		condition.anyOf(
+			condition.comparison("is_active", "=", boolValue(true)),
+			"("+condition.comparison("stock_deactivated", "=", boolValue(true))+" AND "+condition.notExists("deleted_at")+")",
+		)
+	} else {
+		condition.equal("is_active", boolValue(true))
+	}
+	condition.compare("stock", "<>", numberValue(stock))
+	if !models.IsValidStockForUnit(stock, models.UnitEach) {
+		var units []*dynamodb.AttributeValue
+		for _, unit := range models.FractionalUnits() {
+			units = append(units, stringValue(unit))
+		}
+		condition.in("unit", units...)
+	}
+	update := fmt.Sprintf("SET %s = %s, updated_at = %s, stock_updated_at = %s, updated_by = %s",
+		condition.name("stock"), condition.value("stock", numberValue(stock)),
+		condition.value("updated_at", timestamp), condition.value("stock_updated_at", timestamp),
+		condition.value("updated_by", stringValue(actor)),
+	)
+	switch {
+	case restock:
+		update += ", is_active = " + condition.value("is_active", boolValue(true)) + " REMOVE stock_deactivated"
+	case followStock:
+		update += fmt.Sprintf(", is_active = %s, stock_deactivated = %s",
+			condition.value("is_active", boolValue(false)), condition.value("stock_deactivated", boolValue(true)))
+	}
+	update += " ADD version " + condition.value("version", numberValue(1))
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:                    aws.String(update),
+		ConditionExpression:                 aws.String(expression),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValues:                        aws.String(dynamodb.ReturnValueAllNew),
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+
+	result, err := r.db.Client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if !errors.As(err, &failed) {
+			return nil, false, fmt.Errorf("failed to set stock: %w", err)
+		}
+		if len(failed.Item) == 0 {
+			return nil, false, nil
+		}
+		var product models.Product
+		if err := dynamodbattribute.UnmarshalMap(failed.Item, &product); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal product: %w", err)
+		}
+		return &product, false, nil
+	}
+
+	var product models.Product
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &product); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, true, nil
+}
+
+// SetStockActivation applies the out-of-stock policy after a stock change
+// that did not read the product first, such as a reservation. With active
+// false it deactivates an active product that has no stock left, marking it
+// sold out; with active true it reactivates a product deactivated that way
+// once it has stock again. It reports whether the product changed.
+func (r *productRepository) SetStockActivation(ctx context.Context, id string, active bool) (bool, error) {
+	condition := newFilterBuilder()
+	var update string
+	if active {
+		condition.
+			equal("stock_deactivated", boolValue(true)).
+			compare("stock", ">", numberValue(0)).
+			missing("deleted_at")
+		update = "SET is_active = " + condition.value("is_active", boolValue(true)) + " REMOVE stock_deactivated"
+	} else {
+		condition.
+			equal("is_active", boolValue(true)).
+			compare("stock", "<=", numberValue(0))
+		update = fmt.Sprintf("SET is_active = %s, stock_deactivated = %s",
+			condition.value("is_active", boolValue(false)), condition.value("stock_deactivated", boolValue(true)))
+	}
+	update += " ADD version " + condition.value("version", numberValue(1))
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set stock activation: %w", err)
+	}
+	return true, nil
+}
+
+// ClearStock sets the product's stock to zero, and with deactivate takes it
+// off sale as sold out, in a single update conditional on the product still
+// being at version. It reports false when the product is missing or has been
+// written since, so the caller can re-read and retry.
+func (r *productRepository) ClearStock(ctx context.Context, id string, version int64, deactivate bool, actor string, now time.Time) (*models.Product, bool, error) {
+	timestamp, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	condition := newFilterBuilder().exists("id").atVersion(version)
+	update := fmt.Sprintf("SET %s = %s, updated_at = %s, stock_updated_at = %s, updated_by = %s",
+		condition.name("stock"), condition.value("stock", numberValue(0)),
+		condition.value("updated_at", timestamp), condition.value("stock_updated_at", timestamp),
+		condition.value("updated_by", stringValue(actor)),
+	)
+	if deactivate {
+		update += fmt.Sprintf(", is_active = %s, stock_deactivated = %s",
+			condition.value("is_active", boolValue(false)), condition.value("stock_deactivated", boolValue(true)))
+	}
+	update += " ADD version " + condition.value("version", numberValue(1))
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+		ReturnValues:              aws.String(dynamodb.ReturnValueAllNew),
+	}
+
+	result, err := r.db.Client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return nil, false, nil
+		}
+		return nil, false, fmt.Errorf("failed to clear stock: %w", err)
+	}
+
+	var product models.Product
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &product); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, true, nil
+}
+
+// SetCounts overwrites the product's counters with to, provided they still
+// hold from. It reports false when the product is missing or an increment
+// has landed since from was read, so the caller can re-read and retry.
+func (r *productRepository) SetCounts(ctx context.Context, id string, from, to models.ProductCounts) (bool, error) {
+	condition := newFilterBuilder().exists("id")
+	for _, counter := range []struct {
+		attr string
+		from int64
+	}{
+		{"view_count", from.ViewCount},
+		{"rating_sum", from.RatingSum},
+		{"rating_count", int64(from.RatingCount)},
+	} {
+		// Items written before a counter existed have no attribute for it.
+		if counter.from == 0 {
+			condition.equalOrAbsent(counter.attr, numberValue(0))
+		} else {
+			condition.equal(counter.attr, numberValue(float64(counter.from)))
+		}
+	}
+	update := fmt.Sprintf("SET view_count = %s, rating_sum = %s, rating_count = %s",
+		condition.value("view_count", numberValue(float64(to.ViewCount))),
+		condition.value("rating_sum", numberValue(float64(to.RatingSum))),
+		condition.value("rating_count", numberValue(float64(to.RatingCount))),
+	)
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set counts: %w", err)
+	}
+	return true, nil
+}
+
+// SetBrokenImages records which of the product's images failed a
+// reachability check. The write is conditional on the product still having
+// exactly images, so a check that raced an image change is discarded; it
+// reports whether the write happened. Version and UpdatedAt are left alone
+// because the flags are derived rather than edited.
+func (r *productRepository) SetBrokenImages(ctx context.Context, id string, images, broken []string) (bool, error) {
+	current, err := dynamodbattribute.Marshal(images)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal images: %w", err)
+	}
+	condition := newFilterBuilder().equal("images", current)
+	update := "REMOVE broken_images"
+	if len(broken) > 0 {
+		flagged, err := dynamodbattribute.Marshal(broken)
+		if err != nil {
+			return false, fmt.Errorf("failed to marshal broken images: %w", err)
+		}
+		update = "SET broken_images = " + condition.value("broken_images", flagged)
+	}
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to set broken images: %w", err)
+	}
+	return true, nil
+}
+
+// ReserveStock takes the reservation's quantity off an active product's stock
+// and records the reservation, in one conditional update. It reports whether
+// the reservation was made; when the product is inactive or holds too little
+// stock it is returned unchanged so the caller can tell which. A missing
+// product yields nil.
+func (r *productRepository) ReserveStock(ctx context.Context, reservation models.Reservation, now time.Time) (*models.Product, bool, error) {
+	if err := r.ensureReservations(ctx, reservation.ProductID); err != nil {
+		return nil, false, err
+	}
+
+	item, err := dynamodbattribute.Marshal(reservation)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal reservation: %w", err)
+	}
+	timestamp, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	condition := newFilterBuilder().
+		exists("id").
+		equal("is_active", boolValue(true)).
+		compare("stock", ">=", numberValue(reservation.Quantity))
+	stock := condition.name("stock")
+	update := fmt.Sprintf("SET %s = %s - %s, reservations.%s = %s, updated_at = %s, stock_updated_at = %s ADD version %s",
+		stock, stock, condition.value("quantity", numberValue(reservation.Quantity)),
+		condition.alias("reservation", reservation.ID), condition.value("reservation", item),
+		condition.value("updated_at", timestamp), condition.value("stock_updated_at", timestamp),
+		condition.value("version", numberValue(1)),
+	)
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(reservation.ProductID),
+			},
+		},
+		UpdateExpression:                    aws.String(update),
+		ConditionExpression:                 aws.String(expression),
+		ExpressionAttributeNames:            names,
+		ExpressionAttributeValues:           values,
+		ReturnValues:                        aws.String(dynamodb.ReturnValueAllNew),
+		ReturnValuesOnConditionCheckFailure: aws.String(dynamodb.ReturnValuesOnConditionCheckFailureAllOld),
+	}
+
+	result, err := r.db.Client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if !errors.As(err, &failed) {
+			return nil, false, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+		if len(failed.Item) == 0 {
+			return nil, false, nil
+		}
+		var product models.Product
+		if err := dynamodbattribute.UnmarshalMap(failed.Item, &product); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal product: %w", err)
+		}
+		return &product, false, nil
+	}
+
+	var product models.Product
+	if err := dynamodbattribute.UnmarshalMap(result.Attributes, &product); err != nil {
+		return nil, false, fmt.Errorf("failed to unmarshal product: %w", err)
+	}
+	return &product, true, nil
+}
+
+// ensureReservations gives the product an empty reservations map if it has
+// none, since DynamoDB cannot set a key inside a map that does not exist.
+// A missing product is left for the caller's own condition to report.
+func (r *productRepository) ensureReservations(ctx context.Context, id string) error {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:    aws.String("SET reservations = if_not_exists(reservations, :empty)"),
+		ConditionExpression: aws.String("attribute_exists(id)"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":empty": {M: map[string]*dynamodb.AttributeValue{}},
+		},
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return nil
+		}
+		return fmt.Errorf("failed to prepare reservations: %w", err)
+	}
+	return nil
+}
+
+// ReleaseReservation removes a reservation and sets the product's stock to
+// stock, the caller having added the reserved quantity back, in a single
+// update conditional on the product still being at version. It reports
+// false when the product or the reservation no longer exists, or the
+// product has been written since, so releasing twice restores the stock
+// only once.
+func (r *productRepository) ReleaseReservation(ctx context.Context, id, reservationID string, version int64, stock float64, now time.Time) (bool, error) {
+	timestamp, err := dynamodbattribute.Marshal(now)
+	if err != nil {
+		return false, fmt.Errorf("failed to marshal timestamp: %w", err)
+	}
+
+	condition := newFilterBuilder().exists("id").atVersion(version)
+	reservation := "reservations." + condition.alias("reservation", reservationID)
+	condition.exists(reservation)
+	update := fmt.Sprintf("SET %s = %s, updated_at = %s, stock_updated_at = %s, version = %s REMOVE %s",
+		condition.name("stock"), condition.value("stock", numberValue(stock)),
+		condition.value("updated_at", timestamp), condition.value("stock_updated_at", timestamp),
+		condition.value("version", numberValue(float64(version+1))), reservation,
+	)
+	expression, names, values := condition.build()
+
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.db.TableName),
+		Key: map[string]*dynamodb.AttributeValue{
+			"id": {
+				S: aws.String(id),
+			},
+		},
+		UpdateExpression:          aws.String(update),
+		ConditionExpression:       aws.String(expression),
+		ExpressionAttributeNames:  names,
+		ExpressionAttributeValues: values,
+	}
+
+	if _, err := r.db.Client.UpdateItemWithContext(ctx, input); err != nil {
+		var failed *dynamodb.ConditionalCheckFailedException
+		if errors.As(err, &failed) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to release reservation: %w", err)
+	}
+	return true, nil
+}
+
+// GetReserved returns the products that have, or have had, stock
+// reservations.
+func (r *productRepository) GetReserved(ctx context.Context) ([]*models.Product, error) {
+	input := newFilterBuilder().
+		exists("reservations").
+		apply(&dynamodb.ScanInput{
+			TableName: aws.String(r.db.TableName),
+		})
+
+	list, err := r.scanProducts(ctx, input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan reserved products: %w", err)
+	}
+
+	return list.Products, nil
+}
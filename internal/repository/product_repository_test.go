@@ -1,14 +1,21 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/awserr"
+	"github.com/aws/aws-sdk-go/aws/request"
 	"github.com/aws/aws-sdk-go/service/dynamodb"
 	"github.com/aws/aws-sdk-go/service/dynamodb/dynamodbattribute"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
 	"product-service/internal/database"
 	"product-service/internal/models"
@@ -18,22 +25,37 @@ type MockDynamoDBClient struct {
 	mock.Mock
 }
 
-func (m *MockDynamoDBClient) PutItem(input *dynamodb.PutItemInput) (*dynamodb.PutItemOutput, error) {
+func (m *MockDynamoDBClient) PutItemWithContext(ctx aws.Context, input *dynamodb.PutItemInput, opts ...request.Option) (*dynamodb.PutItemOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*dynamodb.PutItemOutput), args.Error(1)
 }
 
-func (m *MockDynamoDBClient) GetItem(input *dynamodb.GetItemInput) (*dynamodb.GetItemOutput, error) {
+func (m *MockDynamoDBClient) GetItemWithContext(ctx aws.Context, input *dynamodb.GetItemInput, opts ...request.Option) (*dynamodb.GetItemOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*dynamodb.GetItemOutput), args.Error(1)
 }
 
-func (m *MockDynamoDBClient) Scan(input *dynamodb.ScanInput) (*dynamodb.ScanOutput, error) {
+func (m *MockDynamoDBClient) BatchGetItemWithContext(ctx aws.Context, input *dynamodb.BatchGetItemInput, opts ...request.Option) (*dynamodb.BatchGetItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.BatchGetItemOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBClient) UpdateItemWithContext(ctx aws.Context, input *dynamodb.UpdateItemInput, opts ...request.Option) (*dynamodb.UpdateItemOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.UpdateItemOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBClient) QueryWithContext(ctx aws.Context, input *dynamodb.QueryInput, opts ...request.Option) (*dynamodb.QueryOutput, error) {
+	args := m.Called(input)
+	return args.Get(0).(*dynamodb.QueryOutput), args.Error(1)
+}
+
+func (m *MockDynamoDBClient) ScanWithContext(ctx aws.Context, input *dynamodb.ScanInput, opts ...request.Option) (*dynamodb.ScanOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*dynamodb.ScanOutput), args.Error(1)
 }
 
-func (m *MockDynamoDBClient) DeleteItem(input *dynamodb.DeleteItemInput) (*dynamodb.DeleteItemOutput, error) {
+func (m *MockDynamoDBClient) DeleteItemWithContext(ctx aws.Context, input *dynamodb.DeleteItemInput, opts ...request.Option) (*dynamodb.DeleteItemOutput, error) {
 	args := m.Called(input)
 	return args.Get(0).(*dynamodb.DeleteItemOutput), args.Error(1)
 }
@@ -63,9 +85,9 @@ func TestProductRepository_Create_Success(t *testing.T) {
 
 	product := createTestProduct()
 
-	mockClient.On("PutItem", mock.AnythingOfType("*dynamodb.PutItemInput")).Return(&dynamodb.PutItemOutput{}, nil)
+	mockClient.On("PutItemWithContext", mock.AnythingOfType("*dynamodb.PutItemInput")).Return(&dynamodb.PutItemOutput{}, nil)
 
-	err := repo.Create(product)
+	err := repo.Create(context.Background(), product)
 
 	assert.NoError(t, err)
 	mockClient.AssertExpectations(t)
@@ -86,12 +108,12 @@ func TestProductRepository_GetByID_Success(t *testing.T) {
 		Item: item,
 	}
 
-	mockClient.On("GetItem", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
-		return *input.TableName == "test-table" && 
-			   *input.Key["id"].S == "test-id"
+	mockClient.On("GetItemWithContext", mock.MatchedBy(func(input *dynamodb.GetItemInput) bool {
+		return *input.TableName == "test-table" &&
+			*input.Key["id"].S == "test-id"
 	})).Return(output, nil)
 
-	result, err := repo.GetByID("test-id")
+	result, err := repo.GetByID(context.Background(), "test-id")
 
 	assert.NoError(t, err)
 	assert.NotNil(t, result)
@@ -100,6 +122,80 @@ func TestProductRepository_GetByID_Success(t *testing.T) {
 	mockClient.AssertExpectations(t)
 }
 
+func TestProductRepository_GetByIDs_RetriesUnprocessedKeys(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item := func(id string) map[string]*dynamodb.AttributeValue {
+		marshaled, _ := dynamodbattribute.MarshalMap(&models.Product{ID: id})
+		return marshaled
+	}
+	key := func(id string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}}
+	}
+	requested := func(ids ...string) interface{} {
+		return mock.MatchedBy(func(input *dynamodb.BatchGetItemInput) bool {
+			keys := input.RequestItems["test-table"].Keys
+			if len(keys) != len(ids) {
+				return false
+			}
+			for i, id := range ids {
+				if *keys[i]["id"].S != id {
+					return false
+				}
+			}
+			return true
+		})
+	}
+
+	mockClient.On("BatchGetItemWithContext", requested("a", "b", "c")).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{"test-table": {item("c")}},
+		UnprocessedKeys: map[string]*dynamodb.KeysAndAttributes{
+			"test-table": {Keys: []map[string]*dynamodb.AttributeValue{key("a"), key("b")}},
+		},
+	}, nil).Once()
+	mockClient.On("BatchGetItemWithContext", requested("a", "b")).Return(&dynamodb.BatchGetItemOutput{
+		Responses: map[string][]map[string]*dynamodb.AttributeValue{"test-table": {item("a")}},
+	}, nil).Once()
+
+	products, err := repo.GetByIDs(context.Background(), []string{"a", "b", "c"})
+
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	assert.Equal(t, "c", products[0].ID)
+	assert.Equal(t, "a", products[1].ID)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetBySKUs_ScansWithInFilter(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item, _ := dynamodbattribute.MarshalMap(&models.Product{ID: "a", SKU: "ELEC-001"})
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "sku IN (:sku, :sku_2)" &&
+			*input.ExpressionAttributeValues[":sku"].S == "ELEC-001" &&
+			*input.ExpressionAttributeValues[":sku_2"].S == "TOY-404"
+	})).Return(&dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{item},
+	}, nil).Once()
+
+	products, err := repo.GetBySKUs(context.Background(), []string{"ELEC-001", "TOY-404"})
+
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "a", products[0].ID)
+	mockClient.AssertExpectations(t)
+}
+
 func TestProductRepository_GetByID_NotFound(t *testing.T) {
 	mockClient := new(MockDynamoDBClient)
 	db := &database.DynamoDBClient{
@@ -112,9 +208,9 @@ func TestProductRepository_GetByID_NotFound(t *testing.T) {
 		Item: nil,
 	}
 
-	mockClient.On("GetItem", mock.AnythingOfType("*dynamodb.GetItemInput")).Return(output, nil)
+	mockClient.On("GetItemWithContext", mock.AnythingOfType("*dynamodb.GetItemInput")).Return(output, nil)
 
-	result, err := repo.GetByID("nonexistent-id")
+	result, err := repo.GetByID(context.Background(), "nonexistent-id")
 
 	assert.NoError(t, err)
 	assert.Nil(t, result)
@@ -144,18 +240,19 @@ func TestProductRepository_GetAll_Success(t *testing.T) {
 		},
 	}
 
-	mockClient.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
 		return *input.TableName == "test-table" &&
-			   input.FilterExpression != nil &&
-			   *input.FilterExpression == "is_active = :active"
+			input.FilterExpression != nil &&
+			*input.FilterExpression == "is_active = :is_active"
 	})).Return(output, nil)
 
-	results, err := repo.GetAll()
+	results, err := repo.GetAll(context.Background())
 
 	assert.NoError(t, err)
-	assert.Len(t, results, 2)
-	assert.Equal(t, "id-1", results[0].ID)
-	assert.Equal(t, "id-2", results[1].ID)
+	assert.Len(t, results.Products, 2)
+	assert.Equal(t, "id-1", results.Products[0].ID)
+	assert.Equal(t, "id-2", results.Products[1].ID)
+	assert.False(t, results.Truncated)
 	mockClient.AssertExpectations(t)
 }
 
@@ -176,18 +273,18 @@ func TestProductRepository_GetByCategory_Success(t *testing.T) {
 		},
 	}
 
-	mockClient.On("Scan", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
 		return *input.TableName == "test-table" &&
-			   input.FilterExpression != nil &&
-			   *input.FilterExpression == "category = :category AND is_active = :active" &&
-			   *input.ExpressionAttributeValues[":category"].S == "electronics"
+			input.FilterExpression != nil &&
+			*input.FilterExpression == "category = :category AND is_active = :is_active" &&
+			*input.ExpressionAttributeValues[":category"].S == "electronics"
 	})).Return(output, nil)
 
-	results, err := repo.GetByCategory("electronics")
+	results, err := repo.GetByCategory(context.Background(), "electronics")
 
 	assert.NoError(t, err)
-	assert.Len(t, results, 1)
-	assert.Equal(t, "electronics", results[0].Category)
+	assert.Len(t, results.Products, 1)
+	assert.Equal(t, "electronics", results.Products[0].Category)
 	mockClient.AssertExpectations(t)
 }
 
@@ -200,12 +297,50 @@ func TestProductRepository_Update_Success(t *testing.T) {
 	repo := NewProductRepository(db)
 
 	product := createTestProduct()
+	product.Version = 3
+	stored := *product
+	stored.Version = 4
+	stored.ViewCount = 12
+	item, _ := dynamodbattribute.MarshalMap(&stored)
 
-	mockClient.On("PutItem", mock.AnythingOfType("*dynamodb.PutItemInput")).Return(&dynamodb.PutItemOutput{}, nil)
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version" &&
+			*input.ExpressionAttributeValues[":version"].N == "3" &&
+			*input.ExpressionAttributeValues[":version_2"].N == "4" &&
+			strings.HasPrefix(*input.UpdateExpression, "SET #name = :name, description = :description, ") &&
+			strings.Contains(*input.UpdateExpression, ", version = :version_2 REMOVE tags, ") &&
+			!strings.Contains(*input.UpdateExpression, "view_count") &&
+			!strings.Contains(*input.UpdateExpression, "rating_") &&
+			!strings.Contains(*input.UpdateExpression, "reservations") &&
+			!strings.Contains(*input.UpdateExpression, " id = ")
+	})).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
 
-	err := repo.Update(product)
+	err := repo.Update(context.Background(), product)
 
 	assert.NoError(t, err)
+	assert.Equal(t, int64(4), product.Version)
+	assert.Equal(t, int64(12), product.ViewCount)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_Update_Conflict(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(id) AND (attribute_not_exists(version) OR version = :version)"
+	})).Return((*dynamodb.UpdateItemOutput)(nil), &dynamodb.ConditionalCheckFailedException{})
+
+	err := repo.Update(context.Background(), product)
+
+	assert.ErrorIs(t, err, ErrVersionConflict)
+	assert.Equal(t, int64(0), product.Version)
 	mockClient.AssertExpectations(t)
 }
 
@@ -217,13 +352,1138 @@ func TestProductRepository_Delete_Success(t *testing.T) {
 	}
 	repo := NewProductRepository(db)
 
-	mockClient.On("DeleteItem", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+	mockClient.On("DeleteItemWithContext", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
 		return *input.TableName == "test-table" &&
-			   *input.Key["id"].S == "test-id"
+			*input.Key["id"].S == "test-id"
 	})).Return(&dynamodb.DeleteItemOutput{}, nil)
 
-	err := repo.Delete("test-id")
+	err := repo.Delete(context.Background(), "test-id")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_DeleteVersion(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("DeleteItemWithContext", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version" &&
+			*input.ExpressionAttributeValues[":version"].N == "3"
+	})).Return(&dynamodb.DeleteItemOutput{}, nil).Once()
+	mockClient.On("DeleteItemWithContext", mock.MatchedBy(func(input *dynamodb.DeleteItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(id) AND (attribute_not_exists(version) OR version = :version)"
+	})).Return(&dynamodb.DeleteItemOutput{}, &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	deleted, err := repo.DeleteVersion(context.Background(), "test-id", 3)
+
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = repo.DeleteVersion(context.Background(), "test-id", 0)
+
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetBySKU_Success(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	item, _ := dynamodbattribute.MarshalMap(product)
+
+	firstPage := &dynamodb.ScanOutput{
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{
+			"id": {S: aws.String("other-id")},
+		},
+	}
+	secondPage := &dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{item},
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil &&
+			*input.ExpressionAttributeValues[":sku"].S == "TEST-001"
+	})).Return(firstPage, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil &&
+			*input.ExclusiveStartKey["id"].S == "other-id"
+	})).Return(secondPage, nil).Once()
+
+	result, err := repo.GetBySKU(context.Background(), "TEST-001")
+
+	assert.NoError(t, err)
+	assert.NotNil(t, result)
+	assert.Equal(t, "TEST-001", result.SKU)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetBySKU_NotFound(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("ScanWithContext", mock.AnythingOfType("*dynamodb.ScanInput")).Return(&dynamodb.ScanOutput{}, nil)
+
+	result, err := repo.GetBySKU(context.Background(), "MISSING-001")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetBySlug(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	product.Slug = "test-product"
+	item, _ := dynamodbattribute.MarshalMap(product)
+
+	mockClient.On("QueryWithContext", mock.MatchedBy(func(input *dynamodb.QueryInput) bool {
+		return *input.IndexName == database.SlugIndex &&
+			*input.KeyConditionExpression == "slug = :slug" &&
+			*input.ExpressionAttributeValues[":slug"].S == "test-product"
+	})).Return(&dynamodb.QueryOutput{Items: []map[string]*dynamodb.AttributeValue{item}}, nil).Once()
+	mockClient.On("QueryWithContext", mock.AnythingOfType("*dynamodb.QueryInput")).Return(&dynamodb.QueryOutput{}, nil).Once()
+
+	result, err := repo.GetBySlug(context.Background(), "test-product")
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", result.ID)
+
+	result, err = repo.GetBySlug(context.Background(), "missing")
+
+	assert.NoError(t, err)
+	assert.Nil(t, result)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ExistsBySKU(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	output := &dynamodb.ScanOutput{
+		Items: []map[string]*dynamodb.AttributeValue{
+			{"id": {S: aws.String("test-id")}},
+		},
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ProjectionExpression != nil &&
+			*input.ProjectionExpression == "id" &&
+			*input.ExpressionAttributeValues[":sku"].S == "TEST-001"
+	})).Return(output, nil)
+
+	exists, err := repo.ExistsBySKU(context.Background(), "TEST-001")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetInactive_Success(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	product.IsActive = false
+	item, _ := dynamodbattribute.MarshalMap(product)
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "is_active = :is_active" &&
+			!*input.ExpressionAttributeValues[":is_active"].BOOL
+	})).Return(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}, nil)
+
+	results, err := repo.GetInactive(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.False(t, results[0].IsActive)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetUpdatedSince(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	since := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	var items []map[string]*dynamodb.AttributeValue
+	for _, updated := range []time.Time{since.Add(-500 * time.Millisecond), since, since.Add(500 * time.Millisecond)} {
+		product := createTestProduct()
+		product.ID = updated.Format(time.RFC3339Nano)
+		product.UpdatedAt = updated
+		product.IsActive = false
+		item, _ := dynamodbattribute.MarshalMap(product)
+		items = append(items, item)
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "updated_at >= :updated_at" &&
+			*input.ExpressionAttributeValues[":updated_at"].S == "2023-12-31T23:59:59Z"
+	})).Return(&dynamodb.ScanOutput{Items: items}, nil)
+
+	list, err := repo.GetUpdatedSince(context.Background(), since)
+
+	assert.NoError(t, err)
+	if assert.Len(t, list.Products, 2) {
+		assert.Equal(t, since, list.Products[0].UpdatedAt)
+		assert.Equal(t, since.Add(500*time.Millisecond), list.Products[1].UpdatedAt)
+	}
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetAll_TruncatesAtScanCap(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanMaxItems(3))
+
+	page := func(ids ...string) []map[string]*dynamodb.AttributeValue {
+		var items []map[string]*dynamodb.AttributeValue
+		for _, id := range ids {
+			product := createTestProduct()
+			product.ID = id
+			item, _ := dynamodbattribute.MarshalMap(product)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-1", "id-2"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == "id-2"
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-3", "id-4"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-4")}},
+	}, nil).Once()
+
+	results, err := repo.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results.Products, 3)
+	assert.Equal(t, "id-3", results.Products[2].ID)
+	assert.True(t, results.Truncated)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetFeatured_IgnoresScanCap(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanMaxItems(1))
+
+	page := func(ids ...string) []map[string]*dynamodb.AttributeValue {
+		var items []map[string]*dynamodb.AttributeValue
+		for _, id := range ids {
+			product := createTestProduct()
+			product.ID = id
+			product.Featured = true
+			item, _ := dynamodbattribute.MarshalMap(product)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "is_active = :is_active AND featured = :featured" && input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-1", "id-2"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == "id-2"
+	})).Return(&dynamodb.ScanOutput{Items: page("id-3")}, nil).Once()
+
+	products, err := repo.GetFeatured(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, products, 3)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetAll_CountsPastScanCap(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanMaxItems(3), WithExactCounts(true))
+
+	page := func(ids ...string) []map[string]*dynamodb.AttributeValue {
+		var items []map[string]*dynamodb.AttributeValue
+		for _, id := range ids {
+			product := createTestProduct()
+			product.ID = id
+			item, _ := dynamodbattribute.MarshalMap(product)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-1", "id-2"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.Select == nil && input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == "id-2"
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-3", "id-4"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-4")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return aws.StringValue(input.Select) == dynamodb.SelectCount && *input.ExclusiveStartKey["id"].S == "id-4"
+	})).Return(&dynamodb.ScanOutput{
+		Count:            aws.Int64(4),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-8")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return aws.StringValue(input.Select) == dynamodb.SelectCount && *input.ExclusiveStartKey["id"].S == "id-8"
+	})).Return(&dynamodb.ScanOutput{Count: aws.Int64(1)}, nil).Once()
+
+	results, err := repo.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results.Products, 3)
+	assert.True(t, results.Truncated)
+	// 3 collected, id-4 left on the capped page, 5 counted afterwards.
+	assert.Equal(t, 9, results.Matched)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetAll_PagesUntilExhausted(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanMaxItems(10))
+
+	item, _ := dynamodbattribute.MarshalMap(createTestProduct())
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("test-id")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{}, nil).Once()
+
+	results, err := repo.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results.Products, 1)
+	assert.False(t, results.Truncated)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_GetAll_StopsAtCapacityBudget(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanCapacityBudget(10))
 
+	page := func(ids ...string) []map[string]*dynamodb.AttributeValue {
+		var items []map[string]*dynamodb.AttributeValue
+		for _, id := range ids {
+			product := createTestProduct()
+			product.ID = id
+			item, _ := dynamodbattribute.MarshalMap(product)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil &&
+			aws.StringValue(input.ReturnConsumedCapacity) == dynamodb.ReturnConsumedCapacityTotal
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-1", "id-2"),
+		ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(6)},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == "id-2"
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-3"),
+		ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(6)},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-3")}},
+	}, nil).Once()
+
+	results, err := repo.GetAll(context.Background())
+
+	// 12 units consumed against a budget of 10: the third page is never read.
 	assert.NoError(t, err)
+	assert.Len(t, results.Products, 3)
+	assert.True(t, results.BudgetExceeded)
+	assert.False(t, results.Truncated)
 	mockClient.AssertExpectations(t)
-}
\ No newline at end of file
+}
+
+func TestProductRepository_GetAll_FinishesWithinCapacityBudget(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanCapacityBudget(10))
+
+	item, _ := dynamodbattribute.MarshalMap(createTestProduct())
+
+	// The last page may go over the budget; there is nothing left to skip.
+	mockClient.On("ScanWithContext", mock.Anything).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item},
+		ConsumedCapacity: &dynamodb.ConsumedCapacity{CapacityUnits: aws.Float64(25)},
+	}, nil).Once()
+
+	results, err := repo.GetAll(context.Background())
+
+	assert.NoError(t, err)
+	assert.Len(t, results.Products, 1)
+	assert.False(t, results.BudgetExceeded)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ScanActive_DeliversEachPage(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	// The scan cap applies to listings, not to exports.
+	repo := NewProductRepository(db, WithScanMaxItems(1))
+
+	page := func(ids ...string) []map[string]*dynamodb.AttributeValue {
+		var items []map[string]*dynamodb.AttributeValue
+		for _, id := range ids {
+			product := createTestProduct()
+			product.ID = id
+			item, _ := dynamodbattribute.MarshalMap(product)
+			items = append(items, item)
+		}
+		return items
+	}
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            page("id-1", "id-2"),
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("id-2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{
+		Items: page("id-3"),
+	}, nil).Once()
+
+	var pages [][]string
+	err := repo.ScanActive(context.Background(), func(products []*models.Product) error {
+		var ids []string
+		for _, p := range products {
+			ids = append(ids, p.ID)
+		}
+		pages = append(pages, ids)
+		return nil
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, [][]string{{"id-1", "id-2"}, {"id-3"}}, pages)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ScanActive_ParallelSegments(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanSegments(3))
+
+	for segment := int64(0); segment < 3; segment++ {
+		product := createTestProduct()
+		product.ID = fmt.Sprintf("id-%d", segment)
+		item, _ := dynamodbattribute.MarshalMap(product)
+
+		mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			return *input.TotalSegments == 3 && *input.Segment == segment
+		})).Return(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}, nil).Once()
+	}
+
+	var ids []string
+	err := repo.ScanActive(context.Background(), func(page []*models.Product) error {
+		for _, p := range page {
+			ids = append(ids, p.ID)
+		}
+		return nil
+	})
+
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"id-0", "id-1", "id-2"}, ids)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ScanActive_SegmentFailure(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db, WithScanSegments(2))
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.Segment == 0
+	})).Return(&dynamodb.ScanOutput{}, nil)
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.Segment == 1
+	})).Return(&dynamodb.ScanOutput{}, errors.New("throttled"))
+
+	err := repo.ScanActive(context.Background(), func(page []*models.Product) error { return nil })
+
+	assert.ErrorContains(t, err, "segment 1")
+	assert.ErrorContains(t, err, "throttled")
+}
+
+func TestProductRepository_SetStock(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	product.Stock = 25
+	item, _ := dynamodbattribute.MarshalMap(product)
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND is_active = :is_active AND #stock <> :stock" &&
+			*input.UpdateExpression == "SET #stock = :stock_2, updated_at = :updated_at, stock_updated_at = :stock_updated_at, updated_by = :updated_by ADD version :version" &&
+			*input.ExpressionAttributeValues[":stock_2"].N == "25" &&
+			*input.ExpressionAttributeValues[":updated_at"].S == "2026-03-01T12:00:00Z" &&
+			*input.ExpressionAttributeValues[":stock_updated_at"].S == "2026-03-01T12:00:00Z" &&
+			*input.ExpressionAttributeValues[":updated_by"].S == "user-1"
+	})).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil)
+
+	updated, written, err := repo.SetStock(context.Background(), "test-id", 25, false, "user-1", now)
+
+	assert.NoError(t, err)
+	assert.True(t, written)
+	assert.Equal(t, float64(25), updated.Stock)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetStock_FractionalRequiresUnit(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item, _ := dynamodbattribute.MarshalMap(createTestProduct())
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return strings.HasSuffix(*input.ConditionExpression, " AND #unit IN (:unit, :unit_2, :unit_3, :unit_4, :unit_5)") &&
+			*input.ExpressionAttributeNames["#unit"] == "unit"
+	})).Return(&dynamodb.UpdateItemOutput{}, &dynamodb.ConditionalCheckFailedException{Item: item})
+
+	product, written, err := repo.SetStock(context.Background(), "test-id", 1.5, false, "", time.Now())
+
+	assert.NoError(t, err)
+	assert.False(t, written)
+	require.NotNil(t, product)
+	assert.Equal(t, "test-id", product.ID)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ClearStock(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	product.Stock = 0
+	product.IsActive = false
+	item, _ := dynamodbattribute.MarshalMap(product)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version" &&
+			*input.UpdateExpression == "SET #stock = :stock, updated_at = :updated_at, stock_updated_at = :stock_updated_at, updated_by = :updated_by, is_active = :is_active, stock_deactivated = :stock_deactivated ADD version :version_2" &&
+			*input.ExpressionAttributeValues[":version"].N == "4" &&
+			*input.ExpressionAttributeValues[":stock"].N == "0" &&
+			!*input.ExpressionAttributeValues[":is_active"].BOOL
+	})).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return(&dynamodb.UpdateItemOutput{}, &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	cleared, written, err := repo.ClearStock(context.Background(), "test-id", 4, true, "user-1", time.Now())
+
+	assert.NoError(t, err)
+	assert.True(t, written)
+	assert.Equal(t, float64(0), cleared.Stock)
+	assert.False(t, cleared.IsActive)
+
+	// A product written since the version was read is left alone.
+	cleared, written, err = repo.ClearStock(context.Background(), "test-id", 4, false, "user-1", time.Now())
+
+	assert.NoError(t, err)
+	assert.False(t, written)
+	assert.Nil(t, cleared)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetStock_FollowStock(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	item, _ := dynamodbattribute.MarshalMap(product)
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(id) AND (is_active = :is_active OR (stock_deactivated = :stock_deactivated AND attribute_not_exists(deleted_at))) AND #stock <> :stock" &&
+			*input.UpdateExpression == "SET #stock = :stock_2, updated_at = :updated_at, stock_updated_at = :stock_updated_at, updated_by = :updated_by, is_active = :is_active_2 REMOVE stock_deactivated ADD version :version"
+	})).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(id) AND is_active = :is_active AND #stock <> :stock" &&
+			*input.UpdateExpression == "SET #stock = :stock_2, updated_at = :updated_at, stock_updated_at = :stock_updated_at, updated_by = :updated_by, is_active = :is_active_2, stock_deactivated = :stock_deactivated ADD version :version" &&
+			!*input.ExpressionAttributeValues[":is_active_2"].BOOL
+	})).Return(&dynamodb.UpdateItemOutput{Attributes: item}, nil).Once()
+
+	_, written, err := repo.SetStock(context.Background(), "test-id", 10, true, "user-1", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	_, written, err = repo.SetStock(context.Background(), "test-id", 0, true, "user-1", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, written)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetStockActivation(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "stock_deactivated = :stock_deactivated AND #stock > :stock AND attribute_not_exists(deleted_at)" &&
+			*input.UpdateExpression == "SET is_active = :is_active REMOVE stock_deactivated ADD version :version"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "is_active = :is_active AND #stock <= :stock"
+	})).Return((*dynamodb.UpdateItemOutput)(nil), &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	changed, err := repo.SetStockActivation(context.Background(), "test-id", true)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	// A product that still has stock, or is already inactive, is left alone.
+	changed, err = repo.SetStockActivation(context.Background(), "test-id", false)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetStock_Missing(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return(&dynamodb.UpdateItemOutput{}, &dynamodb.ConditionalCheckFailedException{})
+
+	product, written, err := repo.SetStock(context.Background(), "missing", 3, false, "", time.Now())
+
+	assert.NoError(t, err)
+	assert.False(t, written)
+	assert.Nil(t, product)
+}
+
+func TestProductRepository_SetCounts(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.ConditionExpression == "attribute_exists(id) AND view_count = :view_count AND rating_sum = :rating_sum AND (attribute_not_exists(rating_count) OR rating_count = :rating_count)" &&
+			*input.UpdateExpression == "SET view_count = :view_count_2, rating_sum = :rating_sum_2, rating_count = :rating_count_2" &&
+			*input.ExpressionAttributeValues[":rating_sum"].N == "7" &&
+			*input.ExpressionAttributeValues[":rating_sum_2"].N == "0"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return(&dynamodb.UpdateItemOutput{}, &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	from := models.ProductCounts{ViewCount: 3, RatingSum: 7}
+	to := models.ProductCounts{ViewCount: 3}
+
+	written, err := repo.SetCounts(context.Background(), "test-id", from, to)
+
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	written, err = repo.SetCounts(context.Background(), "test-id", from, to)
+
+	assert.NoError(t, err)
+	assert.False(t, written)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetDerivedFields(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version" &&
+			*input.UpdateExpression == "SET slug = :slug, version = :version_2 REMOVE category_path" &&
+			*input.ExpressionAttributeValues[":version"].N == "2" &&
+			*input.ExpressionAttributeValues[":version_2"].N == "3"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return((*dynamodb.UpdateItemOutput)(nil), &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	written, err := repo.SetDerivedFields(context.Background(), "test-id", 2, "widget", nil)
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	// A product written since it was read is left alone.
+	written, err = repo.SetDerivedFields(context.Background(), "test-id", 2, "widget", nil)
+	assert.NoError(t, err)
+	assert.False(t, written)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SetCategory(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version" &&
+			*input.UpdateExpression == "SET category = :category, category_path = :category_path, updated_at = :updated_at, updated_by = :updated_by, version = :version_2" &&
+			*input.ExpressionAttributeValues[":category"].S == "phones" &&
+			len(input.ExpressionAttributeValues[":category_path"].L) == 2 &&
+			*input.ExpressionAttributeValues[":version"].N == "2" &&
+			*input.ExpressionAttributeValues[":version_2"].N == "3"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return((*dynamodb.UpdateItemOutput)(nil), &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	path := []string{"electronics", "phones"}
+	written, err := repo.SetCategory(context.Background(), "test-id", 2, "phones", path, "admin", time.Now())
+	assert.NoError(t, err)
+	assert.True(t, written)
+
+	// A product written since it was read is left alone.
+	written, err = repo.SetCategory(context.Background(), "test-id", 2, "phones", path, "admin", time.Now())
+	assert.NoError(t, err)
+	assert.False(t, written)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_IncrementViewCount(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == "test-table" &&
+			*input.Key["id"].S == "test-id" &&
+			*input.UpdateExpression == "ADD view_count :one" &&
+			*input.ExpressionAttributeValues[":one"].N == "1" &&
+			*input.ConditionExpression == "attribute_exists(id)"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil)
+
+	err := repo.IncrementViewCount(context.Background(), "test-id")
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_AddRating(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.UpdateExpression == "ADD rating_sum :rating, rating_count :one" &&
+			*input.ExpressionAttributeValues[":rating"].N == "4" &&
+			*input.ConditionExpression == "attribute_exists(id)" &&
+			*input.ReturnValues == dynamodb.ReturnValueAllNew
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{
+			"id":           {S: aws.String("test-id")},
+			"rating_sum":   {N: aws.String("9")},
+			"rating_count": {N: aws.String("2")},
+		},
+	}, nil)
+
+	product, err := repo.AddRating(context.Background(), "test-id", 4)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2, product.RatingCount)
+	assert.Equal(t, 4.5, product.AverageRating())
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_AddRating_NotFound(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.Anything).Return((*dynamodb.UpdateItemOutput)(nil),
+		awserr.New(dynamodb.ErrCodeConditionalCheckFailedException, "condition failed", nil))
+
+	product, err := repo.AddRating(context.Background(), "missing", 4)
+
+	assert.NoError(t, err)
+	assert.Nil(t, product)
+}
+
+func TestProductFilter(t *testing.T) {
+	min, max := 10.0, 99.5
+	inStock, outOfStock := true, false
+	now := time.Date(2026, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		name       string
+		filter     models.ProductFilter
+		expression string
+		names      map[string]string
+		values     map[string]string
+	}{
+		{
+			name:       "no criteria",
+			expression: "is_active = :is_active",
+		},
+		{
+			name:       "category only",
+			filter:     models.ProductFilter{Category: "electronics"},
+			expression: "is_active = :is_active AND category = :category",
+			values:     map[string]string{":category": "electronics"},
+		},
+		{
+			name:       "price range",
+			filter:     models.ProductFilter{MinPrice: &min, MaxPrice: &max},
+			expression: "is_active = :is_active AND price >= :price AND price <= :price_2",
+			values:     map[string]string{":price": "10", ":price_2": "99.5"},
+		},
+		{
+			name:       "out of stock with tag",
+			filter:     models.ProductFilter{InStock: &outOfStock, Tag: "clearance", AsOf: now},
+			expression: "is_active = :is_active AND (#stock <= :stock OR available_from > :available_from) AND contains(tags, :tags)",
+			names:      map[string]string{"#stock": "stock"},
+			values:     map[string]string{":stock": "0", ":available_from": "2026-03-01T12:00:00Z", ":tags": "clearance"},
+		},
+		{
+			name:       "everything",
+			filter:     models.ProductFilter{Category: "toys", MinPrice: &min, MaxPrice: &max, InStock: &inStock, Tag: "sale", AsOf: now},
+			expression: "is_active = :is_active AND category = :category AND price >= :price AND price <= :price_2 AND #stock > :stock AND (attribute_not_exists(available_from) OR available_from <= :available_from) AND contains(tags, :tags)",
+			names:      map[string]string{"#stock": "stock"},
+			values:     map[string]string{":category": "toys", ":price": "10", ":price_2": "99.5", ":stock": "0", ":available_from": "2026-03-01T12:00:00Z", ":tags": "sale"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			expression, names, values := productFilter(tt.filter).build()
+
+			assert.Equal(t, tt.expression, expression)
+			assert.Len(t, names, len(tt.names))
+			for alias, attr := range tt.names {
+				if assert.NotNil(t, names[alias], alias) {
+					assert.Equal(t, attr, *names[alias])
+				}
+			}
+			assert.Len(t, values, len(tt.values)+1)
+			assert.True(t, *values[":is_active"].BOOL)
+			for key, want := range tt.values {
+				got := values[key]
+				if assert.NotNil(t, got, key) {
+					if got.S != nil {
+						assert.Equal(t, want, *got.S, key)
+					} else {
+						assert.Equal(t, want, *got.N, key)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestProductRepository_Filter(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	product := createTestProduct()
+	item, _ := dynamodbattribute.MarshalMap(product)
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "is_active = :is_active AND category = :category" &&
+			*input.ExpressionAttributeValues[":category"].S == "electronics"
+	})).Return(&dynamodb.ScanOutput{Items: []map[string]*dynamodb.AttributeValue{item}}, nil)
+
+	list, err := repo.Filter(context.Background(), models.ProductFilter{Category: "electronics"})
+
+	assert.NoError(t, err)
+	assert.Len(t, list.Products, 1)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_Filter_NameIsAliased(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "is_active = :is_active AND contains(#name, :name)" &&
+			*input.ExpressionAttributeNames["#name"] == "name" &&
+			*input.ExpressionAttributeValues[":name"].S == "Widget"
+	})).Return(&dynamodb.ScanOutput{}, nil)
+
+	_, err := repo.Filter(context.Background(), models.ProductFilter{Name: "Widget"})
+
+	assert.NoError(t, err)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SearchByNamePrefix(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item := func(id, name string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}, "name": {S: aws.String(name)}}
+	}
+	matches := func(input *dynamodb.ScanInput) bool {
+		return *input.FilterExpression == "is_active = :is_active AND begins_with(#name, :name)" &&
+			*input.ProjectionExpression == "id, #name" &&
+			*input.ExpressionAttributeNames["#name"] == "name" &&
+			*input.ExpressionAttributeValues[":name"].S == "Wid"
+	}
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return matches(input) && input.ExclusiveStartKey == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item("1", "Widget")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("1")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return matches(input) && input.ExclusiveStartKey != nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item("2", "Widget Pro"), item("3", "Widgetry")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("3")}},
+	}, nil).Once()
+
+	// The scan stops as soon as the limit is reached, mid-page.
+	products, err := repo.SearchByNamePrefix(context.Background(), "Wid", 2)
+
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	assert.Equal(t, "Widget", products[0].Name)
+	assert.Equal(t, "2", products[1].ID)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_SearchByName(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item := func(id, name string) map[string]*dynamodb.AttributeValue {
+		return map[string]*dynamodb.AttributeValue{"id": {S: aws.String(id)}, "name": {S: aws.String(name)}}
+	}
+	scan := func(after string) interface{} {
+		return mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+			if *input.FilterExpression != "is_active = :is_active AND contains(#name, :name)" ||
+				*input.ExpressionAttributeValues[":name"].S != "Lamp" {
+				return false
+			}
+			if after == "" {
+				return input.ExclusiveStartKey == nil
+			}
+			return input.ExclusiveStartKey != nil && *input.ExclusiveStartKey["id"].S == after
+		})
+	}
+	mockClient.On("ScanWithContext", scan("")).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item("1", "Desk Lamp")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("2")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", scan("2")).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item("3", "Floor Lamp"), item("4", "Lamp Shade")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("5")}},
+	}, nil).Once()
+
+	// Matches accumulate across pages; the limit is reached mid-page, so
+	// the cursor is the last match rather than the page's LastEvaluatedKey.
+	products, next, err := repo.SearchByName(context.Background(), "Lamp", "", 2)
+
+	require.NoError(t, err)
+	require.Len(t, products, 2)
+	assert.Equal(t, "1", products[0].ID)
+	assert.Equal(t, "3", products[1].ID)
+	assert.Equal(t, "3", next)
+
+	mockClient.On("ScanWithContext", scan("3")).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item("4", "Lamp Shade")},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("5")}},
+	}, nil).Once()
+	mockClient.On("ScanWithContext", scan("5")).Return(&dynamodb.ScanOutput{}, nil).Once()
+
+	products, next, err = repo.SearchByName(context.Background(), "Lamp", next, 2)
+
+	require.NoError(t, err)
+	require.Len(t, products, 1)
+	assert.Equal(t, "Lamp Shade", products[0].Name)
+	assert.Empty(t, next)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ReleaseReservation(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.Key["id"].S == "test-id" &&
+			*input.ConditionExpression == "attribute_exists(id) AND version = :version AND attribute_exists(reservations.#reservation)" &&
+			*input.UpdateExpression == "SET #stock = :stock, updated_at = :updated_at, stock_updated_at = :stock_updated_at, version = :version_2 REMOVE reservations.#reservation" &&
+			*input.ExpressionAttributeNames["#reservation"] == "res-1" &&
+			*input.ExpressionAttributeValues[":stock"].N == "7" &&
+			*input.ExpressionAttributeValues[":version_2"].N == "3"
+	})).Return(&dynamodb.UpdateItemOutput{}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).
+		Return(&dynamodb.UpdateItemOutput{}, &dynamodb.ConditionalCheckFailedException{}).Once()
+
+	released, err := repo.ReleaseReservation(context.Background(), "test-id", "res-1", 2, 7, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, released)
+
+	// Once removed, the reservation cannot be released again.
+	released, err = repo.ReleaseReservation(context.Background(), "test-id", "res-1", 2, 7, time.Now())
+	assert.NoError(t, err)
+	assert.False(t, released)
+	mockClient.AssertExpectations(t)
+}
+
+func TestProductRepository_ScanPage(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	db := &database.DynamoDBClient{
+		Client:    mockClient,
+		TableName: "test-table",
+	}
+	repo := NewProductRepository(db)
+
+	item, _ := dynamodbattribute.MarshalMap(createTestProduct())
+	mockClient.On("ScanWithContext", mock.MatchedBy(func(input *dynamodb.ScanInput) bool {
+		return *input.Limit == 50 &&
+			*input.ExclusiveStartKey["id"].S == "after-id" &&
+			input.FilterExpression == nil
+	})).Return(&dynamodb.ScanOutput{
+		Items:            []map[string]*dynamodb.AttributeValue{item},
+		LastEvaluatedKey: map[string]*dynamodb.AttributeValue{"id": {S: aws.String("test-id")}},
+	}, nil)
+
+	page, next, err := repo.ScanPage(context.Background(), "after-id", 50)
+
+	assert.NoError(t, err)
+	require.Len(t, page, 1)
+	assert.Equal(t, "test-id", page[0].ID)
+	assert.Equal(t, "test-id", next)
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,85 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+
+	"product-service/internal/database"
+)
+
+// SequenceRepository hands out numbers from named sequences. Each call
+// returns the next number of the sequence, starting at 1, and concurrent
+// callers never receive the same number.
+type SequenceRepository interface {
+	Next(ctx context.Context, name string) (int64, error)
+}
+
+// sequenceRepository keeps one counter item per sequence in its own table,
+// keyed by the string attribute "sequence". Keeping the counters out of the
+// products table stops them showing up in product scans.
+type sequenceRepository struct {
+	client database.DynamoDBAPI
+	table  string
+}
+
+func NewSequenceRepository(db *database.DynamoDBClient, table string) SequenceRepository {
+	return &sequenceRepository{client: db.Client, table: table}
+}
+
+// Next increments the sequence's counter with a single atomic ADD, which
+// creates the item at 1 the first time.
+func (r *sequenceRepository) Next(ctx context.Context, name string) (int64, error) {
+	input := &dynamodb.UpdateItemInput{
+		TableName: aws.String(r.table),
+		Key: map[string]*dynamodb.AttributeValue{
+			"sequence": {
+				S: aws.String(name),
+			},
+		},
+		UpdateExpression: aws.String("ADD last_value :one"),
+		ExpressionAttributeValues: map[string]*dynamodb.AttributeValue{
+			":one": {
+				N: aws.String("1"),
+			},
+		},
+		ReturnValues: aws.String(dynamodb.ReturnValueUpdatedNew),
+	}
+
+	result, err := r.client.UpdateItemWithContext(ctx, input)
+	if err != nil {
+		return 0, fmt.Errorf("failed to advance sequence %q: %w", name, err)
+	}
+	value, ok := result.Attributes["last_value"]
+	if !ok || value.N == nil {
+		return 0, fmt.Errorf("sequence %q returned no value", name)
+	}
+	n, err := strconv.ParseInt(*value.N, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("sequence %q: %w", name, err)
+	}
+	return n, nil
+}
+
+// memorySequences is an in-process SequenceRepository for tests and local
+// tooling.
+type memorySequences struct {
+	mu     sync.Mutex
+	values map[string]int64
+}
+
+func NewMemorySequenceRepository() SequenceRepository {
+	return &memorySequences{values: make(map[string]int64)}
+}
+
+func (r *memorySequences) Next(ctx context.Context, name string) (int64, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.values[name]++
+	return r.values[name], nil
+}
@@ -0,0 +1,39 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/service/dynamodb"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+
+	"product-service/internal/database"
+)
+
+func TestSequenceRepository_Next(t *testing.T) {
+	mockClient := new(MockDynamoDBClient)
+	repo := NewSequenceRepository(&database.DynamoDBClient{Client: mockClient, TableName: "products"}, "sequences")
+
+	mockClient.On("UpdateItemWithContext", mock.MatchedBy(func(input *dynamodb.UpdateItemInput) bool {
+		return *input.TableName == "sequences" &&
+			*input.Key["sequence"].S == "electronics" &&
+			*input.UpdateExpression == "ADD last_value :one" &&
+			input.ConditionExpression == nil &&
+			*input.ReturnValues == dynamodb.ReturnValueUpdatedNew
+	})).Return(&dynamodb.UpdateItemOutput{
+		Attributes: map[string]*dynamodb.AttributeValue{"last_value": {N: aws.String("123")}},
+	}, nil).Once()
+	mockClient.On("UpdateItemWithContext", mock.Anything).Return(&dynamodb.UpdateItemOutput{}, errors.New("throttled")).Once()
+
+	n, err := repo.Next(context.Background(), "electronics")
+
+	assert.NoError(t, err)
+	assert.Equal(t, int64(123), n)
+
+	_, err = repo.Next(context.Background(), "electronics")
+	assert.ErrorContains(t, err, "throttled")
+	mockClient.AssertExpectations(t)
+}
@@ -0,0 +1,49 @@
+// Package requestid carries the ID of the API request a piece of work was
+// started by, so logs and published events can be tied back to it.
+package requestid
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Header is the request and response header holding the request ID.
+const Header = "X-Request-ID"
+
+type requestIDKey struct{}
+
+// With returns a copy of ctx carrying id.
+func With(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// FromContext returns the request ID in ctx, or "" if there is none.
+func FromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// Logger returns a logger that adds a request_id attribute to every record
+// logged with a context carrying a request ID.
+func Logger(logger *slog.Logger) *slog.Logger {
+	return slog.New(logHandler{logger.Handler()})
+}
+
+type logHandler struct {
+	slog.Handler
+}
+
+func (h logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if id := FromContext(ctx); id != "" {
+		record.AddAttrs(slog.String("request_id", id))
+	}
+	return h.Handler.Handle(ctx, record)
+}
+
+func (h logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return logHandler{h.Handler.WithAttrs(attrs)}
+}
+
+func (h logHandler) WithGroup(name string) slog.Handler {
+	return logHandler{h.Handler.WithGroup(name)}
+}
@@ -0,0 +1,86 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// Archiver periodically hard-deletes products that have been inactive for
+// longer than maxAge. A product's UpdatedAt is taken as the time it was
+// deactivated.
+type Archiver struct {
+	repo     repository.ProductRepository
+	interval time.Duration
+	maxAge   time.Duration
+	now      func() time.Time
+}
+
+func NewArchiver(repo repository.ProductRepository, interval, maxAge time.Duration) *Archiver {
+	return &Archiver{
+		repo:     repo,
+		interval: interval,
+		maxAge:   maxAge,
+		now:      time.Now,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (a *Archiver) Run(ctx context.Context) {
+	ticker := time.NewTicker(a.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			archived, err := a.Sweep(ctx)
+			if err != nil {
+				log.Printf("Archiver sweep failed: %v", err)
+				continue
+			}
+			if archived > 0 {
+				log.Printf("Archiver removed %d stale inactive products", archived)
+			}
+		}
+	}
+}
+
+// Sweep deletes every stale product and returns how many were removed.
+func (a *Archiver) Sweep(ctx context.Context) (int, error) {
+	stale, err := a.staleProducts(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	archived := 0
+	for _, product := range stale {
+		if err := a.repo.Delete(ctx, product.ID); err != nil {
+			return archived, fmt.Errorf("failed to archive product %s: %w", product.ID, err)
+		}
+		archived++
+	}
+	return archived, nil
+}
+
+func (a *Archiver) staleProducts(ctx context.Context) ([]*models.Product, error) {
+	inactive, err := a.repo.GetInactive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inactive products: %w", err)
+	}
+
+	cutoff := a.now().Add(-a.maxAge)
+	var stale []*models.Product
+	for _, product := range inactive {
+		// Drafts are inactive until published, not abandoned.
+		if !product.IsActive && !product.IsDraft() && product.UpdatedAt.Before(cutoff) {
+			stale = append(stale, product)
+		}
+	}
+	return stale, nil
+}
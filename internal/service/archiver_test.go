@@ -0,0 +1,58 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestArchiver_StaleProductSelection(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := repository.NewMemoryProductRepository()
+
+	fixtures := []*models.Product{
+		{ID: "active-old", IsActive: true, UpdatedAt: now.Add(-90 * 24 * time.Hour)},
+		{ID: "inactive-old", IsActive: false, UpdatedAt: now.Add(-31 * 24 * time.Hour)},
+		{ID: "inactive-recent", IsActive: false, UpdatedAt: now.Add(-29 * 24 * time.Hour)},
+		{ID: "inactive-boundary", IsActive: false, UpdatedAt: now.Add(-30 * 24 * time.Hour)},
+	}
+	for _, p := range fixtures {
+		require.NoError(t, repo.Create(context.Background(), p))
+	}
+
+	archiver := NewArchiver(repo, time.Hour, 30*24*time.Hour)
+	archiver.now = func() time.Time { return now }
+
+	stale, err := archiver.staleProducts(context.Background())
+
+	require.NoError(t, err)
+	require.Len(t, stale, 1)
+	assert.Equal(t, "inactive-old", stale[0].ID)
+}
+
+func TestArchiver_SweepDeletesStaleProducts(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := repository.NewMemoryProductRepository()
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "stale", UpdatedAt: now.Add(-48 * time.Hour)}))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "fresh", UpdatedAt: now.Add(-time.Hour)}))
+
+	archiver := NewArchiver(repo, time.Hour, 24*time.Hour)
+	archiver.now = func() time.Time { return now }
+
+	archived, err := archiver.Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, archived)
+
+	stale, _ := repo.GetByID(context.Background(), "stale")
+	assert.Nil(t, stale)
+	fresh, _ := repo.GetByID(context.Background(), "fresh")
+	assert.NotNil(t, fresh)
+}
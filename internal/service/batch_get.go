@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// GetProductsByIDs fetches the listed products in one call. The repository
+// returns them in no particular order, so they are put back in the order
+// the IDs were given; an ID given more than once is returned once, at its
+// first position. IDs with no product are reported in Missing, also in
+// request order.
+func (s *productService) GetProductsByIDs(ctx context.Context, ids []string) (*models.BatchGetResult, error) {
+	if len(ids) == 0 || len(ids) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a batch get must list between 1 and %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	unique := make([]string, 0, len(ids))
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if id == "" {
+			return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidQuery)
+		}
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	found, err := s.repo.GetByIDs(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products: %w", err)
+	}
+
+	byID := make(map[string]*models.Product, len(found))
+	for _, product := range found {
+		byID[product.ID] = product
+	}
+	result := &models.BatchGetResult{
+		Products: make([]*models.Product, 0, len(found)),
+		Missing:  []string{},
+	}
+	for _, id := range unique {
+		if product, ok := byID[id]; ok {
+			result.Products = append(result.Products, product)
+		} else {
+			result.Missing = append(result.Missing, id)
+		}
+	}
+	return result, nil
+}
+
+// GetProductsBySKUs fetches the products with the listed SKUs in one call,
+// in the order the SKUs were given. SKUs match exactly, as they do in
+// GetProductBySKU, once surrounding spaces are trimmed, so " ELEC-001"
+// finds ELEC-001 but "elec-001" does not; a SKU that trims to an earlier
+// one is returned once. SKUs with no product are reported in Missing in
+// their trimmed form. Should two products share a SKU, the first found
+// is returned, as with GetProductBySKU.
+func (s *productService) GetProductsBySKUs(ctx context.Context, skus []string) (*models.BatchGetResult, error) {
+	if len(skus) == 0 || len(skus) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a batch get must list between 1 and %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	unique := make([]string, 0, len(skus))
+	seen := make(map[string]bool, len(skus))
+	for _, sku := range skus {
+		sku = normalizeSKU(sku)
+		if sku == "" {
+			return nil, fmt.Errorf("%w: product SKU cannot be empty", ErrInvalidQuery)
+		}
+		if !seen[sku] {
+			seen[sku] = true
+			unique = append(unique, sku)
+		}
+	}
+
+	found, err := s.repo.GetBySKUs(ctx, unique)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products by sku: %w", err)
+	}
+
+	bySKU := make(map[string]*models.Product, len(found))
+	for _, product := range found {
+		if _, ok := bySKU[product.SKU]; !ok {
+			bySKU[product.SKU] = product
+		}
+	}
+	result := &models.BatchGetResult{
+		Products: make([]*models.Product, 0, len(found)),
+		Missing:  []string{},
+	}
+	for _, sku := range unique {
+		if product, ok := bySKU[sku]; ok {
+			result.Products = append(result.Products, product)
+		} else {
+			result.Missing = append(result.Missing, sku)
+		}
+	}
+	return result, nil
+}
+
+// normalizeSKU is the form SKUs are looked up in. SKUs are stored as
+// given, so only surrounding spaces are dropped; changing the case would
+// miss products created with lower-case SKUs.
+func normalizeSKU(sku string) string {
+	return strings.TrimSpace(sku)
+}
@@ -0,0 +1,115 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_GetProductsByIDs_KeepsRequestOrder(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	// The repository answers in its own order, as BatchGetItem does.
+	mockRepo.On("GetByIDs", []string{"c", "a", "missing", "b"}).Return([]*models.Product{
+		{ID: "b"}, {ID: "c"}, {ID: "a"},
+	}, nil)
+
+	result, err := service.GetProductsByIDs(context.Background(), []string{"c", "a", "missing", "b", "a"})
+
+	require.NoError(t, err)
+	ids := make([]string, 0, len(result.Products))
+	for _, product := range result.Products {
+		ids = append(ids, product.ID)
+	}
+	assert.Equal(t, []string{"c", "a", "b"}, ids)
+	assert.Equal(t, []string{"missing"}, result.Missing)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_GetProductsByIDs_Validation(t *testing.T) {
+	service := NewProductService(new(MockProductRepository))
+
+	for _, ids := range [][]string{nil, {"a", ""}, make([]string, DefaultMaxBatchSize+1)} {
+		_, err := service.GetProductsByIDs(context.Background(), ids)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	}
+}
+
+func TestProductService_GetProductsBySKUs_TrimsAndKeepsRequestOrder(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetBySKUs", []string{"ELEC-002", "ELEC-001", "TOY-404"}).Return([]*models.Product{
+		{ID: "a", SKU: "ELEC-001"}, {ID: "b", SKU: "ELEC-002"},
+	}, nil)
+
+	result, err := service.GetProductsBySKUs(context.Background(), []string{"ELEC-002", " ELEC-001", "TOY-404", "ELEC-001 "})
+
+	require.NoError(t, err)
+	ids := make([]string, 0, len(result.Products))
+	for _, product := range result.Products {
+		ids = append(ids, product.ID)
+	}
+	assert.Equal(t, []string{"b", "a"}, ids)
+	assert.Equal(t, []string{"TOY-404"}, result.Missing)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_GetProductsBySKUs_MatchesSingleLookup(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+	ctx := context.Background()
+
+	created, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Lamp", Price: 30, Category: "home", SKU: "lamp-001",
+	})
+	require.NoError(t, err)
+
+	single, err := service.GetProductBySKU(ctx, "lamp-001")
+	require.NoError(t, err)
+	assert.Equal(t, created.ID, single.ID)
+
+	result, err := service.GetProductsBySKUs(ctx, []string{" lamp-001", "LAMP-001"})
+	require.NoError(t, err)
+	require.Len(t, result.Products, 1)
+	assert.Equal(t, created.ID, result.Products[0].ID)
+	assert.Equal(t, []string{"LAMP-001"}, result.Missing)
+
+	_, err = service.GetProductBySKU(ctx, "LAMP-001")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_GetProductsBySKUs_Validation(t *testing.T) {
+	service := NewProductService(new(MockProductRepository))
+
+	for _, skus := range [][]string{nil, {"ELEC-001", " "}, make([]string, DefaultMaxBatchSize+1)} {
+		_, err := service.GetProductsBySKUs(context.Background(), skus)
+		assert.ErrorIs(t, err, ErrInvalidQuery)
+	}
+}
+
+func TestProductService_MaxBatchSize(t *testing.T) {
+	// The mock has no expectations, so any repository call fails the test.
+	service := NewProductService(new(MockProductRepository), WithMaxBatchSize(2))
+	ctx := context.Background()
+	ids := []string{"a", "b", "c"}
+
+	_, err := service.GetProductsByIDs(ctx, ids)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.GetProductsBySKUs(ctx, ids)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.BulkSetStock(ctx, make([]models.StockUpdate, 3))
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.BulkUpsertProducts(ctx, make([]models.CreateProductRequest, 3))
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	active := false
+	_, err = service.BulkSetStatus(ctx, models.BulkStatusRequest{IDs: ids, Active: &active})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.ReorderFeatured(ctx, ids)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,131 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+)
+
+// MaxBundleItems bounds how many components one bundle may list.
+const MaxBundleItems = 50
+
+// ErrNotBundle is returned when a bundle is requested for a product that
+// has no bundle items.
+var ErrNotBundle = errors.New("product is not a bundle")
+
+// validateBundleItems checks the shape of a bundle's items: each names a
+// product once, with a quantity of at least one.
+func validateBundleItems(items []models.BundleItem) error {
+	if len(items) > MaxBundleItems {
+		return fieldError("bundle_items", "a bundle cannot have more than %d items", MaxBundleItems)
+	}
+	seen := make(map[string]bool, len(items))
+	for _, item := range items {
+		if item.ProductID == "" {
+			return fieldError("bundle_items", "bundle item product ID is required")
+		}
+		if item.Quantity < 1 {
+			return fieldError("bundle_items", "bundle item %q quantity must be at least 1", item.ProductID)
+		}
+		if seen[item.ProductID] {
+			return fieldError("bundle_items", "bundle item %q is listed more than once", item.ProductID)
+		}
+		seen[item.ProductID] = true
+	}
+	return nil
+}
+
+// validateBundleComponents checks that every item of the bundle with id
+// refers to an existing product other than the bundle itself that is not a
+// bundle either. Keeping bundles one level deep rules out cycles such as a
+// bundle containing a bundle that contains it. Invalid items are reported
+// wrapped in ErrInvalidProduct; repository failures are not.
+func (s *productService) validateBundleComponents(ctx context.Context, operation, id string, items []models.BundleItem) error {
+	ids := make([]string, 0, len(items))
+	for _, item := range items {
+		if item.ProductID == id {
+			return s.rejectBundle(ctx, operation, id, fieldError("bundle_items", "a bundle cannot contain itself"))
+		}
+		ids = append(ids, item.ProductID)
+	}
+	components, err := s.bundleComponents(ctx, ids)
+	if err != nil {
+		return err
+	}
+	for _, item := range items {
+		component := components[item.ProductID]
+		if component == nil {
+			return s.rejectBundle(ctx, operation, id, fieldError("bundle_items", "bundle item product %q does not exist", item.ProductID))
+		}
+		if component.IsBundle() {
+			return s.rejectBundle(ctx, operation, id, fieldError("bundle_items", "bundle item product %q is itself a bundle", item.ProductID))
+		}
+	}
+	return nil
+}
+
+// bundleComponents fetches the products with ids in one batch, keyed by ID.
+// Missing and deleted products are left out.
+func (s *productService) bundleComponents(ctx context.Context, ids []string) (map[string]*models.Product, error) {
+	if len(ids) == 0 {
+		return nil, nil
+	}
+	found, err := s.repo.GetByIDs(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get bundle components: %w", err)
+	}
+	components := make(map[string]*models.Product, len(found))
+	for _, component := range found {
+		if !component.IsDeleted() {
+			components[component.ID] = component
+		}
+	}
+	return components, nil
+}
+
+func (s *productService) rejectBundle(ctx context.Context, operation, id string, err error) error {
+	s.logRejected(ctx, operation, id, err)
+	return fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+}
+
+// GetBundle expands a bundle into its component products and derives the
+// price and availability the components give it. Components that have since
+// been deleted are returned without a product. Like GetShipping, it does not
+// count as a view.
+func (s *productService) GetBundle(ctx context.Context, id string) (*models.ProductBundle, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+	if !product.IsBundle() {
+		return nil, ErrNotBundle
+	}
+
+	ids := make([]string, 0, len(product.BundleItems))
+	for _, item := range product.BundleItems {
+		ids = append(ids, item.ProductID)
+	}
+	found, err := s.bundleComponents(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	components := make([]models.BundleComponent, 0, len(product.BundleItems))
+	for _, item := range product.BundleItems {
+		components = append(components, models.BundleComponent{
+			ProductID: item.ProductID,
+			Quantity:  item.Quantity,
+			Product:   found[item.ProductID],
+		})
+	}
+	return models.NewProductBundle(product, components), nil
+}
@@ -0,0 +1,130 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func createComponent(t *testing.T, service ProductService, sku string, price, stock float64) *models.Product {
+	t.Helper()
+	product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Component " + sku, Price: price, Category: "office", SKU: sku, Stock: floatPtr(stock),
+	})
+	require.NoError(t, err)
+	return product
+}
+
+func bundleRequest(sku string, items ...models.BundleItem) models.CreateProductRequest {
+	return models.CreateProductRequest{Name: "Desk Kit", Price: 45, Category: "office", SKU: sku, BundleItems: items}
+}
+
+func TestProductService_CreateProduct_BundleValidation(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+	lamp := createComponent(t, service, "LAMP-1", 30, 4)
+	pad := createComponent(t, service, "PAD-1", 10, 9)
+
+	bundle, err := service.CreateProduct(context.Background(), bundleRequest("KIT-1",
+		models.BundleItem{ProductID: lamp.ID, Quantity: 1},
+		models.BundleItem{ProductID: pad.ID, Quantity: 2},
+	))
+	require.NoError(t, err)
+	assert.True(t, bundle.IsBundle())
+	assert.False(t, lamp.IsBundle())
+
+	for name, items := range map[string][]models.BundleItem{
+		"missing product":  {{ProductID: "nope", Quantity: 1}},
+		"empty product ID": {{Quantity: 1}},
+		"zero quantity":    {{ProductID: lamp.ID}},
+		"duplicate":        {{ProductID: lamp.ID, Quantity: 1}, {ProductID: lamp.ID, Quantity: 1}},
+		"too many":         make([]models.BundleItem, MaxBundleItems+1),
+		"nested bundle":    {{ProductID: bundle.ID, Quantity: 1}},
+	} {
+		_, err := service.CreateProduct(context.Background(), bundleRequest("KIT-2", items...))
+
+		assert.ErrorIs(t, err, ErrInvalidProduct, name)
+		var fieldErr *FieldError
+		if assert.ErrorAs(t, err, &fieldErr, name) {
+			assert.Equal(t, "bundle_items", fieldErr.Field, name)
+		}
+	}
+}
+
+func TestProductService_UpdateProduct_BundleValidation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	lamp := createComponent(t, service, "LAMP-1", 30, 4)
+	bundle, err := service.CreateProduct(context.Background(), bundleRequest("KIT-1", models.BundleItem{ProductID: lamp.ID, Quantity: 1}))
+	require.NoError(t, err)
+
+	self := []models.BundleItem{{ProductID: bundle.ID, Quantity: 1}}
+	_, err = service.UpdateProduct(context.Background(), bundle.ID, models.UpdateProductRequest{BundleItems: &self})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	// Turning the component into a bundle of the bundle would close a cycle.
+	_, err = service.UpdateProduct(context.Background(), lamp.ID, models.UpdateProductRequest{BundleItems: &self})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	// Once a component is gone the bundle can still be edited, as long as
+	// its items are left alone.
+	deleted := time.Now()
+	lamp.DeletedAt = &deleted
+	require.NoError(t, repo.Update(context.Background(), lamp))
+
+	name := "Desk Kit Deluxe"
+	items := []models.BundleItem{{ProductID: lamp.ID, Quantity: 1}}
+	updated, err := service.UpdateProduct(context.Background(), bundle.ID, models.UpdateProductRequest{Name: &name, BundleItems: &items})
+	require.NoError(t, err)
+	assert.Equal(t, name, updated.Name)
+
+	items = []models.BundleItem{{ProductID: lamp.ID, Quantity: 2}}
+	_, err = service.UpdateProduct(context.Background(), bundle.ID, models.UpdateProductRequest{BundleItems: &items})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
+
+func TestProductService_GetBundle(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	lamp := createComponent(t, service, "LAMP-1", 30, 4)
+	pad := createComponent(t, service, "PAD-1", 10, 9)
+	bundle, err := service.CreateProduct(context.Background(), bundleRequest("KIT-1",
+		models.BundleItem{ProductID: lamp.ID, Quantity: 1},
+		models.BundleItem{ProductID: pad.ID, Quantity: 2},
+	))
+	require.NoError(t, err)
+
+	expanded, err := service.GetBundle(context.Background(), bundle.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, bundle.ID, expanded.Product.ID)
+	require.Len(t, expanded.Components, 2)
+	assert.Equal(t, lamp.ID, expanded.Components[0].Product.ID)
+	assert.Equal(t, 2, expanded.Components[1].Quantity)
+	assert.Equal(t, 50.0, expanded.ComponentsPrice)
+	// Four lamps but only enough pads for four kits.
+	assert.Equal(t, 4.0, expanded.AvailableStock)
+	assert.Equal(t, 45.0, expanded.Product.Price)
+
+	_, err = service.GetBundle(context.Background(), lamp.ID)
+	assert.ErrorIs(t, err, ErrNotBundle)
+
+	_, err = service.GetBundle(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	// A deleted component leaves the bundle unavailable.
+	deleted := time.Now()
+	pad.DeletedAt = &deleted
+	require.NoError(t, repo.Update(context.Background(), pad))
+
+	expanded, err = service.GetBundle(context.Background(), bundle.ID)
+
+	require.NoError(t, err)
+	assert.Nil(t, expanded.Components[1].Product)
+	assert.Zero(t, expanded.AvailableStock)
+}
@@ -0,0 +1,116 @@
+package service
+
+import (
+	"context"
+	"fmt"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// maxSubtreeCategories bounds how many categories a subtree listing matches,
+// the most a DynamoDB IN condition accepts.
+const maxSubtreeCategories = 100
+
+// WithCategoryTaxonomy arranges categories in a hierarchy. Products record
+// their category's path through it, and category listings can include
+// subcategories. Without one, categories are flat.
+func WithCategoryTaxonomy(taxonomy models.CategoryTaxonomy) Option {
+	return func(s *productService) {
+		s.taxonomy = taxonomy
+	}
+}
+
+// categoryPath is the CategoryPath recorded for category; nil while the
+// taxonomy is flat.
+func (s *productService) categoryPath(category string) []string {
+	if s.taxonomy.IsFlat() {
+		return nil
+	}
+	return s.taxonomy.Path(category)
+}
+
+// RenameCategory moves every product in category from, active or not, to
+// category to and returns how many products were moved. With a taxonomy, to
+// must be one of its categories. Each product is checked against the target
+// category's price floor and SKU prefix before anything is written, so a
+// rejected rename leaves the catalog unchanged. Only the category is
+// written, and a product edited since it was read is skipped, as are
+// products past the scan cap; repeating the call moves them.
+func (s *productService) RenameCategory(ctx context.Context, from, to string) (int, error) {
+	if from == "" || to == "" {
+		return 0, fmt.Errorf("%w: source and target categories are required", ErrInvalidQuery)
+	}
+	if from == to {
+		return 0, fmt.Errorf("%w: source and target categories are the same", ErrInvalidQuery)
+	}
+	if !s.taxonomy.IsFlat() && !s.taxonomy.Contains(to) {
+		return 0, fmt.Errorf("%w: target category %q is not in the category taxonomy", ErrInvalidQuery, to)
+	}
+
+	products, err := s.productsInCategory(ctx, from)
+	if err != nil {
+		return 0, err
+	}
+
+	req := models.UpdateProductRequest{Category: &to}
+	for _, product := range products {
+		err := s.validateCategoryPrice(product, req)
+		if err == nil {
+			err = s.validateCategorySKU(product, req)
+		}
+		if err != nil {
+			s.logRejected(ctx, "rename category", product.ID, err)
+			return 0, fmt.Errorf("%w: product %s: %w", ErrInvalidProduct, product.ID, err)
+		}
+	}
+
+	if IsDryRun(ctx) {
+		return len(products), nil
+	}
+
+	actor := auth.ActorID(ctx)
+	path := s.categoryPath(to)
+	moved := 0
+	for _, product := range products {
+		written, err := s.repo.SetCategory(ctx, product.ID, product.Version, to, path, actor, s.clock.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "category rename failed", "product_id", product.ID, "error", err)
+			return moved, fmt.Errorf("failed to move product %s: %w", product.ID, err)
+		}
+		if !written {
+			continue
+		}
+		moved++
+		s.publish(ctx, models.EventProductUpdated, product.ID)
+	}
+
+	s.logger.InfoContext(ctx, "category renamed",
+		"from", from,
+		"to", to,
+		"products", moved,
+		"skipped", len(products)-moved,
+		"actor", actor,
+	)
+
+	return moved, nil
+}
+
+func (s *productService) productsInCategory(ctx context.Context, category string) ([]*models.Product, error) {
+	list, err := s.repo.GetByCategory(ctx, category)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products by category: %w", err)
+	}
+	inactive, err := s.repo.GetInactive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get inactive products: %w", err)
+	}
+
+	products := list.Products
+	for _, product := range inactive {
+		if product.Category == category {
+			products = append(products, product)
+		}
+	}
+	return products, nil
+}
@@ -0,0 +1,244 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func seedCategories(t *testing.T, repo repository.ProductRepository) {
+	t.Helper()
+	for _, product := range []*models.Product{
+		{ID: "1", Category: "electronic", Price: 10, IsActive: true},
+		{ID: "2", Category: "electronic", Price: 3, IsActive: true},
+		{ID: "3", Category: "electronic", Price: 20, IsActive: false},
+		{ID: "4", Category: "garden", Price: 5, IsActive: true},
+	} {
+		require.NoError(t, repo.Create(context.Background(), product))
+	}
+}
+
+func TestProductService_RenameCategory(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+
+	updated, err := service.RenameCategory(context.Background(), "electronic", "electronics")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+
+	source, err := repo.GetByCategory(context.Background(), "electronic")
+	require.NoError(t, err)
+	assert.Empty(t, source.Products)
+
+	inactive, err := repo.GetByID(context.Background(), "3")
+	require.NoError(t, err)
+	assert.Equal(t, "electronics", inactive.Category)
+
+	untouched, err := repo.GetByID(context.Background(), "4")
+	require.NoError(t, err)
+	assert.Equal(t, "garden", untouched.Category)
+}
+
+func TestProductService_RenameCategory_DryRun(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+
+	updated, err := service.RenameCategory(WithDryRun(context.Background()), "electronic", "electronics")
+
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+
+	source, err := repo.GetByCategory(context.Background(), "electronic")
+	require.NoError(t, err)
+	assert.Len(t, source.Products, 2)
+}
+
+func TestProductService_RenameCategory_PriceFloor(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryMinPrice(map[string]float64{"electronics": 5}))
+	seedCategories(t, repo)
+
+	updated, err := service.RenameCategory(context.Background(), "electronic", "electronics")
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Zero(t, updated)
+
+	// Nothing moves when any product is rejected.
+	source, err := repo.GetByCategory(context.Background(), "electronic")
+	require.NoError(t, err)
+	assert.Len(t, source.Products, 2)
+}
+
+func TestProductService_RenameCategory_Invalid(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	_, err := service.RenameCategory(context.Background(), "electronic", "")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = service.RenameCategory(context.Background(), "garden", "garden")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+// restockingRepository restocks product 1 just before its category is first
+// written, as if a stock update had raced the rename.
+type restockingRepository struct {
+	repository.ProductRepository
+	restocked bool
+}
+
+func (r *restockingRepository) SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error) {
+	if id == "1" && !r.restocked {
+		r.restocked = true
+		product, err := r.GetByID(ctx, id)
+		if err != nil {
+			return false, err
+		}
+		product.Stock = 7
+		if err := r.Update(ctx, product); err != nil {
+			return false, err
+		}
+	}
+	return r.ProductRepository.SetCategory(ctx, id, version, category, categoryPath, actor, now)
+}
+
+func TestProductService_RenameCategory_SkipsConcurrentEdit(t *testing.T) {
+	repo := &restockingRepository{ProductRepository: repository.NewMemoryProductRepository()}
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+	ctx := context.Background()
+
+	updated, err := service.RenameCategory(ctx, "electronic", "electronics")
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, updated)
+
+	// The edit survives, and the product waits for the next rename.
+	raced, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, "electronic", raced.Category)
+	assert.Equal(t, 7.0, raced.Stock)
+
+	updated, err = service.RenameCategory(ctx, "electronic", "electronics")
+	require.NoError(t, err)
+	assert.Equal(t, 1, updated)
+}
+
+func TestProductService_RenameCategory_Taxonomy(t *testing.T) {
+	taxonomy, err := models.NewCategoryTaxonomy(map[string]string{"phones": "electronics"})
+	require.NoError(t, err)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryTaxonomy(taxonomy))
+	seedCategories(t, repo)
+	ctx := context.Background()
+
+	_, err = service.RenameCategory(ctx, "electronic", "electronix")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	updated, err := service.RenameCategory(ctx, "electronic", "phones")
+	require.NoError(t, err)
+	assert.Equal(t, 3, updated)
+	moved, err := repo.GetByID(ctx, "1")
+	require.NoError(t, err)
+	assert.Equal(t, []string{"electronics", "phones"}, moved.CategoryPath)
+}
+
+func TestProductService_GetProductsByCategory_Subcategories(t *testing.T) {
+	taxonomy, err := models.NewCategoryTaxonomy(map[string]string{
+		"phones":      "electronics",
+		"accessories": "phones",
+	})
+	require.NoError(t, err)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryTaxonomy(taxonomy))
+	ctx := context.Background()
+	for _, product := range []*models.Product{
+		{ID: "tv", Category: "electronics", IsActive: true},
+		{ID: "phone", Category: "phones", IsActive: true},
+		{ID: "case", Category: "accessories", IsActive: true},
+		{ID: "old-case", Category: "accessories", IsActive: false},
+		{ID: "rake", Category: "garden", IsActive: true},
+	} {
+		require.NoError(t, repo.Create(ctx, product))
+	}
+
+	ids := func(list *models.ProductList) []string {
+		var ids []string
+		for _, product := range list.Products {
+			ids = append(ids, product.ID)
+		}
+		return ids
+	}
+
+	exact, err := service.GetProductsByCategory(ctx, "electronics", models.ListOptions{})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tv"}, ids(exact))
+
+	subtree, err := service.GetProductsByCategory(ctx, "electronics", models.ListOptions{Subcategories: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"tv", "phone", "case"}, ids(subtree))
+
+	subtree, err = service.GetProductsByCategory(ctx, "phones", models.ListOptions{Subcategories: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"phone", "case"}, ids(subtree))
+
+	leaf, err := service.GetProductsByCategory(ctx, "garden", models.ListOptions{Subcategories: true})
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{"rake"}, ids(leaf))
+}
+
+func TestProductService_CreateProduct_RecordsCategoryPath(t *testing.T) {
+	taxonomy, err := models.NewCategoryTaxonomy(map[string]string{"phones": "electronics", "tools": "garden"})
+	require.NoError(t, err)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryTaxonomy(taxonomy))
+	ctx := context.Background()
+
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Phone", Price: 300, Category: "phones", SKU: "PHN-1", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"electronics", "phones"}, product.CategoryPath)
+
+	garden := "garden"
+	updated, err := service.UpdateProduct(ctx, product.ID, models.UpdateProductRequest{Category: &garden})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"garden"}, updated.CategoryPath)
+}
+
+func TestProductService_CategoryOutsideTaxonomy(t *testing.T) {
+	taxonomy, err := models.NewCategoryTaxonomy(map[string]string{"phones": "electronics"})
+	require.NoError(t, err)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryTaxonomy(taxonomy))
+	ctx := context.Background()
+
+	_, err = service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Rake", Price: 20, Category: "garden", SKU: "RAK-1", Stock: floatPtr(1),
+	})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "category", fieldErr.Field)
+
+	_, err = service.CreateDraft(ctx, models.CreateProductRequest{Name: "Rake", Category: "garden"})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	// A product filed before the taxonomy changed can still be edited.
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "legacy", Name: "Rake", Category: "garden", Price: 20, IsActive: true}))
+	name, category := "Leaf rake", "garden"
+	_, err = service.UpdateProduct(ctx, "legacy", models.UpdateProductRequest{Name: &name, Category: &category})
+	require.NoError(t, err)
+
+	category = "lawn"
+	_, err = service.UpdateProduct(ctx, "legacy", models.UpdateProductRequest{Category: &category})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
@@ -0,0 +1,40 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// changeFeedSort orders the change feed oldest change first, so a consumer
+// can resume from the last updated_at it saw.
+var changeFeedSort = SortSpec{Field: "updated_at"}
+
+// GetProductChanges returns one page of the products updated at or after
+// since, including inactive and soft-deleted ones. Unlike the other
+// listings the feed is always paginated, DefaultPageLimit at a time unless
+// opts asks otherwise.
+func (s *productService) GetProductChanges(ctx context.Context, since time.Time, opts models.ListOptions) (*models.ProductList, error) {
+	if since.IsZero() {
+		return nil, fmt.Errorf("%w: since is required", ErrInvalidQuery)
+	}
+	if opts.Limit == 0 {
+		opts.Limit = DefaultPageLimit
+	}
+	if _, _, err := pageBounds(opts); err != nil {
+		return nil, err
+	}
+
+	products, err := s.repo.GetUpdatedSince(ctx, since)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product changes: %w", err)
+	}
+
+	sortProducts(products.Products, changeFeedSort)
+	if err := paginate(products, opts); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
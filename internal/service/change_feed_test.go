@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_GetProductChanges_IncludesSinceBoundary(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	since := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	deletedAt := since.Add(time.Hour)
+
+	for i, product := range []*models.Product{
+		{ID: "before", UpdatedAt: since.Add(-time.Nanosecond), IsActive: true},
+		{ID: "deleted", UpdatedAt: deletedAt, DeletedAt: &deletedAt},
+		{ID: "at", UpdatedAt: since, IsActive: true},
+		{ID: "after", UpdatedAt: since.Add(time.Minute), IsActive: true},
+	} {
+		product.SKU = fmt.Sprintf("SKU-%d", i)
+		require.NoError(t, repo.Create(context.Background(), product))
+	}
+	service := NewProductService(repo)
+
+	list, err := service.GetProductChanges(context.Background(), since, models.ListOptions{})
+
+	require.NoError(t, err)
+	var ids []string
+	for _, product := range list.Products {
+		ids = append(ids, product.ID)
+	}
+	assert.Equal(t, []string{"at", "after", "deleted"}, ids)
+	assert.Equal(t, DefaultPageLimit, list.Limit)
+	assert.Empty(t, list.NextCursor)
+
+	list, err = service.GetProductChanges(context.Background(), since, models.ListOptions{Limit: 2})
+
+	require.NoError(t, err)
+	assert.Len(t, list.Products, 2)
+	assert.Equal(t, 3, list.Total)
+	assert.NotEmpty(t, list.NextCursor)
+}
+
+func TestProductService_GetProductChanges_RequiresSince(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	_, err := service.GetProductChanges(context.Background(), time.Time{}, models.ListOptions{})
+
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,41 @@
+package service
+
+import (
+	"context"
+
+	"product-service/internal/models"
+)
+
+// Changes receives the fields an update changed, as computed by
+// models.Diff. A dry run records the changes it would have made.
+type Changes struct {
+	Fields []models.FieldChange
+}
+
+type changesKey struct{}
+
+// WithChanges asks updates on ctx to record what they changed in the
+// returned Changes.
+func WithChanges(ctx context.Context) (context.Context, *Changes) {
+	changes := &Changes{Fields: []models.FieldChange{}}
+	return context.WithValue(ctx, changesKey{}, changes), changes
+}
+
+// recordChanges stores the diff between before and after when the caller
+// asked for it.
+func recordChanges(ctx context.Context, before, after *models.Product) []models.FieldChange {
+	fields := models.Diff(before, after)
+	if changes, ok := ctx.Value(changesKey{}).(*Changes); ok {
+		changes.Fields = fields
+	}
+	return fields
+}
+
+// changedFields names the fields in changes, for logging without values.
+func changedFields(changes []models.FieldChange) []string {
+	names := make([]string, 0, len(changes))
+	for _, change := range changes {
+		names = append(names, change.Field)
+	}
+	return names
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductService_RestoreProduct_SoftDeleted(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget", IsActive: true}))
+	require.NoError(t, service.SoftDeleteProduct(ctx, "test-id"))
+
+	deleted, err := repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.True(t, deleted.IsDeleted())
+	assert.False(t, deleted.IsActive)
+
+	restored, err := service.RestoreProduct(ctx, "test-id")
+
+	require.NoError(t, err)
+	assert.Nil(t, restored.DeletedAt)
+	assert.True(t, restored.IsActive)
+
+	stored, err := repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.False(t, stored.IsDeleted())
+	assert.True(t, stored.IsActive)
+}
+
+func TestProductService_SoftDeleted_RejectsUpdates(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Name: "Widget", Category: "tools", IsActive: true}))
+	require.NoError(t, service.SoftDeleteProduct(ctx, "test-id"))
+
+	active := true
+	_, err := service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{IsActive: &active})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	_, err = service.PatchProduct(ctx, "test-id", []models.PatchOperation{{Op: "replace", Path: "/name", Value: []byte(`"Gadget"`)}})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	byCategory, err := service.BulkSetStatus(ctx, models.BulkStatusRequest{Category: "tools", Active: &active})
+	require.NoError(t, err)
+	assert.Zero(t, byCategory.Matched)
+
+	byID, err := service.BulkSetStatus(ctx, models.BulkStatusRequest{IDs: []string{"test-id"}, Active: &active})
+	require.NoError(t, err)
+	assert.Zero(t, byID.Changed)
+	assert.Len(t, byID.Failures, 1)
+
+	stored, err := repo.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	assert.True(t, stored.IsDeleted())
+	assert.False(t, stored.IsActive)
+	assert.Equal(t, "Widget", stored.Name)
+}
+
+func TestProductService_RestoreProduct_NotDeleted(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := &models.Product{ID: "test-id", IsActive: true, UpdatedAt: updatedAt}
+	mockRepo.On("GetByID", "test-id").Return(existing, nil)
+
+	product, err := service.RestoreProduct(context.Background(), "test-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, updatedAt, product.UpdatedAt)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_RestoreProduct_NotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetByID", "missing").Return((*models.Product)(nil), nil)
+
+	_, err := service.RestoreProduct(context.Background(), "missing")
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_DeleteProduct_HardDeleteDisabled(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithHardDeleteDisabled(true))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", IsActive: true}))
+
+	err := service.DeleteProduct(ctx, "test-id")
+	assert.ErrorIs(t, err, ErrHardDeleteDisabled)
+
+	err = service.DeleteProduct(WithDryRun(ctx), "test-id")
+	assert.ErrorIs(t, err, ErrHardDeleteDisabled)
+
+	stored, err := repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.NotNil(t, stored, "product must not be removed")
+
+	require.NoError(t, service.SoftDeleteProduct(ctx, "test-id"))
+	stored, err = repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.True(t, stored.IsDeleted())
+}
+
+func TestProductService_DeleteProduct_ExpectedVersion(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Widget", Price: 10, Category: "tools", SKU: "TOOL-001", Stock: floatPtr(5),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), product.Version)
+
+	name := "Widget Pro"
+	_, err = service.UpdateProduct(ctx, product.ID, models.UpdateProductRequest{Name: &name})
+	require.NoError(t, err)
+
+	// A client still holding version 1 must not delete the renamed product.
+	err = service.DeleteProduct(WithExpectedVersion(ctx, 1), product.ID)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	err = service.SoftDeleteProduct(WithExpectedVersion(ctx, 1), product.ID)
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+
+	stored, err := repo.GetByID(ctx, product.ID)
+	require.NoError(t, err)
+	require.NotNil(t, stored)
+	assert.False(t, stored.IsDeleted())
+
+	require.NoError(t, service.DeleteProduct(WithExpectedVersion(ctx, 2), product.ID))
+	stored, err = repo.GetByID(ctx, product.ID)
+	require.NoError(t, err)
+	assert.Nil(t, stored)
+}
+
+func TestProductService_DeleteProduct_VersionRace(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	// The read sees version 2, but an update lands before the delete.
+	mockRepo.On("GetByID", "test-id").Return(&models.Product{ID: "test-id", Version: 2}, nil)
+	mockRepo.On("DeleteVersion", "test-id", int64(2)).Return(false, nil)
+
+	err := service.DeleteProduct(WithExpectedVersion(context.Background(), 2), "test-id")
+
+	assert.ErrorIs(t, err, ErrPreconditionFailed)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_SoftDeleteProduct_UsesClock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	deletedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(deletedAt)
+	service := NewProductService(repo, WithClock(clock))
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Name: "Widget", IsActive: true}))
+	require.NoError(t, service.SoftDeleteProduct(ctx, "test-id"))
+
+	deleted, err := repo.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	require.NotNil(t, deleted.DeletedAt)
+	assert.Equal(t, deletedAt, *deleted.DeletedAt)
+
+	clock.Advance(time.Hour)
+	restored, err := service.RestoreProduct(ctx, "test-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, deletedAt.Add(time.Hour), restored.UpdatedAt)
+}
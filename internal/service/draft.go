@@ -0,0 +1,236 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// CreateDraft stores a draft product to be completed and published later.
+// Only the name is required; the rest of a create's validation is deferred
+// to PublishProduct, though values that are given must still be well
+// formed. Drafts are inactive, so they stay out of every public listing.
+func (s *productService) CreateDraft(ctx context.Context, req models.CreateProductRequest) (*models.Product, error) {
+	if err := s.sanitizeCreateRequest(&req); err != nil {
+		s.logRejected(ctx, "create draft", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	s.applyDefaultStock(&req)
+
+	if err := s.validateDraftRequest(req); err != nil {
+		s.logRejected(ctx, "create draft", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	if err := s.checkSKUAvailable(ctx, "create draft", "", req.SKU); err != nil {
+		return nil, err
+	}
+
+	product := models.NewProductWithID(s.idScheme.NewID(), req, s.clock)
+	if err := validateImageKeys(product.ID, product.Images, nil); err != nil {
+		s.logRejected(ctx, "create draft", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	product.Status = models.StatusDraft
+	product.IsActive = false
+	product.CategoryPath = s.categoryPath(product.Category)
+	product.CreatedBy = auth.ActorID(ctx)
+	product.UpdatedBy = product.CreatedBy
+
+	slug, err := s.uniqueSlug(ctx, product.Name, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	product.Slug = slug
+
+	if IsDryRun(ctx) {
+		return product, nil
+	}
+
+	if err := s.repo.Create(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "draft create failed", "sku", product.SKU, "error", err)
+		return nil, fmt.Errorf("failed to create draft: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "draft created",
+		"product_id", product.ID,
+		"actor", product.CreatedBy,
+	)
+	s.publish(ctx, models.EventProductCreated, product.ID)
+
+	return product, nil
+}
+
+// validateDraftRequest checks the fields a draft sets without requiring
+// the ones it leaves for later.
+func (s *productService) validateDraftRequest(req models.CreateProductRequest) error {
+	if req.Name == "" {
+		return fieldError("name", "product name is required")
+	}
+	if err := s.validateText(req.Name, req.Description); err != nil {
+		return err
+	}
+	if err := validateFinite("price", req.Price); err != nil {
+		return err
+	}
+	if req.Price < 0 {
+		return fieldError("price", "product price cannot be negative")
+	}
+	if req.Category != "" {
+		if err := s.validateCategory(req.Category); err != nil {
+			return err
+		}
+	}
+	stock := req.StockOrZero()
+	if err := validateFinite("stock", stock); err != nil {
+		return err
+	}
+	if stock < 0 {
+		return fieldError("stock", "product stock cannot be negative")
+	}
+	if err := s.validateMaxStock(stock); err != nil {
+		return err
+	}
+	if !models.IsValidUnit(req.Unit) {
+		return fieldError("unit", "product unit %q is not supported", req.Unit)
+	}
+	if !models.IsValidStockForUnit(stock, req.Unit) {
+		return fieldError("stock", "product stock must be a whole number for unit %q", models.NormalizeUnit(req.Unit))
+	}
+	if field := models.NegativeShippingAttribute(&req.WeightGrams, &req.LengthMM, &req.WidthMM, &req.HeightMM); field != "" {
+		return fieldError(field, "product %s cannot be negative", field)
+	}
+	if err := validateBundleItems(req.BundleItems); err != nil {
+		return err
+	}
+	if err := s.validateAvailableFrom(req.AvailableFrom); err != nil {
+		return err
+	}
+	return s.validateTags(req.Tags)
+}
+
+// PublishProduct publishes a draft, or an archived product, making it
+// active. The product must pass everything a create checks; a draft without
+// a SKU is numbered as it is written when its category has a SKU sequence.
+// Publishing a published product returns it unchanged, and a deleted
+// product is not found until it is restored.
+func (s *productService) PublishProduct(ctx context.Context, id string) (*models.Product, error) {
+	return retryOnConflict(func() (*models.Product, error) {
+		return s.publishProduct(ctx, id)
+	})
+}
+
+func (s *productService) publishProduct(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.productForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnmodifiedSince(ctx, product); err != nil {
+		return nil, err
+	}
+	if product.CurrentStatus() == models.StatusPublished {
+		return product, nil
+	}
+	recordPrevious(ctx, product)
+
+	before := *product
+	numbered := false
+	if product.SKU == "" {
+		product.SKU, numbered = s.skuPlaceholder(product.Category)
+	}
+	if err := s.validatePublish(ctx, product); err != nil {
+		s.logRejected(ctx, "publish", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	if err := s.validateBundleComponents(ctx, "publish", id, product.BundleItems); err != nil {
+		return nil, err
+	}
+	// Another product may have taken the SKU while this one was a draft.
+	if !numbered {
+		if err := s.checkSKUAvailable(ctx, "publish", id, product.SKU); err != nil {
+			return nil, err
+		}
+	}
+
+	product.Status = models.StatusPublished
+	product.IsActive = true
+	product.StockDeactivated = false
+	product.UpdatedAt = s.clock.Now()
+	product.UpdatedBy = auth.ActorID(ctx)
+
+	if IsDryRun(ctx) {
+		if numbered {
+			product.SKU = ""
+		}
+		recordChanges(ctx, &before, product)
+		return product, nil
+	}
+
+	if numbered {
+		if product.SKU, err = s.nextSKU(ctx, product.Category); err != nil {
+			return nil, err
+		}
+	}
+	recordChanges(ctx, &before, product)
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "product publish failed", "product_id", id, "error", err)
+		return nil, fmt.Errorf("failed to publish product: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "product published",
+		"product_id", id,
+		"sku", product.SKU,
+		"from", before.CurrentStatus(),
+		"actor", product.UpdatedBy,
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+	return product, nil
+}
+
+// validatePublish holds the product to a create's validation.
+func (s *productService) validatePublish(ctx context.Context, product *models.Product) error {
+	stock := product.Stock
+	err := s.validateCreateRequest(models.CreateProductRequest{
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Category:    product.Category,
+		SKU:         product.SKU,
+		Stock:       &stock,
+		Unit:        product.Unit,
+		Tags:        product.Tags,
+		Images:      product.Images,
+		WeightGrams: product.WeightGrams,
+		LengthMM:    product.LengthMM,
+		WidthMM:     product.WidthMM,
+		HeightMM:    product.HeightMM,
+		BundleItems: product.BundleItems,
+	})
+	if err != nil {
+		return err
+	}
+	return s.validateRegionCurrency(ctx)
+}
+
+// GetDraftProducts lists the drafts that have not been deleted, most
+// recently updated first, for the admin tool.
+func (s *productService) GetDraftProducts(ctx context.Context) ([]*models.Product, error) {
+	inactive, err := s.repo.GetInactive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get draft products: %w", err)
+	}
+
+	drafts := make([]*models.Product, 0)
+	for _, product := range inactive {
+		if product.IsDraft() && !product.IsDeleted() {
+			drafts = append(drafts, product)
+		}
+	}
+	sort.SliceStable(drafts, func(i, j int) bool {
+		return drafts[i].UpdatedAt.After(drafts[j].UpdatedAt)
+	})
+	return drafts, nil
+}
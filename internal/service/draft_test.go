@@ -0,0 +1,163 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_PublishProduct_FromDraft(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, models.CreateProductRequest{Name: "Lamp"})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusDraft, draft.Status)
+	assert.False(t, draft.IsActive)
+
+	// A draft is held to a create's validation when it is published.
+	_, err = service.PublishProduct(ctx, draft.ID)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "price", fieldErr.Field)
+
+	price, category, sku := 40.0, "lighting", "LAMP-001"
+	_, err = service.UpdateProduct(ctx, draft.ID, models.UpdateProductRequest{Price: &price, Category: &category, SKU: &sku})
+	require.NoError(t, err)
+
+	ctx, changes := WithChanges(ctx)
+	published, err := service.PublishProduct(ctx, draft.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusPublished, published.Status)
+	assert.True(t, published.IsActive)
+	assert.Contains(t, changes.Fields, models.FieldChange{Field: "status", Before: models.StatusDraft, After: models.StatusPublished})
+
+	stored, err := repo.GetByID(ctx, draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusPublished, stored.Status)
+
+	// Publishing again changes nothing.
+	again, err := service.PublishProduct(context.Background(), draft.ID)
+
+	require.NoError(t, err)
+	assert.Equal(t, stored.Version, again.Version)
+}
+
+func TestProductService_PublishProduct_SoftDeleted(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, models.CreateProductRequest{
+		Name: "Lamp", Price: 40, Category: "lighting", SKU: "LAMP-001",
+	})
+	require.NoError(t, err)
+	require.NoError(t, service.SoftDeleteProduct(ctx, draft.ID))
+
+	_, err = service.PublishProduct(ctx, draft.ID)
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_PublishProduct_NumbersSKUOnlyOnWrite(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(),
+		WithCategorySKUPrefix(map[string]string{"lighting": "LGT-"}),
+		WithSKUSequences(repository.NewMemorySequenceRepository()),
+	)
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, models.CreateProductRequest{Name: "Lamp", Price: 40, Category: "lighting"})
+	require.NoError(t, err)
+
+	preview, err := service.PublishProduct(WithDryRun(ctx), draft.ID)
+	require.NoError(t, err)
+	assert.Empty(t, preview.SKU)
+
+	published, err := service.PublishProduct(ctx, draft.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "LGT-000001", published.SKU)
+}
+
+func TestProductService_CreateDraft_Validation(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	for _, req := range []models.CreateProductRequest{
+		{},
+		{Name: "Lamp", Price: -1},
+		{Name: "Lamp", Unit: "bushel"},
+	} {
+		_, err := service.CreateDraft(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidProduct, req)
+	}
+}
+
+func TestProductService_Drafts_ExcludedFromListings(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	published, err := service.CreateProduct(ctx, models.CreateProductRequest{Name: "Desk", Price: 120, Category: "furniture", SKU: "DESK-001"})
+	require.NoError(t, err)
+	draft, err := service.CreateDraft(ctx, models.CreateProductRequest{Name: "Chair", Category: "furniture"})
+	require.NoError(t, err)
+
+	list, err := service.GetAllProducts(ctx, models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Products, 1)
+	assert.Equal(t, published.ID, list.Products[0].ID)
+
+	list, err = service.GetProductsByCategory(ctx, "furniture", models.ListOptions{})
+	require.NoError(t, err)
+	require.Len(t, list.Products, 1)
+	assert.Equal(t, published.ID, list.Products[0].ID)
+
+	drafts, err := service.GetDraftProducts(ctx)
+	require.NoError(t, err)
+	require.Len(t, drafts, 1)
+	assert.Equal(t, draft.ID, drafts[0].ID)
+}
+
+func TestProductService_UpdateProduct_CannotActivateDraft(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+	ctx := context.Background()
+
+	draft, err := service.CreateDraft(ctx, models.CreateProductRequest{Name: "Lamp"})
+	require.NoError(t, err)
+
+	active := true
+	_, err = service.UpdateProduct(ctx, draft.ID, models.UpdateProductRequest{IsActive: &active})
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "is_active", fieldErr.Field)
+}
+
+func TestProductService_DeactivateArchivesAndPublishRestores(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	// Stored before statuses existed.
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "legacy", Name: "Desk", Price: 120, Category: "furniture", SKU: "DESK-001", IsActive: true}))
+
+	inactive := false
+	product, err := service.UpdateProduct(ctx, "legacy", models.UpdateProductRequest{IsActive: &inactive})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusArchived, product.Status)
+
+	product, err = service.PublishProduct(ctx, "legacy")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.StatusPublished, product.Status)
+	assert.True(t, product.IsActive)
+}
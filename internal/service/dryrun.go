@@ -0,0 +1,17 @@
+package service
+
+import "context"
+
+type dryRunKey struct{}
+
+// WithDryRun marks ctx so that mutations run their validation and return the
+// would-be result without writing to the repository.
+func WithDryRun(ctx context.Context) context.Context {
+	return context.WithValue(ctx, dryRunKey{}, true)
+}
+
+// IsDryRun reports whether ctx was marked with WithDryRun.
+func IsDryRun(ctx context.Context) bool {
+	dryRun, _ := ctx.Value(dryRunKey{}).(bool)
+	return dryRun
+}
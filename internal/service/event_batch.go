@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"product-service/internal/models"
+)
+
+// BatchPublisher delivers several events in one call.
+type BatchPublisher interface {
+	PublishBatch(ctx context.Context, events []models.ProductEvent) error
+}
+
+// BatchingPublisher coalesces the events published within a window into a
+// single batch, so a burst of changes such as a bulk import reaches
+// consumers as one message. The window starts with the first event after a
+// flush. Repeats of the same event type for the same product within a
+// window collapse into the latest one.
+//
+// Events are held in memory until the window closes; call Flush on shutdown
+// so none are lost.
+type BatchingPublisher struct {
+	target BatchPublisher
+	window time.Duration
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	pending []models.ProductEvent
+	index   map[batchKey]int
+	timer   *time.Timer
+}
+
+type batchKey struct {
+	eventType string
+	productID string
+}
+
+func NewBatchingPublisher(target BatchPublisher, window time.Duration, logger *slog.Logger) *BatchingPublisher {
+	return &BatchingPublisher{
+		target: target,
+		window: window,
+		logger: logger,
+		index:  make(map[batchKey]int),
+	}
+}
+
+// Publish queues the event for the current batch. It never fails; delivery
+// errors are logged when the batch is flushed.
+func (p *BatchingPublisher) Publish(ctx context.Context, event models.ProductEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	key := batchKey{eventType: event.Type, productID: event.ProductID}
+	if i, ok := p.index[key]; ok {
+		p.pending[i] = event
+		return nil
+	}
+	p.index[key] = len(p.pending)
+	p.pending = append(p.pending, event)
+
+	if p.timer == nil {
+		p.timer = time.AfterFunc(p.window, p.flushWindow)
+	}
+	return nil
+}
+
+// Flush publishes the pending events now instead of at the end of the
+// window.
+func (p *BatchingPublisher) Flush(ctx context.Context) error {
+	events := p.take()
+	if len(events) == 0 {
+		return nil
+	}
+	return p.target.PublishBatch(ctx, events)
+}
+
+func (p *BatchingPublisher) flushWindow() {
+	if err := p.Flush(context.Background()); err != nil {
+		p.logger.Error("product event batch publish failed", "error", err)
+	}
+}
+
+// take removes and returns the pending events, ending the window.
+func (p *BatchingPublisher) take() []models.ProductEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.timer != nil {
+		p.timer.Stop()
+		p.timer = nil
+	}
+	events := p.pending
+	p.pending = nil
+	clear(p.index)
+	return events
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+type recordingBatchPublisher struct {
+	mu      sync.Mutex
+	batches [][]models.ProductEvent
+	err     error
+	sent    chan struct{}
+}
+
+func newRecordingBatchPublisher() *recordingBatchPublisher {
+	return &recordingBatchPublisher{sent: make(chan struct{}, 10)}
+}
+
+func (p *recordingBatchPublisher) PublishBatch(ctx context.Context, events []models.ProductEvent) error {
+	p.mu.Lock()
+	p.batches = append(p.batches, events)
+	p.mu.Unlock()
+	p.sent <- struct{}{}
+	return p.err
+}
+
+func (p *recordingBatchPublisher) recorded() [][]models.ProductEvent {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.batches
+}
+
+func TestBatchingPublisher_CoalescesBurst(t *testing.T) {
+	target := newRecordingBatchPublisher()
+	batcher := NewBatchingPublisher(target, 50*time.Millisecond, slog.New(slog.DiscardHandler))
+	service := NewProductService(repository.NewMemoryProductRepository(), WithEventPublisher(batcher))
+
+	const n = 20
+	for i := 0; i < n; i++ {
+		_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+			Name: fmt.Sprintf("Item %d", i), Price: 1, Category: "bulk", SKU: fmt.Sprintf("BULK-%d", i),
+		})
+		require.NoError(t, err)
+	}
+
+	select {
+	case <-target.sent:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch was not published when the window closed")
+	}
+
+	batches := target.recorded()
+	require.Len(t, batches, 1)
+	assert.Len(t, batches[0], n)
+	for _, event := range batches[0] {
+		assert.Equal(t, models.EventProductCreated, event.Type)
+	}
+}
+
+func TestBatchingPublisher_CollapsesRepeats(t *testing.T) {
+	target := newRecordingBatchPublisher()
+	batcher := NewBatchingPublisher(target, time.Hour, slog.New(slog.DiscardHandler))
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, batcher.Publish(context.Background(), models.ProductEvent{
+			Type: models.EventProductUpdated, ProductID: "p1", RequestID: fmt.Sprintf("req-%d", i),
+		}))
+	}
+	require.NoError(t, batcher.Publish(context.Background(), models.ProductEvent{
+		Type: models.EventProductDeleted, ProductID: "p1",
+	}))
+
+	require.NoError(t, batcher.Flush(context.Background()))
+
+	batches := target.recorded()
+	require.Len(t, batches, 1)
+	require.Len(t, batches[0], 2)
+	assert.Equal(t, "req-2", batches[0][0].RequestID)
+	assert.Equal(t, models.EventProductDeleted, batches[0][1].Type)
+}
+
+func TestBatchingPublisher_FlushDeliversPendingEvents(t *testing.T) {
+	target := newRecordingBatchPublisher()
+	target.err = errors.New("broker down")
+	batcher := NewBatchingPublisher(target, time.Hour, slog.New(slog.DiscardHandler))
+
+	require.NoError(t, batcher.Flush(context.Background()))
+	assert.Empty(t, target.recorded())
+
+	require.NoError(t, batcher.Publish(context.Background(), models.ProductEvent{Type: models.EventProductCreated, ProductID: "p1"}))
+	assert.EqualError(t, batcher.Flush(context.Background()), "broker down")
+	require.Len(t, target.recorded(), 1)
+
+	// A flushed batch is not delivered again.
+	require.NoError(t, batcher.Flush(context.Background()))
+	assert.Len(t, target.recorded(), 1)
+}
@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+	"product-service/internal/requestid"
+)
+
+// EventPublisher delivers product change events to downstream consumers.
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.ProductEvent) error
+}
+
+// WithEventPublisher publishes an event after every successful product
+// write. By default no events are published.
+func WithEventPublisher(publisher EventPublisher) Option {
+	return func(s *productService) {
+		s.events = publisher
+	}
+}
+
+// publish sends an event for a write that has already been stored. A failed
+// publish is logged rather than returned, since the write itself succeeded.
+func (s *productService) publish(ctx context.Context, eventType, productID string) {
+	if s.events == nil {
+		return
+	}
+	event := models.ProductEvent{
+		Type:       eventType,
+		ProductID:  productID,
+		Actor:      auth.ActorID(ctx),
+		RequestID:  requestid.FromContext(ctx),
+		OccurredAt: s.clock.Now().UTC(),
+	}
+	if err := s.events.Publish(ctx, event); err != nil {
+		s.logger.ErrorContext(ctx, "product event publish failed",
+			"type", eventType,
+			"product_id", productID,
+			"error", err,
+		)
+	}
+}
+
+// LogPublisher publishes events as log records, for deployments without a
+// message broker.
+type LogPublisher struct {
+	logger *slog.Logger
+}
+
+func NewLogPublisher(logger *slog.Logger) *LogPublisher {
+	return &LogPublisher{logger: logger}
+}
+
+func (p *LogPublisher) Publish(ctx context.Context, event models.ProductEvent) error {
+	p.logger.InfoContext(ctx, "product event",
+		"type", event.Type,
+		"product_id", event.ProductID,
+		"actor", event.Actor,
+		"request_id", event.RequestID,
+		"occurred_at", event.OccurredAt,
+	)
+	return nil
+}
+
+// PublishBatch logs the batch as a single record.
+func (p *LogPublisher) PublishBatch(ctx context.Context, events []models.ProductEvent) error {
+	p.logger.InfoContext(ctx, "product event batch",
+		"count", len(events),
+		"events", events,
+	)
+	return nil
+}
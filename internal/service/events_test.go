@@ -0,0 +1,89 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+	"product-service/internal/requestid"
+)
+
+type recordingPublisher struct {
+	events []models.ProductEvent
+	err    error
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event models.ProductEvent) error {
+	p.events = append(p.events, event)
+	return p.err
+}
+
+func TestProductService_PublishesEventsWithRequestID(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := NewProductService(repository.NewMemoryProductRepository(), WithEventPublisher(publisher))
+
+	ctx := requestid.With(context.Background(), "req-123")
+	ctx = auth.WithPrincipal(ctx, auth.Principal{ID: "user-42"})
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+
+	price := 25.0
+	_, err = service.UpdateProduct(requestid.With(context.Background(), "req-456"), product.ID,
+		models.UpdateProductRequest{Price: &price})
+	require.NoError(t, err)
+
+	require.Len(t, publisher.events, 2)
+	created := publisher.events[0]
+	assert.Equal(t, models.EventProductCreated, created.Type)
+	assert.Equal(t, product.ID, created.ProductID)
+	assert.Equal(t, "req-123", created.RequestID)
+	assert.Equal(t, "user-42", created.Actor)
+	assert.False(t, created.OccurredAt.IsZero())
+
+	assert.Equal(t, models.EventProductUpdated, publisher.events[1].Type)
+	assert.Equal(t, "req-456", publisher.events[1].RequestID)
+}
+
+func TestProductService_PublishFailureKeepsWrite(t *testing.T) {
+	logger, records := captureLogs()
+	publisher := &recordingPublisher{err: errors.New("broker down")}
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithEventPublisher(publisher), WithLogger(logger))
+
+	ctx := requestid.With(context.Background(), "req-789")
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+
+	stored, err := repo.GetByID(context.Background(), product.ID)
+	require.NoError(t, err)
+	assert.NotNil(t, stored)
+
+	// Audit records logged with the request context carry its ID too.
+	logged := records()
+	require.Len(t, logged, 2)
+	assert.Equal(t, "product created", logged[0]["msg"])
+	assert.Equal(t, "req-789", logged[0]["request_id"])
+	assert.Equal(t, "product event publish failed", logged[1]["msg"])
+}
+
+func TestProductService_DryRunPublishesNothing(t *testing.T) {
+	publisher := &recordingPublisher{}
+	service := NewProductService(repository.NewMemoryProductRepository(), WithEventPublisher(publisher))
+
+	_, err := service.CreateProduct(WithDryRun(context.Background()), models.CreateProductRequest{
+		Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+
+	assert.Empty(t, publisher.events)
+}
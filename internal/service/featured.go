@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sort"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// GetFeaturedProducts returns the featured active products by rank. Products
+// sharing a rank are ordered by ID so the list is stable.
+func (s *productService) GetFeaturedProducts(ctx context.Context) ([]*models.Product, error) {
+	products, err := s.repo.GetFeatured(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get featured products: %w", err)
+	}
+
+	featured := make([]*models.Product, 0, len(products))
+	for _, product := range products {
+		if product.FeaturedRank != nil {
+			featured = append(featured, product)
+		}
+	}
+	sort.Slice(featured, func(i, j int) bool {
+		a, b := featured[i], featured[j]
+		if *a.FeaturedRank != *b.FeaturedRank {
+			return *a.FeaturedRank < *b.FeaturedRank
+		}
+		return a.ID < b.ID
+	})
+	return featured, nil
+}
+
+// SetFeatured features the product at rank, 1 being listed first, or moves
+// an already featured product to rank. Other featured products keep their
+// ranks, so ranks may tie.
+func (s *productService) SetFeatured(ctx context.Context, id string, rank int) (*models.Product, error) {
+	if rank < 1 {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, fieldError("rank", "featured rank must be at least 1"))
+	}
+	return s.updateFeatured(ctx, id, &rank)
+}
+
+// UnsetFeatured removes the product from the featured list. Unfeaturing a
+// product that is not featured is not an error.
+func (s *productService) UnsetFeatured(ctx context.Context, id string) (*models.Product, error) {
+	return s.updateFeatured(ctx, id, nil)
+}
+
+// ReorderFeatured ranks the listed products 1, 2, ... in the order given.
+// Every product must already be featured; featured products left out keep
+// their ranks. Products are written one at a time, so a failure part way
+// leaves the earlier ones reordered.
+func (s *productService) ReorderFeatured(ctx context.Context, ids []string) ([]*models.Product, error) {
+	if len(ids) == 0 || len(ids) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a reorder must list between 1 and %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	// Every product is checked before any is written.
+	seen := make(map[string]bool, len(ids))
+	for _, id := range ids {
+		if seen[id] {
+			return nil, fmt.Errorf("%w: product %q is listed more than once", ErrInvalidQuery, id)
+		}
+		seen[id] = true
+
+		product, err := s.productForUpdate(ctx, id)
+		if errors.Is(err, ErrProductNotFound) {
+			return nil, fmt.Errorf("%w: product %q not found", ErrInvalidQuery, id)
+		}
+		if err != nil {
+			return nil, err
+		}
+		if !product.Featured {
+			return nil, fmt.Errorf("%w: product %q is not featured", ErrInvalidQuery, id)
+		}
+	}
+
+	products := make([]*models.Product, 0, len(ids))
+	for i, id := range ids {
+		rank := i + 1
+		product, err := s.updateFeatured(ctx, id, &rank)
+		if err != nil {
+			return nil, err
+		}
+		products = append(products, product)
+	}
+	return products, nil
+}
+
+// updateFeatured features the product at rank, or unfeatures it when rank
+// is nil, and stores it if that changes anything.
+func (s *productService) updateFeatured(ctx context.Context, id string, rank *int) (*models.Product, error) {
+	return retryOnConflict(func() (*models.Product, error) {
+		return s.writeFeatured(ctx, id, rank)
+	})
+}
+
+func (s *productService) writeFeatured(ctx context.Context, id string, rank *int) (*models.Product, error) {
+	product, err := s.productForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnmodifiedSince(ctx, product); err != nil {
+		return nil, err
+	}
+
+	featured := rank != nil
+	if product.Featured == featured && (!featured || *product.FeaturedRank == *rank) {
+		return product, nil
+	}
+	recordPrevious(ctx, product)
+
+	before := *product
+	product.Featured, product.FeaturedRank = featured, rank
+	product.UpdatedAt = s.clock.Now()
+	product.UpdatedBy = auth.ActorID(ctx)
+	recordChanges(ctx, &before, product)
+
+	if IsDryRun(ctx) {
+		return product, nil
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "featured update failed", "product_id", id, "error", err)
+		return nil, fmt.Errorf("failed to update featured status: %w", err)
+	}
+
+	attrs := []any{"product_id", id, "featured", featured}
+	if featured {
+		attrs = append(attrs, "rank", *rank)
+	}
+	s.logger.InfoContext(ctx, "product featured status updated", append(attrs, "actor", product.UpdatedBy)...)
+	s.publish(ctx, models.EventProductUpdated, id)
+	return product, nil
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func featuredIDs(products []*models.Product) []string {
+	ids := make([]string, 0, len(products))
+	for _, product := range products {
+		ids = append(ids, product.ID)
+	}
+	return ids
+}
+
+func TestProductService_GetFeaturedProducts_Ordering(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	for _, id := range []string{"d", "c", "b", "a", "retired", "plain"} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: id, IsActive: id != "retired"}))
+	}
+
+	ctx := context.Background()
+	for id, rank := range map[string]int{"d": 1, "c": 3, "b": 2, "a": 2, "retired": 1} {
+		_, err := service.SetFeatured(ctx, id, rank)
+		require.NoError(t, err)
+	}
+
+	featured, err := service.GetFeaturedProducts(ctx)
+
+	require.NoError(t, err)
+	// Ties on rank 2 fall back to ID; inactive and unfeatured products are
+	// left out.
+	assert.Equal(t, []string{"d", "a", "b", "c"}, featuredIDs(featured))
+
+	_, err = service.UnsetFeatured(ctx, "d")
+	require.NoError(t, err)
+	_, err = service.ReorderFeatured(ctx, []string{"c", "b", "a"})
+	require.NoError(t, err)
+
+	featured, err = service.GetFeaturedProducts(ctx)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"c", "b", "a"}, featuredIDs(featured))
+
+	d, _ := repo.GetByID(ctx, "d")
+	assert.False(t, d.Featured)
+	assert.Nil(t, d.FeaturedRank)
+}
+
+func TestProductService_SetFeatured_Validation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "a", IsActive: true}))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "b", IsActive: true}))
+
+	ctx := context.Background()
+	_, err := service.SetFeatured(ctx, "a", 0)
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	_, err = service.SetFeatured(ctx, "missing", 1)
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	_, err = service.SetFeatured(ctx, "a", 1)
+	require.NoError(t, err)
+
+	// Every listed product must be featured, exactly once.
+	for _, ids := range [][]string{nil, {"a", "b"}, {"a", "a"}, {"a", "missing"}} {
+		_, err = service.ReorderFeatured(ctx, ids)
+		assert.ErrorIs(t, err, ErrInvalidQuery, ids)
+	}
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
+
+	"product-service/internal/models"
+	"product-service/internal/storage"
+)
+
+// ErrImageStoreDisabled is returned by AddProductImage when no image store
+// is configured.
+var ErrImageStoreDisabled = errors.New("image store is not configured")
+
+// WithImageStore sets where uploaded product images are kept. Without one,
+// uploads fail with ErrImageStoreDisabled.
+func WithImageStore(store storage.ImageStore) Option {
+	return func(s *productService) {
+		s.images = store
+	}
+}
+
+// AddProductImage stores an image and appends its key to the product's
+// images. contentType must be an image format the store accepts. The image
+// is written before the product, so a failed product update leaves an
+// unreferenced object behind.
+func (s *productService) AddProductImage(ctx context.Context, id, contentType string, body io.ReadSeeker) (*models.Product, string, error) {
+	if s.images == nil {
+		return nil, "", ErrImageStoreDisabled
+	}
+
+	ext, ok := storage.ImageExtension(contentType)
+	if !ok {
+		return nil, "", fmt.Errorf("%w: %w", ErrInvalidProduct,
+			fieldError("image", "unsupported image content type %q", contentType))
+	}
+
+	product, err := s.productForUpdate(ctx, id)
+	if err != nil {
+		return nil, "", err
+	}
+
+	key := storage.ProductImagePrefix(product.ID) + s.idScheme.NewID() + ext
+	if !IsDryRun(ctx) {
+		if err := s.images.Put(ctx, key, body, contentType); err != nil {
+			s.logger.ErrorContext(ctx, "product image upload failed", "product_id", id, "error", err)
+			return nil, "", fmt.Errorf("failed to upload image: %w", err)
+		}
+	}
+
+	product, err = retryOnConflict(func() (*models.Product, error) {
+		current, err := s.productForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		images := append(slices.Clone(current.Images), key)
+		return s.applyUpdate(ctx, current, models.UpdateProductRequest{Images: &images})
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return product, key, nil
+}
@@ -0,0 +1,221 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"slices"
+	"sync"
+	"syscall"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+const (
+	// imageCheckQueueSize bounds the products waiting for a check. Checks
+	// queued past it are dropped.
+	imageCheckQueueSize = 1000
+
+	// imageCheckTimeout bounds a single HEAD request.
+	imageCheckTimeout = 5 * time.Second
+)
+
+// ImageChecker checks in the background whether the image URLs of created
+// and updated products respond, and records the ones that do not in the
+// product's BrokenImages. Writes only queue a check, so they never wait on
+// a remote host. HEAD requests are paced to at most rate per second across
+// all products. Image store keys are not URLs and are never checked.
+type ImageChecker struct {
+	repo     repository.ProductRepository
+	client   *http.Client
+	interval time.Duration
+	logger   *slog.Logger
+	queue    chan string
+
+	mu   sync.Mutex
+	next time.Time
+}
+
+func NewImageChecker(repo repository.ProductRepository, rate int, logger *slog.Logger) *ImageChecker {
+	return &ImageChecker{
+		repo:     repo,
+		client:   newImageCheckClient(),
+		interval: time.Second / time.Duration(rate),
+		logger:   logger,
+		queue:    make(chan string, imageCheckQueueSize),
+	}
+}
+
+// newImageCheckClient returns the client image checks use. Image URLs come
+// from API callers, so it never connects to an address that is not public,
+// and it does not follow redirects, which could point it at one. A redirect
+// still answers the request, so the image counts as reachable. Proxies are
+// not used, since the address checked would then be the proxy's.
+func newImageCheckClient() *http.Client {
+	dialer := &net.Dialer{Timeout: imageCheckTimeout, Control: refuseNonPublic}
+	return &http.Client{
+		Timeout: imageCheckTimeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			TLSHandshakeTimeout: imageCheckTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+// errNonPublicAddress is returned when an image host resolves to an address
+// image checks may not reach.
+var errNonPublicAddress = errors.New("image host address is not public")
+
+// refuseNonPublic is a net.Dialer Control function. It runs after the host
+// is resolved, so it sees the address actually dialled and a DNS name cannot
+// smuggle in a private one. Loopback, private, link-local (which includes
+// the cloud metadata address 169.254.169.254), multicast and unspecified
+// addresses are refused.
+func refuseNonPublic(network, address string, _ syscall.RawConn) error {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return err
+	}
+	ip, err := netip.ParseAddr(host)
+	if err != nil {
+		return err
+	}
+	if ip = ip.Unmap(); !ip.IsGlobalUnicast() || ip.IsPrivate() {
+		return fmt.Errorf("%w: %s", errNonPublicAddress, ip)
+	}
+	return nil
+}
+
+// WithImageChecker queues a check of a product's image URLs whenever a
+// write adds images to it. By default image URLs are not checked.
+func WithImageChecker(checker *ImageChecker) Option {
+	return func(s *productService) {
+		s.imageChecks = checker
+	}
+}
+
+// checkImages queues a check of product's images if any of them is a URL.
+func (s *productService) checkImages(product *models.Product) {
+	if s.imageChecks == nil || !slices.ContainsFunc(product.Images, isImageURL) {
+		return
+	}
+	s.imageChecks.Enqueue(product.ID)
+}
+
+// Enqueue schedules a check of the product's images. A full queue drops the
+// check rather than block the caller; the product's next image change
+// queues another.
+func (c *ImageChecker) Enqueue(id string) {
+	select {
+	case c.queue <- id:
+	default:
+		c.logger.Warn("image check queue full, check dropped", "product_id", id)
+	}
+}
+
+// Run checks queued products until ctx is cancelled.
+func (c *ImageChecker) Run(ctx context.Context) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case id := <-c.queue:
+			if err := c.Check(ctx, id); err != nil && ctx.Err() == nil {
+				c.logger.Error("image check failed", "product_id", id, "error", err)
+			}
+		}
+	}
+}
+
+// Check HEADs each image URL of the product and records those that fail or
+// answer with an error status. A product deleted, or whose images changed,
+// while it was being checked is left alone.
+func (c *ImageChecker) Check(ctx context.Context, id string) error {
+	product, err := c.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil
+	}
+
+	var broken []string
+	for _, image := range product.Images {
+		if !isImageURL(image) {
+			continue
+		}
+		if err := c.wait(ctx); err != nil {
+			return err
+		}
+		if !c.reachable(ctx, image) {
+			broken = append(broken, image)
+		}
+	}
+	// A cancelled request says nothing about the image.
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	if slices.Equal(broken, product.BrokenImages) {
+		return nil
+	}
+
+	if _, err := c.repo.SetBrokenImages(ctx, id, product.Images, broken); err != nil {
+		return err
+	}
+	if len(broken) > 0 {
+		c.logger.InfoContext(ctx, "broken product images found", "product_id", id, "images", broken)
+	}
+	return nil
+}
+
+// wait blocks until the rate limit allows another request.
+func (c *ImageChecker) wait(ctx context.Context) error {
+	c.mu.Lock()
+	now := time.Now()
+	if c.next.Before(now) {
+		c.next = now
+	}
+	at := c.next
+	c.next = c.next.Add(c.interval)
+	c.mu.Unlock()
+
+	timer := time.NewTimer(time.Until(at))
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+func (c *ImageChecker) reachable(ctx context.Context, image string) bool {
+	req, err := http.NewRequestWithContext(ctx, http.MethodHead, image, nil)
+	if err != nil {
+		return false
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return false
+	}
+	resp.Body.Close()
+	// Some hosts refuse HEAD outright, which says nothing about the image.
+	return resp.StatusCode < http.StatusBadRequest || resp.StatusCode == http.StatusMethodNotAllowed
+}
+
+// isImageURL reports whether image is an absolute http(s) URL rather than
+// an image store key.
+func isImageURL(image string) bool {
+	u, err := url.Parse(image)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
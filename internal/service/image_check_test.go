@@ -0,0 +1,141 @@
+package service
+
+import (
+	"context"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func newImageHost(t *testing.T) *httptest.Server {
+	t.Helper()
+	mux := http.NewServeMux()
+	mux.HandleFunc("HEAD /ok.jpg", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})
+	mux.HandleFunc("HEAD /moved.jpg", func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, "http://169.254.169.254/latest/meta-data/", http.StatusFound)
+	})
+	mux.HandleFunc("/", http.NotFound)
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+	return server
+}
+
+// allowLoopback lets checker reach test servers, which listen on loopback
+// addresses it otherwise refuses.
+func allowLoopback(checker *ImageChecker) {
+	checker.client.Transport.(*http.Transport).DialContext = (&net.Dialer{}).DialContext
+}
+
+func TestImageChecker_FlagsBrokenImages(t *testing.T) {
+	host := newImageHost(t)
+	repo := repository.NewMemoryProductRepository()
+	checker := NewImageChecker(repo, 100, slog.New(slog.DiscardHandler))
+	allowLoopback(checker)
+	service := NewProductService(repo, WithImageChecker(checker))
+
+	ok, missing := host.URL+"/ok.jpg", host.URL+"/missing.jpg"
+	product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Lamp", Price: 30, Category: "office", SKU: "LAMP-1",
+		Images: []string{ok, missing},
+	})
+	require.NoError(t, err)
+	assert.Empty(t, product.BrokenImages, "the create does not wait for the check")
+
+	// The create queued the check; run it as the background job would.
+	require.Equal(t, product.ID, <-checker.queue)
+	require.NoError(t, checker.Check(context.Background(), product.ID))
+
+	stored, err := repo.GetByID(context.Background(), product.ID)
+	require.NoError(t, err)
+	assert.Equal(t, []string{missing}, stored.BrokenImages)
+
+	// Dropping the broken image clears its flag without waiting for a check.
+	images := []string{ok}
+	updated, err := service.UpdateProduct(context.Background(), product.ID, models.UpdateProductRequest{Images: &images})
+	require.NoError(t, err)
+	assert.Empty(t, updated.BrokenImages)
+	assert.Equal(t, product.ID, <-checker.queue)
+}
+
+func TestImageChecker_SkipsProductsWithoutImageURLs(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	checker := NewImageChecker(repo, 100, slog.New(slog.DiscardHandler))
+	service := NewProductService(repo, WithImageChecker(checker))
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "lamp", Name: "Lamp", Price: 30, Category: "office", SKU: "LAMP-1", Images: []string{"products/lamp/1.jpg"},
+	}))
+
+	images := []string{"products/lamp/1.jpg", "products/lamp/2.jpg"}
+	_, err := service.UpdateProduct(context.Background(), "lamp", models.UpdateProductRequest{Images: &images})
+	require.NoError(t, err)
+	assert.Empty(t, checker.queue)
+
+	// Other updates do not queue a check either.
+	name := "Desk Lamp"
+	_, err = service.UpdateProduct(context.Background(), "lamp", models.UpdateProductRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Empty(t, checker.queue)
+}
+
+func TestImageChecker_WritesOnlyCurrentImages(t *testing.T) {
+	host := newImageHost(t)
+	repo := repository.NewMemoryProductRepository()
+	checker := NewImageChecker(repo, 100, slog.New(slog.DiscardHandler))
+	allowLoopback(checker)
+
+	missing := host.URL + "/missing.jpg"
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "lamp", Images: []string{missing}}))
+
+	ok, err := repo.SetBrokenImages(context.Background(), "lamp", []string{"other.jpg"}, []string{missing})
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	require.NoError(t, checker.Check(context.Background(), "lamp"))
+	stored, err := repo.GetByID(context.Background(), "lamp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{missing}, stored.BrokenImages)
+
+	// A product deleted before its check is skipped.
+	assert.NoError(t, checker.Check(context.Background(), "gone"))
+}
+
+func TestImageChecker_RefusesNonPublicAddresses(t *testing.T) {
+	host := newImageHost(t)
+	repo := repository.NewMemoryProductRepository()
+	checker := NewImageChecker(repo, 100, slog.New(slog.DiscardHandler))
+
+	ok := host.URL + "/ok.jpg"
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "lamp", Images: []string{ok}}))
+
+	require.NoError(t, checker.Check(context.Background(), "lamp"))
+	stored, err := repo.GetByID(context.Background(), "lamp")
+	require.NoError(t, err)
+	assert.Equal(t, []string{ok}, stored.BrokenImages, "the loopback host is never contacted")
+
+	for _, address := range []string{"127.0.0.1:80", "10.1.2.3:443", "192.168.0.1:80", "169.254.169.254:80", "[::1]:80", "[fd00:ec2::254]:80", "[::ffff:127.0.0.1]:80", "0.0.0.0:80"} {
+		assert.ErrorIs(t, refuseNonPublic("tcp", address, nil), errNonPublicAddress, address)
+	}
+	assert.NoError(t, refuseNonPublic("tcp", "93.184.216.34:443", nil))
+}
+
+func TestImageChecker_DoesNotFollowRedirects(t *testing.T) {
+	host := newImageHost(t)
+	checker := NewImageChecker(repository.NewMemoryProductRepository(), 100, slog.New(slog.DiscardHandler))
+	allowLoopback(checker)
+
+	moved := host.URL + "/moved.jpg"
+	// Following the redirect would reach the metadata address; the redirect
+	// itself shows the host answers.
+	assert.True(t, checker.reachable(context.Background(), moved))
+}
@@ -0,0 +1,99 @@
+package service
+
+import (
+	"context"
+	"io"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+type recordingImageStore struct {
+	keys []string
+}
+
+func (s *recordingImageStore) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	s.keys = append(s.keys, key)
+	return nil
+}
+
+func (s *recordingImageStore) SignedURL(ctx context.Context, key string) (string, error) {
+	return key, nil
+}
+
+func TestProductService_AddProductImage(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	store := &recordingImageStore{}
+	service := NewProductService(repo, WithImageStore(store))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", Name: "Shoe", Price: 10, Category: "shoes", SKU: "S-1", IsActive: true,
+		Images: []string{"products/test-id/existing.jpg"},
+	}))
+
+	product, key, err := service.AddProductImage(context.Background(), "test-id", "image/png", strings.NewReader("png"))
+
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(key, "products/test-id/"))
+	assert.True(t, strings.HasSuffix(key, ".png"))
+	assert.Equal(t, []string{key}, store.keys)
+	assert.Equal(t, []string{"products/test-id/existing.jpg", key}, product.Images)
+
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, product.Images, stored.Images)
+}
+
+func TestProductService_AddProductImage_Rejected(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe"}))
+
+	_, _, err := NewProductService(repo).AddProductImage(context.Background(), "test-id", "image/png", strings.NewReader("png"))
+	assert.ErrorIs(t, err, ErrImageStoreDisabled)
+
+	store := &recordingImageStore{}
+	service := NewProductService(repo, WithImageStore(store))
+
+	_, _, err = service.AddProductImage(context.Background(), "test-id", "application/pdf", strings.NewReader("pdf"))
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	_, _, err = service.AddProductImage(context.Background(), "missing", "image/png", strings.NewReader("png"))
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	assert.Empty(t, store.keys)
+}
+
+func TestProductService_ImageKeysMustBelongToProduct(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{
+		ID: "test-id", Name: "Shoe", Price: 10, Category: "shoes", SKU: "S-1", IsActive: true,
+		Images: []string{"legacy/shoe.jpg"},
+	}))
+
+	// A new product has no uploaded images yet, so only URLs are accepted.
+	_, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Boot", Price: 10, Category: "shoes", SKU: "B-1", Images: []string{"products/test-id/front.jpg"},
+	})
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "images", fieldErr.Field)
+
+	for _, images := range [][]string{
+		{"products/other-id/front.jpg"},
+		{"exports/catalog.csv"},
+		{"products/test-id-2/front.jpg"},
+	} {
+		_, err = service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Images: &images})
+		assert.ErrorIs(t, err, ErrInvalidProduct, images)
+	}
+
+	// Keys the product already has are kept, whatever their prefix.
+	images := []string{"https://cdn.example.com/shoe.jpg", "products/test-id/side.jpg", "legacy/shoe.jpg"}
+	product, err := service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Images: &images})
+	require.NoError(t, err)
+	assert.Equal(t, images, product.Images)
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// captureLogs returns a logger writing JSON records to buf and a function to
+// decode them.
+func captureLogs() (*slog.Logger, func() []map[string]any) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug}))
+	return logger, func() []map[string]any {
+		var records []map[string]any
+		for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+			if line == "" {
+				continue
+			}
+			var record map[string]any
+			if err := json.Unmarshal([]byte(line), &record); err == nil {
+				records = append(records, record)
+			}
+		}
+		return records
+	}
+}
+
+func TestProductService_CreateProduct_LogsEvent(t *testing.T) {
+	logger, records := captureLogs()
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithLogger(logger))
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "user-42"})
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name:        "Test Product",
+		Description: "secret launch details",
+		Price:       99.99,
+		Category:    "electronics",
+		SKU:         "TEST-001",
+		Stock:       floatPtr(10),
+	})
+	require.NoError(t, err)
+
+	logged := records()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "INFO", logged[0]["level"])
+	assert.Equal(t, "product created", logged[0]["msg"])
+	assert.Equal(t, product.ID, logged[0]["product_id"])
+	assert.Equal(t, "TEST-001", logged[0]["sku"])
+	assert.Equal(t, "user-42", logged[0]["actor"])
+	assert.NotContains(t, logged[0], "description")
+}
+
+func TestProductService_UpdateProduct_LogsChangedFields(t *testing.T) {
+	logger, records := captureLogs()
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithLogger(logger))
+
+	mockRepo.On("GetByID", "test-id").Return(&models.Product{ID: "test-id", Name: "Old", Price: 5}, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	name := "New"
+	price := 7.5
+	release := time.Now().Add(24 * time.Hour)
+	_, err := service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Name: &name, Price: &price, AvailableFrom: &release})
+	require.NoError(t, err)
+
+	logged := records()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "product updated", logged[0]["msg"])
+	assert.Equal(t, []any{"name", "price", "available_from"}, logged[0]["fields"])
+	assert.Equal(t, []any{"name", "price", "available_from"}, logged[0]["changed"])
+}
+
+func TestProductService_CreateProduct_LogsValidationRejection(t *testing.T) {
+	logger, records := captureLogs()
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithLogger(logger))
+
+	_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name:     "Test Product",
+		Category: "electronics",
+		SKU:      "TEST-001",
+	})
+	require.Error(t, err)
+
+	logged := records()
+	require.Len(t, logged, 1)
+	assert.Equal(t, "product validation rejected", logged[0]["msg"])
+	assert.Equal(t, "create", logged[0]["operation"])
+	assert.Equal(t, "price", logged[0]["field"])
+}
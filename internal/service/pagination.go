@@ -0,0 +1,78 @@
+package service
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+
+	"product-service/internal/models"
+)
+
+const (
+	// DefaultPageLimit applies when a cursor is given without a limit.
+	DefaultPageLimit = 20
+	// MaxPageLimit bounds the page size a caller may request.
+	MaxPageLimit = 100
+)
+
+// paginate cuts a sorted listing down to the page opts asks for. Cursors are
+// opaque offsets into the sorted result, so pages stay consistent as long as
+// the sort is a total order, which sortProducts guarantees.
+func paginate(list *models.ProductList, opts models.ListOptions) error {
+	if opts.Limit == 0 && opts.Cursor == "" {
+		return nil
+	}
+
+	limit, offset, err := pageBounds(opts)
+	if err != nil {
+		return err
+	}
+
+	available := len(list.Products)
+	start := min(offset, available)
+	end := min(start+limit, available)
+
+	list.Products = list.Products[start:end]
+	list.Limit = limit
+	list.Total = max(available, list.Matched)
+	if end < available {
+		list.NextCursor = encodeCursor(end)
+	}
+	return nil
+}
+
+// pageBounds validates and resolves the page size and starting offset.
+func pageBounds(opts models.ListOptions) (limit, offset int, err error) {
+	limit = opts.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return 0, 0, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxPageLimit)
+	}
+
+	offset, err = decodeCursor(opts.Cursor)
+	if err != nil {
+		return 0, 0, err
+	}
+	return limit, offset, nil
+}
+
+func encodeCursor(offset int) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.Itoa(offset)))
+}
+
+func decodeCursor(cursor string) (int, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	offset, err := strconv.Atoi(string(raw))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return offset, nil
+}
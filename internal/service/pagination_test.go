@@ -0,0 +1,72 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestProductService_GetAllProducts_Pages(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithDefaultSort(SortSpec{Field: "name"}))
+
+	// Insert out of name order so the default sort has work to do.
+	for _, i := range []int{4, 1, 3, 0, 2} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: fmt.Sprint(i), Name: fmt.Sprintf("product-%d", i), IsActive: true}))
+	}
+
+	var pages [][]string
+	opts := models.ListOptions{Limit: 2}
+	for {
+		list, err := service.GetAllProducts(context.Background(), opts)
+		require.NoError(t, err)
+		assert.Equal(t, 2, list.Limit)
+		assert.Equal(t, 5, list.Total)
+
+		pages = append(pages, productIDs(list.Products))
+		if list.NextCursor == "" {
+			break
+		}
+		opts.Cursor = list.NextCursor
+	}
+
+	assert.Equal(t, [][]string{{"0", "1"}, {"2", "3"}, {"4"}}, pages)
+}
+
+func TestProductService_GetAllProducts_Unpaged(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+
+	for i := range 3 {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{ID: fmt.Sprint(i), IsActive: true}))
+	}
+
+	list, err := service.GetAllProducts(context.Background(), models.ListOptions{})
+
+	require.NoError(t, err)
+	assert.Len(t, list.Products, 3)
+	assert.Zero(t, list.Limit)
+	assert.Empty(t, list.NextCursor)
+}
+
+func TestProductService_GetAllProducts_InvalidPage(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	for _, opts := range []models.ListOptions{
+		{Limit: -1},
+		{Limit: MaxPageLimit + 1},
+		{Cursor: "not base64!"},
+		{Cursor: encodeCursor(-5)},
+	} {
+		_, err := service.GetAllProducts(context.Background(), opts)
+		assert.ErrorIs(t, err, ErrInvalidQuery, "%+v", opts)
+	}
+	mockRepo.AssertNotCalled(t, "GetAll")
+}
@@ -0,0 +1,255 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// ErrPatchTestFailed is returned when a JSON Patch "test" operation does not
+// match the current product, in which case nothing is changed.
+var ErrPatchTestFailed = errors.New("patch test operation failed")
+
+// patchDocument holds the fields a JSON Patch may read and change. Paths
+// outside it are either immutable or unknown.
+type patchDocument struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Price       float64  `json:"price"`
+	Category    string   `json:"category"`
+	SKU         string   `json:"sku"`
+	Stock       float64  `json:"stock"`
+	Unit        string   `json:"unit"`
+	IsActive    bool     `json:"is_active"`
+	Tags        []string `json:"tags"`
+}
+
+// immutableFields are product fields that exist but only the service may
+// set.
+var immutableFields = map[string]bool{
+	"id":               true,
+	"created_at":       true,
+	"updated_at":       true,
+	"created_by":       true,
+	"updated_by":       true,
+	"view_count":       true,
+	"average_rating":   true,
+	"rating_count":     true,
+	"price_updated_at": true,
+	"stock_updated_at": true,
+	"deleted_at":       true,
+}
+
+// removableFields may be removed, which resets them to empty. Removing any
+// other field would leave the product invalid.
+var removableFields = map[string]bool{
+	"description": true,
+	"tags":        true,
+}
+
+// PatchProduct applies an RFC 6902 JSON Patch to a product. The operations
+// run against the current product in order; the result is then validated
+// and stored exactly like an update.
+func (s *productService) PatchProduct(ctx context.Context, id string, ops []models.PatchOperation) (*models.Product, error) {
+	return retryOnConflict(func() (*models.Product, error) {
+		product, err := s.productForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		req, err := applyPatch(product, ops)
+		if err != nil {
+			if !errors.Is(err, ErrPatchTestFailed) {
+				s.logRejected(ctx, "patch", id, err)
+				err = fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+			}
+			return nil, err
+		}
+		return s.applyUpdate(ctx, product, req)
+	})
+}
+
+// applyPatch runs ops against the patchable fields of product and returns
+// the outcome as an update setting only the fields the operations changed,
+// so the rest are neither rewritten nor revalidated. Test operations change
+// nothing.
+func applyPatch(product *models.Product, ops []models.PatchOperation) (models.UpdateProductRequest, error) {
+	raw, err := json.Marshal(patchDocument{
+		Name:        product.Name,
+		Description: product.Description,
+		Price:       product.Price,
+		Category:    product.Category,
+		SKU:         product.SKU,
+		Stock:       product.Stock,
+		Unit:        product.Unit,
+		IsActive:    product.IsActive,
+		Tags:        product.Tags,
+	})
+	if err != nil {
+		return models.UpdateProductRequest{}, err
+	}
+	var doc map[string]any
+	if err := json.Unmarshal(raw, &doc); err != nil {
+		return models.UpdateProductRequest{}, err
+	}
+
+	touched := make(map[string]bool)
+	for i, op := range ops {
+		if err := applyPatchOperation(doc, op); err != nil {
+			if errors.Is(err, ErrPatchTestFailed) {
+				return models.UpdateProductRequest{}, fmt.Errorf("%w: operation %d at %s", err, i, op.Path)
+			}
+			return models.UpdateProductRequest{}, err
+		}
+		if op.Op != "test" {
+			field, _, _ := patchPath(op.Path)
+			touched[field] = true
+		}
+	}
+
+	if raw, err = json.Marshal(doc); err != nil {
+		return models.UpdateProductRequest{}, err
+	}
+	var patched patchDocument
+	if err := json.Unmarshal(raw, &patched); err != nil {
+		var typeErr *json.UnmarshalTypeError
+		if errors.As(err, &typeErr) {
+			return models.UpdateProductRequest{}, fieldError(typeErr.Field, "product %s must be a %s", typeErr.Field, typeErr.Type)
+		}
+		return models.UpdateProductRequest{}, err
+	}
+
+	var req models.UpdateProductRequest
+	if touched["name"] {
+		req.Name = &patched.Name
+	}
+	if touched["description"] {
+		req.Description = &patched.Description
+	}
+	if touched["price"] {
+		req.Price = &patched.Price
+	}
+	if touched["category"] {
+		req.Category = &patched.Category
+	}
+	if touched["sku"] {
+		req.SKU = &patched.SKU
+	}
+	if touched["stock"] {
+		req.Stock = &patched.Stock
+	}
+	if touched["unit"] {
+		req.Unit = &patched.Unit
+	}
+	if touched["is_active"] {
+		req.IsActive = &patched.IsActive
+	}
+	if touched["tags"] {
+		req.Tags = &patched.Tags
+	}
+	return req, nil
+}
+
+func applyPatchOperation(doc map[string]any, op models.PatchOperation) error {
+	field, index, err := patchPath(op.Path)
+	if err != nil {
+		return err
+	}
+	if immutableFields[field] {
+		return fieldError(field, "product %s cannot be patched", field)
+	}
+	if _, ok := doc[field]; !ok {
+		return fieldError(field, "unknown product field %q", field)
+	}
+
+	var value any
+	if op.Op != "remove" {
+		if len(op.Value) == 0 {
+			return fieldError(field, "%s operation at %s requires a value", op.Op, op.Path)
+		}
+		decoder := json.NewDecoder(bytes.NewReader(op.Value))
+		if err := decoder.Decode(&value); err != nil {
+			return fieldError(field, "invalid value for %s: %v", op.Path, err)
+		}
+	}
+
+	if index == "" {
+		switch op.Op {
+		case "add", "replace":
+			doc[field] = value
+		case "remove":
+			if !removableFields[field] {
+				return fieldError(field, "product %s cannot be removed", field)
+			}
+			if field == "tags" {
+				doc[field] = nil
+			} else {
+				doc[field] = ""
+			}
+		case "test":
+			if !reflect.DeepEqual(doc[field], value) {
+				return ErrPatchTestFailed
+			}
+		default:
+			return fieldError(field, "unsupported patch operation %q", op.Op)
+		}
+		return nil
+	}
+
+	// Only tags is an array, so it is the only field with child paths.
+	if field != "tags" {
+		return fieldError(field, "product %s has no element %s", field, index)
+	}
+	tags, _ := doc[field].([]any)
+	if index == "-" {
+		if op.Op != "add" {
+			return fieldError(field, "%s cannot address the end of the array", op.Op)
+		}
+		doc[field] = append(tags, value)
+		return nil
+	}
+	i, err := strconv.Atoi(index)
+	if err != nil || i < 0 || i > len(tags) || (i == len(tags) && op.Op != "add") {
+		return fieldError(field, "array index %s is out of range", index)
+	}
+	switch op.Op {
+	case "add":
+		doc[field] = append(tags[:i], append([]any{value}, tags[i:]...)...)
+	case "replace":
+		tags[i] = value
+	case "remove":
+		doc[field] = append(tags[:i], tags[i+1:]...)
+	case "test":
+		if !reflect.DeepEqual(tags[i], value) {
+			return ErrPatchTestFailed
+		}
+	default:
+		return fieldError(field, "unsupported patch operation %q", op.Op)
+	}
+	return nil
+}
+
+// patchPath splits a JSON Pointer into the product field and, for array
+// elements, the index token.
+func patchPath(path string) (field, index string, err error) {
+	if !strings.HasPrefix(path, "/") {
+		return "", "", fieldError("path", "patch path %q must start with /", path)
+	}
+	tokens := strings.Split(path[1:], "/")
+	if len(tokens) > 2 {
+		return "", "", fieldError("path", "patch path %q is too deep", path)
+	}
+	unescape := strings.NewReplacer("~1", "/", "~0", "~")
+	field = unescape.Replace(tokens[0])
+	if len(tokens) == 2 {
+		index = unescape.Replace(tokens[1])
+	}
+	return field, index, nil
+}
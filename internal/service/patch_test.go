@@ -0,0 +1,35 @@
+package service
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+)
+
+func TestApplyPatch_SetsOnlyChangedFields(t *testing.T) {
+	product := &models.Product{Name: "Widget", Price: 10, Category: "tools", SKU: "W-1", Stock: 5, Unit: models.UnitEach, Tags: []string{"new"}}
+	ops := []models.PatchOperation{
+		{Op: "test", Path: "/price", Value: json.RawMessage(`10`)},
+		{Op: "replace", Path: "/name", Value: json.RawMessage(`"Gadget"`)},
+		{Op: "add", Path: "/tags/-", Value: json.RawMessage(`"sale"`)},
+	}
+
+	req, err := applyPatch(product, ops)
+
+	require.NoError(t, err)
+	name, tags := "Gadget", []string{"new", "sale"}
+	assert.Equal(t, models.UpdateProductRequest{Name: &name, Tags: &tags}, req)
+}
+
+func TestApplyPatch_TestOnlyChangesNothing(t *testing.T) {
+	product := &models.Product{Name: "Widget", Price: 10}
+
+	req, err := applyPatch(product, []models.PatchOperation{{Op: "test", Path: "/name", Value: json.RawMessage(`"Widget"`)}})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.UpdateProductRequest{}, req)
+}
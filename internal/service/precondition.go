@@ -0,0 +1,87 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// ErrPreconditionFailed is returned when a conditional mutation finds the
+// product has changed since the caller last saw it.
+var ErrPreconditionFailed = errors.New("precondition failed")
+
+// ErrConcurrentModification is returned when a mutation kept losing races
+// with other writes to the same product and gave up.
+var ErrConcurrentModification = errors.New("product was modified concurrently")
+
+// maxConflictAttempts bounds how often a mutation is re-run after another
+// write lands between its read and its write.
+const maxConflictAttempts = 3
+
+// retryOnConflict runs mutate, which must read the product afresh, again
+// whenever its conditional write loses a race. Preconditions are checked
+// against each fresh read, so a caller that asked for a specific version or
+// time gets ErrPreconditionFailed rather than a retry on top of another
+// write.
+func retryOnConflict[T any](mutate func() (T, error)) (T, error) {
+	for range maxConflictAttempts - 1 {
+		result, err := mutate()
+		if !errors.Is(err, repository.ErrVersionConflict) {
+			return result, err
+		}
+	}
+	result, err := mutate()
+	if errors.Is(err, repository.ErrVersionConflict) {
+		return result, fmt.Errorf("%w: %w", ErrConcurrentModification, err)
+	}
+	return result, err
+}
+
+type (
+	unmodifiedSinceKey struct{}
+	expectedVersionKey struct{}
+)
+
+// WithUnmodifiedSince marks ctx so that updates are refused when the product
+// was modified after t.
+func WithUnmodifiedSince(ctx context.Context, t time.Time) context.Context {
+	return context.WithValue(ctx, unmodifiedSinceKey{}, t)
+}
+
+// checkUnmodifiedSince enforces a WithUnmodifiedSince precondition. HTTP
+// dates only carry whole seconds, so UpdatedAt is truncated before comparing.
+func checkUnmodifiedSince(ctx context.Context, product *models.Product) error {
+	since, ok := ctx.Value(unmodifiedSinceKey{}).(time.Time)
+	if !ok {
+		return nil
+	}
+	if product.UpdatedAt.Truncate(time.Second).After(since) {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
+
+// WithExpectedVersion marks ctx so that deletes are refused unless the
+// product is still at version.
+func WithExpectedVersion(ctx context.Context, version int64) context.Context {
+	return context.WithValue(ctx, expectedVersionKey{}, version)
+}
+
+// expectedVersion returns the version set by WithExpectedVersion.
+func expectedVersion(ctx context.Context) (int64, bool) {
+	version, ok := ctx.Value(expectedVersionKey{}).(int64)
+	return version, ok
+}
+
+// checkVersion enforces a WithExpectedVersion precondition against the
+// product as read.
+func checkVersion(ctx context.Context, product *models.Product) error {
+	if version, ok := expectedVersion(ctx); ok && product.Version != version {
+		return ErrPreconditionFailed
+	}
+	return nil
+}
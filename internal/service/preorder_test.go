@@ -0,0 +1,75 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_FilterProducts_ExcludesPreordersFromInStock(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(now)
+	service := NewProductService(repository.NewMemoryProductRepository(), WithClock(clock))
+	ctx := context.Background()
+
+	stock := 10.0
+	release := now.Add(48 * time.Hour)
+	preorder, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Console", Price: 400, Category: "games", SKU: "CON-1", Stock: &stock, AvailableFrom: &release,
+	})
+	require.NoError(t, err)
+	onSale, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Controller", Price: 60, Category: "games", SKU: "CTL-1", Stock: &stock,
+	})
+	require.NoError(t, err)
+
+	inStock, outOfStock := true, false
+	ids := func(filter models.ProductFilter) []string {
+		list, err := service.FilterProducts(ctx, filter, models.ListOptions{})
+		require.NoError(t, err)
+		var ids []string
+		for _, p := range list.Products {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	assert.Equal(t, []string{onSale.ID}, ids(models.ProductFilter{InStock: &inStock}))
+	assert.Equal(t, []string{preorder.ID}, ids(models.ProductFilter{InStock: &outOfStock}))
+
+	// Once the date passes the pre-order is in stock like any other product.
+	clock.Set(release)
+
+	assert.ElementsMatch(t, []string{preorder.ID, onSale.ID}, ids(models.ProductFilter{InStock: &inStock}))
+	assert.Empty(t, ids(models.ProductFilter{InStock: &outOfStock}))
+}
+
+func TestProductService_CreateProduct_AvailableFromInDistantPast(t *testing.T) {
+	now := time.Date(2026, 5, 1, 12, 0, 0, 0, time.UTC)
+	service := NewProductService(repository.NewMemoryProductRepository(), WithClock(models.NewFakeClock(now)))
+
+	recent := now.Add(-MaxAvailableFromAge)
+	product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Console", Price: 400, Category: "games", SKU: "CON-1", AvailableFrom: &recent,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, recent, *product.AvailableFrom)
+
+	stale := recent.Add(-time.Second)
+	_, err = service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Console", Price: 400, Category: "games", SKU: "CON-2", AvailableFrom: &stale,
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "available_from", fieldErr.Field)
+	assert.Equal(t, "product available_from cannot be before 2026-04-01", fieldErr.Message)
+}
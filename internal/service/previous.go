@@ -0,0 +1,30 @@
+package service
+
+import (
+	"context"
+
+	"product-service/internal/models"
+)
+
+// Previous receives the state a product had before an update or delete.
+type Previous struct {
+	Product *models.Product
+}
+
+type previousKey struct{}
+
+// WithPrevious asks mutations on ctx to record the product's prior state in
+// the returned Previous.
+func WithPrevious(ctx context.Context) (context.Context, *Previous) {
+	previous := &Previous{}
+	return context.WithValue(ctx, previousKey{}, previous), previous
+}
+
+// recordPrevious stores a copy of product when the caller asked for it. It
+// must be called before the product is modified.
+func recordPrevious(ctx context.Context, product *models.Product) {
+	if previous, ok := ctx.Value(previousKey{}).(*Previous); ok {
+		prior := *product
+		previous.Product = &prior
+	}
+}
@@ -1,57 +1,352 @@
 package service
 
 import (
+	"context"
 	"errors"
 	"fmt"
-
+	"io"
+	"log/slog"
+	"slices"
+	"sort"
+	"time"
+
+	"product-service/internal/auth"
+	"product-service/internal/database"
 	"product-service/internal/models"
 	"product-service/internal/repository"
+	"product-service/internal/requestid"
+	"product-service/internal/storage"
 )
 
 var (
 	ErrProductNotFound = errors.New("product not found")
 	ErrInvalidProduct  = errors.New("invalid product data")
+	ErrInvalidQuery    = errors.New("invalid query")
+
+	// ErrHardDeleteDisabled is returned by DeleteProduct when hard deletes
+	// are turned off and products may only be soft deleted.
+	ErrHardDeleteDisabled = errors.New("hard delete is disabled")
 )
 
+// viewCountTimeout bounds the background view count update, which no longer
+// inherits the request deadline.
+const viewCountTimeout = 5 * time.Second
+
+// MaxTrendingLimit bounds how many products GetTrendingProducts returns.
+const MaxTrendingLimit = 100
+
+// MaxSuggestLimit bounds how many suggestions SuggestProducts returns.
+const MaxSuggestLimit = 20
+
 type ProductService interface {
-	CreateProduct(req models.CreateProductRequest) (*models.Product, error)
-	GetProduct(id string) (*models.Product, error)
-	GetAllProducts() ([]*models.Product, error)
-	GetProductsByCategory(category string) ([]*models.Product, error)
-	UpdateProduct(id string, req models.UpdateProductRequest) (*models.Product, error)
-	DeleteProduct(id string) error
+	CreateProduct(ctx context.Context, req models.CreateProductRequest) (*models.Product, error)
+	ValidateProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.ValidationResult, error)
+	GetProduct(ctx context.Context, id string) (*models.Product, error)
+	GetProductBySlug(ctx context.Context, slug string) (*models.Product, error)
+	CreateDraft(ctx context.Context, req models.CreateProductRequest) (*models.Product, error)
+	PublishProduct(ctx context.Context, id string) (*models.Product, error)
+	GetDraftProducts(ctx context.Context) ([]*models.Product, error)
+	GetProductsByIDs(ctx context.Context, ids []string) (*models.BatchGetResult, error)
+	GetProductsBySKUs(ctx context.Context, skus []string) (*models.BatchGetResult, error)
+	GetProductBySKU(ctx context.Context, sku string) (*models.Product, error)
+	GetShipping(ctx context.Context, id string) (*models.ShippingInfo, error)
+	GetBundle(ctx context.Context, id string) (*models.ProductBundle, error)
+	ProductExistsBySKU(ctx context.Context, sku string) (bool, error)
+	GetAllProducts(ctx context.Context, opts models.ListOptions) (*models.ProductList, error)
+	GetProductsByCategory(ctx context.Context, category string, opts models.ListOptions) (*models.ProductList, error)
+	GetProductChanges(ctx context.Context, since time.Time, opts models.ListOptions) (*models.ProductList, error)
+	GetTrendingProducts(ctx context.Context, limit int) ([]*models.Product, error)
+	GetFeaturedProducts(ctx context.Context) ([]*models.Product, error)
+	SetFeatured(ctx context.Context, id string, rank int) (*models.Product, error)
+	UnsetFeatured(ctx context.Context, id string) (*models.Product, error)
+	ReorderFeatured(ctx context.Context, ids []string) ([]*models.Product, error)
+	SuggestProducts(ctx context.Context, prefix string, limit int) ([]models.ProductSuggestion, error)
+	SearchProducts(ctx context.Context, query string, opts models.ListOptions) (*models.ProductList, error)
+	GetInventoryValuation(ctx context.Context) (*models.InventoryValuation, error)
+	ExportProducts(ctx context.Context, fn func(page []*models.Product) error) error
+	FilterProducts(ctx context.Context, filter models.ProductFilter, opts models.ListOptions) (*models.ProductList, error)
+	UpdateProduct(ctx context.Context, id string, req models.UpdateProductRequest) (*models.Product, error)
+	PatchProduct(ctx context.Context, id string, ops []models.PatchOperation) (*models.Product, error)
+	DeleteProduct(ctx context.Context, id string) error
+	SoftDeleteProduct(ctx context.Context, id string) error
+	RestoreProduct(ctx context.Context, id string) (*models.Product, error)
+	RateProduct(ctx context.Context, id string, rating int) (*models.Product, error)
+	RenameCategory(ctx context.Context, from, to string) (int, error)
+	BulkUpsertProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.UpsertResult, error)
+	BulkSetStock(ctx context.Context, updates []models.StockUpdate) ([]models.StockUpdateResult, error)
+	ClearStock(ctx context.Context, id string, req models.ClearStockRequest) (*models.Product, error)
+	BulkSetStatus(ctx context.Context, req models.BulkStatusRequest) (*models.BulkStatusResult, error)
+	SetTranslation(ctx context.Context, id, locale string, translation models.ProductTranslation) (*models.Product, error)
+	RemoveTranslation(ctx context.Context, id, locale string) (*models.Product, error)
+	AddProductImage(ctx context.Context, id, contentType string, body io.ReadSeeker) (*models.Product, string, error)
+	ReconcileProduct(ctx context.Context, id string) (*models.ReconcileResult, error)
+	ReserveStock(ctx context.Context, id string, req models.ReserveStockRequest) (*models.Reservation, error)
+	ReleaseReservation(ctx context.Context, id, reservationID string) error
+	Reindex(ctx context.Context, cursor string, limit int) (*models.ReindexResult, error)
 }
 
 type productService struct {
-	repo repository.ProductRepository
+	repo         repository.ProductRepository
+	logger       *slog.Logger
+	maxStock     float64
+	defaultStock float64
+	minPrices    map[string]float64
+	skuPrefixes  map[string]string
+	skuSequences repository.SequenceRepository
+	sanitizeMode SanitizeMode
+	defaultSort  SortSpec
+	idScheme     models.IDScheme
+	currency     models.CurrencyRules
+	clock        models.Clock
+	taxonomy     models.CategoryTaxonomy
+
+	maxBatchSize int
+
+	regionCurrencies map[string][]string
+	defaultRegion    string
+
+	minNameLen        int
+	maxDescriptionLen int
+	maxTags           int
+	maxTagLen         int
+
+	hardDeleteDisabled bool
+	regenerateSlug     bool
+	autoDeactivateOOS  bool
+
+	images      storage.ImageStore
+	imageChecks *ImageChecker
+	counts      CountSource
+	events      EventPublisher
+}
+
+// Option configures optional productService behavior.
+type Option func(*productService)
+
+// DefaultMaxBatchSize bounds how many items one batch call takes unless
+// configured otherwise with WithMaxBatchSize.
+const DefaultMaxBatchSize = 100
+
+// WithMaxBatchSize bounds how many items one batch call takes: the IDs or
+// SKUs of a batch get, the items of a bulk stock update, upsert or status
+// change, and the products of a featured reorder. Larger batches fail with
+// ErrInvalidQuery before anything is read or written. Zero keeps
+// DefaultMaxBatchSize. Validation batches have their own, larger bound,
+// MaxValidateItems.
+func WithMaxBatchSize(n int) Option {
+	return func(s *productService) {
+		if n > 0 {
+			s.maxBatchSize = n
+		}
+	}
+}
+
+// WithMaxStock rejects creates and updates that would leave a product with
+// more than max units in stock. Zero disables the ceiling.
+func WithMaxStock(max float64) Option {
+	return func(s *productService) {
+		s.maxStock = max
+	}
+}
+
+// WithDefaultStock sets the stock of products created without one. An
+// explicit stock, including zero, is kept.
+func WithDefaultStock(stock float64) Option {
+	return func(s *productService) {
+		s.defaultStock = stock
+	}
+}
+
+// WithCategoryMinPrice sets per-category price floors enforced on create and
+// update. Categories without an entry only require a positive price.
+func WithCategoryMinPrice(floors map[string]float64) Option {
+	return func(s *productService) {
+		s.minPrices = floors
+	}
+}
+
+// WithCategorySKUPrefix requires the SKUs of products in the listed
+// categories to start with the category's prefix, e.g. "ELEC-" for
+// electronics. Categories without an entry accept any SKU.
+func WithCategorySKUPrefix(prefixes map[string]string) Option {
+	return func(s *productService) {
+		s.skuPrefixes = prefixes
+	}
+}
+
+// WithSKUSequences numbers the SKUs of products created without one, per
+// category: the category's WithCategorySKUPrefix prefix followed by the next
+// value of the category's sequence, zero-padded to six digits, e.g.
+// "ELEC-000123". Categories without a prefix still require a SKU.
+func WithSKUSequences(sequences repository.SequenceRepository) Option {
+	return func(s *productService) {
+		s.skuSequences = sequences
+	}
+}
+
+// WithMinNameLength rejects product names shorter than n characters. Zero
+// only requires a non-empty name.
+func WithMinNameLength(n int) Option {
+	return func(s *productService) {
+		s.minNameLen = n
+	}
+}
+
+// WithMaxDescriptionLength rejects descriptions longer than n characters,
+// keeping items well below DynamoDB's size limit. Zero leaves them
+// unbounded.
+func WithMaxDescriptionLength(n int) Option {
+	return func(s *productService) {
+		s.maxDescriptionLen = n
+	}
+}
+
+// WithLogger sets the logger used for business events. By default the
+// service does not log.
+func WithLogger(logger *slog.Logger) Option {
+	return func(s *productService) {
+		s.logger = logger
+	}
+}
+
+// WithIDScheme selects how new product IDs are generated. UUIDs are the
+// default.
+func WithIDScheme(scheme models.IDScheme) Option {
+	return func(s *productService) {
+		s.idScheme = scheme
+	}
 }
 
-func NewProductService(repo repository.ProductRepository) ProductService {
-	return &productService{
-		repo: repo,
+// WithCurrencyRules sets the currency prices are held in and how amounts
+// derived from them are rounded.
+func WithCurrencyRules(rules models.CurrencyRules) Option {
+	return func(s *productService) {
+		s.currency = rules
 	}
 }
 
-func (s *productService) CreateProduct(req models.CreateProductRequest) (*models.Product, error) {
+// WithClock sets the clock product timestamps are taken from. The system
+// clock is the default.
+func WithClock(clock models.Clock) Option {
+	return func(s *productService) {
+		s.clock = clock
+	}
+}
+
+// WithHardDeleteDisabled makes DeleteProduct fail with ErrHardDeleteDisabled
+// so products can only be soft deleted.
+func WithHardDeleteDisabled(disabled bool) Option {
+	return func(s *productService) {
+		s.hardDeleteDisabled = disabled
+	}
+}
+
+func NewProductService(repo repository.ProductRepository, opts ...Option) ProductService {
+	s := &productService{
+		repo:         repo,
+		logger:       slog.New(slog.DiscardHandler),
+		currency:     models.NewCurrencyRules(models.DefaultCurrency, nil),
+		clock:        models.SystemClock,
+		maxBatchSize: DefaultMaxBatchSize,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	s.logger = requestid.Logger(s.logger)
+	return s
+}
+
+func (s *productService) CreateProduct(ctx context.Context, req models.CreateProductRequest) (*models.Product, error) {
+	if err := s.sanitizeCreateRequest(&req); err != nil {
+		s.logRejected(ctx, "create", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	s.applyDefaultStock(&req)
+
+	numbered := false
+	if req.SKU == "" {
+		req.SKU, numbered = s.skuPlaceholder(req.Category)
+	}
+
 	if err := s.validateCreateRequest(req); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		s.logRejected(ctx, "create", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	if err := s.validateRegionCurrency(ctx); err != nil {
+		s.logRejected(ctx, "create", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
 	}
 
-	product := models.NewProduct(req)
+	if err := s.validateBundleComponents(ctx, "create", "", req.BundleItems); err != nil {
+		return nil, err
+	}
 
-	if err := s.repo.Create(product); err != nil {
+	if !numbered {
+		if err := s.checkSKUAvailable(ctx, "create", "", req.SKU); err != nil {
+			return nil, err
+		}
+	}
+
+	product := models.NewProductWithID(s.idScheme.NewID(), req, s.clock)
+	if err := validateImageKeys(product.ID, product.Images, nil); err != nil {
+		s.logRejected(ctx, "create", "", err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+	product.CategoryPath = s.categoryPath(product.Category)
+	product.CreatedBy = auth.ActorID(ctx)
+	product.UpdatedBy = product.CreatedBy
+
+	slug, err := s.uniqueSlug(ctx, product.Name, product.ID)
+	if err != nil {
+		return nil, err
+	}
+	product.Slug = slug
+
+	if IsDryRun(ctx) {
+		if numbered {
+			// The number is only drawn for a create that is written.
+			product.SKU = ""
+		}
+		return product, nil
+	}
+
+	if numbered {
+		if product.SKU, err = s.nextSKU(ctx, product.Category); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.repo.Create(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "product create failed", "sku", product.SKU, "error", err)
 		return nil, fmt.Errorf("failed to create product: %w", err)
 	}
 
+	s.logger.InfoContext(ctx, "product created",
+		"product_id", product.ID,
+		"sku", product.SKU,
+		"actor", product.CreatedBy,
+	)
+	s.publish(ctx, models.EventProductCreated, product.ID)
+	s.checkImages(product)
+
 	return product, nil
 }
 
-func (s *productService) GetProduct(id string) (*models.Product, error) {
+// applyDefaultStock gives a request without a stock the default one.
+func (s *productService) applyDefaultStock(req *models.CreateProductRequest) {
+	if req.Stock == nil {
+		stock := s.defaultStock
+		req.Stock = &stock
+	}
+}
+
+func (s *productService) GetProduct(ctx context.Context, id string) (*models.Product, error) {
 	if id == "" {
 		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
 	}
 
-	product, err := s.repo.GetByID(id)
+	product, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product: %w", err)
 	}
@@ -60,64 +355,366 @@ func (s *productService) GetProduct(id string) (*models.Product, error) {
 		return nil, ErrProductNotFound
 	}
 
+	// The request context is cancelled once the response is written, so the
+	// background update keeps its values but not its cancellation.
+	go s.recordView(context.WithoutCancel(ctx), id)
+
+	return product, nil
+}
+
+// GetProductBySKU is GetProduct keyed by SKU.
+func (s *productService) GetProductBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	sku = normalizeSKU(sku)
+	if sku == "" {
+		return nil, fmt.Errorf("%w: product SKU cannot be empty", ErrInvalidProduct)
+	}
+
+	product, err := s.repo.GetBySKU(ctx, sku)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by sku: %w", err)
+	}
+
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	go s.recordView(context.WithoutCancel(ctx), product.ID)
+
 	return product, nil
 }
 
-func (s *productService) GetAllProducts() ([]*models.Product, error) {
-	products, err := s.repo.GetAll()
+func (s *productService) ProductExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	sku = normalizeSKU(sku)
+	if sku == "" {
+		return false, fmt.Errorf("%w: product SKU cannot be empty", ErrInvalidProduct)
+	}
+
+	exists, err := s.repo.ExistsBySKU(ctx, sku)
+	if err != nil {
+		return false, fmt.Errorf("failed to check product sku: %w", err)
+	}
+
+	return exists, nil
+}
+
+func (s *productService) GetAllProducts(ctx context.Context, opts models.ListOptions) (*models.ProductList, error) {
+	spec, err := s.listSort(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	products, err := s.repo.GetAll(ctx)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get products: %w", err)
 	}
 
+	sortProducts(products.Products, spec)
+	if err := paginate(products, opts); err != nil {
+		return nil, err
+	}
 	return products, nil
 }
 
-func (s *productService) GetProductsByCategory(category string) ([]*models.Product, error) {
+func (s *productService) GetProductsByCategory(ctx context.Context, category string, opts models.ListOptions) (*models.ProductList, error) {
 	if category == "" {
 		return nil, fmt.Errorf("%w: category cannot be empty", ErrInvalidProduct)
 	}
 
-	products, err := s.repo.GetByCategory(category)
+	spec, err := s.listSort(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var products *models.ProductList
+	if subtree := s.taxonomy.Subtree(category); opts.Subcategories && len(subtree) > 1 {
+		if len(subtree) > maxSubtreeCategories {
+			return nil, fmt.Errorf("%w: category %q has more than %d subcategories", ErrInvalidQuery, category, maxSubtreeCategories-1)
+		}
+		products, err = s.repo.Filter(ctx, models.ProductFilter{Categories: subtree})
+	} else {
+		products, err = s.repo.GetByCategory(ctx, category)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to get products by category: %w", err)
 	}
 
+	sortProducts(products.Products, spec)
+	if err := paginate(products, opts); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// FilterProducts returns the active products matching every criterion set in
+// filter.
+func (s *productService) FilterProducts(ctx context.Context, filter models.ProductFilter, opts models.ListOptions) (*models.ProductList, error) {
+	if filter.MinPrice != nil && *filter.MinPrice < 0 {
+		return nil, fmt.Errorf("%w: min_price cannot be negative", ErrInvalidQuery)
+	}
+	if filter.MaxPrice != nil && *filter.MaxPrice < 0 {
+		return nil, fmt.Errorf("%w: max_price cannot be negative", ErrInvalidQuery)
+	}
+	if filter.MinPrice != nil && filter.MaxPrice != nil && *filter.MinPrice > *filter.MaxPrice {
+		return nil, fmt.Errorf("%w: min_price cannot exceed max_price", ErrInvalidQuery)
+	}
+
+	spec, err := s.listSort(opts)
+	if err != nil {
+		return nil, err
+	}
+
+	filter.Tag = normalizeTag(filter.Tag)
+	filter.AsOf = s.clock.Now()
+	products, err := s.repo.Filter(ctx, filter)
+	if err != nil {
+		return nil, fmt.Errorf("failed to filter products: %w", err)
+	}
+
+	sortProducts(products.Products, spec)
+	if err := paginate(products, opts); err != nil {
+		return nil, err
+	}
+	return products, nil
+}
+
+// GetTrendingProducts returns the most-viewed active products. Ties are
+// broken by the most recent update and then by ID so the order is stable.
+func (s *productService) GetTrendingProducts(ctx context.Context, limit int) ([]*models.Product, error) {
+	if limit <= 0 || limit > MaxTrendingLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxTrendingLimit)
+	}
+
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get trending products: %w", err)
+	}
+
+	products := list.Products
+	sort.SliceStable(products, func(i, j int) bool {
+		a, b := products[i], products[j]
+		if a.ViewCount != b.ViewCount {
+			return a.ViewCount > b.ViewCount
+		}
+		if !a.UpdatedAt.Equal(b.UpdatedAt) {
+			return a.UpdatedAt.After(b.UpdatedAt)
+		}
+		return a.ID < b.ID
+	})
+
+	if len(products) > limit {
+		products = products[:limit]
+	}
 	return products, nil
 }
 
-func (s *productService) UpdateProduct(id string, req models.UpdateProductRequest) (*models.Product, error) {
+// SuggestProducts returns up to limit active products whose name starts with
+// prefix, sorted by name, for typeahead. Matching is case-sensitive.
+func (s *productService) SuggestProducts(ctx context.Context, prefix string, limit int) ([]models.ProductSuggestion, error) {
+	if prefix == "" {
+		return nil, fmt.Errorf("%w: prefix is required", ErrInvalidQuery)
+	}
+	if limit <= 0 || limit > MaxSuggestLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxSuggestLimit)
+	}
+
+	products, err := s.repo.SearchByNamePrefix(ctx, prefix, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products by name: %w", err)
+	}
+
+	suggestions := make([]models.ProductSuggestion, 0, len(products))
+	for _, product := range products {
+		suggestions = append(suggestions, models.ProductSuggestion{ID: product.ID, Name: product.Name})
+	}
+	sort.Slice(suggestions, func(i, j int) bool {
+		if suggestions[i].Name != suggestions[j].Name {
+			return suggestions[i].Name < suggestions[j].Name
+		}
+		return suggestions[i].ID < suggestions[j].ID
+	})
+	return suggestions, nil
+}
+
+// ExportProducts streams every active product to fn a page at a time, so an
+// export never holds the whole catalog in memory. It stops at the first
+// error fn returns.
+func (s *productService) ExportProducts(ctx context.Context, fn func(page []*models.Product) error) error {
+	if err := s.repo.ScanActive(ctx, fn); err != nil {
+		return fmt.Errorf("failed to export products: %w", err)
+	}
+	return nil
+}
+
+func (s *productService) UpdateProduct(ctx context.Context, id string, req models.UpdateProductRequest) (*models.Product, error) {
+	return retryOnConflict(func() (*models.Product, error) {
+		product, err := s.productForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		return s.applyUpdate(ctx, product, req)
+	})
+}
+
+func (s *productService) productForUpdate(ctx context.Context, id string) (*models.Product, error) {
 	if id == "" {
 		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
 	}
 
-	product, err := s.repo.GetByID(id)
+	product, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get product for update: %w", err)
 	}
 
-	if product == nil {
+	// A deleted product has to be restored before it can be changed.
+	if product == nil || product.IsDeleted() {
+		return nil, ErrProductNotFound
+	}
+	return product, nil
+}
+
+// applyUpdate validates req against the loaded product and stores the result.
+// Deleted products are reported as not found, like productForUpdate does, for
+// callers that loaded the product some other way.
+func (s *productService) applyUpdate(ctx context.Context, product *models.Product, req models.UpdateProductRequest) (*models.Product, error) {
+	id := product.ID
+	if product.IsDeleted() {
 		return nil, ErrProductNotFound
 	}
+	if err := checkUnmodifiedSince(ctx, product); err != nil {
+		return nil, err
+	}
+	recordPrevious(ctx, product)
+
+	if err := s.sanitizeUpdateRequest(&req); err != nil {
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
 
 	if err := s.validateUpdateRequest(req); err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrInvalidProduct, err)
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	if req.IsActive != nil && *req.IsActive && product.IsDraft() {
+		err := fieldError("is_active", "draft products are activated by publishing them")
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	if err := validateStockUnit(product, req); err != nil {
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	// Only a new category is held to the taxonomy, so products filed before
+	// the taxonomy changed can still be edited otherwise.
+	if req.Category != nil && *req.Category != product.Category {
+		if err := s.validateCategory(*req.Category); err != nil {
+			s.logRejected(ctx, "update", id, err)
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+		}
 	}
 
-	product.Update(req)
+	if err := s.validateCategoryPrice(product, req); err != nil {
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
 
-	if err := s.repo.Update(product); err != nil {
+	if err := s.validateCategorySKU(product, req); err != nil {
+		s.logRejected(ctx, "update", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	if req.Images != nil {
+		if err := validateImageKeys(id, *req.Images, product.Images); err != nil {
+			s.logRejected(ctx, "update", id, err)
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+		}
+	}
+
+	if req.SKU != nil && *req.SKU != product.SKU {
+		if err := s.checkSKUAvailable(ctx, "update", id, *req.SKU); err != nil {
+			return nil, err
+		}
+	}
+
+	// Only a new price is held to the region's currencies, so products
+	// priced before the restriction can still be edited otherwise, even by
+	// writes such as upserts that restate the price they already have.
+	if req.Price != nil && *req.Price != product.Price {
+		if err := s.validateRegionCurrency(ctx); err != nil {
+			s.logRejected(ctx, "update", id, err)
+			return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+		}
+	}
+
+	// Components are only checked when they change, so a bundle whose
+	// component was deleted can still be updated otherwise.
+	if req.BundleItems != nil && !slices.Equal(*req.BundleItems, product.BundleItems) {
+		if err := s.validateBundleComponents(ctx, "update", id, *req.BundleItems); err != nil {
+			return nil, err
+		}
+	}
+
+	// A request that changes nothing is answered without a write so it
+	// neither consumes capacity nor moves UpdatedAt.
+	if !product.Changes(req) {
+		return product, nil
+	}
+
+	before := *product
+	renamed := req.Name != nil && *req.Name != product.Name
+	product.Update(req, s.clock)
+	if s.autoDeactivateOOS {
+		s.applyStockActivation(ctx, &before, product, req)
+	}
+	if req.Category != nil {
+		product.CategoryPath = s.categoryPath(product.Category)
+	}
+	product.UpdatedBy = auth.ActorID(ctx)
+
+	if renamed && s.regenerateSlug {
+		slug, err := s.uniqueSlug(ctx, product.Name, product.ID)
+		if err != nil {
+			return nil, err
+		}
+		product.Slug = slug
+	}
+	changes := recordChanges(ctx, &before, product)
+
+	if IsDryRun(ctx) {
+		return product, nil
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "product update failed", "product_id", id, "error", err)
 		return nil, fmt.Errorf("failed to update product: %w", err)
 	}
 
+	s.logger.InfoContext(ctx, "product updated",
+		"product_id", id,
+		"fields", requestedFields(req),
+		"changed", changedFields(changes),
+		"actor", product.UpdatedBy,
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+	if !slices.Equal(before.Images, product.Images) {
+		s.checkImages(product)
+	}
+
 	return product, nil
 }
 
-func (s *productService) DeleteProduct(id string) error {
+func (s *productService) DeleteProduct(ctx context.Context, id string) error {
 	if id == "" {
 		return fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
 	}
 
-	product, err := s.repo.GetByID(id)
+	if s.hardDeleteDisabled {
+		return ErrHardDeleteDisabled
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("failed to get product for deletion: %w", err)
 	}
@@ -125,48 +722,288 @@ func (s *productService) DeleteProduct(id string) error {
 	if product == nil {
 		return ErrProductNotFound
 	}
+	if err := checkVersion(ctx, product); err != nil {
+		return err
+	}
+	recordPrevious(ctx, product)
 
-	if err := s.repo.Delete(id); err != nil {
-		return fmt.Errorf("failed to delete product: %w", err)
+	if IsDryRun(ctx) {
+		return nil
 	}
 
+	if err := s.deleteProduct(ctx, id); err != nil {
+		return err
+	}
+
+	s.logger.InfoContext(ctx, "product deleted",
+		"product_id", id,
+		"sku", product.SKU,
+		"actor", auth.ActorID(ctx),
+	)
+	s.publish(ctx, models.EventProductDeleted, id)
+
 	return nil
 }
 
-func (s *productService) validateCreateRequest(req models.CreateProductRequest) error {
-	if req.Name == "" {
-		return errors.New("product name is required")
+// deleteProduct removes the product, conditionally on the expected version
+// when ctx carries one, so a delete cannot race an update that landed after
+// the read.
+func (s *productService) deleteProduct(ctx context.Context, id string) error {
+	version, ok := expectedVersion(ctx)
+	if !ok {
+		if err := s.repo.Delete(ctx, id); err != nil {
+			s.logger.ErrorContext(ctx, "product delete failed", "product_id", id, "error", err)
+			return fmt.Errorf("failed to delete product: %w", err)
+		}
+		return nil
 	}
-	if req.Price <= 0 {
-		return errors.New("product price must be greater than 0")
+
+	deleted, err := s.repo.DeleteVersion(ctx, id, version)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "product delete failed", "product_id", id, "error", err)
+		return fmt.Errorf("failed to delete product: %w", err)
 	}
-	if req.Category == "" {
-		return errors.New("product category is required")
+	if !deleted {
+		return ErrPreconditionFailed
 	}
-	if req.SKU == "" {
-		return errors.New("product SKU is required")
+	return nil
+}
+
+// SoftDeleteProduct marks a product deleted and inactive without removing it,
+// so it drops out of listings but can be restored.
+func (s *productService) SoftDeleteProduct(ctx context.Context, id string) error {
+	if id == "" {
+		return fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
 	}
-	if req.Stock < 0 {
-		return errors.New("product stock cannot be negative")
+
+	_, err := retryOnConflict(func() (struct{}, error) {
+		return struct{}{}, s.softDeleteProduct(ctx, id)
+	})
+	return err
+}
+
+func (s *productService) softDeleteProduct(ctx context.Context, id string) error {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("failed to get product for deletion: %w", err)
 	}
+
+	if product == nil {
+		return ErrProductNotFound
+	}
+	if err := checkVersion(ctx, product); err != nil {
+		return err
+	}
+	recordPrevious(ctx, product)
+
+	if product.IsDeleted() || IsDryRun(ctx) {
+		return nil
+	}
+
+	now := s.clock.Now()
+	product.DeletedAt = &now
+	product.Status = product.CurrentStatus()
+	product.IsActive, product.StockDeactivated = false, false
+	product.UpdatedAt = now
+	product.UpdatedBy = auth.ActorID(ctx)
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "product soft delete failed", "product_id", id, "error", err)
+		return fmt.Errorf("failed to delete product: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "product soft deleted",
+		"product_id", id,
+		"sku", product.SKU,
+		"actor", product.UpdatedBy,
+	)
+	s.publish(ctx, models.EventProductDeleted, id)
+
 	return nil
 }
 
-func (s *productService) validateUpdateRequest(req models.UpdateProductRequest) error {
-	if req.Price != nil && *req.Price <= 0 {
-		return errors.New("product price must be greater than 0")
+// RestoreProduct undoes a soft delete and reactivates the product unless it
+// is a draft or archived. Restoring a product that is not deleted returns it
+// unchanged.
+func (s *productService) RestoreProduct(ctx context.Context, id string) (*models.Product, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
+	}
+
+	return retryOnConflict(func() (*models.Product, error) {
+		return s.restoreProduct(ctx, id)
+	})
+}
+
+func (s *productService) restoreProduct(ctx context.Context, id string) (*models.Product, error) {
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product for restore: %w", err)
 	}
-	if req.Stock != nil && *req.Stock < 0 {
-		return errors.New("product stock cannot be negative")
+
+	if product == nil {
+		return nil, ErrProductNotFound
 	}
-	if req.Name != nil && *req.Name == "" {
-		return errors.New("product name cannot be empty")
+
+	if !product.IsDeleted() {
+		return product, nil
+	}
+
+	product.DeletedAt = nil
+	// Drafts and archived products come back inactive. Products deleted
+	// before statuses existed have none and are reactivated, as they were.
+	product.IsActive = product.Status == "" || product.Status == models.StatusPublished
+	product.UpdatedAt = s.clock.Now()
+	product.UpdatedBy = auth.ActorID(ctx)
+
+	if IsDryRun(ctx) {
+		return product, nil
 	}
-	if req.Category != nil && *req.Category == "" {
-		return errors.New("product category cannot be empty")
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "product restore failed", "product_id", id, "error", err)
+		return nil, fmt.Errorf("failed to restore product: %w", err)
 	}
-	if req.SKU != nil && *req.SKU == "" {
-		return errors.New("product SKU cannot be empty")
+
+	s.logger.InfoContext(ctx, "product restored",
+		"product_id", id,
+		"sku", product.SKU,
+		"actor", product.UpdatedBy,
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+
+	return product, nil
+}
+
+// RateProduct records a rating between MinRating and MaxRating and returns the
+// product with its updated rating aggregate.
+func (s *productService) RateProduct(ctx context.Context, id string, rating int) (*models.Product, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
 	}
-	return nil
-}
\ No newline at end of file
+
+	if rating < models.MinRating || rating > models.MaxRating {
+		err := fieldError("rating", "rating must be between %d and %d", models.MinRating, models.MaxRating)
+		s.logRejected(ctx, "rate", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	product, err := s.repo.AddRating(ctx, id, rating)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "product rating failed", "product_id", id, "error", err)
+		return nil, fmt.Errorf("failed to rate product: %w", err)
+	}
+
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	s.logger.InfoContext(ctx, "product rated",
+		"product_id", id,
+		"rating", rating,
+		"actor", auth.ActorID(ctx),
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+
+	return product, nil
+}
+
+// GetShipping returns the product's shipping attributes. Shipping lookups
+// come from checkout rather than shoppers, so they do not count as views.
+func (s *productService) GetShipping(ctx context.Context, id string) (*models.ShippingInfo, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product: %w", err)
+	}
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	shipping := product.Shipping()
+	return &shipping, nil
+}
+
+// recordView increments the view count in the background so a slow or
+// failing counter update never delays the read that triggered it. It does
+// not wait for a DynamoDB slot either: when the limiter is saturated the
+// view is dropped rather than taking a slot a request is waiting for.
+func (s *productService) recordView(ctx context.Context, id string) {
+	ctx, cancel := context.WithTimeout(database.WithoutWaiting(ctx), viewCountTimeout)
+	defer cancel()
+
+	if err := s.repo.IncrementViewCount(ctx, id); err != nil {
+		s.logger.WarnContext(ctx, "product view count increment failed", "product_id", id, "error", err)
+	}
+}
+
+// logRejected records a validation failure. Only the failing field and
+// reason are logged, never the request payload.
+func (s *productService) logRejected(ctx context.Context, operation, id string, err error) {
+	attrs := []any{"operation", operation, "reason", err.Error()}
+	if id != "" {
+		attrs = append(attrs, "product_id", id)
+	}
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		attrs = append(attrs, "field", fieldErr.Field)
+	}
+	s.logger.InfoContext(ctx, "product validation rejected", attrs...)
+}
+
+// requestedFields lists the fields an update request sets, for logging.
+func requestedFields(req models.UpdateProductRequest) []string {
+	var fields []string
+	if req.Name != nil {
+		fields = append(fields, "name")
+	}
+	if req.Description != nil {
+		fields = append(fields, "description")
+	}
+	if req.Price != nil {
+		fields = append(fields, "price")
+	}
+	if req.Category != nil {
+		fields = append(fields, "category")
+	}
+	if req.SKU != nil {
+		fields = append(fields, "sku")
+	}
+	if req.Stock != nil {
+		fields = append(fields, "stock")
+	}
+	if req.Unit != nil {
+		fields = append(fields, "unit")
+	}
+	if req.IsActive != nil {
+		fields = append(fields, "is_active")
+	}
+	if req.Tags != nil {
+		fields = append(fields, "tags")
+	}
+	if req.Images != nil {
+		fields = append(fields, "images")
+	}
+	if req.WeightGrams != nil {
+		fields = append(fields, "weight_grams")
+	}
+	if req.LengthMM != nil {
+		fields = append(fields, "length_mm")
+	}
+	if req.WidthMM != nil {
+		fields = append(fields, "width_mm")
+	}
+	if req.HeightMM != nil {
+		fields = append(fields, "height_mm")
+	}
+	if req.BundleItems != nil {
+		fields = append(fields, "bundle_items")
+	}
+	if req.AvailableFrom != nil {
+		fields = append(fields, "available_from")
+	}
+	return fields
+}
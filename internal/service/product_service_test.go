@@ -1,49 +1,211 @@
 package service
 
 import (
-	"errors"
+	"context"
+	"fmt"
+	"math"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 
+	"product-service/internal/auth"
 	"product-service/internal/models"
+	"product-service/internal/repository"
 )
 
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
 type MockProductRepository struct {
 	mock.Mock
 }
 
-func (m *MockProductRepository) Create(product *models.Product) error {
+func (m *MockProductRepository) Create(ctx context.Context, product *models.Product) error {
 	args := m.Called(product)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) GetByID(id string) (*models.Product, error) {
+func (m *MockProductRepository) GetByID(ctx context.Context, id string) (*models.Product, error) {
 	args := m.Called(id)
 	return args.Get(0).(*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetAll() ([]*models.Product, error) {
+func (m *MockProductRepository) GetByIDs(ctx context.Context, ids []string) ([]*models.Product, error) {
+	args := m.Called(ids)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySKUs(ctx context.Context, skus []string) ([]*models.Product, error) {
+	args := m.Called(skus)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySKU(ctx context.Context, sku string) (*models.Product, error) {
+	args := m.Called(sku)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) GetBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	args := m.Called(slug)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) ExistsBySKU(ctx context.Context, sku string) (bool, error) {
+	args := m.Called(sku)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) GetAll(ctx context.Context) (*models.ProductList, error) {
+	args := m.Called()
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductRepository) ScanActive(ctx context.Context, fn func(page []*models.Product) error) error {
+	args := m.Called()
+	for _, page := range args.Get(0).([][]*models.Product) {
+		if err := fn(page); err != nil {
+			return err
+		}
+	}
+	return args.Error(1)
+}
+
+func (m *MockProductRepository) GetInactive(ctx context.Context) ([]*models.Product, error) {
 	args := m.Called()
 	return args.Get(0).([]*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) GetByCategory(category string) ([]*models.Product, error) {
-	args := m.Called(category)
+func (m *MockProductRepository) GetFeatured(ctx context.Context) ([]*models.Product, error) {
+	args := m.Called()
 	return args.Get(0).([]*models.Product), args.Error(1)
 }
 
-func (m *MockProductRepository) Update(product *models.Product) error {
+func (m *MockProductRepository) GetUpdatedSince(ctx context.Context, since time.Time) (*models.ProductList, error) {
+	args := m.Called(since)
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductRepository) GetByCategory(ctx context.Context, category string) (*models.ProductList, error) {
+	args := m.Called(category)
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductRepository) Update(ctx context.Context, product *models.Product) error {
 	args := m.Called(product)
 	return args.Error(0)
 }
 
-func (m *MockProductRepository) Delete(id string) error {
+func (m *MockProductRepository) Delete(ctx context.Context, id string) error {
 	args := m.Called(id)
 	return args.Error(0)
 }
 
+func (m *MockProductRepository) DeleteVersion(ctx context.Context, id string, version int64) (bool, error) {
+	args := m.Called(id, version)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) IncrementViewCount(ctx context.Context, id string) error {
+	args := m.Called(id)
+	return args.Error(0)
+}
+
+func (m *MockProductRepository) Filter(ctx context.Context, filter models.ProductFilter) (*models.ProductList, error) {
+	args := m.Called(filter)
+	return args.Get(0).(*models.ProductList), args.Error(1)
+}
+
+func (m *MockProductRepository) AddRating(ctx context.Context, id string, rating int) (*models.Product, error) {
+	args := m.Called(id, rating)
+	return args.Get(0).(*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) SetStock(ctx context.Context, id string, stock float64, followStock bool, actor string, now time.Time) (*models.Product, bool, error) {
+	args := m.Called(id, stock, followStock, actor)
+	return args.Get(0).(*models.Product), args.Bool(1), args.Error(2)
+}
+
+func (m *MockProductRepository) SetStockActivation(ctx context.Context, id string, active bool) (bool, error) {
+	args := m.Called(id, active)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) ClearStock(ctx context.Context, id string, version int64, deactivate bool, actor string, now time.Time) (*models.Product, bool, error) {
+	args := m.Called(id, version, deactivate, actor)
+	if args.Get(0) == nil {
+		return nil, args.Bool(1), args.Error(2)
+	}
+	return args.Get(0).(*models.Product), args.Bool(1), args.Error(2)
+}
+
+func (m *MockProductRepository) SearchByNamePrefix(ctx context.Context, prefix string, limit int) ([]*models.Product, error) {
+	args := m.Called(prefix, limit)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
+func (m *MockProductRepository) SearchByName(ctx context.Context, query, after string, limit int) ([]*models.Product, string, error) {
+	args := m.Called(query, after, limit)
+	if args.Get(0) == nil {
+		return nil, "", args.Error(2)
+	}
+	return args.Get(0).([]*models.Product), args.String(1), args.Error(2)
+}
+
+func (m *MockProductRepository) SetCounts(ctx context.Context, id string, from, to models.ProductCounts) (bool, error) {
+	args := m.Called(id, from, to)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error) {
+	args := m.Called(id, version, slug, categoryPath)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) SetCategory(ctx context.Context, id string, version int64, category string, categoryPath []string, actor string, now time.Time) (bool, error) {
+	args := m.Called(id, version, category, categoryPath, actor)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) SetBrokenImages(ctx context.Context, id string, images, broken []string) (bool, error) {
+	args := m.Called(id, images, broken)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) ReserveStock(ctx context.Context, reservation models.Reservation, now time.Time) (*models.Product, bool, error) {
+	args := m.Called(reservation)
+	return args.Get(0).(*models.Product), args.Bool(1), args.Error(2)
+}
+
+func (m *MockProductRepository) ReleaseReservation(ctx context.Context, id, reservationID string, version int64, stock float64, now time.Time) (bool, error) {
+	args := m.Called(id, reservationID, version, stock)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockProductRepository) ScanPage(ctx context.Context, after string, limit int) ([]*models.Product, string, error) {
+	args := m.Called(after, limit)
+	return args.Get(0).([]*models.Product), args.String(1), args.Error(2)
+}
+
+func (m *MockProductRepository) GetReserved(ctx context.Context) ([]*models.Product, error) {
+	args := m.Called()
+	return args.Get(0).([]*models.Product), args.Error(1)
+}
+
 func TestProductService_CreateProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
@@ -54,12 +216,14 @@ func TestProductService_CreateProduct_Success(t *testing.T) {
 		Price:       99.99,
 		Category:    "electronics",
 		SKU:         "TEST-001",
-		Stock:       10,
+		Stock:       floatPtr(10),
 	}
 
 	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
 
-	product, err := service.CreateProduct(req)
+	product, err := service.CreateProduct(context.Background(), req)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, product)
@@ -68,6 +232,27 @@ func TestProductService_CreateProduct_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_CreateProduct_DefaultStock(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(), WithDefaultStock(25))
+	req := models.CreateProductRequest{Name: "Pen", Price: 1.5, Category: "office", SKU: "PEN-1"}
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(25), product.Stock)
+
+	req.SKU, req.Stock = "PEN-2", floatPtr(0)
+	product, err = service.CreateProduct(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Zero(t, product.Stock, "an explicit zero stock is kept")
+
+	req.SKU, req.Stock = "PEN-3", floatPtr(-1)
+	_, err = service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
+
 func TestProductService_CreateProduct_ValidationError(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
@@ -77,16 +262,455 @@ func TestProductService_CreateProduct_ValidationError(t *testing.T) {
 		Price:    99.99,
 		Category: "electronics",
 		SKU:      "TEST-001",
-		Stock:    10,
+		Stock:    floatPtr(10),
 	}
 
-	product, err := service.CreateProduct(req)
+	product, err := service.CreateProduct(context.Background(), req)
 
 	assert.Error(t, err)
 	assert.Nil(t, product)
 	assert.Contains(t, err.Error(), "invalid product data")
 }
 
+func TestProductService_CreateProduct_WithinMaxStock(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithMaxStock(100))
+
+	req := models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(100),
+	}
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, float64(100), product.Stock)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_CreateProduct_ExceedsMaxStock(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithMaxStock(100))
+
+	req := models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(2000000000),
+	}
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "stock", fieldErr.Field)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestProductService_UpdateProduct_ExceedsMaxStock(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithMaxStock(100))
+
+	existingProduct := &models.Product{
+		ID:    "test-id",
+		Name:  "Test Product",
+		Price: 99.99,
+		Stock: 10,
+	}
+
+	newStock := 101.0
+	updateReq := models.UpdateProductRequest{
+		Stock: &newStock,
+	}
+
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+
+	product, err := service.UpdateProduct(context.Background(), "test-id", updateReq)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_ShippingAttributesMustNotBeNegative(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name:        "Parcel",
+		Price:       9.99,
+		Category:    "electronics",
+		SKU:         "SHIP-001",
+		WeightGrams: 500,
+		LengthMM:    -1,
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "length_mm", fieldErr.Field)
+	}
+
+	mockRepo.On("GetByID", "test-id").Return(&models.Product{ID: "test-id", Name: "Parcel", Price: 9.99, WeightGrams: 500}, nil)
+
+	weight := -5
+	_, err = service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{WeightGrams: &weight})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "weight_grams", fieldErr.Field)
+	}
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_GetProductBySKU(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetBySKU", "WID-001").Return(&models.Product{ID: "test-id", SKU: "WID-001"}, nil)
+	mockRepo.On("GetBySKU", "WID-404").Return((*models.Product)(nil), nil)
+	mockRepo.On("IncrementViewCount", "test-id").Return(nil).Maybe()
+
+	product, err := service.GetProductBySKU(context.Background(), "WID-001")
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", product.ID)
+
+	_, err = service.GetProductBySKU(context.Background(), "WID-404")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	_, err = service.GetProductBySKU(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
+
+func TestProductService_GetShipping(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetByID", "test-id").Return(&models.Product{ID: "test-id", SKU: "SHIP-001", WeightGrams: 500, LengthMM: 300, WidthMM: 200, HeightMM: 100}, nil)
+	mockRepo.On("GetByID", "missing").Return((*models.Product)(nil), nil)
+
+	shipping, err := service.GetShipping(context.Background(), "test-id")
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1200, shipping.VolumetricWeightGrams)
+	assert.Equal(t, 1200, shipping.ChargeableWeightGrams)
+	mockRepo.AssertNotCalled(t, "IncrementViewCount", mock.Anything)
+
+	_, err = service.GetShipping(context.Background(), "missing")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_CreateProduct_CategoryMinPrice(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCategoryMinPrice(map[string]float64{"electronics": 5}))
+
+	req := models.CreateProductRequest{
+		Name:     "Cable",
+		Price:    4.99,
+		Category: "electronics",
+		SKU:      "ELEC-001",
+		Stock:    floatPtr(10),
+	}
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "price", fieldErr.Field)
+	assert.Contains(t, fieldErr.Message, "at least 5")
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	// Categories without a floor only need a positive price.
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+	req.Category = "books"
+	req.SKU = "BOOK-001"
+
+	product, err = service.CreateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4.99, product.Price)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_UpdateProduct_CategoryMinPrice(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCategoryMinPrice(map[string]float64{"electronics": 5}))
+
+	existingProduct := &models.Product{
+		ID:       "test-id",
+		Name:     "Cable",
+		Price:    3,
+		Category: "books",
+		Stock:    10,
+	}
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+
+	// Moving into a category with a floor holds the current price to it.
+	category := "electronics"
+	product, err := service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Category: &category})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "price", fieldErr.Field)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+	price := 2.0
+	product, err = service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Price: &price})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 2.0, product.Price)
+}
+
+func TestProductService_CreateProduct_CategorySKUPrefix(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCategorySKUPrefix(map[string]string{"electronics": "ELEC-"}))
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+
+	req := models.CreateProductRequest{
+		Name:     "Cable",
+		Price:    4.99,
+		Category: "electronics",
+		SKU:      "ELEC-001",
+		Stock:    floatPtr(10),
+	}
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC-001", product.SKU)
+
+	req.SKU = "BOOK-001"
+	product, err = service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "sku", fieldErr.Field)
+	assert.Contains(t, fieldErr.Message, `"ELEC-"`)
+
+	// Categories without a prefix accept any SKU.
+	req.Category = "books"
+	req.SKU = "ANY-001"
+	product, err = service.CreateProduct(context.Background(), req)
+
+	require.NoError(t, err)
+	assert.Equal(t, "ANY-001", product.SKU)
+	mockRepo.AssertNumberOfCalls(t, "Create", 2)
+}
+
+func TestProductService_UpdateProduct_CategorySKUPrefix(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCategorySKUPrefix(map[string]string{"electronics": "ELEC-"}))
+
+	existingProduct := &models.Product{
+		ID:       "test-id",
+		Name:     "Cable",
+		Price:    3,
+		Category: "books",
+		SKU:      "BOOK-001",
+		Stock:    10,
+	}
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+
+	// Moving into a category with a prefix holds the current SKU to it.
+	category := "electronics"
+	product, err := service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Category: &category})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "sku", fieldErr.Field)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	sku := "ELEC-001"
+	product, err = service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Category: &category, SKU: &sku})
+
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC-001", product.SKU)
+	assert.Equal(t, "electronics", product.Category)
+}
+
+func TestProductService_CreateProduct_TextLimits(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(),
+		WithMinNameLength(3), WithMaxDescriptionLength(10))
+
+	tests := []struct {
+		name        string
+		productName string
+		description string
+		wantField   string
+	}{
+		{"name at minimum", "Pen", "", ""},
+		{"name below minimum", "Pe", "", "name"},
+		{"multibyte name at minimum", "日本語", "", ""},
+		{"description at maximum", "Pen", strings.Repeat("a", 10), ""},
+		{"description above maximum", "Pen", strings.Repeat("a", 11), "description"},
+		{"multibyte description at maximum", "Pen", strings.Repeat("é", 10), ""},
+	}
+
+	for i, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+				Name:        tt.productName,
+				Description: tt.description,
+				Price:       1,
+				Category:    "office",
+				SKU:         fmt.Sprintf("PEN-%d", i),
+			})
+
+			if tt.wantField == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrInvalidProduct)
+			var fieldErr *FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tt.wantField, fieldErr.Field)
+		})
+	}
+}
+
+func TestProductService_UpdateProduct_TextLimits(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithMinNameLength(3), WithMaxDescriptionLength(10))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "p1", Name: "Pen", Price: 1, IsActive: true}))
+
+	short := "Pe"
+	_, err := service.UpdateProduct(context.Background(), "p1", models.UpdateProductRequest{Name: &short})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	long := strings.Repeat("a", 11)
+	_, err = service.UpdateProduct(context.Background(), "p1", models.UpdateProductRequest{Description: &long})
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "description", fieldErr.Field)
+
+	exact := strings.Repeat("a", 10)
+	product, err := service.UpdateProduct(context.Background(), "p1", models.UpdateProductRequest{Description: &exact})
+	require.NoError(t, err)
+	assert.Equal(t, exact, product.Description)
+
+	_, err = service.SetTranslation(context.Background(), "p1", "fr", models.ProductTranslation{Name: "Stylo", Description: long})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
+
+func TestProductService_CreateProduct_NonFinite(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	tests := []struct {
+		name      string
+		price     float64
+		stock     float64
+		wantField string
+	}{
+		{"infinite price", math.Inf(1), 1, "price"},
+		{"negative infinite price", math.Inf(-1), 1, "price"},
+		{"NaN price", math.NaN(), 1, "price"},
+		{"infinite stock", 1, math.Inf(1), "stock"},
+		{"NaN stock", 1, math.NaN(), "stock"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+				Name:     "Pen",
+				Price:    tt.price,
+				Category: "office",
+				SKU:      "PEN-1",
+				Stock:    floatPtr(tt.stock),
+			})
+
+			assert.ErrorIs(t, err, ErrInvalidProduct)
+			var fieldErr *FieldError
+			require.ErrorAs(t, err, &fieldErr)
+			assert.Equal(t, tt.wantField, fieldErr.Field)
+			assert.Contains(t, fieldErr.Message, "finite")
+		})
+	}
+}
+
+func TestProductService_UpdateProduct_NonFinite(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "p1", Name: "Pen", Price: 1, IsActive: true}))
+
+	for _, v := range []float64{math.Inf(1), math.NaN()} {
+		_, err := service.UpdateProduct(context.Background(), "p1", models.UpdateProductRequest{Price: &v})
+		var fieldErr *FieldError
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "price", fieldErr.Field)
+
+		_, err = service.UpdateProduct(context.Background(), "p1", models.UpdateProductRequest{Stock: &v})
+		require.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "stock", fieldErr.Field)
+	}
+
+	inf := math.Inf(1)
+	results, err := service.BulkSetStock(context.Background(), []models.StockUpdate{{ID: "p1", Stock: &inf}})
+	require.NoError(t, err)
+	assert.Equal(t, models.StockFailed, results[0].Status)
+
+	stored, _ := repo.GetByID(context.Background(), "p1")
+	assert.Equal(t, float64(1), stored.Price)
+	assert.Zero(t, stored.Stock)
+}
+
+func TestProductService_CreateProduct_Attribution(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	req := models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(10),
+	}
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "user-42"})
+	product, err := service.CreateProduct(ctx, req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "user-42", product.CreatedBy)
+	assert.Equal(t, "user-42", product.UpdatedBy)
+
+	anonymous, err := service.CreateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, auth.Anonymous, anonymous.CreatedBy)
+	assert.Equal(t, auth.Anonymous, anonymous.UpdatedBy)
+}
+
 func TestProductService_GetProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
@@ -98,21 +722,43 @@ func TestProductService_GetProduct_Success(t *testing.T) {
 	}
 
 	mockRepo.On("GetByID", "test-id").Return(expectedProduct, nil)
+	mockRepo.On("IncrementViewCount", "test-id").Return(nil).Maybe()
 
-	product, err := service.GetProduct("test-id")
+	product, err := service.GetProduct(context.Background(), "test-id")
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProduct, product)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_GetProduct_RecordsView(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	viewed := make(chan string, 1)
+	mockRepo.On("GetByID", "test-id").Return(&models.Product{ID: "test-id"}, nil)
+	mockRepo.On("IncrementViewCount", "test-id").Return(nil).Run(func(args mock.Arguments) {
+		viewed <- args.String(0)
+	})
+
+	_, err := service.GetProduct(context.Background(), "test-id")
+	assert.NoError(t, err)
+
+	select {
+	case id := <-viewed:
+		assert.Equal(t, "test-id", id)
+	case <-time.After(time.Second):
+		t.Fatal("view count was not incremented")
+	}
+}
+
 func TestProductService_GetProduct_NotFound(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
 
 	mockRepo.On("GetByID", "nonexistent-id").Return((*models.Product)(nil), nil)
 
-	product, err := service.GetProduct("nonexistent-id")
+	product, err := service.GetProduct(context.Background(), "nonexistent-id")
 
 	assert.Error(t, err)
 	assert.Nil(t, product)
@@ -124,42 +770,186 @@ func TestProductService_GetProduct_EmptyID(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
 
-	product, err := service.GetProduct("")
+	product, err := service.GetProduct(context.Background(), "")
 
 	assert.Error(t, err)
 	assert.Nil(t, product)
 	assert.Contains(t, err.Error(), "invalid product data")
 }
 
+func TestProductService_ProductExistsBySKU(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("ExistsBySKU", "TEST-001").Return(true, nil)
+
+	exists, err := service.ProductExistsBySKU(context.Background(), "TEST-001")
+
+	assert.NoError(t, err)
+	assert.True(t, exists)
+
+	_, err = service.ProductExistsBySKU(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestProductService_GetAllProducts_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
 
-	expectedProducts := []*models.Product{
-		{ID: "1", Name: "Product 1"},
-		{ID: "2", Name: "Product 2"},
+	expectedProducts := &models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Name: "Product 1"},
+			{ID: "2", Name: "Product 2"},
+		},
 	}
 
 	mockRepo.On("GetAll").Return(expectedProducts, nil)
 
-	products, err := service.GetAllProducts()
+	products, err := service.GetAllProducts(context.Background(), models.ListOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProducts, products)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_FilterProducts_InvalidPriceRange(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	min, max := 20.0, 10.0
+	_, err := service.FilterProducts(context.Background(), models.ProductFilter{MinPrice: &min, MaxPrice: &max}, models.ListOptions{})
+
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	mockRepo.AssertNotCalled(t, "Filter", mock.Anything)
+}
+
+func TestProductService_GetTrendingProducts_Ordering(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "low", ViewCount: 1, UpdatedAt: base},
+			{ID: "tie-older", ViewCount: 20, UpdatedAt: base},
+			{ID: "top", ViewCount: 99, UpdatedAt: base},
+			{ID: "tie-newer", ViewCount: 20, UpdatedAt: base.Add(time.Hour)},
+			{ID: "tie-older-b", ViewCount: 20, UpdatedAt: base},
+			{ID: "none", ViewCount: 0, UpdatedAt: base.Add(2 * time.Hour)},
+		},
+	}, nil)
+
+	products, err := service.GetTrendingProducts(context.Background(), 5)
+
+	assert.NoError(t, err)
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	assert.Equal(t, []string{"top", "tie-newer", "tie-older", "tie-older-b", "low"}, ids)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_GetTrendingProducts_InvalidLimit(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	_, err := service.GetTrendingProducts(context.Background(), 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = service.GetTrendingProducts(context.Background(), MaxTrendingLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestProductService_GetInventoryValuation(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Category: "electronics", Price: 19.99, Stock: 3},
+			{ID: "2", Category: "electronics", Price: 0.1, Stock: 7},
+			{ID: "3", Category: "garden", Price: 2.5, Stock: 1.5},
+			{ID: "4", Category: "garden", Price: 100, Stock: 0},
+		},
+	}, nil)
+
+	valuation, err := service.GetInventoryValuation(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "USD", valuation.Currency)
+	assert.Equal(t, 64.42, valuation.Total)
+	assert.Equal(t, 4, valuation.ProductCount)
+	assert.Equal(t, map[string]models.CategoryValuation{
+		"electronics": {Total: 60.67, ProductCount: 2},
+		"garden":      {Total: 3.75, ProductCount: 2},
+	}, valuation.Categories)
+	assert.False(t, valuation.Truncated)
+}
+
+func TestProductService_GetInventoryValuation_ZeroDecimalCurrency(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCurrencyRules(models.NewCurrencyRules("JPY", nil)))
+
+	mockRepo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Category: "snacks", Price: 149.5, Stock: 3},
+		},
+	}, nil)
+
+	valuation, err := service.GetInventoryValuation(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, "JPY", valuation.Currency)
+	assert.Equal(t, float64(449), valuation.Total)
+}
+
+func TestProductService_GetInventoryValuation_RoundsHalfAwayFromZero(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	// 1.005 is stored just below itself, which a float rounding takes to 1.
+	mockRepo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Category: "snacks", Price: 1.005, Stock: 1},
+		},
+	}, nil)
+
+	valuation, err := service.GetInventoryValuation(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1.01, valuation.Total)
+}
+
+func TestProductService_GetInventoryValuation_Overflow(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Category: "bulk", Price: math.MaxFloat64, Stock: 10},
+		},
+	}, nil)
+
+	_, err := service.GetInventoryValuation(context.Background())
+
+	assert.Error(t, err)
+}
+
 func TestProductService_GetProductsByCategory_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
 
-	expectedProducts := []*models.Product{
-		{ID: "1", Name: "Product 1", Category: "electronics"},
+	expectedProducts := &models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Name: "Product 1", Category: "electronics"},
+		},
 	}
 
 	mockRepo.On("GetByCategory", "electronics").Return(expectedProducts, nil)
 
-	products, err := service.GetProductsByCategory("electronics")
+	products, err := service.GetProductsByCategory(context.Background(), "electronics", models.ListOptions{})
 
 	assert.NoError(t, err)
 	assert.Equal(t, expectedProducts, products)
@@ -184,7 +974,7 @@ func TestProductService_UpdateProduct_Success(t *testing.T) {
 	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
 	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
 
-	product, err := service.UpdateProduct("test-id", updateReq)
+	product, err := service.UpdateProduct(context.Background(), "test-id", updateReq)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, product)
@@ -192,6 +982,35 @@ func TestProductService_UpdateProduct_Success(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_UpdateProduct_Attribution(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	existingProduct := &models.Product{
+		ID:        "test-id",
+		Name:      "Original Name",
+		Price:     50.00,
+		CreatedBy: "creator",
+		UpdatedBy: "creator",
+	}
+
+	newName := "Updated Name"
+	updateReq := models.UpdateProductRequest{
+		Name: &newName,
+	}
+
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "editor"})
+	product, err := service.UpdateProduct(ctx, "test-id", updateReq)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "creator", product.CreatedBy)
+	assert.Equal(t, "editor", product.UpdatedBy)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestProductService_UpdateProduct_NotFound(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
@@ -200,7 +1019,7 @@ func TestProductService_UpdateProduct_NotFound(t *testing.T) {
 
 	mockRepo.On("GetByID", "nonexistent-id").Return((*models.Product)(nil), nil)
 
-	product, err := service.UpdateProduct("nonexistent-id", updateReq)
+	product, err := service.UpdateProduct(context.Background(), "nonexistent-id", updateReq)
 
 	assert.Error(t, err)
 	assert.Nil(t, product)
@@ -208,6 +1027,179 @@ func TestProductService_UpdateProduct_NotFound(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_UpdateProduct_NoChanges(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	updatedAt := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	existing := &models.Product{
+		ID:        "test-id",
+		Name:      "Widget",
+		Price:     10,
+		Unit:      models.UnitEach,
+		UpdatedAt: updatedAt,
+		UpdatedBy: "alice",
+	}
+	mockRepo.On("GetByID", "test-id").Return(existing, nil)
+
+	name := "Widget"
+	price := 10.0
+	for _, req := range []models.UpdateProductRequest{{}, {Name: &name, Price: &price}} {
+		ctx := auth.WithPrincipal(context.Background(), auth.Principal{ID: "bob"})
+		product, err := service.UpdateProduct(ctx, "test-id", req)
+
+		assert.NoError(t, err)
+		assert.Equal(t, updatedAt, product.UpdatedAt)
+		assert.Equal(t, "alice", product.UpdatedBy)
+	}
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_UpdateProduct_RecordsChanges(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	existing := &models.Product{ID: "test-id", Name: "Widget", Price: 10, Stock: 4, Unit: models.UnitEach, IsActive: true}
+	mockRepo.On("GetByID", "test-id").Return(existing, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	// Name and stock are sent unchanged and must not be reported.
+	name := "Widget"
+	price := 12.0
+	stock := 4.0
+	active := false
+	ctx, changes := WithChanges(context.Background())
+	_, err := service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Name: &name, Price: &price, Stock: &stock, IsActive: &active})
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.FieldChange{
+		{Field: "price", Before: 10.0, After: 12.0},
+		{Field: "is_active", Before: true, After: false},
+		{Field: "status", Before: models.StatusPublished, After: models.StatusArchived},
+	}, changes.Fields)
+}
+
+func TestProductService_RateProduct_Average(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Widget"}))
+
+	var product *models.Product
+	var err error
+	for _, rating := range []int{5, 4, 4, 2} {
+		product, err = service.RateProduct(context.Background(), "test-id", rating)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, product.RatingCount)
+	assert.InDelta(t, 3.75, product.AverageRating(), 1e-9)
+
+	stored, err := repo.GetByID(context.Background(), "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), stored.RatingSum)
+}
+
+func TestProductService_RateProduct_SurvivesConcurrentEdit(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Name: "Widget", Price: 10, Unit: models.UnitEach}))
+
+	// The edit reads the product before the rating lands and writes after.
+	read, err := repo.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	_, err = service.RateProduct(ctx, "test-id", 5)
+	require.NoError(t, err)
+	read.Name = "Renamed"
+	require.NoError(t, repo.Update(ctx, read))
+
+	stored, err := repo.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", stored.Name)
+	assert.Equal(t, 1, stored.RatingCount)
+	assert.Equal(t, int64(5), stored.RatingSum)
+}
+
+func TestProductService_RateProduct_OutOfRange(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	for _, rating := range []int{0, 6, -1} {
+		_, err := service.RateProduct(context.Background(), "test-id", rating)
+
+		assert.ErrorIs(t, err, ErrInvalidProduct)
+		var fieldErr *FieldError
+		assert.ErrorAs(t, err, &fieldErr)
+		assert.Equal(t, "rating", fieldErr.Field)
+	}
+	mockRepo.AssertNotCalled(t, "AddRating", mock.Anything, mock.Anything)
+}
+
+func TestProductService_RateProduct_NotFound(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("AddRating", "missing", 3).Return((*models.Product)(nil), nil)
+
+	_, err := service.RateProduct(context.Background(), "missing", 3)
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
+
+func TestProductService_UpdateProduct_FractionalStockForEach(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	existingProduct := &models.Product{
+		ID:    "test-id",
+		Name:  "Coffee Beans",
+		Price: 18.50,
+		Stock: 2.5,
+		Unit:  models.UnitKilogram,
+	}
+
+	each := models.UnitEach
+	updateReq := models.UpdateProductRequest{
+		Unit: &each,
+	}
+
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+
+	product, err := service.UpdateProduct(context.Background(), "test-id", updateReq)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestProductService_UpdateProduct_FractionalStockForKg(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	existingProduct := &models.Product{
+		ID:    "test-id",
+		Name:  "Coffee Beans",
+		Price: 18.50,
+		Stock: 2,
+		Unit:  models.UnitKilogram,
+	}
+
+	newStock := 0.75
+	updateReq := models.UpdateProductRequest{
+		Stock: &newStock,
+	}
+
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+	mockRepo.On("Update", mock.AnythingOfType("*models.Product")).Return(nil)
+
+	product, err := service.UpdateProduct(context.Background(), "test-id", updateReq)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.75, product.Stock)
+	mockRepo.AssertExpectations(t)
+}
+
 func TestProductService_DeleteProduct_Success(t *testing.T) {
 	mockRepo := new(MockProductRepository)
 	service := NewProductService(mockRepo)
@@ -220,7 +1212,7 @@ func TestProductService_DeleteProduct_Success(t *testing.T) {
 	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
 	mockRepo.On("Delete", "test-id").Return(nil)
 
-	err := service.DeleteProduct("test-id")
+	err := service.DeleteProduct(context.Background(), "test-id")
 
 	assert.NoError(t, err)
 	mockRepo.AssertExpectations(t)
@@ -232,13 +1224,67 @@ func TestProductService_DeleteProduct_NotFound(t *testing.T) {
 
 	mockRepo.On("GetByID", "nonexistent-id").Return((*models.Product)(nil), nil)
 
-	err := service.DeleteProduct("nonexistent-id")
+	err := service.DeleteProduct(context.Background(), "nonexistent-id")
 
 	assert.Error(t, err)
 	assert.Equal(t, ErrProductNotFound, err)
 	mockRepo.AssertExpectations(t)
 }
 
+func TestProductService_DryRun_NeverWrites(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+	ctx := WithDryRun(context.Background())
+
+	existingProduct := &models.Product{
+		ID:    "test-id",
+		Name:  "Original Name",
+		Price: 50.00,
+	}
+	mockRepo.On("GetByID", "test-id").Return(existingProduct, nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+
+	created, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    99.99,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(10),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "Test Product", created.Name)
+
+	newName := "Updated Name"
+	updated, err := service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Name: &newName})
+	assert.NoError(t, err)
+	assert.Equal(t, newName, updated.Name)
+
+	err = service.DeleteProduct(ctx, "test-id")
+	assert.NoError(t, err)
+
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+	mockRepo.AssertNotCalled(t, "Delete", mock.Anything)
+}
+
+func TestProductService_DryRun_ReportsValidationErrors(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	product, err := service.CreateProduct(WithDryRun(context.Background()), models.CreateProductRequest{
+		Name:     "Test Product",
+		Price:    0,
+		Category: "electronics",
+		SKU:      "TEST-001",
+		Stock:    floatPtr(10),
+	})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
 func TestProductService_validateCreateRequest(t *testing.T) {
 	service := &productService{}
 
@@ -255,7 +1301,7 @@ func TestProductService_validateCreateRequest(t *testing.T) {
 				Price:    99.99,
 				Category: "electronics",
 				SKU:      "TEST-001",
-				Stock:    10,
+				Stock:    floatPtr(10),
 			},
 			wantErr: false,
 		},
@@ -266,7 +1312,7 @@ func TestProductService_validateCreateRequest(t *testing.T) {
 				Price:    99.99,
 				Category: "electronics",
 				SKU:      "TEST-001",
-				Stock:    10,
+				Stock:    floatPtr(10),
 			},
 			wantErr: true,
 			errMsg:  "product name is required",
@@ -278,7 +1324,7 @@ func TestProductService_validateCreateRequest(t *testing.T) {
 				Price:    0,
 				Category: "electronics",
 				SKU:      "TEST-001",
-				Stock:    10,
+				Stock:    floatPtr(10),
 			},
 			wantErr: true,
 			errMsg:  "product price must be greater than 0",
@@ -290,11 +1336,49 @@ func TestProductService_validateCreateRequest(t *testing.T) {
 				Price:    99.99,
 				Category: "electronics",
 				SKU:      "TEST-001",
-				Stock:    -1,
+				Stock:    floatPtr(-1),
 			},
 			wantErr: true,
 			errMsg:  "product stock cannot be negative",
 		},
+		{
+			name: "fractional stock for each",
+			req: models.CreateProductRequest{
+				Name:     "Test Product",
+				Price:    99.99,
+				Category: "electronics",
+				SKU:      "TEST-001",
+				Stock:    floatPtr(1.5),
+				Unit:     models.UnitEach,
+			},
+			wantErr: true,
+			errMsg:  "product stock must be a whole number",
+		},
+		{
+			name: "fractional stock for kg",
+			req: models.CreateProductRequest{
+				Name:     "Coffee Beans",
+				Price:    18.50,
+				Category: "grocery",
+				SKU:      "GROC-001",
+				Stock:    floatPtr(1.5),
+				Unit:     models.UnitKilogram,
+			},
+			wantErr: false,
+		},
+		{
+			name: "unsupported unit",
+			req: models.CreateProductRequest{
+				Name:     "Test Product",
+				Price:    99.99,
+				Category: "electronics",
+				SKU:      "TEST-001",
+				Stock:    floatPtr(10),
+				Unit:     "bushel",
+			},
+			wantErr: true,
+			errMsg:  "product unit \"bushel\" is not supported",
+		},
 	}
 
 	for _, tt := range tests {
@@ -308,4 +1392,73 @@ func TestProductService_validateCreateRequest(t *testing.T) {
 			}
 		})
 	}
-}
\ No newline at end of file
+}
+
+func TestProductService_SuggestProducts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	for _, product := range []*models.Product{
+		{ID: "1", Name: "Widget Pro", IsActive: true},
+		{ID: "2", Name: "Widget", IsActive: true},
+		{ID: "3", Name: "Widget Mini", IsActive: false},
+		{ID: "4", Name: "Gadget", IsActive: true},
+		{ID: "5", Name: "widget lowercase", IsActive: true},
+	} {
+		require.NoError(t, repo.Create(context.Background(), product))
+	}
+
+	suggestions, err := service.SuggestProducts(context.Background(), "Wid", 10)
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.ProductSuggestion{
+		{ID: "2", Name: "Widget"},
+		{ID: "1", Name: "Widget Pro"},
+	}, suggestions)
+
+	_, err = service.SuggestProducts(context.Background(), "", 10)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	_, err = service.SuggestProducts(context.Background(), "Wid", MaxSuggestLimit+1)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+// racingRepository lands a stock change just before each of the first races
+// updates, as if another request had written the product in between.
+type racingRepository struct {
+	repository.ProductRepository
+	races int
+}
+
+func (r *racingRepository) Update(ctx context.Context, product *models.Product) error {
+	if r.races > 0 {
+		r.races--
+		if _, _, err := r.SetStock(ctx, product.ID, float64(100+r.races), false, "other", time.Now()); err != nil {
+			return err
+		}
+	}
+	return r.ProductRepository.Update(ctx, product)
+}
+
+func TestProductService_UpdateProduct_RetriesLostRace(t *testing.T) {
+	repo := &racingRepository{ProductRepository: repository.NewMemoryProductRepository(), races: 1}
+	service := NewProductService(repo)
+	ctx := context.Background()
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Widget", Price: 10, Category: "tools", SKU: "W-1", Stock: floatPtr(5),
+	})
+	require.NoError(t, err)
+
+	name := "Renamed"
+	updated, err := service.UpdateProduct(ctx, product.ID, models.UpdateProductRequest{Name: &name})
+
+	require.NoError(t, err)
+	assert.Equal(t, "Renamed", updated.Name)
+	// The concurrent stock change survives the rename.
+	assert.Equal(t, float64(100), updated.Stock)
+
+	repo.races = maxConflictAttempts
+	_, err = service.UpdateProduct(ctx, product.ID, models.UpdateProductRequest{Name: &product.Name})
+
+	assert.ErrorIs(t, err, ErrConcurrentModification)
+	stored, _ := repo.GetByID(ctx, product.ID)
+	assert.Equal(t, "Renamed", stored.Name)
+}
@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// maxReconcileAttempts bounds how often ReconcileProduct re-reads a product
+// whose counters keep moving under it.
+const maxReconcileAttempts = 3
+
+// CountSource reports what a product's counters should be, recomputed from
+// the records they aggregate.
+type CountSource interface {
+	ProductCounts(ctx context.Context, id string) (models.ProductCounts, error)
+}
+
+// WithCountSource sets where ReconcileProduct recomputes counters from.
+// Without one, reconciliation can only repair counters that are internally
+// inconsistent, such as a rating sum outside what the count allows.
+func WithCountSource(source CountSource) Option {
+	return func(s *productService) {
+		s.counts = source
+	}
+}
+
+// ReconcileProduct corrects the product's view and rating counters. The
+// write is conditional on the counters it read, so increments that land
+// meanwhile are never lost; the product is re-read and reconciled again.
+func (s *productService) ReconcileProduct(ctx context.Context, id string) (*models.ReconcileResult, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
+	}
+
+	for attempt := 0; attempt < maxReconcileAttempts; attempt++ {
+		product, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get product for reconciliation: %w", err)
+		}
+		if product == nil {
+			return nil, ErrProductNotFound
+		}
+
+		result := &models.ReconcileResult{ProductID: id, Before: product.Counts()}
+		result.After, err = s.expectedCounts(ctx, product)
+		if err != nil {
+			return nil, err
+		}
+		result.Corrected = result.After != result.Before
+
+		if !result.Corrected || IsDryRun(ctx) {
+			return result, nil
+		}
+
+		ok, err := s.repo.SetCounts(ctx, id, result.Before, result.After)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile product: %w", err)
+		}
+		if ok {
+			s.logger.InfoContext(ctx, "product counters reconciled",
+				"product_id", id,
+				"before", result.Before,
+				"after", result.After,
+			)
+			return result, nil
+		}
+	}
+	return nil, fmt.Errorf("failed to reconcile product %s: counters kept changing", id)
+}
+
+func (s *productService) expectedCounts(ctx context.Context, product *models.Product) (models.ProductCounts, error) {
+	counts := product.Counts()
+	if s.counts != nil {
+		var err error
+		if counts, err = s.counts.ProductCounts(ctx, product.ID); err != nil {
+			return models.ProductCounts{}, fmt.Errorf("failed to recompute counts: %w", err)
+		}
+	}
+	return repairCounts(counts), nil
+}
+
+// repairCounts clamps counters to values they could actually reach: none
+// negative, and a rating sum every rating of which is in range.
+func repairCounts(c models.ProductCounts) models.ProductCounts {
+	c.ViewCount = max(c.ViewCount, 0)
+	c.RatingCount = max(c.RatingCount, 0)
+	c.RatingSum = min(max(c.RatingSum, int64(c.RatingCount)*models.MinRating), int64(c.RatingCount)*models.MaxRating)
+	return c
+}
+
+// Reconciler periodically reconciles the counters of every active product.
+type Reconciler struct {
+	service  ProductService
+	repo     repository.ProductRepository
+	interval time.Duration
+}
+
+func NewReconciler(service ProductService, repo repository.ProductRepository, interval time.Duration) *Reconciler {
+	return &Reconciler{
+		service:  service,
+		repo:     repo,
+		interval: interval,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (r *Reconciler) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			corrected, err := r.Sweep(ctx)
+			if err != nil {
+				log.Printf("Reconciler sweep failed: %v", err)
+				continue
+			}
+			if corrected > 0 {
+				log.Printf("Reconciler corrected counters on %d products", corrected)
+			}
+		}
+	}
+}
+
+// Sweep reconciles every active product and returns how many were
+// corrected. A product that fails to reconcile stops the sweep.
+func (r *Reconciler) Sweep(ctx context.Context) (int, error) {
+	corrected := 0
+	err := r.repo.ScanActive(ctx, func(page []*models.Product) error {
+		for _, product := range page {
+			result, err := r.service.ReconcileProduct(ctx, product.ID)
+			if err != nil {
+				return err
+			}
+			if result.Corrected {
+				corrected++
+			}
+		}
+		return nil
+	})
+	return corrected, err
+}
@@ -0,0 +1,95 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+type fixedCountSource map[string]models.ProductCounts
+
+func (f fixedCountSource) ProductCounts(ctx context.Context, id string) (models.ProductCounts, error) {
+	return f[id], nil
+}
+
+func TestProductService_ReconcileProduct_FromSource(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", IsActive: true, ViewCount: 40, RatingSum: 9, RatingCount: 3,
+	}))
+	want := models.ProductCounts{ViewCount: 42, RatingSum: 9, RatingCount: 2}
+	service := NewProductService(repo, WithCountSource(fixedCountSource{"test-id": want}))
+
+	result, err := service.ReconcileProduct(context.Background(), "test-id")
+
+	require.NoError(t, err)
+	assert.True(t, result.Corrected)
+	assert.Equal(t, models.ProductCounts{ViewCount: 40, RatingSum: 9, RatingCount: 3}, result.Before)
+	assert.Equal(t, want, result.After)
+
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, want, stored.Counts())
+
+	result, err = service.ReconcileProduct(context.Background(), "test-id")
+
+	require.NoError(t, err)
+	assert.False(t, result.Corrected)
+}
+
+func TestProductService_ReconcileProduct_RepairsInconsistentCounts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{
+		ID: "test-id", ViewCount: -3, RatingSum: 20, RatingCount: 2,
+	}))
+	service := NewProductService(repo)
+
+	result, err := service.ReconcileProduct(WithDryRun(context.Background()), "test-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ProductCounts{ViewCount: 0, RatingSum: 10, RatingCount: 2}, result.After)
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, int64(20), stored.RatingSum)
+
+	_, err = service.ReconcileProduct(context.Background(), "test-id")
+
+	require.NoError(t, err)
+	stored, _ = repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, models.ProductCounts{ViewCount: 0, RatingSum: 10, RatingCount: 2}, stored.Counts())
+}
+
+func TestProductService_ReconcileProduct_RetriesWhenCountsMove(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	first := &models.Product{ID: "test-id", RatingSum: 20, RatingCount: 2}
+	second := &models.Product{ID: "test-id", RatingSum: 24, RatingCount: 3}
+	mockRepo.On("GetByID", "test-id").Return(first, nil).Once()
+	mockRepo.On("GetByID", "test-id").Return(second, nil).Once()
+	mockRepo.On("SetCounts", "test-id", first.Counts(), mock.Anything).Return(false, nil).Once()
+	mockRepo.On("SetCounts", "test-id", second.Counts(), models.ProductCounts{RatingSum: 15, RatingCount: 3}).Return(true, nil).Once()
+
+	result, err := service.ReconcileProduct(context.Background(), "test-id")
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(15), result.After.RatingSum)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestReconciler_Sweep(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "drifted", IsActive: true, RatingSum: 1, RatingCount: 4}))
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "fine", IsActive: true, RatingSum: 5, RatingCount: 1}))
+
+	corrected, err := NewReconciler(NewProductService(repo), repo, 0).Sweep(context.Background())
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, corrected)
+	stored, _ := repo.GetByID(context.Background(), "drifted")
+	assert.Equal(t, int64(4), stored.RatingSum)
+}
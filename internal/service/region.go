@@ -0,0 +1,59 @@
+package service
+
+import (
+	"context"
+	"slices"
+	"strings"
+)
+
+type regionKey struct{}
+
+// WithRegion marks ctx with the region a request is made for, which
+// decides the currencies its prices may be held in.
+func WithRegion(ctx context.Context, region string) context.Context {
+	return context.WithValue(ctx, regionKey{}, region)
+}
+
+// Region returns the region ctx was marked with, or "" if none.
+func Region(ctx context.Context) string {
+	region, _ := ctx.Value(regionKey{}).(string)
+	return region
+}
+
+// WithRegionCurrencies restricts the currencies prices may be held in by
+// region, e.g. {"eu": ["EUR"]}. A write's region comes from WithRegion;
+// writes for no region, or for one that is not listed, are held to
+// defaultRegion instead, so a caller cannot escape the restriction by
+// naming an unknown region. Regions are case-insensitive; writes are only
+// unrestricted when defaultRegion is not listed either.
+func WithRegionCurrencies(allowed map[string][]string, defaultRegion string) Option {
+	return func(s *productService) {
+		s.regionCurrencies = make(map[string][]string, len(allowed))
+		for region, codes := range allowed {
+			upper := make([]string, len(codes))
+			for i, code := range codes {
+				upper[i] = strings.ToUpper(code)
+			}
+			s.regionCurrencies[strings.ToLower(region)] = upper
+		}
+		s.defaultRegion = defaultRegion
+	}
+}
+
+// validateRegionCurrency checks that the region of the write allows the
+// currency prices are held in. Products do not carry a currency of their
+// own yet, so every price is in the service's default currency.
+func (s *productService) validateRegionCurrency(ctx context.Context) error {
+	region := Region(ctx)
+	allowed, ok := s.regionCurrencies[strings.ToLower(region)]
+	if !ok {
+		region = s.defaultRegion
+		if allowed, ok = s.regionCurrencies[strings.ToLower(region)]; !ok {
+			return nil
+		}
+	}
+	if currency := s.currency.Default; !slices.Contains(allowed, currency) {
+		return fieldError("currency", "product currency %q is not allowed in region %q", currency, region)
+	}
+	return nil
+}
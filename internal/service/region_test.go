@@ -0,0 +1,103 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func regionService(repo repository.ProductRepository, defaultRegion string) ProductService {
+	return NewProductService(repo,
+		WithCurrencyRules(models.NewCurrencyRules("EUR", nil)),
+		WithRegionCurrencies(map[string][]string{
+			"EU": {"EUR"},
+			"ch": {"chf", "eur"},
+			"us": {"USD"},
+		}, defaultRegion),
+	)
+}
+
+func regionRequest(sku string) models.CreateProductRequest {
+	return models.CreateProductRequest{Name: "Kettle", Price: 30, Category: "kitchen", SKU: sku}
+}
+
+func TestProductService_CreateProduct_RegionCurrency(t *testing.T) {
+	service := regionService(repository.NewMemoryProductRepository(), "")
+
+	for i, region := range []string{"eu", "CH", "jp", ""} {
+		ctx := context.Background()
+		if region != "" {
+			ctx = WithRegion(ctx, region)
+		}
+		_, err := service.CreateProduct(ctx, regionRequest(fmt.Sprintf("KET-%03d", i)))
+		assert.NoError(t, err, region)
+	}
+
+	product, err := service.CreateProduct(WithRegion(context.Background(), "us"), regionRequest("KET-100"))
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	assert.Nil(t, product)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "currency", fieldErr.Field)
+	assert.Equal(t, `product currency "EUR" is not allowed in region "us"`, fieldErr.Message)
+}
+
+func TestProductService_CreateProduct_DefaultRegionCurrency(t *testing.T) {
+	service := regionService(repository.NewMemoryProductRepository(), "us")
+
+	_, err := service.CreateProduct(context.Background(), regionRequest("KET-001"))
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "currency", fieldErr.Field)
+
+	// An unknown region is held to the default rather than unrestricted.
+	_, err = service.CreateProduct(WithRegion(context.Background(), "moon"), regionRequest("KET-001"))
+
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, `product currency "EUR" is not allowed in region "us"`, fieldErr.Message)
+
+	// The request's region takes precedence over the default.
+	_, err = service.CreateProduct(WithRegion(context.Background(), "eu"), regionRequest("KET-001"))
+
+	assert.NoError(t, err)
+}
+
+func TestProductService_UpdateProduct_RegionCurrency(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "kettle", Name: "Kettle", Price: 30, Category: "kitchen", Unit: models.UnitEach}))
+	service := regionService(repo, "")
+	us := WithRegion(context.Background(), "us")
+
+	price := 25.0
+	_, err := service.UpdateProduct(us, "kettle", models.UpdateProductRequest{Price: &price})
+
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "currency", fieldErr.Field)
+
+	// Leaving the price alone is allowed anywhere.
+	name := "Electric kettle"
+	product, err := service.UpdateProduct(us, "kettle", models.UpdateProductRequest{Name: &name})
+
+	require.NoError(t, err)
+	assert.Equal(t, 30.0, product.Price)
+
+	// Restating the current price, as an upsert does, is not a change.
+	same := 30.0
+	_, err = service.UpdateProduct(us, "kettle", models.UpdateProductRequest{Price: &same})
+
+	require.NoError(t, err)
+
+	product, err = service.UpdateProduct(WithRegion(context.Background(), "eu"), "kettle", models.UpdateProductRequest{Price: &price})
+
+	require.NoError(t, err)
+	assert.Equal(t, 25.0, product.Price)
+}
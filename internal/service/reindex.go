@@ -0,0 +1,101 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"slices"
+
+	"product-service/internal/models"
+)
+
+const (
+	// DefaultReindexBatch is how many products one Reindex call visits when
+	// no limit is given.
+	DefaultReindexBatch = 100
+	// MaxReindexBatch bounds the products one Reindex call visits.
+	MaxReindexBatch = 1000
+)
+
+// Reindex backfills the derived fields of up to limit products, starting
+// where the previous call's NextCursor left off: a slug for products
+// created before slugs existed, and the category path when a category
+// taxonomy is configured. Products whose derived fields are current are not
+// written, and only the derived fields are, so a product edited while the
+// batch runs is skipped rather than overwritten. Repeating a batch is
+// harmless, so an interrupted run can resume from any cursor it was given.
+func (s *productService) Reindex(ctx context.Context, cursor string, limit int) (*models.ReindexResult, error) {
+	if limit == 0 {
+		limit = DefaultReindexBatch
+	}
+	if limit < 0 || limit > MaxReindexBatch {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxReindexBatch)
+	}
+	after, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+
+	products, next, err := s.repo.ScanPage(ctx, string(after), limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan products for reindex: %w", err)
+	}
+
+	result := &models.ReindexResult{Processed: len(products), Done: next == ""}
+	if next != "" {
+		result.NextCursor = base64.RawURLEncoding.EncodeToString([]byte(next))
+	}
+	for _, product := range products {
+		changed, err := s.deriveFields(ctx, product)
+		if err != nil {
+			return nil, err
+		}
+		if !changed {
+			continue
+		}
+		if IsDryRun(ctx) {
+			result.Updated++
+			continue
+		}
+		written, err := s.repo.SetDerivedFields(ctx, product.ID, product.Version, product.Slug, product.CategoryPath)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "product reindex failed", "product_id", product.ID, "error", err)
+			return nil, fmt.Errorf("failed to reindex product %s: %w", product.ID, err)
+		}
+		if !written {
+			result.Skipped++
+			continue
+		}
+		result.Updated++
+	}
+
+	if !IsDryRun(ctx) {
+		s.logger.InfoContext(ctx, "products reindexed",
+			"processed", result.Processed,
+			"updated", result.Updated,
+			"skipped", result.Skipped,
+			"done", result.Done,
+		)
+	}
+	return result, nil
+}
+
+// deriveFields recomputes the product's derived fields and reports whether
+// any of them changed. UpdatedAt is left alone, since nothing the caller set
+// has changed.
+func (s *productService) deriveFields(ctx context.Context, product *models.Product) (bool, error) {
+	changed := false
+	if product.Slug == "" && product.Name != "" {
+		slug, err := s.uniqueSlug(ctx, product.Name, product.ID)
+		if err != nil {
+			return false, err
+		}
+		product.Slug = slug
+		changed = true
+	}
+	if path := s.categoryPath(product.Category); !slices.Equal(path, product.CategoryPath) {
+		product.CategoryPath = path
+		changed = true
+	}
+	return changed, nil
+}
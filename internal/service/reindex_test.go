@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_Reindex_BackfillsDerivedFields(t *testing.T) {
+	taxonomy, err := models.NewCategoryTaxonomy(map[string]string{"phones": "electronics"})
+	require.NoError(t, err)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithCategoryTaxonomy(taxonomy))
+	ctx := context.Background()
+
+	for _, product := range []*models.Product{
+		{ID: "legacy", Name: "Old Phone", Category: "phones", IsActive: true},
+		{ID: "current", Name: "New Phone", Slug: "new-phone", Category: "phones", CategoryPath: []string{"electronics", "phones"}},
+		{ID: "flat", Name: "Rake", Slug: "rake", Category: "garden", CategoryPath: []string{"garden"}},
+	} {
+		require.NoError(t, repo.Create(ctx, product))
+	}
+
+	first, err := service.Reindex(ctx, "", 2)
+	require.NoError(t, err)
+	assert.Equal(t, 2, first.Processed)
+	assert.Equal(t, 1, first.Updated)
+	assert.False(t, first.Done)
+	require.NotEmpty(t, first.NextCursor)
+
+	legacy, _ := repo.GetByID(ctx, "legacy")
+	assert.Equal(t, "old-phone", legacy.Slug)
+	assert.Equal(t, []string{"electronics", "phones"}, legacy.CategoryPath)
+
+	second, err := service.Reindex(ctx, first.NextCursor, 2)
+	require.NoError(t, err)
+	assert.Equal(t, models.ReindexResult{Processed: 1, Updated: 0, Done: true}, *second)
+
+	// A rerun finds nothing left to backfill.
+	again, err := service.Reindex(ctx, "", 0)
+	require.NoError(t, err)
+	assert.Equal(t, 3, again.Processed)
+	assert.Zero(t, again.Updated)
+}
+
+func TestProductService_Reindex_DryRun(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "legacy", Name: "Old Phone"}))
+
+	result, err := service.Reindex(WithDryRun(ctx), "", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Updated)
+	stored, _ := repo.GetByID(ctx, "legacy")
+	assert.Empty(t, stored.Slug)
+}
+
+func TestProductService_Reindex_Invalid(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	_, err := service.Reindex(context.Background(), "", MaxReindexBatch+1)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = service.Reindex(context.Background(), "not base64!", 0)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+// editingRepository renames a product just before its derived fields are
+// written, as if an edit had raced the reindex.
+type editingRepository struct {
+	repository.ProductRepository
+}
+
+func (r *editingRepository) SetDerivedFields(ctx context.Context, id string, version int64, slug string, categoryPath []string) (bool, error) {
+	product, err := r.GetByID(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	product.Name = "Edited"
+	if err := r.Update(ctx, product); err != nil {
+		return false, err
+	}
+	return r.ProductRepository.SetDerivedFields(ctx, id, version, slug, categoryPath)
+}
+
+func TestProductService_Reindex_SkipsConcurrentEdit(t *testing.T) {
+	repo := &editingRepository{ProductRepository: repository.NewMemoryProductRepository()}
+	service := NewProductService(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "legacy", Name: "Old Phone"}))
+
+	result, err := service.Reindex(ctx, "", 0)
+
+	require.NoError(t, err)
+	assert.Equal(t, models.ReindexResult{Processed: 1, Skipped: 1, Done: true}, *result)
+	stored, _ := repo.GetByID(ctx, "legacy")
+	assert.Equal(t, "Edited", stored.Name)
+	assert.Empty(t, stored.Slug)
+}
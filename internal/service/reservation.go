@@ -0,0 +1,164 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// Reservation lifetimes accepted by ReserveStock.
+const (
+	DefaultReservationTTL = 15 * time.Minute
+	MaxReservationTTL     = 24 * time.Hour
+)
+
+var (
+	ErrInsufficientStock   = errors.New("insufficient stock")
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+// ReserveStock sets req.Quantity of an active product's stock aside until
+// the reservation is released or expires. Expired reservations are returned
+// to stock by the ReservationSweeper.
+func (s *productService) ReserveStock(ctx context.Context, id string, req models.ReserveStockRequest) (*models.Reservation, error) {
+	if id == "" {
+		return nil, fmt.Errorf("%w: product ID cannot be empty", ErrInvalidProduct)
+	}
+
+	ttl := DefaultReservationTTL
+	if req.TTLSeconds != 0 {
+		ttl = time.Duration(req.TTLSeconds) * time.Second
+	}
+	var invalid error
+	switch {
+	case math.IsNaN(req.Quantity) || math.IsInf(req.Quantity, 0):
+		invalid = fieldError("quantity", "reservation quantity must be a finite number")
+	case req.Quantity <= 0:
+		invalid = fieldError("quantity", "reservation quantity must be positive")
+	case ttl <= 0 || ttl > MaxReservationTTL:
+		invalid = fieldError("ttl_seconds", "reservation TTL must be between 1 and %d seconds", int(MaxReservationTTL.Seconds()))
+	}
+	if invalid != nil {
+		s.logRejected(ctx, "reserve", id, invalid)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, invalid)
+	}
+
+	product, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product for reservation: %w", err)
+	}
+	if product == nil || product.IsDeleted() {
+		return nil, ErrProductNotFound
+	}
+	if !models.IsValidStockForUnit(req.Quantity, product.Unit) {
+		err := fieldError("quantity", "reservation quantity must be a whole number for unit %q", models.NormalizeUnit(product.Unit))
+		s.logRejected(ctx, "reserve", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	reservation := models.Reservation{
+		ID:        models.IDSchemeUUID.NewID(),
+		ProductID: id,
+		Quantity:  req.Quantity,
+		ExpiresAt: s.clock.Now().Add(ttl).UTC(),
+	}
+
+	reserved := product.IsActive && product.Stock >= req.Quantity
+	if !IsDryRun(ctx) {
+		product, reserved, err = s.repo.ReserveStock(ctx, reservation, s.clock.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "stock reservation failed", "product_id", id, "error", err)
+			return nil, fmt.Errorf("failed to reserve stock: %w", err)
+		}
+	}
+
+	switch {
+	case product == nil:
+		return nil, ErrProductNotFound
+	case !product.IsActive:
+		return nil, fmt.Errorf("%w: product is not active", ErrInvalidProduct)
+	case !reserved:
+		return nil, fmt.Errorf("%w: %g requested, %g available", ErrInsufficientStock, req.Quantity, product.Stock)
+	}
+
+	if !IsDryRun(ctx) {
+		s.logger.InfoContext(ctx, "stock reserved",
+			"product_id", id,
+			"reservation_id", reservation.ID,
+			"quantity", reservation.Quantity,
+			"expires_at", reservation.ExpiresAt,
+			"actor", auth.ActorID(ctx),
+		)
+		if s.autoDeactivateOOS && product.Stock <= 0 {
+			s.followStock(ctx, id, false)
+		}
+		s.publish(ctx, models.EventProductUpdated, id)
+	}
+	return &reservation, nil
+}
+
+// ReleaseReservation returns a reservation's quantity to the product's stock
+// ahead of its expiry. Stock is restored up to the configured maximum.
+func (s *productService) ReleaseReservation(ctx context.Context, id, reservationID string) error {
+	if id == "" || reservationID == "" {
+		return fmt.Errorf("%w: product and reservation IDs cannot be empty", ErrInvalidProduct)
+	}
+
+	_, err := retryOnConflict(func() (struct{}, error) {
+		product, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return struct{}{}, fmt.Errorf("failed to get product for reservation: %w", err)
+		}
+		if product == nil {
+			return struct{}{}, ErrReservationNotFound
+		}
+		reservation, ok := product.Reservations[reservationID]
+		if !ok {
+			return struct{}{}, ErrReservationNotFound
+		}
+		if IsDryRun(ctx) {
+			return struct{}{}, nil
+		}
+
+		released, err := s.repo.ReleaseReservation(ctx, id, reservationID, product.Version, restoredStock(product, reservation, s.maxStock), s.clock.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "reservation release failed", "product_id", id, "reservation_id", reservationID, "error", err)
+			return struct{}{}, fmt.Errorf("failed to release reservation: %w", err)
+		}
+		if !released {
+			return struct{}{}, repository.ErrVersionConflict
+		}
+		return struct{}{}, nil
+	})
+	if err != nil || IsDryRun(ctx) {
+		return err
+	}
+	if s.autoDeactivateOOS {
+		s.followStock(ctx, id, true)
+	}
+
+	s.logger.InfoContext(ctx, "reservation released",
+		"product_id", id,
+		"reservation_id", reservationID,
+		"actor", auth.ActorID(ctx),
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+	return nil
+}
+
+// restoredStock is the product's stock once reservation is returned to it.
+// Like every other stock write it is held to maxStock, though stock already
+// above the maximum, set before it was configured, is not lowered.
+func restoredStock(product *models.Product, reservation models.Reservation, maxStock float64) float64 {
+	stock := product.Stock + reservation.Quantity
+	if maxStock > 0 && stock > maxStock {
+		return max(maxStock, product.Stock)
+	}
+	return stock
+}
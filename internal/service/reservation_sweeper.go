@@ -0,0 +1,97 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"time"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// ReservationSweeper periodically returns the stock of expired reservations
+// to their products, up to maxStock when it is positive. Each release is
+// conditional on the reservation still existing and the product being
+// unchanged since the sweep read it, so a sweep that overlaps another sweep
+// or a manual release never restores the same stock twice; a product
+// written in between is left for the next sweep. With followStock, a
+// product the out-of-stock policy deactivated is reactivated once stock
+// returns.
+type ReservationSweeper struct {
+	repo        repository.ProductRepository
+	interval    time.Duration
+	followStock bool
+	maxStock    float64
+	clock       models.Clock
+}
+
+func NewReservationSweeper(repo repository.ProductRepository, interval time.Duration, followStock bool, maxStock float64) *ReservationSweeper {
+	return &ReservationSweeper{
+		repo:        repo,
+		interval:    interval,
+		followStock: followStock,
+		maxStock:    maxStock,
+		clock:       models.SystemClock,
+	}
+}
+
+// Run sweeps every interval until ctx is cancelled.
+func (s *ReservationSweeper) Run(ctx context.Context) {
+	ticker := time.NewTicker(s.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			released, err := s.Sweep(ctx)
+			if err != nil {
+				log.Printf("Reservation sweep failed: %v", err)
+				continue
+			}
+			if released > 0 {
+				log.Printf("Reservation sweeper released %d expired reservations", released)
+			}
+		}
+	}
+}
+
+// Sweep releases every expired reservation and returns how many it released.
+func (s *ReservationSweeper) Sweep(ctx context.Context) (int, error) {
+	products, err := s.repo.GetReserved(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get reserved products: %w", err)
+	}
+
+	now := s.clock.Now()
+	released := 0
+	for _, product := range products {
+		restocked := false
+		for id, reservation := range product.Reservations {
+			if !reservation.Expired(now) {
+				continue
+			}
+			stock := restoredStock(product, reservation, s.maxStock)
+			ok, err := s.repo.ReleaseReservation(ctx, product.ID, id, product.Version, stock, now)
+			if err != nil {
+				return released, fmt.Errorf("failed to release reservation %s of product %s: %w", id, product.ID, err)
+			}
+			if !ok {
+				continue
+			}
+			// The product's next release builds on this one.
+			product.Stock = stock
+			product.Version++
+			released++
+			restocked = true
+		}
+		if restocked && s.followStock {
+			if _, err := s.repo.SetStockActivation(ctx, product.ID, true); err != nil {
+				return released, fmt.Errorf("failed to reactivate product %s: %w", product.ID, err)
+			}
+		}
+	}
+	return released, nil
+}
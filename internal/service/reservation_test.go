@@ -0,0 +1,151 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_ReserveStock(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithClock(models.NewFakeClock(now)))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Stock: 10, IsActive: true}))
+
+	reservation, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 4, TTLSeconds: 60})
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", reservation.ProductID)
+	assert.Equal(t, now.Add(time.Minute), reservation.ExpiresAt)
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(6), stored.Stock)
+	assert.Contains(t, stored.Reservations, reservation.ID)
+
+	_, err = service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 7})
+	assert.ErrorIs(t, err, ErrInsufficientStock)
+
+	_, err = service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 1.5})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	_, err = service.ReserveStock(ctx, "missing", models.ReserveStockRequest{Quantity: 1})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	require.NoError(t, service.ReleaseReservation(ctx, "test-id", reservation.ID))
+	stored, _ = repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(10), stored.Stock)
+	assert.ErrorIs(t, service.ReleaseReservation(ctx, "test-id", reservation.ID), ErrReservationNotFound)
+}
+
+func TestReservationSweeper_ReleasesExpiredReservations(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(now)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithClock(clock))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Stock: 10, IsActive: true}))
+
+	short, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 3, TTLSeconds: 60})
+	require.NoError(t, err)
+	long, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 2, TTLSeconds: 3600})
+	require.NoError(t, err)
+
+	sweeper := NewReservationSweeper(repo, time.Minute, false, 0)
+	sweeper.clock = clock
+	clock.Advance(5 * time.Minute)
+
+	released, err := sweeper.Sweep(ctx)
+
+	require.NoError(t, err)
+	assert.Equal(t, 1, released)
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(8), stored.Stock)
+	assert.NotContains(t, stored.Reservations, short.ID)
+	assert.Contains(t, stored.Reservations, long.ID)
+
+	// A repeat sweep finds nothing left to release.
+	released, err = sweeper.Sweep(ctx)
+	require.NoError(t, err)
+	assert.Zero(t, released)
+	stored, _ = repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(8), stored.Stock)
+}
+
+func TestProductService_ReserveStock_NotUndoneByConcurrentEdit(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Name: "Widget", Price: 10, Unit: models.UnitEach, Stock: 10, IsActive: true}))
+
+	// An edit that read the product before the reservation must not write
+	// the old stock back.
+	read, err := repo.GetByID(ctx, "test-id")
+	require.NoError(t, err)
+	reservation, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 4})
+	require.NoError(t, err)
+	read.Name = "Renamed"
+	assert.ErrorIs(t, repo.Update(ctx, read), repository.ErrVersionConflict)
+
+	// Re-read and retried, the edit keeps the reservation.
+	name := "Renamed"
+	updated, err := service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Name: &name})
+	require.NoError(t, err)
+	assert.Equal(t, float64(6), updated.Stock)
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Contains(t, stored.Reservations, reservation.ID)
+
+	require.NoError(t, service.ReleaseReservation(ctx, "test-id", reservation.ID))
+	assert.ErrorIs(t, repo.Update(ctx, updated), repository.ErrVersionConflict)
+}
+
+func TestProductService_ReleaseReservation_MaxStock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithMaxStock(10))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Name: "Widget", Price: 10, Stock: 10, IsActive: true}))
+
+	reservation, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 4})
+	require.NoError(t, err)
+	restock := 9.0
+	_, err = service.UpdateProduct(ctx, "test-id", models.UpdateProductRequest{Stock: &restock})
+	require.NoError(t, err)
+
+	require.NoError(t, service.ReleaseReservation(ctx, "test-id", reservation.ID))
+
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(10), stored.Stock)
+	assert.Empty(t, stored.Reservations)
+}
+
+func TestReservationSweeper_MaxStock(t *testing.T) {
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := models.NewFakeClock(now)
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithClock(clock))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "test-id", Stock: 10, IsActive: true}))
+
+	for range 2 {
+		_, err := service.ReserveStock(ctx, "test-id", models.ReserveStockRequest{Quantity: 3, TTLSeconds: 60})
+		require.NoError(t, err)
+	}
+
+	sweeper := NewReservationSweeper(repo, time.Minute, false, 8)
+	sweeper.clock = clock
+	clock.Advance(5 * time.Minute)
+
+	released, err := sweeper.Sweep(ctx)
+
+	// Both reservations go in one sweep, but stock stops at the maximum.
+	require.NoError(t, err)
+	assert.Equal(t, 2, released)
+	stored, _ := repo.GetByID(ctx, "test-id")
+	assert.Equal(t, float64(8), stored.Stock)
+	assert.Empty(t, stored.Reservations)
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"regexp"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// SanitizeMode controls how HTML in product text fields is handled.
+type SanitizeMode int
+
+const (
+	// SanitizeStrip removes tags and keeps the surrounding text.
+	SanitizeStrip SanitizeMode = iota
+	// SanitizeReject fails validation when a field contains markup.
+	SanitizeReject
+)
+
+// ParseSanitizeMode maps a configuration value to a SanitizeMode.
+func ParseSanitizeMode(value string) (SanitizeMode, bool) {
+	switch strings.ToLower(value) {
+	case "strip":
+		return SanitizeStrip, true
+	case "reject":
+		return SanitizeReject, true
+	}
+	return SanitizeStrip, false
+}
+
+// WithSanitizeMode sets how HTML in names and descriptions is handled. The
+// default is SanitizeStrip.
+func WithSanitizeMode(mode SanitizeMode) Option {
+	return func(s *productService) {
+		s.sanitizeMode = mode
+	}
+}
+
+var (
+	// scriptBlockPattern matches script and style elements including their
+	// content, which would otherwise survive tag stripping as plain text.
+	scriptBlockPattern = regexp.MustCompile(`(?is)<(script|style)\b[^>]*>.*?</(script|style)\s*>`)
+	// tagPattern only matches things that look like tags or comments so
+	// comparisons such as "a < b > c" are left alone.
+	tagPattern = regexp.MustCompile(`(?s)<!--.*?-->|</?[a-zA-Z][^>]*>`)
+)
+
+// stripHTML removes markup from s. Entities and other characters, such as
+// ampersands and quotes, are kept as written.
+func stripHTML(s string) string {
+	s = scriptBlockPattern.ReplaceAllString(s, "")
+	s = tagPattern.ReplaceAllString(s, "")
+	return strings.TrimSpace(s)
+}
+
+func containsHTML(s string) bool {
+	return tagPattern.MatchString(s)
+}
+
+func (s *productService) sanitizeText(field, value string) (string, error) {
+	if !containsHTML(value) {
+		return value, nil
+	}
+	if s.sanitizeMode == SanitizeReject {
+		return "", fieldError(field, "product %s must not contain HTML", field)
+	}
+	return stripHTML(value), nil
+}
+
+func (s *productService) sanitizeCreateRequest(req *models.CreateProductRequest) error {
+	var err error
+	if req.Name, err = s.sanitizeText("name", req.Name); err != nil {
+		return err
+	}
+	if req.Description, err = s.sanitizeText("description", req.Description); err != nil {
+		return err
+	}
+	req.Tags = normalizeTags(req.Tags)
+	return nil
+}
+
+func (s *productService) sanitizeUpdateRequest(req *models.UpdateProductRequest) error {
+	if req.Name != nil {
+		name, err := s.sanitizeText("name", *req.Name)
+		if err != nil {
+			return err
+		}
+		req.Name = &name
+	}
+	if req.Description != nil {
+		description, err := s.sanitizeText("description", *req.Description)
+		if err != nil {
+			return err
+		}
+		req.Description = &description
+	}
+	if req.Tags != nil {
+		tags := normalizeTags(*req.Tags)
+		req.Tags = &tags
+	}
+	return nil
+}
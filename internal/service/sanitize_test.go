@@ -0,0 +1,109 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"product-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestStripHTML(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{`Widget<script>alert("xss")</script>`, "Widget"},
+		{`<b>Bold</b> & "quoted"`, `Bold & "quoted"`},
+		{`Salt & Pepper's <i>finest</i>`, `Salt & Pepper's finest`},
+		{`<img src=x onerror=alert(1)>Camera`, "Camera"},
+		{`5 < 6 and 7 > 3`, `5 < 6 and 7 > 3`},
+		{`Plain name`, `Plain name`},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.want, stripHTML(tt.in), tt.in)
+	}
+}
+
+func TestProductService_CreateProduct_StripsHTML(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	req := models.CreateProductRequest{
+		Name:        `Widget <script>alert("xss")</script>`,
+		Description: `Fish & Chips <a href="javascript:alert(1)">"best"</a>`,
+		Price:       9.99,
+		Category:    "food",
+		SKU:         "FOOD-001",
+		Stock:       floatPtr(1),
+	}
+
+	mockRepo.On("Create", mock.AnythingOfType("*models.Product")).Return(nil)
+	mockRepo.On("GetBySKU", mock.Anything).Return((*models.Product)(nil), nil)
+	mockRepo.On("GetBySlug", mock.Anything).Return((*models.Product)(nil), nil)
+
+	product, err := service.CreateProduct(context.Background(), req)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Widget", product.Name)
+	assert.Equal(t, `Fish & Chips "best"`, product.Description)
+	mockRepo.AssertExpectations(t)
+}
+
+func TestProductService_CreateProduct_ScriptOnlyNameIsRequired(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	req := models.CreateProductRequest{
+		Name:     `<script>alert(1)</script>`,
+		Price:    9.99,
+		Category: "food",
+		SKU:      "FOOD-001",
+	}
+
+	_, err := service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+}
+
+func TestProductService_RejectMode(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithSanitizeMode(SanitizeReject))
+
+	req := models.CreateProductRequest{
+		Name:     `Widget <script>alert(1)</script>`,
+		Price:    9.99,
+		Category: "food",
+		SKU:      "FOOD-001",
+	}
+
+	_, err := service.CreateProduct(context.Background(), req)
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	assert.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "name", fieldErr.Field)
+	mockRepo.AssertNotCalled(t, "Create", mock.Anything)
+
+	existing := &models.Product{ID: "1", Name: "Widget", Unit: models.UnitEach}
+	mockRepo.On("GetByID", "1").Return(existing, nil)
+	description := `<iframe src="evil"></iframe>`
+
+	_, err = service.UpdateProduct(context.Background(), "1", models.UpdateProductRequest{Description: &description})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	mockRepo.AssertNotCalled(t, "Update", mock.Anything)
+}
+
+func TestParseSanitizeMode(t *testing.T) {
+	mode, ok := ParseSanitizeMode("Reject")
+	assert.True(t, ok)
+	assert.Equal(t, SanitizeReject, mode)
+
+	_, ok = ParseSanitizeMode("escape")
+	assert.False(t, ok)
+}
@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// SearchProducts returns a page of active products whose name contains
+// query, case-sensitively. Search pages through the table as it scans, so
+// unlike a listing it is never held in memory whole: the cursor resumes the
+// scan after the last product returned. Results come in scan order and
+// cannot be sorted, and their total is not counted.
+func (s *productService) SearchProducts(ctx context.Context, query string, opts models.ListOptions) (*models.ProductList, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, fmt.Errorf("%w: query is required", ErrInvalidQuery)
+	}
+	if opts.Sort != "" {
+		return nil, fmt.Errorf("%w: search results cannot be sorted", ErrInvalidQuery)
+	}
+	limit := opts.Limit
+	if limit == 0 {
+		limit = DefaultPageLimit
+	}
+	if limit < 0 || limit > MaxPageLimit {
+		return nil, fmt.Errorf("%w: limit must be between 1 and %d", ErrInvalidQuery, MaxPageLimit)
+	}
+	after, err := decodeSearchCursor(opts.Cursor)
+	if err != nil {
+		return nil, err
+	}
+
+	products, next, err := s.repo.SearchByName(ctx, query, after, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search products: %w", err)
+	}
+
+	list := &models.ProductList{Products: products, Limit: limit, Uncounted: true}
+	if next != "" {
+		list.NextCursor = encodeSearchCursor(next)
+	}
+	return list, nil
+}
+
+// Search cursors carry the ID of the last product returned, kept opaque so
+// clients do not come to rely on it.
+func encodeSearchCursor(id string) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(id))
+}
+
+func decodeSearchCursor(cursor string) (string, error) {
+	if cursor == "" {
+		return "", nil
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil || len(raw) == 0 {
+		return "", fmt.Errorf("%w: malformed cursor", ErrInvalidQuery)
+	}
+	return string(raw), nil
+}
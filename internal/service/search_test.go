@@ -0,0 +1,51 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_SearchProducts(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	for i, name := range []string{"Desk Lamp", "Chair", "Floor Lamp", "Lamp Shade", "Old Lamp"} {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{
+			ID: fmt.Sprintf("p%d", i), Name: name, IsActive: name != "Old Lamp",
+		}))
+	}
+
+	var names []string
+	opts := models.ListOptions{Limit: 2}
+	for page := 0; ; page++ {
+		require.Less(t, page, 3)
+		list, err := service.SearchProducts(context.Background(), " Lamp ", opts)
+		require.NoError(t, err)
+		assert.True(t, list.Uncounted)
+		for _, p := range list.Products {
+			names = append(names, p.Name)
+		}
+		if list.NextCursor == "" {
+			break
+		}
+		opts.Cursor = list.NextCursor
+	}
+	assert.Equal(t, []string{"Desk Lamp", "Floor Lamp", "Lamp Shade"}, names)
+
+	for name, opts := range map[string]models.ListOptions{
+		"limit":  {Limit: MaxPageLimit + 1},
+		"sort":   {Sort: "name"},
+		"cursor": {Cursor: "%%%"},
+	} {
+		_, err := service.SearchProducts(context.Background(), "Lamp", opts)
+		assert.ErrorIs(t, err, ErrInvalidQuery, name)
+	}
+	_, err := service.SearchProducts(context.Background(), "  ", models.ListOptions{})
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
@@ -0,0 +1,43 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+// ErrDuplicateSKU is returned when a write would give a product a SKU that
+// another product already has. It is wrapped together with a
+// *DuplicateSKUError naming that product.
+var ErrDuplicateSKU = errors.New("duplicate SKU")
+
+// DuplicateSKUError names the product that already owns a SKU, so a client
+// can update it instead.
+type DuplicateSKUError struct {
+	SKU        string
+	ExistingID string
+}
+
+func (e *DuplicateSKUError) Error() string {
+	return fmt.Sprintf("product SKU %q is already used by product %s", e.SKU, e.ExistingID)
+}
+
+// checkSKUAvailable rejects sku when a product other than id has it.
+// Deleted products keep their SKU, since they can be restored. The check
+// and the write that follows are not atomic, so two concurrent creates can
+// still both succeed.
+func (s *productService) checkSKUAvailable(ctx context.Context, operation, id, sku string) error {
+	if sku == "" {
+		return nil
+	}
+	existing, err := s.repo.GetBySKU(ctx, sku)
+	if err != nil {
+		return fmt.Errorf("failed to check SKU: %w", err)
+	}
+	if existing == nil || existing.ID == id {
+		return nil
+	}
+	duplicate := &DuplicateSKUError{SKU: sku, ExistingID: existing.ID}
+	s.logRejected(ctx, operation, id, duplicate)
+	return fmt.Errorf("%w: %w", ErrDuplicateSKU, duplicate)
+}
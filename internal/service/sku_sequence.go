@@ -0,0 +1,36 @@
+package service
+
+import (
+	"context"
+	"fmt"
+)
+
+// skuPlaceholder stands in for the SKU nextSKU would number in category
+// while a product is validated, so that numbers are only drawn for writes
+// that go ahead. It reports false when sequences are off or the category
+// has no prefix, leaving validation to report the missing SKU.
+func (s *productService) skuPlaceholder(category string) (string, bool) {
+	prefix, ok := s.skuPrefixes[category]
+	if s.skuSequences == nil || !ok {
+		return "", false
+	}
+	return prefix, true
+}
+
+// nextSKU numbers a SKU for a product created without one in category. It
+// returns "" when sequences are off or the category has no prefix. Numbers
+// are not returned when the write that follows fails, so that leaves a gap
+// in the sequence; it never produces duplicates.
+func (s *productService) nextSKU(ctx context.Context, category string) (string, error) {
+	prefix, ok := s.skuPrefixes[category]
+	if s.skuSequences == nil || !ok {
+		return "", nil
+	}
+
+	n, err := s.skuSequences.Next(ctx, category)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "SKU sequence failed", "category", category, "error", err)
+		return "", fmt.Errorf("failed to generate SKU: %w", err)
+	}
+	return fmt.Sprintf("%s%06d", prefix, n), nil
+}
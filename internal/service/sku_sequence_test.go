@@ -0,0 +1,106 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_CreateProduct_SKUSequence(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo,
+		WithCategorySKUPrefix(map[string]string{"electronics": "ELEC-"}),
+		WithSKUSequences(repository.NewMemorySequenceRepository()),
+	)
+
+	var wg sync.WaitGroup
+	skus := make([]string, 2)
+	for i := range skus {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+				Name: "Cable", Price: 5, Category: "electronics", Stock: floatPtr(1),
+			})
+			if assert.NoError(t, err) {
+				skus[i] = product.SKU
+			}
+		}(i)
+	}
+	wg.Wait()
+
+	assert.ElementsMatch(t, []string{"ELEC-000001", "ELEC-000002"}, skus)
+
+	// An explicit SKU is kept and does not advance the sequence.
+	product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Cable", Price: 5, Category: "electronics", SKU: "ELEC-CUSTOM", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC-CUSTOM", product.SKU)
+
+	product, err = service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Cable", Price: 5, Category: "electronics", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC-000003", product.SKU)
+
+	// Categories without a prefix still need a SKU.
+	_, err = service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Mug", Price: 5, Category: "kitchen", Stock: floatPtr(1),
+	})
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "sku", fieldErr.Field)
+}
+
+type failingSequences struct{}
+
+func (failingSequences) Next(ctx context.Context, name string) (int64, error) {
+	return 0, errors.New("throttled")
+}
+
+func TestProductService_CreateProduct_SKUSequenceFailure(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(),
+		WithCategorySKUPrefix(map[string]string{"electronics": "ELEC-"}),
+		WithSKUSequences(failingSequences{}),
+	)
+
+	_, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Cable", Price: 5, Category: "electronics", Stock: floatPtr(1),
+	})
+
+	assert.ErrorContains(t, err, "failed to generate SKU")
+	assert.NotErrorIs(t, err, ErrInvalidProduct)
+}
+
+func TestProductService_CreateProduct_SKUSequenceOnlyAdvancesOnWrite(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(),
+		WithCategorySKUPrefix(map[string]string{"electronics": "ELEC-"}),
+		WithSKUSequences(repository.NewMemorySequenceRepository()),
+	)
+	ctx := context.Background()
+
+	_, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Cable", Price: -5, Category: "electronics", Stock: floatPtr(1),
+	})
+	require.ErrorIs(t, err, ErrInvalidProduct)
+
+	preview, err := service.CreateProduct(WithDryRun(ctx), models.CreateProductRequest{
+		Name: "Cable", Price: 5, Category: "electronics", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Empty(t, preview.SKU)
+
+	product, err := service.CreateProduct(ctx, models.CreateProductRequest{
+		Name: "Cable", Price: 5, Category: "electronics", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ELEC-000001", product.SKU)
+}
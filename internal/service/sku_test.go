@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_DuplicateSKU(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	original, err := service.CreateProduct(ctx, models.CreateProductRequest{Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1"})
+	require.NoError(t, err)
+
+	_, err = service.CreateProduct(ctx, models.CreateProductRequest{Name: "Other lamp", Price: 25, Category: "home", SKU: "LAMP-1"})
+
+	assert.ErrorIs(t, err, ErrDuplicateSKU)
+	var duplicate *DuplicateSKUError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, original.ID, duplicate.ExistingID)
+	assert.Equal(t, "LAMP-1", duplicate.SKU)
+
+	// A draft cannot claim the SKU either.
+	_, err = service.CreateDraft(ctx, models.CreateProductRequest{Name: "Lamp draft", SKU: "LAMP-1"})
+
+	assert.ErrorIs(t, err, ErrDuplicateSKU)
+
+	other, err := service.CreateProduct(ctx, models.CreateProductRequest{Name: "Shade", Price: 5, Category: "home", SKU: "SHADE-1"})
+	require.NoError(t, err)
+
+	sku := "LAMP-1"
+	_, err = service.UpdateProduct(ctx, other.ID, models.UpdateProductRequest{SKU: &sku})
+
+	assert.ErrorIs(t, err, ErrDuplicateSKU)
+
+	// Keeping its own SKU is not a conflict.
+	_, err = service.UpdateProduct(ctx, original.ID, models.UpdateProductRequest{SKU: &sku})
+
+	assert.NoError(t, err)
+}
+
+func TestProductService_PublishProduct_DuplicateSKU(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	ctx := context.Background()
+
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "taken", SKU: "LAMP-1", IsActive: true}))
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "draft", Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1", Unit: models.UnitEach, Status: models.StatusDraft}))
+
+	_, err := service.PublishProduct(ctx, "draft")
+
+	var duplicate *DuplicateSKUError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, "taken", duplicate.ExistingID)
+}
@@ -0,0 +1,62 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// maxSlugAttempts bounds how many numbered variants of a slug are tried
+// before falling back to a suffix derived from the product ID.
+const maxSlugAttempts = 50
+
+// WithSlugRegeneration makes an update that renames a product regenerate
+// its slug. By default slugs are kept stable so published URLs keep
+// working.
+func WithSlugRegeneration(enabled bool) Option {
+	return func(s *productService) {
+		s.regenerateSlug = enabled
+	}
+}
+
+// uniqueSlug derives a slug from name that no other product uses, adding
+// "-2", "-3", ... on collision. The check is not atomic, so two products
+// created concurrently with the same name can still collide.
+func (s *productService) uniqueSlug(ctx context.Context, name, id string) (string, error) {
+	base := models.Slugify(name)
+	for n := 1; n <= maxSlugAttempts; n++ {
+		candidate := base
+		if n > 1 {
+			candidate = fmt.Sprintf("%s-%d", base, n)
+		}
+		existing, err := s.repo.GetBySlug(ctx, candidate)
+		if err != nil {
+			return "", fmt.Errorf("failed to check slug: %w", err)
+		}
+		if existing == nil || existing.ID == id {
+			return candidate, nil
+		}
+	}
+	return fmt.Sprintf("%s-%s", base, strings.ToLower(id)), nil
+}
+
+func (s *productService) GetProductBySlug(ctx context.Context, slug string) (*models.Product, error) {
+	if slug == "" {
+		return nil, fmt.Errorf("%w: product slug cannot be empty", ErrInvalidProduct)
+	}
+
+	product, err := s.repo.GetBySlug(ctx, slug)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get product by slug: %w", err)
+	}
+
+	if product == nil {
+		return nil, ErrProductNotFound
+	}
+
+	go s.recordView(context.WithoutCancel(ctx), product.ID)
+
+	return product, nil
+}
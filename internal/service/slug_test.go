@@ -0,0 +1,65 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_CreateProduct_SuffixesDuplicateSlugs(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	first, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Blue Widget", Price: 10, Category: "widgets", SKU: "BW-1", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+	second, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Blue  widget!", Price: 10, Category: "widgets", SKU: "BW-2", Stock: floatPtr(1),
+	})
+	require.NoError(t, err)
+
+	assert.Equal(t, "blue-widget", first.Slug)
+	assert.Equal(t, "blue-widget-2", second.Slug)
+}
+
+func TestProductService_UpdateProduct_SlugRegeneration(t *testing.T) {
+	for _, regenerate := range []bool{false, true} {
+		repo := repository.NewMemoryProductRepository()
+		service := NewProductService(repo, WithSlugRegeneration(regenerate))
+		require.NoError(t, repo.Create(context.Background(), &models.Product{
+			ID: "test-id", Name: "Old Name", Slug: "old-name", Price: 10, Category: "widgets", SKU: "W-1", IsActive: true,
+		}))
+
+		name := "New Name"
+		product, err := service.UpdateProduct(context.Background(), "test-id", models.UpdateProductRequest{Name: &name})
+
+		require.NoError(t, err)
+		if regenerate {
+			assert.Equal(t, "new-name", product.Slug)
+		} else {
+			assert.Equal(t, "old-name", product.Slug)
+		}
+	}
+}
+
+func TestProductService_GetProductBySlug(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe", Slug: "shoe"}))
+
+	product, err := service.GetProductBySlug(context.Background(), "shoe")
+
+	require.NoError(t, err)
+	assert.Equal(t, "test-id", product.ID)
+
+	_, err = service.GetProductBySlug(context.Background(), "boot")
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	_, err = service.GetProductBySlug(context.Background(), "")
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+}
@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"product-service/internal/models"
+)
+
+// SortSpec orders a listing by a single product field. The zero value keeps
+// the order the repository returned.
+type SortSpec struct {
+	Field string
+	Desc  bool
+}
+
+// sortFields maps sortable field names to a comparison returning a negative
+// number, zero or a positive number like strings.Compare.
+var sortFields = map[string]func(a, b *models.Product) int{
+	"name":       func(a, b *models.Product) int { return strings.Compare(a.Name, b.Name) },
+	"price":      func(a, b *models.Product) int { return compareFloat(a.Price, b.Price) },
+	"stock":      func(a, b *models.Product) int { return compareFloat(a.Stock, b.Stock) },
+	"view_count": func(a, b *models.Product) int { return compareFloat(float64(a.ViewCount), float64(b.ViewCount)) },
+	"created_at": func(a, b *models.Product) int { return a.CreatedAt.Compare(b.CreatedAt) },
+	"updated_at": func(a, b *models.Product) int { return a.UpdatedAt.Compare(b.UpdatedAt) },
+}
+
+// ParseSort parses "field" or "field:asc|desc". An empty value yields the
+// zero SortSpec.
+func ParseSort(value string) (SortSpec, error) {
+	if value == "" {
+		return SortSpec{}, nil
+	}
+
+	field, direction, _ := strings.Cut(value, ":")
+	if _, ok := sortFields[field]; !ok {
+		return SortSpec{}, fmt.Errorf("%w: unsupported sort field %q", ErrInvalidQuery, field)
+	}
+
+	switch strings.ToLower(direction) {
+	case "", "asc":
+		return SortSpec{Field: field}, nil
+	case "desc":
+		return SortSpec{Field: field, Desc: true}, nil
+	}
+	return SortSpec{}, fmt.Errorf("%w: unsupported sort direction %q", ErrInvalidQuery, direction)
+}
+
+// WithDefaultSort sets the order used by listings that do not request one.
+func WithDefaultSort(spec SortSpec) Option {
+	return func(s *productService) {
+		s.defaultSort = spec
+	}
+}
+
+// listSort resolves the sort for a listing, falling back to the default. It
+// also rejects bad page parameters so they fail before the scan runs.
+func (s *productService) listSort(opts models.ListOptions) (SortSpec, error) {
+	if _, _, err := pageBounds(opts); err != nil {
+		return SortSpec{}, err
+	}
+	if opts.Sort == "" {
+		return s.defaultSort, nil
+	}
+	return ParseSort(opts.Sort)
+}
+
+// sortProducts orders products in place. Equal values are ordered by ID so
+// the result is a total order and stays stable from one request to the next.
+func sortProducts(products []*models.Product, spec SortSpec) {
+	compare, ok := sortFields[spec.Field]
+	if !ok {
+		return
+	}
+	sort.SliceStable(products, func(i, j int) bool {
+		c := compare(products[i], products[j])
+		if c == 0 {
+			return products[i].ID < products[j].ID
+		}
+		if spec.Desc {
+			return c > 0
+		}
+		return c < 0
+	})
+}
+
+func compareFloat(a, b float64) int {
+	switch {
+	case a < b:
+		return -1
+	case a > b:
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"product-service/internal/models"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func productIDs(products []*models.Product) []string {
+	var ids []string
+	for _, p := range products {
+		ids = append(ids, p.ID)
+	}
+	return ids
+}
+
+func TestParseSort(t *testing.T) {
+	spec, err := ParseSort("created_at:desc")
+	require.NoError(t, err)
+	assert.Equal(t, SortSpec{Field: "created_at", Desc: true}, spec)
+
+	spec, err = ParseSort("price")
+	require.NoError(t, err)
+	assert.Equal(t, SortSpec{Field: "price"}, spec)
+
+	spec, err = ParseSort("")
+	require.NoError(t, err)
+	assert.Equal(t, SortSpec{}, spec)
+
+	_, err = ParseSort("color:asc")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = ParseSort("price:sideways")
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func TestProductService_GetAllProducts_DefaultSort(t *testing.T) {
+	repo := new(MockProductRepository)
+	spec, err := ParseSort("created_at:desc")
+	require.NoError(t, err)
+	service := NewProductService(repo, WithDefaultSort(spec))
+
+	base := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "b", CreatedAt: base},
+			{ID: "newest", CreatedAt: base.Add(2 * time.Hour)},
+			{ID: "a", CreatedAt: base},
+			{ID: "middle", CreatedAt: base.Add(time.Hour)},
+		},
+	}, nil)
+
+	list, err := service.GetAllProducts(context.Background(), models.ListOptions{})
+
+	require.NoError(t, err)
+	// Equal timestamps fall back to ID so the order is the same on every call.
+	assert.Equal(t, []string{"newest", "middle", "a", "b"}, productIDs(list.Products))
+}
+
+func TestProductService_GetAllProducts_ExplicitSortOverridesDefault(t *testing.T) {
+	repo := new(MockProductRepository)
+	service := NewProductService(repo, WithDefaultSort(SortSpec{Field: "created_at", Desc: true}))
+
+	repo.On("GetAll").Return(&models.ProductList{
+		Products: []*models.Product{
+			{ID: "1", Price: 30},
+			{ID: "2", Price: 10},
+			{ID: "3", Price: 20},
+		},
+	}, nil)
+
+	list, err := service.GetAllProducts(context.Background(), models.ListOptions{Sort: "price"})
+
+	require.NoError(t, err)
+	assert.Equal(t, []string{"2", "3", "1"}, productIDs(list.Products))
+}
+
+func TestProductService_GetAllProducts_InvalidSort(t *testing.T) {
+	repo := new(MockProductRepository)
+	service := NewProductService(repo)
+
+	_, err := service.GetAllProducts(context.Background(), models.ListOptions{Sort: "color"})
+
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+	repo.AssertNotCalled(t, "GetAll")
+}
@@ -0,0 +1,90 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// MaxBulkStatusItems bounds how many products a category may match before
+// BulkSetStatus requires the request to be confirmed.
+const MaxBulkStatusItems = 100
+
+// BulkSetStatus activates or deactivates the products matched by the
+// request's category or ID list, updating each one as UpdateProduct would.
+// Deleted products are left out of a category and fail when listed by ID.
+// A category that matches more than MaxBulkStatusItems products is refused
+// unless the request sets Confirm, so a mistyped filter cannot take down the
+// catalog. Items fail independently and are reported in the result.
+func (s *productService) BulkSetStatus(ctx context.Context, req models.BulkStatusRequest) (*models.BulkStatusResult, error) {
+	if req.Active == nil {
+		return nil, fmt.Errorf("%w: target status is required", ErrInvalidQuery)
+	}
+	if (req.Category == "") == (len(req.IDs) == 0) {
+		return nil, fmt.Errorf("%w: exactly one of category or ids is required", ErrInvalidQuery)
+	}
+	if len(req.IDs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a bulk status update must list at most %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	var products []*models.Product
+	var failures []models.BulkStatusFailure
+	if req.Category != "" {
+		var err error
+		if products, err = s.productsInCategory(ctx, req.Category); err != nil {
+			return nil, err
+		}
+		// Deleted products stay as they are until restored.
+		products = slices.DeleteFunc(products, (*models.Product).IsDeleted)
+		if len(products) > MaxBulkStatusItems && !req.Confirm {
+			return nil, fmt.Errorf("%w: category %q matches %d products, more than %d; set confirm to apply",
+				ErrInvalidQuery, req.Category, len(products), MaxBulkStatusItems)
+		}
+	} else {
+		for _, id := range req.IDs {
+			product, err := s.productForUpdate(ctx, id)
+			if err != nil {
+				failures = append(failures, models.BulkStatusFailure{ID: id, Error: err.Error()})
+				continue
+			}
+			products = append(products, product)
+		}
+	}
+
+	result := &models.BulkStatusResult{Matched: len(products)}
+	update := models.UpdateProductRequest{IsActive: req.Active}
+	for _, product := range products {
+		// Setting the status a product already has still overrides an
+		// out-of-stock deactivation.
+		if !product.Changes(update) {
+			continue
+		}
+		_, err := s.applyUpdate(ctx, product, update)
+		if errors.Is(err, repository.ErrVersionConflict) {
+			_, err = s.UpdateProduct(ctx, product.ID, update)
+		}
+		if err != nil {
+			failures = append(failures, models.BulkStatusFailure{ID: product.ID, Error: err.Error()})
+			continue
+		}
+		result.Changed++
+	}
+	result.Failures = failures
+
+	if !IsDryRun(ctx) {
+		s.logger.InfoContext(ctx, "bulk status update",
+			"category", req.Category,
+			"active", *req.Active,
+			"matched", result.Matched,
+			"changed", result.Changed,
+			"failed", len(failures),
+			"actor", auth.ActorID(ctx),
+		)
+	}
+	return result, nil
+}
@@ -0,0 +1,139 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_BulkSetStatus_Category(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+
+	inactive := false
+	result, err := service.BulkSetStatus(context.Background(), models.BulkStatusRequest{
+		Category: "electronic",
+		Active:   &inactive,
+	})
+
+	require.NoError(t, err)
+	// Product 3 is already inactive, so only two of the three change.
+	assert.Equal(t, 3, result.Matched)
+	assert.Equal(t, 2, result.Changed)
+	assert.Empty(t, result.Failures)
+
+	for _, id := range []string{"1", "2", "3"} {
+		product, err := repo.GetByID(context.Background(), id)
+		require.NoError(t, err)
+		assert.False(t, product.IsActive, id)
+	}
+	untouched, err := repo.GetByID(context.Background(), "4")
+	require.NoError(t, err)
+	assert.True(t, untouched.IsActive)
+}
+
+func TestProductService_BulkSetStatus_DryRun(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+
+	inactive := false
+	result, err := service.BulkSetStatus(WithDryRun(context.Background()), models.BulkStatusRequest{
+		Category: "electronic",
+		Active:   &inactive,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Changed)
+
+	product, err := repo.GetByID(context.Background(), "1")
+	require.NoError(t, err)
+	assert.True(t, product.IsActive)
+}
+
+func TestProductService_BulkSetStatus_RequiresConfirm(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	for i := 0; i <= MaxBulkStatusItems; i++ {
+		require.NoError(t, repo.Create(context.Background(), &models.Product{
+			ID: fmt.Sprintf("p%d", i), Category: "recalled", Price: 1, IsActive: true,
+		}))
+	}
+
+	inactive := false
+	req := models.BulkStatusRequest{Category: "recalled", Active: &inactive}
+	_, err := service.BulkSetStatus(context.Background(), req)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	product, err := repo.GetByID(context.Background(), "p0")
+	require.NoError(t, err)
+	assert.True(t, product.IsActive)
+
+	req.Confirm = true
+	result, err := service.BulkSetStatus(context.Background(), req)
+	require.NoError(t, err)
+	assert.Equal(t, MaxBulkStatusItems+1, result.Changed)
+}
+
+func TestProductService_BulkSetStatus_IDs(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedCategories(t, repo)
+
+	active := true
+	result, err := service.BulkSetStatus(context.Background(), models.BulkStatusRequest{
+		IDs:    []string{"3", "missing", "4"},
+		Active: &active,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, 2, result.Matched)
+	assert.Equal(t, 1, result.Changed)
+	require.Len(t, result.Failures, 1)
+	assert.Equal(t, "missing", result.Failures[0].ID)
+
+	product, err := repo.GetByID(context.Background(), "3")
+	require.NoError(t, err)
+	assert.True(t, product.IsActive)
+}
+
+func TestProductService_BulkSetStatus_OverridesStockDeactivation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	ctx := context.Background()
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "sold-out", Name: "Widget", Price: 10, Unit: models.UnitEach, StockDeactivated: true}))
+
+	// Deactivating a product already inactive because it sold out keeps it
+	// inactive after a restock.
+	inactive := false
+	result, err := service.BulkSetStatus(ctx, models.BulkStatusRequest{IDs: []string{"sold-out"}, Active: &inactive})
+	require.NoError(t, err)
+	assert.Equal(t, 1, result.Changed)
+
+	_, err = service.UpdateProduct(ctx, "sold-out", models.UpdateProductRequest{Stock: floatPtr(4)})
+	require.NoError(t, err)
+	stored, _ := repo.GetByID(ctx, "sold-out")
+	assert.False(t, stored.IsActive)
+	assert.False(t, stored.StockDeactivated)
+}
+
+func TestProductService_BulkSetStatus_Invalid(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+	active := true
+
+	for name, req := range map[string]models.BulkStatusRequest{
+		"no status":   {Category: "electronic"},
+		"no filter":   {Active: &active},
+		"both filter": {Category: "electronic", IDs: []string{"1"}, Active: &active},
+	} {
+		_, err := service.BulkSetStatus(context.Background(), req)
+		assert.ErrorIs(t, err, ErrInvalidQuery, name)
+	}
+}
@@ -0,0 +1,219 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"unicode/utf8"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// WithAutoDeactivateOutOfStock makes an update that sells a product out
+// deactivate it, and one that restocks a product deactivated that way
+// reactivate it. Products activated or deactivated by hand are left as
+// they were set.
+func WithAutoDeactivateOutOfStock(enabled bool) Option {
+	return func(s *productService) {
+		s.autoDeactivateOOS = enabled
+	}
+}
+
+// applyStockActivation applies the out-of-stock policy to an update that
+// changed product from before. Setting is_active in the same request takes
+// precedence, so a product switched on by hand stays on even with no stock.
+func (s *productService) applyStockActivation(ctx context.Context, before, product *models.Product, req models.UpdateProductRequest) {
+	if req.IsActive != nil || product.Stock == before.Stock || product.DeletedAt != nil {
+		return
+	}
+
+	switch {
+	case product.Stock <= 0 && product.IsActive:
+		product.IsActive, product.StockDeactivated = false, true
+	case product.Stock > 0 && product.StockDeactivated:
+		product.IsActive, product.StockDeactivated = true, false
+	default:
+		return
+	}
+	s.logger.InfoContext(ctx, "product activation follows stock",
+		"product_id", product.ID,
+		"stock", product.Stock,
+		"is_active", product.IsActive,
+	)
+}
+
+// followStock applies the out-of-stock policy after a stock change that did
+// not read the product, such as a reservation. The stock change has already
+// been made, so a failure is logged rather than returned.
+func (s *productService) followStock(ctx context.Context, id string, active bool) {
+	changed, err := s.repo.SetStockActivation(ctx, id, active)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "stock activation failed", "product_id", id, "error", err)
+		return
+	}
+	if changed {
+		s.logger.InfoContext(ctx, "product activation follows stock", "product_id", id, "is_active", active)
+	}
+}
+
+// BulkSetStock sets the stock of each listed product with an independent
+// conditional write, so one failing item does not affect the others. Only
+// active products are updated, and with the out-of-stock policy enabled
+// products it deactivated, which a restock reactivates. Results are
+// returned in request order.
+func (s *productService) BulkSetStock(ctx context.Context, updates []models.StockUpdate) ([]models.StockUpdateResult, error) {
+	if len(updates) == 0 || len(updates) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a bulk stock update must list between 1 and %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	actor := auth.ActorID(ctx)
+	results := make([]models.StockUpdateResult, 0, len(updates))
+	counts := make(map[string]int)
+	for _, update := range updates {
+		result := s.setStock(ctx, update, actor)
+		counts[result.Status]++
+		results = append(results, result)
+	}
+
+	if !IsDryRun(ctx) {
+		s.logger.InfoContext(ctx, "bulk stock update",
+			"updated", counts[models.StockUpdated],
+			"unchanged", counts[models.StockUnchanged],
+			"failed", counts[models.StockFailed],
+			"actor", actor,
+		)
+	}
+	return results, nil
+}
+
+func (s *productService) setStock(ctx context.Context, update models.StockUpdate, actor string) models.StockUpdateResult {
+	failed := func(err error) models.StockUpdateResult {
+		return models.StockUpdateResult{ID: update.ID, Status: models.StockFailed, Error: err.Error()}
+	}
+
+	switch {
+	case update.ID == "":
+		return failed(fieldError("id", "product ID is required"))
+	case update.Stock == nil:
+		return failed(fieldError("stock", "product stock is required"))
+	case math.IsNaN(*update.Stock) || math.IsInf(*update.Stock, 0):
+		return failed(fieldError("stock", "product stock must be a finite number"))
+	case *update.Stock < 0:
+		return failed(fieldError("stock", "product stock cannot be negative"))
+	}
+	stock := *update.Stock
+	if err := s.validateMaxStock(stock); err != nil {
+		return failed(err)
+	}
+
+	var product *models.Product
+	var written bool
+	var err error
+	if IsDryRun(ctx) {
+		product, err = s.repo.GetByID(ctx, update.ID)
+		written = product != nil && product.Stock != stock && models.IsValidStockForUnit(stock, product.Unit) &&
+			(product.IsActive || s.autoDeactivateOOS && stock > 0 && product.StockDeactivated && !product.IsDeleted())
+	} else {
+		product, written, err = s.repo.SetStock(ctx, update.ID, stock, s.autoDeactivateOOS, actor, s.clock.Now())
+	}
+	if err != nil {
+		s.logger.ErrorContext(ctx, "stock update failed", "product_id", update.ID, "error", err)
+		return failed(err)
+	}
+
+	switch {
+	case product == nil:
+		return failed(ErrProductNotFound)
+	case written:
+		if !IsDryRun(ctx) {
+			s.publish(ctx, models.EventProductUpdated, update.ID)
+		}
+		return models.StockUpdateResult{ID: update.ID, Status: models.StockUpdated, Stock: &stock}
+	case !product.IsActive:
+		return failed(fmt.Errorf("product is not active"))
+	case product.Stock == stock:
+		return models.StockUpdateResult{ID: update.ID, Status: models.StockUnchanged, Stock: &stock}
+	default:
+		return failed(fieldError("stock", "product stock must be a whole number for unit %q", models.NormalizeUnit(product.Unit)))
+	}
+}
+
+// MaxStockReasonLength bounds the reason given for clearing stock, in
+// characters.
+const MaxStockReasonLength = 500
+
+// maxClearStockAttempts bounds how often ClearStock re-reads a product that
+// was written between its read and its conditional write.
+const maxClearStockAttempts = 3
+
+// ClearStock sets a product's stock to zero, e.g. when inventory is written
+// off. The write is conditional on the version read, so a concurrent stock
+// change is never overwritten; the product is re-read and cleared again. With
+// the out-of-stock policy enabled an active product is deactivated as sold
+// out. The reason is recorded in the log entry for the change.
+func (s *productService) ClearStock(ctx context.Context, id string, req models.ClearStockRequest) (*models.Product, error) {
+	reason := strings.TrimSpace(req.Reason)
+	var invalid error
+	switch {
+	case reason == "":
+		invalid = fieldError("reason", "a reason for clearing stock is required")
+	case utf8.RuneCountInString(reason) > MaxStockReasonLength:
+		invalid = fieldError("reason", "the reason for clearing stock must be at most %d characters", MaxStockReasonLength)
+	}
+	if invalid != nil {
+		s.logRejected(ctx, "clear stock", id, invalid)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, invalid)
+	}
+
+	actor := auth.ActorID(ctx)
+	for attempt := 0; attempt < maxClearStockAttempts; attempt++ {
+		product, err := s.productForUpdate(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+		if err := checkUnmodifiedSince(ctx, product); err != nil {
+			return nil, err
+		}
+		recordPrevious(ctx, product)
+
+		deactivate := s.autoDeactivateOOS && product.IsActive
+		if product.Stock == 0 && !deactivate {
+			return product, nil
+		}
+
+		before := *product
+		if IsDryRun(ctx) {
+			product.Stock = 0
+			if deactivate {
+				product.IsActive, product.StockDeactivated = false, true
+			}
+			product.UpdatedAt = s.clock.Now()
+			product.UpdatedBy = actor
+			recordChanges(ctx, &before, product)
+			return product, nil
+		}
+
+		cleared, ok, err := s.repo.ClearStock(ctx, id, before.Version, deactivate, actor, s.clock.Now())
+		if err != nil {
+			s.logger.ErrorContext(ctx, "stock clear failed", "product_id", id, "error", err)
+			return nil, fmt.Errorf("failed to clear stock: %w", err)
+		}
+		if !ok {
+			continue
+		}
+		recordChanges(ctx, &before, cleared)
+
+		s.logger.InfoContext(ctx, "product stock cleared",
+			"product_id", id,
+			"previous_stock", before.Stock,
+			"deactivated", deactivate,
+			"reason", reason,
+			"actor", actor,
+		)
+		s.publish(ctx, models.EventProductUpdated, id)
+		return cleared, nil
+	}
+	return nil, fmt.Errorf("failed to clear stock of product %s: it kept changing", id)
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func stockUpdate(id string, stock float64) models.StockUpdate {
+	return models.StockUpdate{ID: id, Stock: &stock}
+}
+
+func seedStock(t *testing.T, repo repository.ProductRepository) {
+	t.Helper()
+	for _, product := range []*models.Product{
+		{ID: "widget", Stock: 5, Unit: models.UnitEach, IsActive: true},
+		{ID: "flour", Stock: 2, Unit: models.UnitKilogram, IsActive: true},
+		{ID: "retired", Stock: 1, Unit: models.UnitEach, IsActive: false},
+	} {
+		require.NoError(t, repo.Create(context.Background(), product))
+	}
+}
+
+func resultStatuses(results []models.StockUpdateResult) map[string]string {
+	statuses := make(map[string]string, len(results))
+	for _, result := range results {
+		statuses[result.ID] = result.Status
+	}
+	return statuses
+}
+
+func TestProductService_BulkSetStock_MixedIDs(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedStock(t, repo)
+
+	results, err := service.BulkSetStock(context.Background(), []models.StockUpdate{
+		stockUpdate("widget", 10),
+		stockUpdate("missing", 3),
+		stockUpdate("flour", 2.5),
+		stockUpdate("retired", 4),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 4)
+	assert.Equal(t, []string{"widget", "missing", "flour", "retired"},
+		[]string{results[0].ID, results[1].ID, results[2].ID, results[3].ID})
+	assert.Equal(t, map[string]string{
+		"widget":  models.StockUpdated,
+		"missing": models.StockFailed,
+		"flour":   models.StockUpdated,
+		"retired": models.StockFailed,
+	}, resultStatuses(results))
+	assert.Equal(t, ErrProductNotFound.Error(), results[1].Error)
+
+	widget, _ := repo.GetByID(context.Background(), "widget")
+	assert.Equal(t, float64(10), widget.Stock)
+	assert.NotNil(t, widget.StockUpdatedAt)
+	retired, _ := repo.GetByID(context.Background(), "retired")
+	assert.Equal(t, float64(1), retired.Stock)
+}
+
+func TestProductService_BulkSetStock_ItemValidation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithMaxStock(100))
+	seedStock(t, repo)
+
+	results, err := service.BulkSetStock(context.Background(), []models.StockUpdate{
+		stockUpdate("widget", 5),
+		stockUpdate("widget", 1.5),
+		stockUpdate("flour", -1),
+		stockUpdate("flour", 1000),
+		{ID: "flour"},
+	})
+
+	require.NoError(t, err)
+	var statuses []string
+	for _, result := range results {
+		statuses = append(statuses, result.Status)
+	}
+	assert.Equal(t, []string{
+		models.StockUnchanged,
+		models.StockFailed,
+		models.StockFailed,
+		models.StockFailed,
+		models.StockFailed,
+	}, statuses)
+
+	widget, _ := repo.GetByID(context.Background(), "widget")
+	assert.Equal(t, float64(5), widget.Stock)
+	assert.Nil(t, widget.StockUpdatedAt)
+}
+
+func TestProductService_BulkSetStock_DryRun(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedStock(t, repo)
+
+	results, err := service.BulkSetStock(WithDryRun(context.Background()), []models.StockUpdate{
+		stockUpdate("widget", 10),
+		stockUpdate("missing", 3),
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{
+		"widget":  models.StockUpdated,
+		"missing": models.StockFailed,
+	}, resultStatuses(results))
+
+	widget, _ := repo.GetByID(context.Background(), "widget")
+	assert.Equal(t, float64(5), widget.Stock)
+}
+
+func TestProductService_BulkSetStock_BatchSize(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	_, err := service.BulkSetStock(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = service.BulkSetStock(context.Background(), make([]models.StockUpdate, DefaultMaxBatchSize+1))
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
+
+func stockRequest(stock float64) models.UpdateProductRequest {
+	return models.UpdateProductRequest{Stock: &stock}
+}
+
+func TestProductService_AutoDeactivateOutOfStock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	seedStock(t, repo)
+	ctx := context.Background()
+
+	product, err := service.UpdateProduct(ctx, "widget", stockRequest(0))
+	require.NoError(t, err)
+	assert.False(t, product.IsActive, "selling out deactivates")
+
+	product, err = service.UpdateProduct(ctx, "widget", stockRequest(4))
+	require.NoError(t, err)
+	assert.True(t, product.IsActive, "restocking reactivates")
+
+	stored, _ := repo.GetByID(ctx, "widget")
+	assert.True(t, stored.IsActive)
+	assert.False(t, stored.StockDeactivated)
+}
+
+func TestProductService_AutoDeactivateOutOfStock_ManualActivation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	seedStock(t, repo)
+	ctx := context.Background()
+	active, inactive := true, false
+
+	// A product deactivated by hand stays inactive when restocked.
+	product, err := service.UpdateProduct(ctx, "retired", stockRequest(0))
+	require.NoError(t, err)
+	require.False(t, product.IsActive)
+	product, err = service.UpdateProduct(ctx, "retired", stockRequest(8))
+	require.NoError(t, err)
+	assert.False(t, product.IsActive)
+
+	// Activating by hand with no stock is respected, and a later restock
+	// leaves it active.
+	product, err = service.UpdateProduct(ctx, "widget", models.UpdateProductRequest{Stock: floatPtr(0), IsActive: &active})
+	require.NoError(t, err)
+	assert.True(t, product.IsActive)
+
+	// Deactivating by hand after a sell-out is not undone by a restock.
+	_, err = service.UpdateProduct(ctx, "flour", stockRequest(0))
+	require.NoError(t, err)
+	_, err = service.UpdateProduct(ctx, "flour", models.UpdateProductRequest{IsActive: &inactive})
+	require.NoError(t, err)
+	product, err = service.UpdateProduct(ctx, "flour", stockRequest(3))
+	require.NoError(t, err)
+	assert.False(t, product.IsActive)
+}
+
+func TestProductService_AutoDeactivateOutOfStock_BulkSetStock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	seedStock(t, repo)
+	ctx := context.Background()
+
+	results, err := service.BulkSetStock(ctx, []models.StockUpdate{stockUpdate("widget", 0), stockUpdate("retired", 0)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"widget": models.StockUpdated, "retired": models.StockFailed}, resultStatuses(results))
+	stored, _ := repo.GetByID(ctx, "widget")
+	assert.False(t, stored.IsActive, "selling out deactivates")
+
+	results, err = service.BulkSetStock(ctx, []models.StockUpdate{stockUpdate("widget", 3), stockUpdate("retired", 3)})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"widget": models.StockUpdated, "retired": models.StockFailed}, resultStatuses(results))
+	stored, _ = repo.GetByID(ctx, "widget")
+	assert.True(t, stored.IsActive, "restocking reactivates")
+	assert.False(t, stored.StockDeactivated)
+	retired, _ := repo.GetByID(ctx, "retired")
+	assert.False(t, retired.IsActive, "a product deactivated by hand stays inactive")
+}
+
+func TestProductService_AutoDeactivateOutOfStock_Reservations(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	seedStock(t, repo)
+	ctx := context.Background()
+
+	reservation, err := service.ReserveStock(ctx, "widget", models.ReserveStockRequest{Quantity: 5})
+	require.NoError(t, err)
+	stored, _ := repo.GetByID(ctx, "widget")
+	assert.False(t, stored.IsActive, "reserving the last stock deactivates")
+
+	require.NoError(t, service.ReleaseReservation(ctx, "widget", reservation.ID))
+	stored, _ = repo.GetByID(ctx, "widget")
+	assert.True(t, stored.IsActive, "releasing it reactivates")
+	assert.False(t, stored.StockDeactivated)
+}
+
+func TestProductService_AutoDeactivateOutOfStock_Disabled(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedStock(t, repo)
+
+	product, err := service.UpdateProduct(context.Background(), "widget", stockRequest(0))
+
+	require.NoError(t, err)
+	assert.True(t, product.IsActive)
+}
+
+func TestProductService_ClearStock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedStock(t, repo)
+
+	product, err := service.ClearStock(context.Background(), "widget", models.ClearStockRequest{Reason: "recount"})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), product.Stock)
+	assert.NotNil(t, product.StockUpdatedAt)
+	// Without the out-of-stock policy the product stays on sale.
+	assert.True(t, product.IsActive)
+
+	// Inactive products are cleared too, and stay inactive.
+	product, err = service.ClearStock(context.Background(), "retired", models.ClearStockRequest{Reason: "recount"})
+
+	require.NoError(t, err)
+	assert.Equal(t, float64(0), product.Stock)
+	assert.False(t, product.StockDeactivated)
+}
+
+func TestProductService_StockWrites_UseClock(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	clock := models.NewFakeClock(time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC))
+	service := NewProductService(repo, WithClock(clock))
+	ctx := context.Background()
+	seedStock(t, repo)
+
+	assertStamped := func(id string) {
+		t.Helper()
+		stored, err := repo.GetByID(ctx, id)
+		require.NoError(t, err)
+		assert.Equal(t, clock.Now(), stored.UpdatedAt)
+		if assert.NotNil(t, stored.StockUpdatedAt) {
+			assert.Equal(t, clock.Now(), *stored.StockUpdatedAt)
+		}
+	}
+
+	_, err := service.BulkSetStock(ctx, []models.StockUpdate{stockUpdate("widget", 8)})
+	require.NoError(t, err)
+	assertStamped("widget")
+
+	clock.Advance(time.Minute)
+	reservation, err := service.ReserveStock(ctx, "widget", models.ReserveStockRequest{Quantity: 2})
+	require.NoError(t, err)
+	assertStamped("widget")
+
+	clock.Advance(time.Minute)
+	require.NoError(t, service.ReleaseReservation(ctx, "widget", reservation.ID))
+	assertStamped("widget")
+
+	clock.Advance(time.Minute)
+	_, err = service.ClearStock(ctx, "widget", models.ClearStockRequest{Reason: "recount"})
+	require.NoError(t, err)
+	assertStamped("widget")
+
+	clock.Advance(time.Minute)
+	require.NoError(t, repo.Create(ctx, &models.Product{ID: "lamp", Category: "electronic", IsActive: true}))
+	_, err = service.RenameCategory(ctx, "electronic", "electronics")
+	require.NoError(t, err)
+	stored, err := repo.GetByID(ctx, "lamp")
+	require.NoError(t, err)
+	assert.Equal(t, clock.Now(), stored.UpdatedAt)
+}
+
+func TestProductService_ClearStock_DeactivatesPerPolicy(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithAutoDeactivateOutOfStock(true))
+	seedStock(t, repo)
+
+	product, err := service.ClearStock(context.Background(), "widget", models.ClearStockRequest{Reason: "damaged"})
+
+	require.NoError(t, err)
+	assert.False(t, product.IsActive)
+	assert.True(t, product.StockDeactivated)
+
+	// A hand-deactivated product is not marked as deactivated by stock, so
+	// restocking it later leaves it off.
+	product, err = service.ClearStock(context.Background(), "retired", models.ClearStockRequest{Reason: "damaged"})
+
+	require.NoError(t, err)
+	assert.False(t, product.StockDeactivated)
+}
+
+func TestProductService_ClearStock_Rejected(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedStock(t, repo)
+
+	_, err := service.ClearStock(context.Background(), "widget", models.ClearStockRequest{Reason: "  "})
+
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "reason", fieldErr.Field)
+
+	_, err = service.ClearStock(context.Background(), "missing", models.ClearStockRequest{Reason: "lost"})
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+
+	stored, _ := repo.GetByID(context.Background(), "widget")
+	assert.Equal(t, float64(5), stored.Stock)
+}
+
+func TestProductService_ClearStock_RetriesAfterConcurrentWrite(t *testing.T) {
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo)
+
+	mockRepo.On("GetByID", "widget").Return(&models.Product{ID: "widget", Stock: 5, Version: 3}, nil).Once()
+	mockRepo.On("ClearStock", "widget", int64(3), false, "anonymous").Return(nil, false, nil).Once()
+	mockRepo.On("GetByID", "widget").Return(&models.Product{ID: "widget", Stock: 7, Version: 4}, nil).Once()
+	mockRepo.On("ClearStock", "widget", int64(4), false, "anonymous").Return(&models.Product{ID: "widget", Version: 5}, true, nil).Once()
+
+	product, err := service.ClearStock(context.Background(), "widget", models.ClearStockRequest{Reason: "recount"})
+
+	require.NoError(t, err)
+	assert.Equal(t, int64(5), product.Version)
+	mockRepo.AssertExpectations(t)
+}
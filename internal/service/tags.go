@@ -0,0 +1,57 @@
+package service
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// WithTagLimits caps how many tags a product may carry and how long each
+// tag may be, in characters. Zero leaves either unbounded.
+func WithTagLimits(maxTags, maxTagLen int) Option {
+	return func(s *productService) {
+		s.maxTags = maxTags
+		s.maxTagLen = maxTagLen
+	}
+}
+
+// normalizeTag is the stored form of a tag: trimmed and lower case, so
+// "Sale" and "sale " are the same tag.
+func normalizeTag(tag string) string {
+	return strings.ToLower(strings.TrimSpace(tag))
+}
+
+// normalizeTags normalizes each tag and drops empty and repeated ones,
+// keeping the first occurrence's position. A nil slice stays nil so an
+// absent field is not mistaken for an empty one.
+func normalizeTags(tags []string) []string {
+	if tags == nil {
+		return nil
+	}
+	normalized := make([]string, 0, len(tags))
+	seen := make(map[string]bool, len(tags))
+	for _, tag := range tags {
+		tag = normalizeTag(tag)
+		if tag == "" || seen[tag] {
+			continue
+		}
+		seen[tag] = true
+		normalized = append(normalized, tag)
+	}
+	return normalized
+}
+
+// validateTags checks normalized tags against the configured limits.
+func (s *productService) validateTags(tags []string) error {
+	if s.maxTags > 0 && len(tags) > s.maxTags {
+		return fieldError("tags", "product cannot have more than %d tags", s.maxTags)
+	}
+	if s.maxTagLen == 0 {
+		return nil
+	}
+	for _, tag := range tags {
+		if utf8.RuneCountInString(tag) > s.maxTagLen {
+			return fieldError("tags", "product tag %q exceeds %d characters", tag, s.maxTagLen)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestNormalizeTags(t *testing.T) {
+	assert.Nil(t, normalizeTags(nil))
+	assert.Equal(t, []string{}, normalizeTags([]string{}))
+	assert.Equal(t, []string{"sale", "new", "summer"}, normalizeTags([]string{" Sale", "new", "SALE", "", "  ", "Summer", "sale "}))
+}
+
+func tagsOf(n int) []string {
+	tags := make([]string, n)
+	for i := range tags {
+		tags[i] = fmt.Sprintf("tag-%d", i)
+	}
+	return tags
+}
+
+func TestProductService_CreateProduct_TagLimits(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository(), WithTagLimits(3, 10))
+	req := func(sku string, tags []string) models.CreateProductRequest {
+		return models.CreateProductRequest{Name: "Widget", Price: 9.99, Category: "toys", SKU: sku, Tags: tags}
+	}
+
+	product, err := service.CreateProduct(context.Background(), req("TOY-001", tagsOf(3)))
+	require.NoError(t, err)
+	assert.Len(t, product.Tags, 3)
+
+	// Duplicates collapse before the count is checked.
+	product, err = service.CreateProduct(context.Background(), req("TOY-002", []string{"Sale", "sale", "SALE ", "new", "gift"}))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"sale", "new", "gift"}, product.Tags)
+
+	for name, tags := range map[string][]string{
+		"too many": tagsOf(4),
+		"too long": {"sale", strings.Repeat("x", 11)},
+	} {
+		_, err := service.CreateProduct(context.Background(), req("TOY-003", tags))
+
+		assert.ErrorIs(t, err, ErrInvalidProduct, name)
+		var fieldErr *FieldError
+		if assert.ErrorAs(t, err, &fieldErr, name) {
+			assert.Equal(t, "tags", fieldErr.Field, name)
+		}
+	}
+}
+
+func TestProductService_UpdateProduct_TagLimits(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo, WithTagLimits(3, 10))
+	product, err := service.CreateProduct(context.Background(), models.CreateProductRequest{
+		Name: "Widget", Price: 9.99, Category: "toys", SKU: "TOY-001", Tags: tagsOf(3),
+	})
+	require.NoError(t, err)
+
+	tags := append(tagsOf(3), "Tag-0")
+	updated, err := service.UpdateProduct(context.Background(), product.ID, models.UpdateProductRequest{Tags: &tags})
+	require.NoError(t, err)
+	assert.Equal(t, tagsOf(3), updated.Tags)
+
+	tags = tagsOf(4)
+	_, err = service.UpdateProduct(context.Background(), product.ID, models.UpdateProductRequest{Tags: &tags})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	// Appending through a patch is held to the same limit.
+	_, err = service.PatchProduct(context.Background(), product.ID, []models.PatchOperation{
+		{Op: "add", Path: "/tags/-", Value: json.RawMessage(`"extra"`)},
+	})
+	var fieldErr *FieldError
+	if assert.ErrorAs(t, err, &fieldErr) {
+		assert.Equal(t, "tags", fieldErr.Field)
+	}
+}
@@ -0,0 +1,120 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"maps"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+)
+
+// SetTranslation adds or replaces the product's name and description for a
+// BCP 47 locale. The locale is stored in canonical form.
+func (s *productService) SetTranslation(ctx context.Context, id, locale string, translation models.ProductTranslation) (*models.Product, error) {
+	canonical, err := models.CanonicalLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, fieldError("locale", "invalid locale %q", locale))
+	}
+	if err := s.sanitizeTranslation(&translation); err != nil {
+		s.logRejected(ctx, "set translation", id, err)
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, err)
+	}
+
+	return s.updateTranslations(ctx, id, func(translations map[string]models.ProductTranslation) bool {
+		if current, ok := translations[canonical]; ok && current == translation {
+			return false
+		}
+		translations[canonical] = translation
+		return true
+	})
+}
+
+// RemoveTranslation deletes the product's translation for a locale.
+// Removing a translation that does not exist is not an error.
+func (s *productService) RemoveTranslation(ctx context.Context, id, locale string) (*models.Product, error) {
+	canonical, err := models.CanonicalLocale(locale)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidProduct, fieldError("locale", "invalid locale %q", locale))
+	}
+
+	return s.updateTranslations(ctx, id, func(translations map[string]models.ProductTranslation) bool {
+		if _, ok := translations[canonical]; !ok {
+			return false
+		}
+		delete(translations, canonical)
+		return true
+	})
+}
+
+// updateTranslations applies change to a copy of the product's translations
+// and stores the product when change reports a modification.
+func (s *productService) updateTranslations(ctx context.Context, id string, change func(map[string]models.ProductTranslation) bool) (*models.Product, error) {
+	return retryOnConflict(func() (*models.Product, error) {
+		return s.writeTranslations(ctx, id, change)
+	})
+}
+
+func (s *productService) writeTranslations(ctx context.Context, id string, change func(map[string]models.ProductTranslation) bool) (*models.Product, error) {
+	product, err := s.productForUpdate(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkUnmodifiedSince(ctx, product); err != nil {
+		return nil, err
+	}
+
+	translations := maps.Clone(product.Translations)
+	if translations == nil {
+		translations = make(map[string]models.ProductTranslation)
+	}
+	if !change(translations) {
+		return product, nil
+	}
+	if len(translations) == 0 {
+		translations = nil
+	}
+	recordPrevious(ctx, product)
+
+	before := *product
+	product.Translations = translations
+	product.UpdatedAt = s.clock.Now()
+	product.UpdatedBy = auth.ActorID(ctx)
+	recordChanges(ctx, &before, product)
+
+	if IsDryRun(ctx) {
+		return product, nil
+	}
+
+	if err := s.repo.Update(ctx, product); err != nil {
+		s.logger.ErrorContext(ctx, "translation update failed", "product_id", id, "error", err)
+		return nil, fmt.Errorf("failed to update translations: %w", err)
+	}
+
+	s.logger.InfoContext(ctx, "product translations updated",
+		"product_id", id,
+		"locales", len(translations),
+		"actor", product.UpdatedBy,
+	)
+	s.publish(ctx, models.EventProductUpdated, id)
+	return product, nil
+}
+
+func (s *productService) sanitizeTranslation(translation *models.ProductTranslation) error {
+	name, err := s.sanitizeText("name", translation.Name)
+	if err != nil {
+		return err
+	}
+	if name == "" {
+		return fieldError("name", "translated name is required")
+	}
+	description, err := s.sanitizeText("description", translation.Description)
+	if err != nil {
+		return err
+	}
+	if err := s.validateText(name, description); err != nil {
+		return err
+	}
+	translation.Name, translation.Description = name, description
+	return nil
+}
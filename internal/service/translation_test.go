@@ -0,0 +1,53 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_SetTranslation(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe"}))
+
+	product, err := service.SetTranslation(context.Background(), "test-id", "fr-ca", models.ProductTranslation{
+		Name: "<b>Chaussure</b>",
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, map[string]models.ProductTranslation{"fr-CA": {Name: "Chaussure"}}, product.Translations)
+
+	stored, _ := repo.GetByID(context.Background(), "test-id")
+	assert.Equal(t, "Chaussure", stored.Translations["fr-CA"].Name)
+
+	product, err = service.RemoveTranslation(context.Background(), "test-id", "fr-CA")
+
+	require.NoError(t, err)
+	assert.Nil(t, product.Translations)
+	stored, _ = repo.GetByID(context.Background(), "test-id")
+	assert.Empty(t, stored.Translations)
+}
+
+func TestProductService_SetTranslation_Invalid(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	require.NoError(t, repo.Create(context.Background(), &models.Product{ID: "test-id", Name: "Shoe"}))
+
+	_, err := service.SetTranslation(context.Background(), "test-id", "not a locale", models.ProductTranslation{Name: "Chaussure"})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+	var fieldErr *FieldError
+	require.ErrorAs(t, err, &fieldErr)
+	assert.Equal(t, "locale", fieldErr.Field)
+
+	_, err = service.SetTranslation(context.Background(), "test-id", "fr", models.ProductTranslation{Name: "<br>"})
+	assert.ErrorIs(t, err, ErrInvalidProduct)
+
+	_, err = service.SetTranslation(context.Background(), "missing", "fr", models.ProductTranslation{Name: "Chaussure"})
+	assert.ErrorIs(t, err, ErrProductNotFound)
+}
@@ -0,0 +1,126 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/auth"
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+// BulkUpsertProducts creates or updates each listed product by SKU, for
+// sync jobs that do not track which products already exist. An item whose
+// SKU is taken updates that product; any other item is created. Each item
+// is written on its own, exactly like a single create or update, so one
+// failing item does not affect the others. Results are returned in request
+// order.
+//
+// An update replaces the product's fields with the item's, except that an
+// omitted stock, unit, list or bundle field keeps the product's current
+// value.
+// Whether the product is active is never changed.
+func (s *productService) BulkUpsertProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.UpsertResult, error) {
+	if len(reqs) == 0 || len(reqs) > s.maxBatchSize {
+		return nil, fmt.Errorf("%w: a bulk upsert must list between 1 and %d products", ErrInvalidQuery, s.maxBatchSize)
+	}
+
+	results := make([]models.UpsertResult, 0, len(reqs))
+	counts := make(map[string]int)
+	firstBySKU := make(map[string]int)
+	for i, req := range reqs {
+		var result models.UpsertResult
+		if first, ok := firstBySKU[req.SKU]; ok && req.SKU != "" {
+			// Applying both would leave whichever came last, silently.
+			result = models.UpsertResult{Index: i, SKU: req.SKU, Status: models.UpsertFailed,
+				Error: fmt.Sprintf("product SKU %q repeats item %d", req.SKU, first)}
+		} else {
+			firstBySKU[req.SKU] = i
+			result = s.upsert(ctx, i, req)
+		}
+		counts[result.Status]++
+		results = append(results, result)
+	}
+
+	if !IsDryRun(ctx) {
+		s.logger.InfoContext(ctx, "bulk upsert",
+			"created", counts[models.UpsertCreated],
+			"updated", counts[models.UpsertUpdated],
+			"unchanged", counts[models.UpsertUnchanged],
+			"failed", counts[models.UpsertFailed],
+			"actor", auth.ActorID(ctx),
+		)
+	}
+	return results, nil
+}
+
+func (s *productService) upsert(ctx context.Context, index int, req models.CreateProductRequest) models.UpsertResult {
+	result := models.UpsertResult{Index: index, SKU: req.SKU}
+	failed := func(err error) models.UpsertResult {
+		result.Status = models.UpsertFailed
+		result.Error = err.Error()
+		return result
+	}
+
+	if req.SKU == "" {
+		return failed(fieldError("sku", "product SKU is required"))
+	}
+	existing, err := s.repo.GetBySKU(ctx, req.SKU)
+	if err != nil {
+		s.logger.ErrorContext(ctx, "upsert lookup failed", "sku", req.SKU, "error", err)
+		return failed(err)
+	}
+
+	if existing == nil {
+		product, err := s.CreateProduct(ctx, req)
+		if err != nil {
+			return failed(err)
+		}
+		result.ID, result.Status = product.ID, models.UpsertCreated
+		return result
+	}
+
+	before := *existing
+	update := upsertUpdate(req)
+	product, err := s.applyUpdate(ctx, existing, update)
+	if errors.Is(err, repository.ErrVersionConflict) {
+		product, err = s.UpdateProduct(ctx, existing.ID, update)
+	}
+	if err != nil {
+		return failed(err)
+	}
+	result.ID, result.Status = product.ID, models.UpsertUpdated
+	if len(models.Diff(&before, product)) == 0 {
+		result.Status = models.UpsertUnchanged
+	}
+	return result
+}
+
+// upsertUpdate turns an upsert item into the update of an existing product.
+func upsertUpdate(req models.CreateProductRequest) models.UpdateProductRequest {
+	update := models.UpdateProductRequest{
+		Name:        &req.Name,
+		Description: &req.Description,
+		Price:       &req.Price,
+		Category:    &req.Category,
+		Stock:       req.Stock,
+		WeightGrams: &req.WeightGrams,
+		LengthMM:    &req.LengthMM,
+		WidthMM:     &req.WidthMM,
+		HeightMM:    &req.HeightMM,
+	}
+	if req.Unit != "" {
+		update.Unit = &req.Unit
+	}
+	if req.Tags != nil {
+		update.Tags = &req.Tags
+	}
+	if req.Images != nil {
+		update.Images = &req.Images
+	}
+	if req.BundleItems != nil {
+		update.BundleItems = &req.BundleItems
+	}
+	return update
+}
@@ -0,0 +1,111 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func upsertRequest(sku, name string, price float64) models.CreateProductRequest {
+	return models.CreateProductRequest{Name: name, Price: price, Category: "electronics", SKU: sku}
+}
+
+func seedUpsert(t *testing.T, repo repository.ProductRepository) {
+	t.Helper()
+	stock := 3.0
+	product := models.NewProductWithID("known", models.CreateProductRequest{
+		Name: "Mouse", Price: 20, Category: "electronics", SKU: "ELEC-0001", Stock: &stock, Tags: []string{"wireless"},
+	}, models.SystemClock)
+	require.NoError(t, repo.Create(context.Background(), product))
+}
+
+func TestProductService_BulkUpsertProducts_UpdatesKnownAndCreatesNew(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedUpsert(t, repo)
+
+	results, err := service.BulkUpsertProducts(context.Background(), []models.CreateProductRequest{
+		upsertRequest("ELEC-0001", "Wireless Mouse", 24.99),
+		upsertRequest("ELEC-0002", "Keyboard", 49.99),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 2)
+	assert.Equal(t, models.UpsertResult{Index: 0, SKU: "ELEC-0001", ID: "known", Status: models.UpsertUpdated}, results[0])
+	assert.Equal(t, models.UpsertCreated, results[1].Status)
+	assert.NotEmpty(t, results[1].ID)
+
+	known, _ := repo.GetByID(context.Background(), "known")
+	assert.Equal(t, "Wireless Mouse", known.Name)
+	assert.Equal(t, 24.99, known.Price)
+	// Omitted stock and tags are kept.
+	assert.Equal(t, float64(3), known.Stock)
+	assert.Equal(t, []string{"wireless"}, known.Tags)
+
+	created, _ := repo.GetBySKU(context.Background(), "ELEC-0002")
+	require.NotNil(t, created)
+	assert.Equal(t, results[1].ID, created.ID)
+	assert.Equal(t, "Keyboard", created.Name)
+}
+
+func TestProductService_BulkUpsertProducts_KeepsOmittedUnit(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	stock := 2.5
+	require.NoError(t, repo.Create(context.Background(), models.NewProductWithID("rice", models.CreateProductRequest{
+		Name: "Rice", Price: 3, Category: "grocery", SKU: "GR-1", Stock: &stock, Unit: models.UnitKilogram,
+	}, models.SystemClock)))
+
+	results, err := service.BulkUpsertProducts(context.Background(), []models.CreateProductRequest{
+		{Name: "Basmati Rice", Price: 4, Category: "grocery", SKU: "GR-1"},
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, models.UpsertUpdated, results[0].Status, results[0].Error)
+	rice, _ := repo.GetByID(context.Background(), "rice")
+	assert.Equal(t, models.UnitKilogram, rice.Unit)
+	assert.Equal(t, 2.5, rice.Stock)
+}
+
+func TestProductService_BulkUpsertProducts_ItemFailures(t *testing.T) {
+	repo := repository.NewMemoryProductRepository()
+	service := NewProductService(repo)
+	seedUpsert(t, repo)
+
+	results, err := service.BulkUpsertProducts(context.Background(), []models.CreateProductRequest{
+		upsertRequest("ELEC-0001", "Mouse", 20),
+		upsertRequest("ELEC-0002", "Keyboard", -1),
+		upsertRequest("", "Cable", 5),
+		upsertRequest("ELEC-0003", "Monitor", 199),
+		upsertRequest("ELEC-0003", "Monitor 2", 249),
+	})
+
+	require.NoError(t, err)
+	require.Len(t, results, 5)
+	assert.Equal(t, models.UpsertUnchanged, results[0].Status)
+	assert.Equal(t, models.UpsertFailed, results[1].Status)
+	assert.Contains(t, results[1].Error, "price")
+	assert.Equal(t, models.UpsertFailed, results[2].Status)
+	assert.Equal(t, models.UpsertCreated, results[3].Status)
+	assert.Equal(t, models.UpsertFailed, results[4].Status)
+	assert.Contains(t, results[4].Error, "repeats item 3")
+
+	monitor, _ := repo.GetBySKU(context.Background(), "ELEC-0003")
+	require.NotNil(t, monitor)
+	assert.Equal(t, "Monitor", monitor.Name)
+}
+
+func TestProductService_BulkUpsertProducts_BatchSize(t *testing.T) {
+	service := NewProductService(repository.NewMemoryProductRepository())
+
+	_, err := service.BulkUpsertProducts(context.Background(), nil)
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+
+	_, err = service.BulkUpsertProducts(context.Background(), make([]models.CreateProductRequest, DefaultMaxBatchSize+1))
+	assert.ErrorIs(t, err, ErrInvalidQuery)
+}
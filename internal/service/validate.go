@@ -0,0 +1,71 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"product-service/internal/models"
+)
+
+// MaxValidateItems bounds how many products one ValidateProducts call
+// checks. Imports are validated ahead of time in larger batches than the
+// bulk mutations allow, since nothing is written.
+const MaxValidateItems = 1000
+
+// ValidateProducts reports, item by item, whether each request would pass
+// a create's validation, without reading or writing the repository. Items
+// that repeat an earlier item's SKU are also reported, since only the first
+// of them could be created.
+//
+// Checks that need the repository are not made: a SKU already taken by an
+// existing product is not detected, bundle items are not checked to refer
+// to existing products, and an item without a SKU in a category that
+// numbers its SKUs is taken to receive one.
+func (s *productService) ValidateProducts(ctx context.Context, reqs []models.CreateProductRequest) ([]models.ValidationResult, error) {
+	if len(reqs) == 0 || len(reqs) > MaxValidateItems {
+		return nil, fmt.Errorf("%w: a validation batch must list between 1 and %d products", ErrInvalidQuery, MaxValidateItems)
+	}
+
+	results := make([]models.ValidationResult, 0, len(reqs))
+	firstBySKU := make(map[string]int)
+	for i, req := range reqs {
+		result := models.ValidationResult{Index: i, SKU: req.SKU}
+		if err := s.validateImportItem(req); err != nil {
+			result.Errors = append(result.Errors, validationError(err))
+		}
+		if req.SKU != "" {
+			if first, ok := firstBySKU[req.SKU]; ok {
+				result.Errors = append(result.Errors, models.ValidationError{
+					Field:   "sku",
+					Message: fmt.Sprintf("product SKU %q repeats item %d", req.SKU, first),
+				})
+			} else {
+				firstBySKU[req.SKU] = i
+			}
+		}
+		result.Valid = len(result.Errors) == 0
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// validateImportItem runs a create's sanitization and validation on req.
+func (s *productService) validateImportItem(req models.CreateProductRequest) error {
+	if err := s.sanitizeCreateRequest(&req); err != nil {
+		return err
+	}
+	s.applyDefaultStock(&req)
+	if placeholder, ok := s.skuPlaceholder(req.Category); ok && req.SKU == "" {
+		req.SKU = placeholder
+	}
+	return s.validateCreateRequest(req)
+}
+
+func validationError(err error) models.ValidationError {
+	var fieldErr *FieldError
+	if errors.As(err, &fieldErr) {
+		return models.ValidationError{Field: fieldErr.Field, Message: fieldErr.Message}
+	}
+	return models.ValidationError{Message: err.Error()}
+}
@@ -0,0 +1,67 @@
+package service
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"product-service/internal/models"
+	"product-service/internal/repository"
+)
+
+func TestProductService_ValidateProducts_MixedBatch(t *testing.T) {
+	// The mock has no expectations, so any repository call fails the test.
+	mockRepo := new(MockProductRepository)
+	service := NewProductService(mockRepo, WithCategorySKUPrefix(map[string]string{"toys": "TOY-"}))
+
+	valid := models.CreateProductRequest{Name: "Kite", Price: 12, Category: "toys", SKU: "TOY-001", Stock: floatPtr(4)}
+	noPrice := valid
+	noPrice.SKU, noPrice.Price = "TOY-002", 0
+	badPrefix := valid
+	badPrefix.SKU = "KITE-003"
+	repeated := valid
+	repeated.Name = "Another kite"
+
+	results, err := service.ValidateProducts(context.Background(), []models.CreateProductRequest{valid, noPrice, badPrefix, repeated})
+
+	require.NoError(t, err)
+	assert.Equal(t, []models.ValidationResult{
+		{Index: 0, SKU: "TOY-001", Valid: true},
+		{Index: 1, SKU: "TOY-002", Errors: []models.ValidationError{{Field: "price", Message: "product price must be greater than 0"}}},
+		{Index: 2, SKU: "KITE-003", Errors: []models.ValidationError{{Field: "sku", Message: `product SKU must start with "TOY-" for category "toys"`}}},
+		{Index: 3, SKU: "TOY-001", Errors: []models.ValidationError{{Field: "sku", Message: `product SKU "TOY-001" repeats item 0`}}},
+	}, results)
+}
+
+func TestProductService_ValidateProducts_SequencedSKU(t *testing.T) {
+	sequences := repository.NewMemorySequenceRepository()
+	service := NewProductService(repository.NewMemoryProductRepository(),
+		WithCategorySKUPrefix(map[string]string{"toys": "TOY-"}),
+		WithSKUSequences(sequences))
+
+	results, err := service.ValidateProducts(context.Background(), []models.CreateProductRequest{
+		{Name: "Kite", Price: 12, Category: "toys"},
+		{Name: "Lamp", Price: 30, Category: "home"},
+	})
+
+	require.NoError(t, err)
+	assert.True(t, results[0].Valid)
+	assert.False(t, results[1].Valid)
+
+	// Validation does not draw numbers from the sequence.
+	n, err := sequences.Next(context.Background(), "toys")
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+}
+
+func TestProductService_ValidateProducts_BatchSize(t *testing.T) {
+	service := NewProductService(new(MockProductRepository))
+
+	for _, n := range []int{0, MaxValidateItems + 1} {
+		_, err := service.ValidateProducts(context.Background(), make([]models.CreateProductRequest, n))
+
+		assert.ErrorIs(t, err, ErrInvalidQuery, n)
+	}
+}
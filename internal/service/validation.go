@@ -0,0 +1,286 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"product-service/internal/models"
+	"product-service/internal/storage"
+)
+
+// FieldError describes a validation failure on a single request field. It is
+// wrapped together with ErrInvalidProduct so handlers can report the field.
+type FieldError struct {
+	Field   string
+	Message string
+}
+
+func (e *FieldError) Error() string {
+	return e.Message
+}
+
+func fieldError(field, format string, args ...any) *FieldError {
+	return &FieldError{Field: field, Message: fmt.Sprintf(format, args...)}
+}
+
+func (s *productService) validateCreateRequest(req models.CreateProductRequest) error {
+	if req.Name == "" {
+		return fieldError("name", "product name is required")
+	}
+	if err := s.validateText(req.Name, req.Description); err != nil {
+		return err
+	}
+	if err := validateFinite("price", req.Price); err != nil {
+		return err
+	}
+	if req.Price <= 0 {
+		return fieldError("price", "product price must be greater than 0")
+	}
+	if req.Category == "" {
+		return fieldError("category", "product category is required")
+	}
+	if err := s.validateCategory(req.Category); err != nil {
+		return err
+	}
+	if err := s.validateMinPrice(req.Price, req.Category); err != nil {
+		return err
+	}
+	if req.SKU == "" {
+		return fieldError("sku", "product SKU is required")
+	}
+	if err := s.validateSKUPrefix(req.SKU, req.Category); err != nil {
+		return err
+	}
+	stock := req.StockOrZero()
+	if err := validateFinite("stock", stock); err != nil {
+		return err
+	}
+	if stock < 0 {
+		return fieldError("stock", "product stock cannot be negative")
+	}
+	if err := s.validateMaxStock(stock); err != nil {
+		return err
+	}
+	if !models.IsValidUnit(req.Unit) {
+		return fieldError("unit", "product unit %q is not supported", req.Unit)
+	}
+	if !models.IsValidStockForUnit(stock, req.Unit) {
+		return fieldError("stock", "product stock must be a whole number for unit %q", models.NormalizeUnit(req.Unit))
+	}
+	if field := models.NegativeShippingAttribute(&req.WeightGrams, &req.LengthMM, &req.WidthMM, &req.HeightMM); field != "" {
+		return fieldError(field, "product %s cannot be negative", field)
+	}
+	if err := validateBundleItems(req.BundleItems); err != nil {
+		return err
+	}
+	if err := s.validateAvailableFrom(req.AvailableFrom); err != nil {
+		return err
+	}
+	return s.validateTags(req.Tags)
+}
+
+// MaxAvailableFromAge is how far in the past a create may date a product's
+// AvailableFrom. Older dates are more likely typos than back-dated releases.
+const MaxAvailableFromAge = 30 * 24 * time.Hour
+
+// validateAvailableFrom rejects an AvailableFrom more than
+// MaxAvailableFromAge in the past.
+func (s *productService) validateAvailableFrom(availableFrom *time.Time) error {
+	if availableFrom == nil {
+		return nil
+	}
+	if earliest := s.clock.Now().Add(-MaxAvailableFromAge); availableFrom.Before(earliest) {
+		return fieldError("available_from", "product available_from cannot be before %s", earliest.UTC().Format(time.DateOnly))
+	}
+	return nil
+}
+
+func (s *productService) validateUpdateRequest(req models.UpdateProductRequest) error {
+	if req.Price != nil {
+		if err := validateFinite("price", *req.Price); err != nil {
+			return err
+		}
+	}
+	if req.Stock != nil {
+		if err := validateFinite("stock", *req.Stock); err != nil {
+			return err
+		}
+	}
+	if req.Price != nil && *req.Price <= 0 {
+		return fieldError("price", "product price must be greater than 0")
+	}
+	if req.Stock != nil && *req.Stock < 0 {
+		return fieldError("stock", "product stock cannot be negative")
+	}
+	if req.Stock != nil {
+		if err := s.validateMaxStock(*req.Stock); err != nil {
+			return err
+		}
+	}
+	if req.Name != nil && *req.Name == "" {
+		return fieldError("name", "product name cannot be empty")
+	}
+	if req.Name != nil {
+		if err := s.validateNameLength(*req.Name); err != nil {
+			return err
+		}
+	}
+	if req.Description != nil {
+		if err := s.validateDescriptionLength(*req.Description); err != nil {
+			return err
+		}
+	}
+	if req.Category != nil && *req.Category == "" {
+		return fieldError("category", "product category cannot be empty")
+	}
+	if req.SKU != nil && *req.SKU == "" {
+		return fieldError("sku", "product SKU cannot be empty")
+	}
+	if req.Unit != nil && !models.IsValidUnit(*req.Unit) {
+		return fieldError("unit", "product unit %q is not supported", *req.Unit)
+	}
+	if field := models.NegativeShippingAttribute(req.WeightGrams, req.LengthMM, req.WidthMM, req.HeightMM); field != "" {
+		return fieldError(field, "product %s cannot be negative", field)
+	}
+	if req.BundleItems != nil {
+		if err := validateBundleItems(*req.BundleItems); err != nil {
+			return err
+		}
+	}
+	if req.Tags != nil {
+		return s.validateTags(*req.Tags)
+	}
+	return nil
+}
+
+// validateText checks the length limits of a name and description. Lengths
+// are counted in characters, not bytes.
+func (s *productService) validateText(name, description string) error {
+	if err := s.validateNameLength(name); err != nil {
+		return err
+	}
+	return s.validateDescriptionLength(description)
+}
+
+func (s *productService) validateNameLength(name string) error {
+	if s.minNameLen > 0 && utf8.RuneCountInString(name) < s.minNameLen {
+		return fieldError("name", "product name must be at least %d characters", s.minNameLen)
+	}
+	return nil
+}
+
+func (s *productService) validateDescriptionLength(description string) error {
+	if s.maxDescriptionLen > 0 && utf8.RuneCountInString(description) > s.maxDescriptionLen {
+		return fieldError("description", "product description cannot exceed %d characters", s.maxDescriptionLen)
+	}
+	return nil
+}
+
+// validateFinite rejects NaN and infinities, which the range checks miss:
+// NaN fails every comparison and +Inf passes every lower bound.
+func validateFinite(field string, v float64) error {
+	if math.IsNaN(v) || math.IsInf(v, 0) {
+		return fieldError(field, "product %s must be a finite number", field)
+	}
+	return nil
+}
+
+func (s *productService) validateMaxStock(stock float64) error {
+	if s.maxStock > 0 && stock > s.maxStock {
+		return fieldError("stock", "product stock cannot exceed %g", s.maxStock)
+	}
+	return nil
+}
+
+// validateCategory rejects categories the taxonomy does not place, so every
+// product's recorded path follows the configured hierarchy. Any category is
+// accepted while the taxonomy is flat.
+func (s *productService) validateCategory(category string) error {
+	if !s.taxonomy.IsFlat() && !s.taxonomy.Contains(category) {
+		return fieldError("category", "product category %q is not in the category taxonomy", category)
+	}
+	return nil
+}
+
+func (s *productService) validateMinPrice(price float64, category string) error {
+	if floor, ok := s.minPrices[category]; ok && price < floor {
+		return fieldError("price", "product price must be at least %g for category %q", floor, category)
+	}
+	return nil
+}
+
+// validateCategoryPrice checks the price against the floor of the category
+// the product would have after applying req, so recategorizing a product is
+// held to the new category's floor.
+func (s *productService) validateCategoryPrice(product *models.Product, req models.UpdateProductRequest) error {
+	if req.Price == nil && req.Category == nil {
+		return nil
+	}
+	price, category := product.Price, product.Category
+	if req.Price != nil {
+		price = *req.Price
+	}
+	if req.Category != nil {
+		category = *req.Category
+	}
+	return s.validateMinPrice(price, category)
+}
+
+func (s *productService) validateSKUPrefix(sku, category string) error {
+	if prefix, ok := s.skuPrefixes[category]; ok && !strings.HasPrefix(sku, prefix) {
+		return fieldError("sku", "product SKU must start with %q for category %q", prefix, category)
+	}
+	return nil
+}
+
+// validateCategorySKU checks the SKU against the prefix of the category the
+// product would have after applying req, so recategorizing a product is held
+// to the new category's convention.
+func (s *productService) validateCategorySKU(product *models.Product, req models.UpdateProductRequest) error {
+	if req.SKU == nil && req.Category == nil {
+		return nil
+	}
+	sku, category := product.SKU, product.Category
+	if req.SKU != nil {
+		sku = *req.SKU
+	}
+	if req.Category != nil {
+		category = *req.Category
+	}
+	return s.validateSKUPrefix(sku, category)
+}
+
+// validateStockUnit checks the stock and unit the product would have after
+// applying req, since either may change independently.
+func validateStockUnit(product *models.Product, req models.UpdateProductRequest) error {
+	stock, unit := product.Stock, product.Unit
+	if req.Stock != nil {
+		stock = *req.Stock
+	}
+	if req.Unit != nil {
+		unit = *req.Unit
+	}
+	if !models.IsValidStockForUnit(stock, unit) {
+		return fieldError("stock", "product stock must be a whole number for unit %q", models.NormalizeUnit(unit))
+	}
+	return nil
+}
+
+// validateImageKeys rejects images of the product with id that are neither
+// URLs nor image store keys under the product's own prefix, so a product
+// cannot be made to serve signed URLs for objects it does not own. Keys in
+// kept are already on the product and are accepted as they are.
+func validateImageKeys(id string, images, kept []string) error {
+	prefix := storage.ProductImagePrefix(id)
+	for _, image := range images {
+		if isImageURL(image) || strings.HasPrefix(image, prefix) || slices.Contains(kept, image) {
+			continue
+		}
+		return fieldError("images", "product image %q must be a URL or an uploaded image key under %q", image, prefix)
+	}
+	return nil
+}
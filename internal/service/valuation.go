@@ -0,0 +1,77 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"math/big"
+
+	"product-service/internal/models"
+)
+
+// valuationPrecision is the mantissa size used while summing price × stock.
+// Products are accumulated in big.Float so large catalogs neither lose cents
+// to float64 rounding nor overflow before the final conversion.
+const valuationPrecision = 128
+
+// GetInventoryValuation returns the total inventory value (price × stock) of
+// all active products, overall and per category.
+func (s *productService) GetInventoryValuation(ctx context.Context) (*models.InventoryValuation, error) {
+	list, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get products for valuation: %w", err)
+	}
+
+	total := newValuationSum()
+	categories := make(map[string]*big.Float)
+	counts := make(map[string]int)
+	for _, product := range list.Products {
+		value := new(big.Float).SetPrec(valuationPrecision).SetFloat64(product.Price)
+		value.Mul(value, big.NewFloat(product.Stock))
+
+		total.Add(total, value)
+		sum, ok := categories[product.Category]
+		if !ok {
+			sum = newValuationSum()
+			categories[product.Category] = sum
+		}
+		sum.Add(sum, value)
+		counts[product.Category]++
+	}
+
+	valuation := &models.InventoryValuation{
+		Currency:     s.currency.Default,
+		ProductCount: len(list.Products),
+		Categories:   make(map[string]models.CategoryValuation, len(categories)),
+		Truncated:    list.Truncated,
+	}
+	if valuation.Total, err = s.valuationAmount(total); err != nil {
+		return nil, err
+	}
+	for category, sum := range categories {
+		amount, err := s.valuationAmount(sum)
+		if err != nil {
+			return nil, fmt.Errorf("category %q: %w", category, err)
+		}
+		valuation.Categories[category] = models.CategoryValuation{
+			Total:        amount,
+			ProductCount: counts[category],
+		}
+	}
+	return valuation, nil
+}
+
+func newValuationSum() *big.Float {
+	return new(big.Float).SetPrec(valuationPrecision)
+}
+
+// valuationAmount converts sum for the response, rounded half away from
+// zero to the currency's minor unit, failing rather than reporting an
+// infinite total.
+func (s *productService) valuationAmount(sum *big.Float) (float64, error) {
+	amount, _ := sum.Float64()
+	if math.IsInf(amount, 0) {
+		return 0, fmt.Errorf("inventory valuation exceeds the representable range")
+	}
+	return s.currency.Round(amount, ""), nil
+}
@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/aws/aws-sdk-go/aws"
+	"github.com/aws/aws-sdk-go/aws/session"
+	"github.com/aws/aws-sdk-go/service/s3"
+)
+
+// DefaultURLExpiry is how long a signed image URL stays valid when no expiry
+// is configured.
+const DefaultURLExpiry = 15 * time.Minute
+
+// imageExtensions maps the accepted image content types to the file
+// extension used in their keys.
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+	"image/webp": ".webp",
+}
+
+// ImageExtension returns the key extension for an image content type, and
+// false when the content type is not an accepted image format.
+func ImageExtension(contentType string) (string, bool) {
+	ext, ok := imageExtensions[contentType]
+	return ext, ok
+}
+
+// ProductImagePrefix is the key prefix every image of the product with
+// productID is stored under. Keys outside it belong to no product.
+func ProductImagePrefix(productID string) string {
+	return "products/" + productID + "/"
+}
+
+// ImageStore keeps product images and resolves their keys to URLs clients
+// can fetch.
+type ImageStore interface {
+	Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error
+	SignedURL(ctx context.Context, key string) (string, error)
+}
+
+// S3API is the subset of S3 used by the image store. It is satisfied by
+// NewS3API and can be mocked in tests.
+type S3API interface {
+	PutObject(ctx context.Context, bucket, key string, body io.ReadSeeker, contentType string) error
+	PresignGetObject(bucket, key string, expiry time.Duration) (string, error)
+}
+
+// S3ImageStore serves images from a private bucket through time-limited
+// presigned URLs.
+type S3ImageStore struct {
+	bucket string
+	expiry time.Duration
+	client S3API
+}
+
+// NewS3ImageStore returns a store for bucket whose URLs expire after expiry,
+// or DefaultURLExpiry when expiry is not positive.
+func NewS3ImageStore(bucket string, expiry time.Duration, client S3API) *S3ImageStore {
+	if expiry <= 0 {
+		expiry = DefaultURLExpiry
+	}
+	return &S3ImageStore{
+		bucket: bucket,
+		expiry: expiry,
+		client: client,
+	}
+}
+
+func (s *S3ImageStore) Put(ctx context.Context, key string, body io.ReadSeeker, contentType string) error {
+	if err := s.client.PutObject(ctx, s.bucket, key, body, contentType); err != nil {
+		return fmt.Errorf("failed to store image %q: %w", key, err)
+	}
+	return nil
+}
+
+func (s *S3ImageStore) SignedURL(ctx context.Context, key string) (string, error) {
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+	url, err := s.client.PresignGetObject(s.bucket, key, s.expiry)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign image %q: %w", key, err)
+	}
+	return url, nil
+}
+
+type s3API struct {
+	client *s3.S3
+}
+
+// NewS3API returns an S3 client using the default AWS credential chain.
+func NewS3API() (S3API, error) {
+	region := os.Getenv("AWS_REGION")
+	if region == "" {
+		region = "us-east-1"
+	}
+
+	cfg := &aws.Config{
+		Region: aws.String(region),
+	}
+
+	// AWS_ENDPOINT_URL points the client at a local S3 during development.
+	if endpoint := os.Getenv("AWS_ENDPOINT_URL"); endpoint != "" {
+		cfg.Endpoint = aws.String(endpoint)
+		cfg.S3ForcePathStyle = aws.Bool(true)
+	}
+
+	sess, err := session.NewSession(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create AWS session: %w", err)
+	}
+
+	return &s3API{client: s3.New(sess)}, nil
+}
+
+func (a *s3API) PutObject(ctx context.Context, bucket, key string, body io.ReadSeeker, contentType string) error {
+	_, err := a.client.PutObjectWithContext(ctx, &s3.PutObjectInput{
+		Bucket:      aws.String(bucket),
+		Key:         aws.String(key),
+		Body:        body,
+		ContentType: aws.String(contentType),
+	})
+	return err
+}
+
+// PresignGetObject signs locally; no request is sent to S3.
+func (a *s3API) PresignGetObject(bucket, key string, expiry time.Duration) (string, error) {
+	req, _ := a.client.GetObjectRequest(&s3.GetObjectInput{
+		Bucket: aws.String(bucket),
+		Key:    aws.String(key),
+	})
+	return req.Presign(expiry)
+}
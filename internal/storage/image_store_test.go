@@ -0,0 +1,86 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type MockS3API struct {
+	mock.Mock
+}
+
+func (m *MockS3API) PutObject(ctx context.Context, bucket, key string, body io.ReadSeeker, contentType string) error {
+	args := m.Called(bucket, key, contentType)
+	return args.Error(0)
+}
+
+func (m *MockS3API) PresignGetObject(bucket, key string, expiry time.Duration) (string, error) {
+	args := m.Called(bucket, key, expiry)
+	return args.String(0), args.Error(1)
+}
+
+func TestS3ImageStore_SignedURL(t *testing.T) {
+	client := new(MockS3API)
+	store := NewS3ImageStore("product-images", 5*time.Minute, client)
+
+	client.On("PresignGetObject", "product-images", "products/p1/front.jpg", 5*time.Minute).
+		Return("https://product-images.s3.amazonaws.com/products/p1/front.jpg?X-Amz-Expires=300", nil)
+
+	url, err := store.SignedURL(context.Background(), "products/p1/front.jpg")
+
+	require.NoError(t, err)
+	assert.Contains(t, url, "X-Amz-Expires=300")
+	client.AssertExpectations(t)
+}
+
+func TestS3ImageStore_DefaultExpiry(t *testing.T) {
+	client := new(MockS3API)
+	store := NewS3ImageStore("product-images", 0, client)
+
+	client.On("PresignGetObject", "product-images", "a.png", DefaultURLExpiry).Return("https://signed", nil)
+
+	_, err := store.SignedURL(context.Background(), "a.png")
+
+	require.NoError(t, err)
+	client.AssertExpectations(t)
+}
+
+func TestS3ImageStore_SignFails(t *testing.T) {
+	client := new(MockS3API)
+	store := NewS3ImageStore("product-images", time.Minute, client)
+
+	client.On("PresignGetObject", "product-images", "a.png", time.Minute).Return("", errors.New("no credentials"))
+
+	_, err := store.SignedURL(context.Background(), "a.png")
+
+	assert.ErrorContains(t, err, "no credentials")
+}
+
+func TestS3ImageStore_Put(t *testing.T) {
+	client := new(MockS3API)
+	store := NewS3ImageStore("product-images", time.Minute, client)
+
+	client.On("PutObject", "product-images", "products/p1/a.png", "image/png").Return(nil).Once()
+	client.On("PutObject", "product-images", "products/p1/b.png", "image/png").Return(errors.New("access denied")).Once()
+
+	require.NoError(t, store.Put(context.Background(), "products/p1/a.png", strings.NewReader("png"), "image/png"))
+	assert.ErrorContains(t, store.Put(context.Background(), "products/p1/b.png", strings.NewReader("png"), "image/png"), "access denied")
+	client.AssertExpectations(t)
+}
+
+func TestImageExtension(t *testing.T) {
+	ext, ok := ImageExtension("image/jpeg")
+	assert.True(t, ok)
+	assert.Equal(t, ".jpg", ext)
+
+	_, ok = ImageExtension("application/pdf")
+	assert.False(t, ok)
+}
@@ -1,3 +1,3 @@
 package version
 
-var Version = "v0.1.0"
\ No newline at end of file
+var Version = "v0.1.0"
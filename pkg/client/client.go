@@ -0,0 +1,273 @@
+// Package client is a typed Go client for the product service HTTP API.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Defaults used when no option overrides them.
+const (
+	DefaultTimeout    = 10 * time.Second
+	DefaultMaxRetries = 2
+	DefaultRetryDelay = 100 * time.Millisecond
+)
+
+// maxErrorBody bounds how much of an error response is read.
+const maxErrorBody = 64 << 10
+
+// Client calls the product service. It is safe for concurrent use.
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	timeout    time.Duration
+	userID     string
+	token      string
+
+	maxRetries int
+	retryDelay time.Duration
+}
+
+// Option configures optional Client behavior.
+type Option func(*Client)
+
+// WithHTTPClient replaces http.DefaultClient, e.g. to customize the
+// transport.
+func WithHTTPClient(httpClient *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = httpClient
+	}
+}
+
+// WithTimeout bounds each attempt of a call, including reading the
+// response. It defaults to DefaultTimeout; zero disables it.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithUserID sends X-User-ID so changes are attributed to the caller.
+func WithUserID(id string) Option {
+	return func(c *Client) {
+		c.userID = id
+	}
+}
+
+// WithToken sends the token as an "Authorization: Bearer" header.
+func WithToken(token string) Option {
+	return func(c *Client) {
+		c.token = token
+	}
+}
+
+// WithRetries sets how many times an idempotent call is retried after a 5xx
+// response or a transport error, and the delay before the first retry,
+// which doubles on each further one. Zero retries disables retrying.
+func WithRetries(maxRetries int, delay time.Duration) Option {
+	return func(c *Client) {
+		c.maxRetries = maxRetries
+		c.retryDelay = delay
+	}
+}
+
+// New returns a client for the service at baseURL, e.g.
+// "https://products.internal".
+func New(baseURL string, opts ...Option) (*Client, error) {
+	u, err := url.Parse(baseURL)
+	if err != nil || u.Scheme == "" || u.Host == "" {
+		return nil, fmt.Errorf("invalid base URL %q: must be an absolute URL", baseURL)
+	}
+	c := &Client{
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		httpClient: http.DefaultClient,
+		timeout:    DefaultTimeout,
+		maxRetries: DefaultMaxRetries,
+		retryDelay: DefaultRetryDelay,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c, nil
+}
+
+func (c *Client) CreateProduct(ctx context.Context, req CreateProductRequest) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodPost, "/api/v1/products", nil, req, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (c *Client) GetProduct(ctx context.Context, id string) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodGet, productPath(id), nil, nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func (c *Client) GetProductBySlug(ctx context.Context, slug string) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodGet, "/api/v1/products/slug/"+url.PathEscape(slug), nil, nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// ListProducts returns one page of active products.
+func (c *Client) ListProducts(ctx context.Context, opts ListOptions) (*ProductList, error) {
+	query := url.Values{}
+	if opts.Sort != "" {
+		query.Set("sort", opts.Sort)
+	}
+	if opts.Limit > 0 {
+		query.Set("limit", strconv.Itoa(opts.Limit))
+	}
+	if opts.Cursor != "" {
+		query.Set("cursor", opts.Cursor)
+	}
+	var list ProductList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/products", query, nil, &list); err != nil {
+		return nil, err
+	}
+	return &list, nil
+}
+
+func (c *Client) UpdateProduct(ctx context.Context, id string, req UpdateProductRequest) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodPut, productPath(id), nil, req, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+// DeleteProduct removes a product, or with soft set marks it deleted so it
+// can be restored.
+func (c *Client) DeleteProduct(ctx context.Context, id string, soft bool) error {
+	var query url.Values
+	if soft {
+		query = url.Values{"soft": {"true"}}
+	}
+	return c.do(ctx, http.MethodDelete, productPath(id), query, nil, nil)
+}
+
+func (c *Client) RestoreProduct(ctx context.Context, id string) (*Product, error) {
+	var product Product
+	if err := c.do(ctx, http.MethodPost, productPath(id)+"/restore", nil, nil, &product); err != nil {
+		return nil, err
+	}
+	return &product, nil
+}
+
+func productPath(id string) string {
+	return "/api/v1/products/" + url.PathEscape(id)
+}
+
+// do sends a request and decodes a successful response into out, retrying
+// idempotent requests that fail with a 5xx or a transport error.
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, in, out any) error {
+	var body []byte
+	if in != nil {
+		var err error
+		if body, err = json.Marshal(in); err != nil {
+			return fmt.Errorf("encode request: %w", err)
+		}
+	}
+	target := c.baseURL + path
+	if len(query) > 0 {
+		target += "?" + query.Encode()
+	}
+
+	retries := 0
+	if isIdempotent(method) {
+		retries = c.maxRetries
+	}
+	delay := c.retryDelay
+	for attempt := 0; ; attempt++ {
+		retry, err := c.attempt(ctx, method, target, body, out)
+		if !retry || attempt >= retries {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(delay):
+		}
+		delay *= 2
+	}
+}
+
+// attempt makes one request and reports whether a failure is worth
+// retrying.
+func (c *Client) attempt(ctx context.Context, method, target string, body []byte, out any) (bool, error) {
+	parent := ctx
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, target, reader)
+	if err != nil {
+		return false, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if c.userID != "" {
+		req.Header.Set("X-User-ID", c.userID)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return parent.Err() == nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return resp.StatusCode >= 500, decodeError(method, resp)
+	}
+	if out == nil {
+		_, _ = io.Copy(io.Discard, resp.Body)
+		return false, nil
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return false, fmt.Errorf("decode response: %w", err)
+	}
+	return false, nil
+}
+
+func decodeError(method string, resp *http.Response) error {
+	apiErr := &APIError{StatusCode: resp.StatusCode}
+	raw, _ := io.ReadAll(io.LimitReader(resp.Body, maxErrorBody))
+	if err := json.Unmarshal(raw, apiErr); err != nil {
+		apiErr.Message = strings.TrimSpace(string(raw))
+	}
+	apiErr.classify(method)
+	return apiErr
+}
+
+func isIdempotent(method string) bool {
+	switch method {
+	case http.MethodGet, http.MethodHead, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,302 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestClient(t *testing.T, handler http.HandlerFunc, opts ...Option) *Client {
+	t.Helper()
+	server := httptest.NewServer(handler)
+	t.Cleanup(server.Close)
+	opts = append([]Option{WithRetries(DefaultMaxRetries, time.Millisecond)}, opts...)
+	c, err := New(server.URL, opts...)
+	require.NoError(t, err)
+	return c
+}
+
+func writeJSON(w http.ResponseWriter, status int, body any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(body)
+}
+
+func TestClient_CreateProduct(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, "/api/v1/products", r.URL.Path)
+		assert.Equal(t, "user-42", r.Header.Get("X-User-ID"))
+		assert.Equal(t, "Bearer t0ken", r.Header.Get("Authorization"))
+
+		var req CreateProductRequest
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&req))
+		writeJSON(w, http.StatusCreated, map[string]any{
+			"id": "p1", "name": req.Name, "price": req.Price, "sku": req.SKU, "is_active": true,
+		})
+	}, WithUserID("user-42"), WithToken("t0ken"))
+
+	stock := 3.0
+	product, err := c.CreateProduct(context.Background(), CreateProductRequest{
+		Name: "Lamp", Price: 20, Category: "home", SKU: "LAMP-1", Stock: &stock,
+	})
+
+	require.NoError(t, err)
+	assert.Equal(t, "p1", product.ID)
+	assert.Equal(t, "Lamp", product.Name)
+	assert.Equal(t, 20.0, product.Price)
+	assert.True(t, product.IsActive)
+}
+
+func TestClient_ListProducts(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "2", r.URL.Query().Get("limit"))
+		assert.Equal(t, "abc", r.URL.Query().Get("cursor"))
+		writeJSON(w, http.StatusOK, map[string]any{
+			"products":   []map[string]any{{"id": "a"}, {"id": "b"}},
+			"count":      2,
+			"pagination": map[string]any{"limit": 2, "returned": 2, "has_more": true, "next_cursor": "def"},
+		})
+	})
+
+	list, err := c.ListProducts(context.Background(), ListOptions{Limit: 2, Cursor: "abc"})
+
+	require.NoError(t, err)
+	require.Len(t, list.Products, 2)
+	assert.True(t, list.Pagination.HasMore)
+	assert.Equal(t, "def", list.Pagination.NextCursor)
+}
+
+func TestClient_ErrorMapping(t *testing.T) {
+	tests := []struct {
+		name   string
+		status int
+		body   map[string]any
+		call   func(c *Client) error
+		want   error
+	}{
+		{
+			name:   "not found",
+			status: http.StatusNotFound,
+			body:   map[string]any{"error": "Product not found", "code": "PRODUCT_NOT_FOUND", "resource": "product", "id": "missing"},
+			call: func(c *Client) error {
+				_, err := c.GetProduct(context.Background(), "missing")
+				return err
+			},
+			want: ErrProductNotFound,
+		},
+		{
+			name:   "invalid product",
+			status: http.StatusBadRequest,
+			body:   map[string]any{"error": "Invalid product data", "code": "INVALID_PRODUCT", "details": "product price must be greater than 0", "field": "price"},
+			call: func(c *Client) error {
+				_, err := c.CreateProduct(context.Background(), CreateProductRequest{})
+				return err
+			},
+			want: ErrInvalidProduct,
+		},
+		{
+			name:   "invalid query",
+			status: http.StatusBadRequest,
+			body:   map[string]any{"error": "Invalid query", "details": "limit: invalid syntax"},
+			call: func(c *Client) error {
+				_, err := c.ListProducts(context.Background(), ListOptions{})
+				return err
+			},
+			want: ErrInvalidQuery,
+		},
+		{
+			name:   "precondition failed",
+			status: http.StatusPreconditionFailed,
+			body:   map[string]any{"error": "Product was modified since the If-Unmodified-Since time"},
+			call: func(c *Client) error {
+				_, err := c.UpdateProduct(context.Background(), "p1", UpdateProductRequest{})
+				return err
+			},
+			want: ErrPreconditionFailed,
+		},
+		{
+			name:   "invalid request body",
+			status: http.StatusBadRequest,
+			body:   map[string]any{"error": "Invalid request body", "details": "unexpected EOF"},
+			call: func(c *Client) error {
+				_, err := c.CreateProduct(context.Background(), CreateProductRequest{})
+				return err
+			},
+			want: ErrInvalidQuery,
+		},
+		{
+			name:   "duplicate SKU",
+			status: http.StatusConflict,
+			body:   map[string]any{"error": "Duplicate SKU", "code": "DUPLICATE_SKU", "existing_id": "p0"},
+			call: func(c *Client) error {
+				_, err := c.CreateProduct(context.Background(), CreateProductRequest{})
+				return err
+			},
+			want: ErrDuplicateSKU,
+		},
+		{
+			name:   "hard delete disabled",
+			status: http.StatusForbidden,
+			body:   map[string]any{"error": "Hard delete is disabled; use soft=true"},
+			call: func(c *Client) error {
+				return c.DeleteProduct(context.Background(), "p1", false)
+			},
+			want: ErrHardDeleteDisabled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, tt.status, tt.body)
+			})
+
+			err := tt.call(c)
+
+			assert.ErrorIs(t, err, tt.want)
+			var apiErr *APIError
+			require.ErrorAs(t, err, &apiErr)
+			assert.Equal(t, tt.status, apiErr.StatusCode)
+			assert.Equal(t, tt.body["error"], apiErr.Message)
+			if code, ok := tt.body["code"]; ok {
+				assert.Equal(t, code, apiErr.Code)
+			}
+			if id, ok := tt.body["id"]; ok {
+				assert.Equal(t, id, apiErr.ID)
+			}
+		})
+	}
+}
+
+func TestClient_ConflictCodes(t *testing.T) {
+	for code, want := range map[string]error{
+		"CONCURRENT_MODIFICATION": ErrConcurrentModification,
+		"INSUFFICIENT_STOCK":      ErrInsufficientStock,
+		"DUPLICATE_SKU":           ErrDuplicateSKU,
+		"PATCH_TEST_FAILED":       ErrConflict,
+	} {
+		t.Run(code, func(t *testing.T) {
+			c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+				writeJSON(w, http.StatusConflict, map[string]any{"error": "Conflict", "code": code})
+			})
+
+			_, err := c.GetProduct(context.Background(), "p1")
+
+			assert.ErrorIs(t, err, want)
+			assert.ErrorIs(t, err, ErrConflict)
+		})
+	}
+}
+
+func TestClient_CreateProduct_OmitsUnsetStock(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&body))
+		assert.NotContains(t, body, "stock")
+		writeJSON(w, http.StatusCreated, map[string]any{"id": "p1", "stock": 10})
+	})
+
+	product, err := c.CreateProduct(context.Background(), CreateProductRequest{Name: "Lamp"})
+
+	require.NoError(t, err)
+	assert.Equal(t, 10.0, product.Stock)
+}
+
+func TestClient_ErrorField(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusBadRequest, map[string]any{
+			"error": "Invalid product data", "details": "product SKU is required", "field": "sku",
+		})
+	})
+
+	_, err := c.CreateProduct(context.Background(), CreateProductRequest{})
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, "sku", apiErr.Field)
+	assert.Contains(t, err.Error(), "product SKU is required")
+}
+
+func TestClient_RetriesServerErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		if calls.Add(1) < 3 {
+			writeJSON(w, http.StatusServiceUnavailable, map[string]any{"error": "unavailable"})
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]any{"id": "p1"})
+	})
+
+	product, err := c.GetProduct(context.Background(), "p1")
+
+	require.NoError(t, err)
+	assert.Equal(t, "p1", product.ID)
+	assert.Equal(t, int32(3), calls.Load())
+}
+
+func TestClient_RetriesExhausted(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Failed to get product"})
+	})
+
+	_, err := c.GetProduct(context.Background(), "p1")
+
+	var apiErr *APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, http.StatusInternalServerError, apiErr.StatusCode)
+	assert.Equal(t, int32(DefaultMaxRetries+1), calls.Load())
+}
+
+func TestClient_DoesNotRetryCreate(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": "Failed to create product"})
+	})
+
+	_, err := c.CreateProduct(context.Background(), CreateProductRequest{Name: "Lamp"})
+
+	assert.Error(t, err)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_DoesNotRetryClientErrors(t *testing.T) {
+	var calls atomic.Int32
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		calls.Add(1)
+		writeJSON(w, http.StatusNotFound, map[string]any{"error": "Product not found"})
+	})
+
+	_, err := c.GetProduct(context.Background(), "p1")
+
+	assert.ErrorIs(t, err, ErrProductNotFound)
+	assert.Equal(t, int32(1), calls.Load())
+}
+
+func TestClient_Timeout(t *testing.T) {
+	c := newTestClient(t, func(w http.ResponseWriter, r *http.Request) {
+		select {
+		case <-r.Context().Done():
+		case <-time.After(time.Second):
+		}
+	}, WithTimeout(20*time.Millisecond), WithRetries(0, 0))
+
+	_, err := c.GetProduct(context.Background(), "p1")
+
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestNew_InvalidBaseURL(t *testing.T) {
+	_, err := New("products.internal")
+	assert.Error(t, err)
+}
@@ -0,0 +1,98 @@
+package client
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Errors mirroring the service's sentinels. Errors returned by Client wrap
+// one of them when the response's code, or failing that its status,
+// identifies it, so callers can test with errors.Is.
+var (
+	ErrProductNotFound     = errors.New("product not found")
+	ErrInvalidProduct      = errors.New("invalid product data")
+	ErrInvalidQuery        = errors.New("invalid query")
+	ErrPreconditionFailed  = errors.New("precondition failed")
+	ErrConflict            = errors.New("conflict")
+	ErrHardDeleteDisabled  = errors.New("hard delete is disabled")
+	ErrUnauthorized        = errors.New("unauthorized")
+	ErrReservationNotFound = errors.New("reservation not found")
+)
+
+// The 409 responses the API tells apart by code. Each also matches
+// ErrConflict.
+var (
+	ErrConcurrentModification = fmt.Errorf("concurrent modification: %w", ErrConflict)
+	ErrInsufficientStock      = fmt.Errorf("insufficient stock: %w", ErrConflict)
+	ErrDuplicateSKU           = fmt.Errorf("duplicate SKU: %w", ErrConflict)
+)
+
+// codeSentinels maps the codes listed by GET /errors to their sentinels.
+var codeSentinels = map[string]error{
+	"PRODUCT_NOT_FOUND":       ErrProductNotFound,
+	"INVALID_PRODUCT":         ErrInvalidProduct,
+	"INVALID_QUERY":           ErrInvalidQuery,
+	"PRECONDITION_FAILED":     ErrPreconditionFailed,
+	"CONCURRENT_MODIFICATION": ErrConcurrentModification,
+	"HARD_DELETE_DISABLED":    ErrHardDeleteDisabled,
+	"INSUFFICIENT_STOCK":      ErrInsufficientStock,
+	"DUPLICATE_SKU":           ErrDuplicateSKU,
+	"RESERVATION_NOT_FOUND":   ErrReservationNotFound,
+}
+
+// APIError is a non-2xx response from the API. Field names the offending
+// request field for validation failures, and ExistingID the product that
+// already has the SKU for a duplicate SKU conflict.
+type APIError struct {
+	StatusCode int    `json:"-"`
+	Message    string `json:"error"`
+	Details    string `json:"details,omitempty"`
+	Field      string `json:"field,omitempty"`
+	Code       string `json:"code,omitempty"`
+	ID         string `json:"id,omitempty"`
+	ExistingID string `json:"existing_id,omitempty"`
+
+	sentinel error
+}
+
+func (e *APIError) Error() string {
+	msg := e.Message
+	if msg == "" {
+		msg = http.StatusText(e.StatusCode)
+	}
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	return fmt.Sprintf("product-service: %d %s", e.StatusCode, msg)
+}
+
+func (e *APIError) Unwrap() error {
+	return e.sentinel
+}
+
+// classify picks the sentinel matching the response's code. Responses
+// without a known code, such as malformed request bodies, are classified
+// by status.
+func (e *APIError) classify(method string) {
+	if sentinel, ok := codeSentinels[e.Code]; ok {
+		e.sentinel = sentinel
+		return
+	}
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		e.sentinel = ErrProductNotFound
+	case http.StatusBadRequest:
+		e.sentinel = ErrInvalidQuery
+	case http.StatusPreconditionFailed:
+		e.sentinel = ErrPreconditionFailed
+	case http.StatusConflict:
+		e.sentinel = ErrConflict
+	case http.StatusUnauthorized:
+		e.sentinel = ErrUnauthorized
+	case http.StatusForbidden:
+		if method == http.MethodDelete {
+			e.sentinel = ErrHardDeleteDisabled
+		}
+	}
+}
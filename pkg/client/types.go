@@ -0,0 +1,120 @@
+package client
+
+import "time"
+
+// Product is a product as returned by the API.
+type Product struct {
+	ID             string                 `json:"id"`
+	Name           string                 `json:"name"`
+	Description    string                 `json:"description"`
+	Price          float64                `json:"price"`
+	Category       string                 `json:"category"`
+	CategoryPath   []string               `json:"category_path"`
+	SKU            string                 `json:"sku"`
+	Slug           string                 `json:"slug"`
+	Stock          float64                `json:"stock"`
+	Unit           string                 `json:"unit"`
+	AvailableFrom  *time.Time             `json:"available_from"`
+	WeightGrams    int                    `json:"weight_grams"`
+	LengthMM       int                    `json:"length_mm"`
+	WidthMM        int                    `json:"width_mm"`
+	HeightMM       int                    `json:"height_mm"`
+	BundleItems    []BundleItem           `json:"bundle_items"`
+	IsActive       bool                   `json:"is_active"`
+	Status         string                 `json:"status"`
+	Featured       bool                   `json:"featured"`
+	FeaturedRank   *int                   `json:"featured_rank"`
+	Tags           []string               `json:"tags"`
+	Images         []string               `json:"images"`
+	BrokenImages   []string               `json:"broken_images"`
+	Translations   map[string]Translation `json:"translations"`
+	ViewCount      int64                  `json:"view_count"`
+	AverageRating  float64                `json:"average_rating"`
+	RatingCount    int                    `json:"rating_count"`
+	CreatedAt      time.Time              `json:"created_at"`
+	UpdatedAt      time.Time              `json:"updated_at"`
+	CreatedBy      string                 `json:"created_by"`
+	UpdatedBy      string                 `json:"updated_by"`
+	Version        int64                  `json:"version"`
+	PriceUpdatedAt *time.Time             `json:"price_updated_at"`
+	StockUpdatedAt *time.Time             `json:"stock_updated_at"`
+	DeletedAt      *time.Time             `json:"deleted_at"`
+}
+
+// BundleItem is one component of a bundle.
+type BundleItem struct {
+	ProductID string `json:"product_id"`
+	Quantity  int    `json:"quantity"`
+}
+
+// Translation is a product's name and description in one locale.
+type Translation struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+}
+
+// CreateProductRequest creates a product. Leaving Stock nil gives the
+// product the server's default stock.
+type CreateProductRequest struct {
+	Name          string       `json:"name"`
+	Description   string       `json:"description,omitempty"`
+	Price         float64      `json:"price"`
+	Category      string       `json:"category"`
+	SKU           string       `json:"sku"`
+	Stock         *float64     `json:"stock,omitempty"`
+	Unit          string       `json:"unit,omitempty"`
+	AvailableFrom *time.Time   `json:"available_from,omitempty"`
+	WeightGrams   int          `json:"weight_grams,omitempty"`
+	LengthMM      int          `json:"length_mm,omitempty"`
+	WidthMM       int          `json:"width_mm,omitempty"`
+	HeightMM      int          `json:"height_mm,omitempty"`
+	BundleItems   []BundleItem `json:"bundle_items,omitempty"`
+	Tags          []string     `json:"tags,omitempty"`
+	Images        []string     `json:"images,omitempty"`
+}
+
+// UpdateProductRequest changes the fields that are set and leaves the rest
+// as they are.
+type UpdateProductRequest struct {
+	Name          *string       `json:"name,omitempty"`
+	Description   *string       `json:"description,omitempty"`
+	Price         *float64      `json:"price,omitempty"`
+	Category      *string       `json:"category,omitempty"`
+	SKU           *string       `json:"sku,omitempty"`
+	Stock         *float64      `json:"stock,omitempty"`
+	Unit          *string       `json:"unit,omitempty"`
+	AvailableFrom *time.Time    `json:"available_from,omitempty"`
+	WeightGrams   *int          `json:"weight_grams,omitempty"`
+	LengthMM      *int          `json:"length_mm,omitempty"`
+	WidthMM       *int          `json:"width_mm,omitempty"`
+	HeightMM      *int          `json:"height_mm,omitempty"`
+	BundleItems   *[]BundleItem `json:"bundle_items,omitempty"`
+	IsActive      *bool         `json:"is_active,omitempty"`
+	Tags          *[]string     `json:"tags,omitempty"`
+	Images        *[]string     `json:"images,omitempty"`
+}
+
+// ListOptions pages and sorts a listing. Zero values use the server's
+// defaults.
+type ListOptions struct {
+	Sort   string
+	Limit  int
+	Cursor string
+}
+
+// ProductList is one page of a listing. NextCursor is empty on the last
+// page.
+type ProductList struct {
+	Products   []Product `json:"products"`
+	Count      int       `json:"count"`
+	Truncated  bool      `json:"truncated"`
+	Pagination PageMeta  `json:"pagination"`
+}
+
+// PageMeta describes the page a listing returned.
+type PageMeta struct {
+	Limit      int    `json:"limit"`
+	Returned   int    `json:"returned"`
+	HasMore    bool   `json:"has_more"`
+	NextCursor string `json:"next_cursor"`
+}
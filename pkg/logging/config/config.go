@@ -8,8 +8,8 @@ type Config struct {
 
 func FromEnv() Config {
 	port := os.Getenv("PORT")
-	if port == ""{
-		port == "8080"
+	if port == "" {
+		port = "8080"
 	}
 	return Config{Addr: ":" + port}
-}
\ No newline at end of file
+}
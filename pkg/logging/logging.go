@@ -1,11 +1,12 @@
 package logging
 
-import(
+import (
 	"log/slog"
 	"os"
 )
 
-func New() *slog.Logger{
-	return *slog.New(slog.NewJsonHandler(os.Stdout, &slog.HandlerOptions{
-	Level: slog.LevelInfo,
-})) }
\ No newline at end of file
+func New() *slog.Logger {
+	return slog.New(slog.NewJSONHandler(os.Stdout, &slog.HandlerOptions{
+		Level: slog.LevelInfo,
+	}))
+}
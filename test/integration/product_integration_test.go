@@ -17,23 +17,28 @@ import (
 	"product-service/internal/models"
 )
 
+// floatPtr returns a pointer to v, for optional request fields.
+func floatPtr(v float64) *float64 {
+	return &v
+}
+
 type ProductIntegrationTestSuite struct {
 	suite.Suite
-	server *gin.Engine
+	server *httpserver.Server
 }
 
 func (suite *ProductIntegrationTestSuite) SetupSuite() {
 	gin.SetMode(gin.TestMode)
-	
+
 	os.Setenv("AWS_REGION", "us-east-1")
 	os.Setenv("PRODUCTS_TABLE", "test-products")
-	
+
 	server, err := httpserver.NewServer()
 	if err != nil {
 		suite.T().Skip("Skipping integration tests: unable to create server (likely missing AWS credentials)")
 		return
 	}
-	
+
 	suite.server = server
 }
 
@@ -67,7 +72,7 @@ func (suite *ProductIntegrationTestSuite) TestProductCRUDOperations() {
 		Price:       149.99,
 		Category:    "test",
 		SKU:         "INT-TEST-001",
-		Stock:       25,
+		Stock:       floatPtr(25),
 	}
 
 	reqBody, _ := json.Marshal(createReq)
@@ -176,4 +181,4 @@ func (suite *ProductIntegrationTestSuite) TestGetProductsByCategory() {
 
 func TestProductIntegrationTestSuite(t *testing.T) {
 	suite.Run(t, new(ProductIntegrationTestSuite))
-}
\ No newline at end of file
+}